@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"go-loyalty-system/internal/accrualsim"
+	"go-loyalty-system/pkg/httpserver"
+	"go-loyalty-system/pkg/logging"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	cfg, err := accrualsim.NewConfig()
+	if err != nil {
+		log.Fatalf("Config error: %s", err)
+	}
+	l, err := logging.NewZapLogger(0)
+	if err != nil {
+		log.Fatalf("Logger error: %s", err)
+	}
+	defer l.Sync()
+
+	rules := accrualsim.DefaultRules()
+	if cfg.RulesFile != "" {
+		if rules, err = accrualsim.LoadRules(cfg.RulesFile); err != nil {
+			log.Fatalf("Rules error: %s", err)
+		}
+	}
+
+	ctx := context.Background()
+	sim := accrualsim.NewServer(*cfg, rules, l)
+	server := httpserver.NewServer(sim.Handler(), httpserver.Address(cfg.Address))
+	l.InfoCtx(ctx, "accrual simulator started",
+		zap.String("address", cfg.Address),
+		zap.Duration("latency", cfg.Latency),
+		zap.Int("rate_limit", cfg.RateLimit),
+		zap.Float64("burst_rate", cfg.BurstRate),
+		zap.Float64("invalid_rate", cfg.InvalidRate),
+		zap.Int64("seed", cfg.Seed))
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case sig := <-sigChan:
+		l.InfoCtx(ctx, "received signal", zap.String("signal", sig.String()))
+	case err := <-server.Notify():
+		l.ErrorCtx(ctx, "server error", zap.Error(err))
+	}
+	if err := server.Shutdown(); err != nil {
+		l.ErrorCtx(ctx, "shutdown error", zap.Error(err))
+	}
+}
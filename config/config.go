@@ -3,7 +3,11 @@ package config
 import (
 	"context"
 	"flag"
+	"fmt"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"os"
 
@@ -15,12 +19,20 @@ import (
 
 type (
 	Config struct {
-		App     `yaml:"app"`
-		HTTP    `yaml:"http"`
-		Log     `yaml:"logger"`
-		PG      `yaml:"postgres"`
-		Jwt     `yaml:"jwt"`
-		Accrual `yaml:"accrual"`
+		App      `yaml:"app"`
+		HTTP     `yaml:"http"`
+		Log      `yaml:"logger"`
+		PG       `yaml:"postgres"`
+		Jwt      `yaml:"jwt"`
+		Accrual  `yaml:"accrual"`
+		Merchant `yaml:"merchant"`
+		Password `yaml:"password"`
+		Tracing  `yaml:"tracing"`
+		Audit    `yaml:"audit"`
+		Expiry   `yaml:"expiry"`
+		Holds    `yaml:"holds"`
+		Transfer `yaml:"transfer"`
+		Tiers    `yaml:"tiers"`
 	}
 
 	App struct {
@@ -43,12 +55,74 @@ type (
 	}
 
 	Jwt struct {
-		EncryptionKey string `json:"encryption_key" env:"AUTH_KEY"`
+		EncryptionKey string        `json:"encryption_key" env:"AUTH_KEY"`
+		AccessTTL     time.Duration `yaml:"access_ttl" env:"ACCESS_TOKEN_TTL"`
+		RefreshTTL    time.Duration `yaml:"refresh_ttl" env:"REFRESH_TOKEN_TTL"`
 	}
 
 	Accrual struct {
 		Accrual string `json:"Accrual" env:"ACCRUAL_SYSTEM_ADDRESS"`
 	}
+
+	Merchant struct {
+		APIKey string `yaml:"api_key" env:"MERCHANT_API_KEY"`
+	}
+
+	Password struct {
+		BcryptCost int `yaml:"bcrypt_cost" env:"BCRYPT_COST"`
+	}
+
+	// Tracing экспортер спанов: none, stdout или otlp
+	Tracing struct {
+		Exporter string `yaml:"exporter" env:"TRACING_EXPORTER"`
+		Endpoint string `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	}
+
+	// Audit файл, куда журнал аудита дублируется строками JSON; пусто — только база
+	Audit struct {
+		File string `yaml:"file" env:"AUDIT_LOG_FILE"`
+	}
+
+	// Expiry сгорание баллов: партия сгорает через Months месяцев после начисления,
+	// списать ее можно еще Grace. Months = 0 или Disabled — баллы бессрочные.
+	// Notice — за сколько до сгорания баллы попадают в expiring_soon,
+	// Interval — как часто запускается списание сгоревших баллов.
+	Expiry struct {
+		Months   int           `yaml:"months" env:"POINTS_EXPIRY_MONTHS"`
+		Grace    time.Duration `yaml:"grace" env:"POINTS_EXPIRY_GRACE"`
+		Notice   time.Duration `yaml:"notice" env:"POINTS_EXPIRY_NOTICE"`
+		Interval time.Duration `yaml:"interval" env:"POINTS_EXPIRY_INTERVAL"`
+		Disabled bool          `yaml:"disabled" env:"POINTS_EXPIRY_DISABLED"`
+	}
+
+	// Holds резервы баллов: TTL — сколько резерв ждет подтверждения,
+	// Interval — как часто отменяются просроченные резервы
+	Holds struct {
+		TTL      time.Duration `yaml:"ttl" env:"HOLD_TTL"`
+		Interval time.Duration `yaml:"interval" env:"HOLD_EXPIRY_INTERVAL"`
+	}
+
+	// Transfer суточные лимиты переводов между пользователями: сумма и число
+	// переводов, отправленных за последние 24 часа
+	Transfer struct {
+		DailyAmount float64 `yaml:"daily_amount" env:"TRANSFER_DAILY_AMOUNT"`
+		DailyCount  int     `yaml:"daily_count" env:"TRANSFER_DAILY_COUNT"`
+	}
+
+	// Tiers уровни участия по сумме начислений за последние 12 месяцев.
+	// В TIERS уровни задаются строкой "SILVER:1000:1.1,GOLD:5000:1.25";
+	// Disabled — начисления без множителей.
+	Tiers struct {
+		Levels   []Tier `yaml:"levels"`
+		Disabled bool   `yaml:"disabled" env:"TIERS_DISABLED"`
+	}
+
+	// Tier порог уровня в баллах и множитель начислений на нем
+	Tier struct {
+		Name       string  `yaml:"name"`
+		Threshold  float64 `yaml:"threshold"`
+		Multiplier float64 `yaml:"multiplier"`
+	}
 )
 
 func NewConfig() (*Config, error) {
@@ -94,6 +168,83 @@ func NewConfig() (*Config, error) {
 		cfg.Accrual.Accrual = accrual
 	}
 
+	if key := os.Getenv("MERCHANT_API_KEY"); key != "" {
+		cfg.Merchant.APIKey = key
+	}
+
+	if ttl, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL")); err == nil {
+		cfg.Jwt.AccessTTL = ttl
+	}
+
+	if ttl, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL")); err == nil {
+		cfg.Jwt.RefreshTTL = ttl
+	}
+
+	if cost, err := strconv.Atoi(os.Getenv("BCRYPT_COST")); err == nil {
+		cfg.Password.BcryptCost = cost
+	}
+
+	if exporter := os.Getenv("TRACING_EXPORTER"); exporter != "" {
+		cfg.Tracing.Exporter = exporter
+	}
+
+	if endpoint := os.Getenv("TRACING_OTLP_ENDPOINT"); endpoint != "" {
+		cfg.Tracing.Endpoint = endpoint
+	}
+
+	if file := os.Getenv("AUDIT_LOG_FILE"); file != "" {
+		cfg.Audit.File = file
+	}
+
+	if months, err := strconv.Atoi(os.Getenv("POINTS_EXPIRY_MONTHS")); err == nil {
+		cfg.Expiry.Months = months
+	}
+
+	if grace, err := time.ParseDuration(os.Getenv("POINTS_EXPIRY_GRACE")); err == nil {
+		cfg.Expiry.Grace = grace
+	}
+
+	if notice, err := time.ParseDuration(os.Getenv("POINTS_EXPIRY_NOTICE")); err == nil {
+		cfg.Expiry.Notice = notice
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv("POINTS_EXPIRY_INTERVAL")); err == nil {
+		cfg.Expiry.Interval = interval
+	}
+
+	if disabled, err := strconv.ParseBool(os.Getenv("POINTS_EXPIRY_DISABLED")); err == nil {
+		cfg.Expiry.Disabled = disabled
+	}
+
+	if ttl, err := time.ParseDuration(os.Getenv("HOLD_TTL")); err == nil {
+		cfg.Holds.TTL = ttl
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv("HOLD_EXPIRY_INTERVAL")); err == nil {
+		cfg.Holds.Interval = interval
+	}
+
+	if amount, err := strconv.ParseFloat(os.Getenv("TRANSFER_DAILY_AMOUNT"), 64); err == nil {
+		cfg.Transfer.DailyAmount = amount
+	}
+
+	if count, err := strconv.Atoi(os.Getenv("TRANSFER_DAILY_COUNT")); err == nil {
+		cfg.Transfer.DailyCount = count
+	}
+
+	if raw := os.Getenv("TIERS"); raw != "" {
+		levels, err := parseTiers(raw)
+		if err != nil {
+			// опечатка в уровнях не должна молча превращаться в другие множители
+			return nil, fmt.Errorf("config - TIERS: %w", err)
+		}
+		cfg.Tiers.Levels = levels
+	}
+
+	if disabled, err := strconv.ParseBool(os.Getenv("TIERS_DISABLED")); err == nil {
+		cfg.Tiers.Disabled = disabled
+	}
+
 	if cfg.HTTP.Address == "" {
 		cfg.HTTP.Address = ":8080"
 	}
@@ -109,6 +260,38 @@ func NewConfig() (*Config, error) {
 		cfg.Log.Level = "debug"
 	}
 
+	if cfg.Expiry.Notice == 0 {
+		cfg.Expiry.Notice = 30 * 24 * time.Hour
+	}
+
+	if cfg.Expiry.Interval == 0 {
+		cfg.Expiry.Interval = time.Hour
+	}
+
+	if cfg.Holds.TTL <= 0 {
+		cfg.Holds.TTL = 15 * time.Minute
+	}
+
+	if cfg.Holds.Interval <= 0 {
+		cfg.Holds.Interval = time.Minute
+	}
+
+	if cfg.Transfer.DailyAmount <= 0 {
+		cfg.Transfer.DailyAmount = 1000
+	}
+
+	if cfg.Transfer.DailyCount <= 0 {
+		cfg.Transfer.DailyCount = 10
+	}
+
+	if len(cfg.Tiers.Levels) == 0 {
+		cfg.Tiers.Levels = []Tier{
+			{Name: "SILVER", Threshold: 1000, Multiplier: 1.1},
+			{Name: "GOLD", Threshold: 5000, Multiplier: 1.25},
+			{Name: "PLATINUM", Threshold: 15000, Multiplier: 1.5},
+		}
+	}
+
 	logger.InfoCtx(context.Background(), "Starting server with parameters",
 		zap.String("address", cfg.HTTP.Address),
 		zap.String("database", cfg.PG.URL),
@@ -116,3 +299,24 @@ func NewConfig() (*Config, error) {
 
 	return cfg, nil
 }
+
+// parseTiers разбирает уровни из строки вида "SILVER:1000:1.1,GOLD:5000:1.25"
+func parseTiers(raw string) ([]Tier, error) {
+	var tiers []Tier
+	for _, item := range strings.Split(raw, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("tier %q: expected name:threshold:multiplier", item)
+		}
+		threshold, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("tier %q threshold: %w", item, err)
+		}
+		multiplier, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("tier %q multiplier: %w", item, err)
+		}
+		tiers = append(tiers, Tier{Name: parts[0], Threshold: threshold, Multiplier: multiplier})
+	}
+	return tiers, nil
+}
@@ -0,0 +1,106 @@
+// Package accrualsim implements an in-process simulator of the accrual system.
+package accrualsim
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAddress       = ":8081"
+	defaultLatency       = 2 * time.Second
+	defaultBurstDuration = 5 * time.Second
+)
+
+// Config настройки симулятора. Флаги совпадают с эталонным бинарником
+// системы начислений, поэтому симулятор можно подставить в автотесты.
+type Config struct {
+	Address string
+	// DatabaseURI принимается для совместимости с эталонным бинарником и не используется
+	DatabaseURI string
+	// RulesFile JSON-файл с начальными правилами вознаграждения
+	RulesFile string
+	// Latency время, за которое заказ проходит REGISTERED -> PROCESSING -> окончательный статус
+	Latency time.Duration
+	// RateLimit допустимое число запросов в минуту, 0 — без ограничения
+	RateLimit int
+	// BurstRate вероятность того, что запрос откроет серию ответов 429
+	BurstRate float64
+	// BurstDuration длительность серии ответов 429
+	BurstDuration time.Duration
+	// InvalidRate доля заказов, которые получат статус INVALID
+	InvalidRate float64
+	// Seed зерно генератора случайных чисел, 0 — от текущего времени
+	Seed int64
+}
+
+func NewConfig() (*Config, error) {
+	cfg := &Config{}
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	fs.StringVar(&cfg.Address, "a", envString("RUN_ADDRESS", defaultAddress), "RUN_ADDRESS")
+	fs.StringVar(&cfg.DatabaseURI, "d", envString("DATABASE_URI", ""), "Database URI (ignored)")
+	fs.StringVar(&cfg.RulesFile, "rules", envString("ACCRUAL_SIM_RULES", ""), "reward rules JSON file")
+	fs.DurationVar(&cfg.Latency, "latency", defaultLatency, "order processing latency")
+	fs.IntVar(&cfg.RateLimit, "rate-limit", 0, "requests per minute, 0 disables the limit")
+	fs.Float64Var(&cfg.BurstRate, "burst-rate", 0, "probability of starting a 429 burst")
+	fs.DurationVar(&cfg.BurstDuration, "burst-duration", defaultBurstDuration, "length of a 429 burst")
+	fs.Float64Var(&cfg.InvalidRate, "invalid-rate", 0, "share of orders that end INVALID")
+	fs.Int64Var(&cfg.Seed, "seed", 0, "random seed, 0 uses current time")
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		return nil, err
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	return cfg, nil
+}
+
+// applyEnv задает значения по умолчанию из окружения, флаги их переопределяют
+func applyEnv(cfg *Config) error {
+	var err error
+	if v := os.Getenv("ACCRUAL_SIM_LATENCY"); v != "" {
+		if cfg.Latency, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("ACCRUAL_SIM_LATENCY: %w", err)
+		}
+	}
+	if v := os.Getenv("ACCRUAL_SIM_RATE_LIMIT"); v != "" {
+		if cfg.RateLimit, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("ACCRUAL_SIM_RATE_LIMIT: %w", err)
+		}
+	}
+	if v := os.Getenv("ACCRUAL_SIM_BURST_RATE"); v != "" {
+		if cfg.BurstRate, err = strconv.ParseFloat(v, 64); err != nil {
+			return fmt.Errorf("ACCRUAL_SIM_BURST_RATE: %w", err)
+		}
+	}
+	if v := os.Getenv("ACCRUAL_SIM_BURST_DURATION"); v != "" {
+		if cfg.BurstDuration, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("ACCRUAL_SIM_BURST_DURATION: %w", err)
+		}
+	}
+	if v := os.Getenv("ACCRUAL_SIM_INVALID_RATE"); v != "" {
+		if cfg.InvalidRate, err = strconv.ParseFloat(v, 64); err != nil {
+			return fmt.Errorf("ACCRUAL_SIM_INVALID_RATE: %w", err)
+		}
+	}
+	if v := os.Getenv("ACCRUAL_SIM_SEED"); v != "" {
+		if cfg.Seed, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return fmt.Errorf("ACCRUAL_SIM_SEED: %w", err)
+		}
+	}
+	return nil
+}
+
+func envString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
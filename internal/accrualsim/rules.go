@@ -0,0 +1,86 @@
+package accrualsim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-loyalty-system/internal/entity"
+)
+
+type RewardType string
+
+const (
+	RewardPercent RewardType = "%"
+	RewardPoints  RewardType = "pt"
+)
+
+var (
+	ErrInvalidRule   = errors.New("invalid reward rule")
+	ErrRuleExists    = errors.New("reward rule already exists")
+	ErrInvalidReward = errors.New("invalid reward type")
+)
+
+// Rule механика вознаграждения: товары, в описании которых встречается Match,
+// получают Reward процентов от цены или Reward баллов.
+type Rule struct {
+	Match      string     `json:"match"`
+	Reward     float64    `json:"reward"`
+	RewardType RewardType `json:"reward_type"`
+}
+
+func (r Rule) validate() error {
+	if r.Match == "" || r.Reward < 0 {
+		return ErrInvalidRule
+	}
+	if r.RewardType != RewardPercent && r.RewardType != RewardPoints {
+		return ErrInvalidReward
+	}
+	return nil
+}
+
+// DefaultRules используются, если файл правил не задан: 5% на любой товар
+func DefaultRules() []Rule {
+	return []Rule{{Match: "", Reward: 5, RewardType: RewardPercent}}
+}
+
+// LoadRules читает правила из JSON-файла
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("LoadRules: %w", err)
+	}
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("LoadRules: %w", err)
+	}
+	for _, r := range rules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("LoadRules %q: %w", r.Match, err)
+		}
+	}
+	return rules, nil
+}
+
+// calculate считает начисление по заказу. К товару применяется первое подходящее
+// правило, процент округляется до сотых.
+func calculate(rules []Rule, goods []entity.Product) entity.Points {
+	var total entity.Points
+	for _, p := range goods {
+		for _, r := range rules {
+			if !strings.Contains(strings.ToLower(p.Description), strings.ToLower(r.Match)) {
+				continue
+			}
+			switch r.RewardType {
+			case RewardPercent:
+				total = total.Add(p.Price.Percent(r.Reward))
+			case RewardPoints:
+				total = total.Add(entity.PointsFromFloat(r.Reward))
+			}
+			break
+		}
+	}
+	return total
+}
@@ -0,0 +1,218 @@
+package accrualsim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type order struct {
+	registeredAt time.Time
+	invalid      bool
+	accrual      entity.Points
+}
+
+// Server in-memory реализация протокола системы начислений
+type Server struct {
+	cfg    Config
+	logger *logging.ZapLogger
+	now    func() time.Time
+
+	mu          sync.Mutex
+	rnd         *rand.Rand
+	rules       []Rule
+	orders      map[string]*order
+	windowStart time.Time
+	windowCount int
+	burstUntil  time.Time
+}
+
+func NewServer(cfg Config, rules []Rule, l *logging.ZapLogger) *Server {
+	return &Server{
+		cfg:    cfg,
+		logger: l,
+		now:    time.Now,
+		rnd:    rand.New(rand.NewSource(cfg.Seed)),
+		rules:  append([]Rule(nil), rules...),
+		orders: make(map[string]*order),
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	r.POST("/api/goods", s.registerRule)
+	orders := r.Group("/api/orders", s.rateLimit)
+	{
+		orders.POST("", s.registerOrder)
+		orders.GET("/:number", s.getOrder)
+	}
+	return r
+}
+
+// rateLimit отвечает 429, пока идет серия отказов или исчерпан лимит запросов в минуту
+func (s *Server) rateLimit(c *gin.Context) {
+	wait, limited := s.throttled()
+	if !limited {
+		c.Next()
+		return
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	if s.cfg.RateLimit > 0 {
+		c.String(http.StatusTooManyRequests, "No more than %d requests per minute allowed", s.cfg.RateLimit)
+	} else {
+		c.String(http.StatusTooManyRequests, "Too many requests")
+	}
+	c.Abort()
+}
+
+func (s *Server) throttled() (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Before(s.burstUntil) {
+		return s.burstUntil.Sub(now), true
+	}
+	if s.cfg.BurstRate > 0 && s.rnd.Float64() < s.cfg.BurstRate {
+		s.burstUntil = now.Add(s.cfg.BurstDuration)
+		return s.cfg.BurstDuration, true
+	}
+	if s.cfg.RateLimit <= 0 {
+		return 0, false
+	}
+	if now.Sub(s.windowStart) >= time.Minute {
+		s.windowStart = now
+		s.windowCount = 0
+	}
+	s.windowCount++
+	if s.windowCount > s.cfg.RateLimit {
+		return s.windowStart.Add(time.Minute).Sub(now), true
+	}
+	return 0, false
+}
+
+func (s *Server) registerOrder(c *gin.Context) {
+	var req entity.AccrualOrder
+	if err := c.ShouldBindJSON(&req); err != nil || !validOrderNumber(req.Order) {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.orders[req.Order]; ok {
+		c.Status(http.StatusConflict)
+		return
+	}
+	s.orders[req.Order] = &order{
+		registeredAt: s.now(),
+		invalid:      s.cfg.InvalidRate > 0 && s.rnd.Float64() < s.cfg.InvalidRate,
+		accrual:      calculate(s.rules, req.Goods),
+	}
+	s.logger.InfoCtx(c.Request.Context(), "order registered", zap.String("order", req.Order))
+	c.Status(http.StatusAccepted)
+}
+
+func (s *Server) getOrder(c *gin.Context) {
+	number := c.Param("number")
+
+	s.mu.Lock()
+	o, ok := s.orders[number]
+	s.mu.Unlock()
+	if !ok {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	resp := entity.AccrualResponse{Order: number, Status: s.status(o)}
+	if resp.Status == entity.AccrualStatusProcessed {
+		resp.Accrual = o.accrual
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// status заказ проводит первую половину Latency в REGISTERED, вторую в PROCESSING
+func (s *Server) status(o *order) string {
+	elapsed := s.now().Sub(o.registeredAt)
+	switch {
+	case elapsed < s.cfg.Latency/2:
+		return entity.AccrualStatusRegistered
+	case elapsed < s.cfg.Latency:
+		return entity.AccrualStatusProcessing
+	case o.invalid:
+		return entity.AccrualStatusInvalid
+	}
+	return entity.AccrualStatusProcessed
+}
+
+func (s *Server) registerRule(c *gin.Context) {
+	var rule Rule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	if err := s.AddRule(rule); err != nil {
+		switch {
+		case errors.Is(err, ErrRuleExists):
+			c.Status(http.StatusConflict)
+		default:
+			c.Status(http.StatusBadRequest)
+		}
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// AddRule регистрирует новую механику вознаграждения
+func (s *Server) AddRule(rule Rule) error {
+	if err := rule.validate(); err != nil {
+		return fmt.Errorf("AddRule: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.rules {
+		if r.Match == rule.Match {
+			return fmt.Errorf("AddRule: %w", ErrRuleExists)
+		}
+	}
+	s.rules = append(s.rules, rule)
+	return nil
+}
+
+// validOrderNumber проверяет номер заказа алгоритмом Луна
+func validOrderNumber(number string) bool {
+	if number == "" {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		if number[i] < '0' || number[i] > '9' {
+			return false
+		}
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
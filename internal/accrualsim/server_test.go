@@ -0,0 +1,188 @@
+package accrualsim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"go-loyalty-system/internal/controller/accrual"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOrder = "12345678903"
+
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func setupServer(t *testing.T, cfg Config, rules []Rule) (*Server, *testClock, *httptest.Server) {
+	log, _ := logging.NewZapLogger(1)
+	clock := &testClock{now: time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)}
+	sim := NewServer(cfg, rules, log)
+	sim.now = clock.Now
+	ts := httptest.NewServer(sim.Handler())
+	t.Cleanup(ts.Close)
+	return sim, clock, ts
+}
+
+func newClient(url string) *accrual.HTTPClient {
+	log, _ := logging.NewZapLogger(1)
+	return accrual.NewHTTPClient(url, log)
+}
+
+func postJSON(t *testing.T, url string, body any) *http.Response {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestCalculate(t *testing.T) {
+	rules := []Rule{
+		{Match: "Bork", Reward: 10, RewardType: RewardPercent},
+		{Match: "LG", Reward: 150, RewardType: RewardPoints},
+	}
+	goods := []entity.Product{
+		{Description: "Чайник Bork", Price: entity.NewPoints(7000, 0)},
+		{Description: "Телевизор lg", Price: entity.NewPoints(50000, 0)},
+		{Description: "Утюг Philips", Price: entity.NewPoints(3000, 0)},
+	}
+	assert.Equal(t, entity.NewPoints(850, 0), calculate(rules, goods))
+	assert.Equal(t, entity.Points(0), calculate(nil, goods))
+	assert.Equal(t, entity.NewPoints(0, 33), calculate([]Rule{{Match: "a", Reward: 33.333, RewardType: RewardPercent}},
+		[]entity.Product{{Description: "a", Price: entity.NewPoints(1, 0)}}))
+}
+
+func TestServer(t *testing.T) {
+	t.Run("order goes through statuses", func(t *testing.T) {
+		_, clock, ts := setupServer(t, Config{Latency: 2 * time.Second}, DefaultRules())
+		client := newClient(ts.URL)
+		ctx := context.Background()
+
+		_, err := client.GetOrderAccrual(ctx, testOrder)
+		assert.ErrorIs(t, err, accrual.ErrOrderNotRegistered)
+
+		require.NoError(t, client.RegisterOrder(ctx, entity.AccrualOrder{
+			Order: testOrder,
+			Goods: []entity.Product{{Description: "Чайник", Price: entity.NewPoints(1000, 0)}},
+		}))
+
+		resp, err := client.GetOrderAccrual(ctx, testOrder)
+		require.NoError(t, err)
+		assert.Equal(t, entity.AccrualStatusRegistered, resp.Status)
+
+		clock.Advance(time.Second)
+		resp, err = client.GetOrderAccrual(ctx, testOrder)
+		require.NoError(t, err)
+		assert.Equal(t, entity.AccrualStatusProcessing, resp.Status)
+
+		clock.Advance(time.Second)
+		resp, err = client.GetOrderAccrual(ctx, testOrder)
+		require.NoError(t, err)
+		assert.Equal(t, entity.AccrualStatusProcessed, resp.Status)
+		assert.Equal(t, entity.NewPoints(50, 0), resp.Accrual)
+	})
+
+	t.Run("order registration errors", func(t *testing.T) {
+		_, _, ts := setupServer(t, Config{}, DefaultRules())
+		order := entity.AccrualOrder{Order: testOrder}
+
+		assert.Equal(t, http.StatusAccepted, postJSON(t, ts.URL+"/api/orders", order).StatusCode)
+		assert.Equal(t, http.StatusConflict, postJSON(t, ts.URL+"/api/orders", order).StatusCode)
+		assert.Equal(t, http.StatusBadRequest,
+			postJSON(t, ts.URL+"/api/orders", entity.AccrualOrder{Order: "12345678904"}).StatusCode)
+	})
+
+	t.Run("every order invalid", func(t *testing.T) {
+		_, _, ts := setupServer(t, Config{InvalidRate: 1}, DefaultRules())
+		client := newClient(ts.URL)
+		ctx := context.Background()
+
+		require.NoError(t, client.RegisterOrder(ctx, entity.AccrualOrder{Order: testOrder}))
+		resp, err := client.GetOrderAccrual(ctx, testOrder)
+		require.NoError(t, err)
+		assert.Equal(t, entity.AccrualStatusInvalid, resp.Status)
+		assert.Zero(t, resp.Accrual)
+	})
+
+	t.Run("goods rules", func(t *testing.T) {
+		_, _, ts := setupServer(t, Config{}, nil)
+		rule := Rule{Match: "Bork", Reward: 10, RewardType: RewardPercent}
+
+		assert.Equal(t, http.StatusOK, postJSON(t, ts.URL+"/api/goods", rule).StatusCode)
+		assert.Equal(t, http.StatusConflict, postJSON(t, ts.URL+"/api/goods", rule).StatusCode)
+		assert.Equal(t, http.StatusBadRequest,
+			postJSON(t, ts.URL+"/api/goods", Rule{Match: "LG", Reward: 1, RewardType: "x"}).StatusCode)
+
+		client := newClient(ts.URL)
+		ctx := context.Background()
+		require.NoError(t, client.RegisterOrder(ctx, entity.AccrualOrder{
+			Order: testOrder,
+			Goods: []entity.Product{{Description: "Bork", Price: entity.NewPoints(500, 0)}},
+		}))
+		resp, err := client.GetOrderAccrual(ctx, testOrder)
+		require.NoError(t, err)
+		assert.Equal(t, entity.NewPoints(50, 0), resp.Accrual)
+	})
+
+	t.Run("rate limit per minute", func(t *testing.T) {
+		_, clock, ts := setupServer(t, Config{RateLimit: 2}, DefaultRules())
+		client := newClient(ts.URL)
+		ctx := context.Background()
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetOrderAccrual(ctx, testOrder)
+			assert.ErrorIs(t, err, accrual.ErrOrderNotRegistered)
+		}
+
+		clock.Advance(20 * time.Second)
+		_, err := client.GetOrderAccrual(ctx, testOrder)
+		var rateErr *accrual.RateLimitError
+		require.True(t, errors.As(err, &rateErr))
+		assert.Equal(t, 40*time.Second, rateErr.RetryAfter)
+
+		clock.Advance(40 * time.Second)
+		_, err = client.GetOrderAccrual(ctx, testOrder)
+		assert.ErrorIs(t, err, accrual.ErrOrderNotRegistered)
+	})
+
+	t.Run("429 burst", func(t *testing.T) {
+		_, clock, ts := setupServer(t, Config{BurstRate: 1, BurstDuration: 3 * time.Second}, DefaultRules())
+		client := newClient(ts.URL)
+		ctx := context.Background()
+
+		var rateErr *accrual.RateLimitError
+		err := client.RegisterOrder(ctx, entity.AccrualOrder{Order: testOrder})
+		require.True(t, errors.As(err, &rateErr))
+		assert.Equal(t, 3*time.Second, rateErr.RetryAfter)
+
+		clock.Advance(time.Second)
+		_, err = client.GetOrderAccrual(ctx, testOrder)
+		require.True(t, errors.As(err, &rateErr))
+		assert.Equal(t, 2*time.Second, rateErr.RetryAfter)
+	})
+}
@@ -5,20 +5,26 @@ import (
 	"errors"
 	"fmt"
 	"go-loyalty-system/config"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"go-loyalty-system/internal/audit"
 	v1 "go-loyalty-system/internal/controller/http"
 	"go-loyalty-system/internal/controller/http/middleware"
 	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/health"
+	"go-loyalty-system/internal/metrics"
 	"go-loyalty-system/internal/usecase"
 	"go-loyalty-system/internal/usecase/repo"
 	"go-loyalty-system/pkg/httpserver"
 	"go-loyalty-system/pkg/logging"
 	"go-loyalty-system/pkg/postgres"
+	"go-loyalty-system/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -28,6 +34,10 @@ const (
 	shutdownTimeout = 5 * time.Second
 	readTimeout     = 10 * time.Second
 	writeTimeout    = 10 * time.Second
+	// readinessDrainDelay сколько /readyz отвечает отказом до закрытия сервера,
+	// чтобы балансировщик успел снять трафик
+	readinessDrainDelay = 2 * time.Second
+	readinessTimeout    = 2 * time.Second
 )
 
 type App struct {
@@ -35,6 +45,10 @@ type App struct {
 	logger     *logging.ZapLogger
 	httpServer *http.Server
 	postgres   *postgres.Postgres
+	health     *health.Checker
+	auditFile  *os.File
+	// shutdownTracing досылает накопленные спаны
+	shutdownTracing func(context.Context) error
 }
 
 // NewApp создает новый экземпляр приложения
@@ -48,37 +62,143 @@ func NewApp(cfg *config.Config) (*App, error) {
 		panic(err)
 	}
 
-	initPostgres(cfg.PG.URL)
+	serviceName := cfg.App.Name
+	if serviceName == "" {
+		serviceName = "gophermart"
+	}
+	shutdownTracing, err := tracing.Setup(ctx, serviceName, cfg.Tracing.Exporter, cfg.Tracing.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("app - NewApp - tracing.Setup: %w", err)
+	}
+
+	tierPolicy, err := newTierPolicy(cfg.Tiers)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaVersion := initPostgres(cfg.PG.URL)
 
 	pg, err := postgres.NewPostgres(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.PoolMax))
 	if err != nil {
-		log.FatalCtx(ctx, "app - Run - postgres.New: %w", zap.Error(err))
+		log.FatalCtx(ctx, "app - Run - postgres.New", zap.Error(err))
 	}
 
 	userRepo := repo.NewUserrepository(pg, log, pg.Pool)
 	balanceRepo := repo.NewBalanceRepository(pg, log, pg.Pool)
 	orderRepo := repo.NewOrderepository(pg, log, pg.Pool)
 	accrualRepo := repo.NewOrderAccrualRepository(pg, log, pg.Pool)
-	uc := usecase.NewGopherMart(accrualRepo, balanceRepo, orderRepo, userRepo, log)
-
-	j := security.NewJwtToken(cfg.Jwt.EncryptionKey, *uc)
-	a := middleware.NewAuthorizer(log)
-
-	accrual := NewPoolController(*uc, cfg.Accrual.Accrual, log)
+	idempotencyRepo := repo.NewIdempotencyRepository(pg, log, pg.Pool)
+	auditFile, err := openAuditFile(cfg.Audit.File)
+	if err != nil {
+		return nil, err
+	}
+	auditLog := audit.NewRecorder(repo.NewAuditRepository(pg, log, pg.Pool), auditMirror(auditFile), log)
+	uc := usecase.NewGopherMart(accrualRepo, balanceRepo, orderRepo, userRepo, log,
+		usecase.WithIdempotency(idempotencyRepo),
+		usecase.WithTransactor(repo.NewTransactor(pg, log, pg.Pool)),
+		usecase.WithPasswordCost(cfg.Password.BcryptCost),
+		usecase.WithTokens(repo.NewTokenRepository(pg, log, pg.Pool)),
+		usecase.WithAdminAudit(repo.NewAdminAuditRepository(pg, log, pg.Pool)),
+		usecase.WithOrderEvents(repo.NewOrderEventRepository(pg, log, pg.Pool)),
+		usecase.WithStats(repo.NewStatsRepository(pg, log, pg.Pool)),
+		usecase.WithAudit(auditLog),
+		usecase.WithPointExpiry(repo.NewPointLotRepository(pg, log, pg.Pool), entity.ExpiryPolicy{
+			Months:   cfg.Expiry.Months,
+			Grace:    cfg.Expiry.Grace,
+			Notice:   cfg.Expiry.Notice,
+			Disabled: cfg.Expiry.Disabled,
+		}),
+		usecase.WithHolds(repo.NewHoldRepository(pg, log, pg.Pool), cfg.Holds.TTL),
+		usecase.WithTransferLimits(entity.TransferLimits{
+			DailyAmount: entity.PointsFromFloat(cfg.Transfer.DailyAmount),
+			DailyCount:  cfg.Transfer.DailyCount,
+		}),
+		usecase.WithTiers(repo.NewTierRepository(pg, log, pg.Pool), tierPolicy))
+
+	reg := metrics.NewRegistry()
+	reg.MustRegister(pg.Collector(metrics.Namespace), metrics.NewLoyaltyCollector(uc))
+
+	j := security.NewJwtToken(cfg.Jwt.EncryptionKey, *uc,
+		security.AccessTTL(cfg.Jwt.AccessTTL), security.RefreshTTL(cfg.Jwt.RefreshTTL))
+	a := middleware.NewAuthorizer(uc, log)
+
+	accrual := NewPoolController(*uc, cfg.Accrual.Accrual, log, reg)
 	startPool(accrual)
 
+	healthRepo := repo.NewHealthRepository(pg, log, pg.Pool)
+	checker := health.NewChecker(readinessTimeout)
+	checker.Add("postgres", healthRepo.Ping)
+	checker.Add("migrations", health.Migrations(healthRepo, schemaVersion))
+	checker.Add("accrual_workers", accrual.CheckWorkers)
+	checker.Add("accrual_system", accrual.CheckAccrual)
+
 	handler := gin.New()
+	handler.Use(middleware.Metrics(reg), middleware.Tracing(), middleware.RequestID())
+	handler.GET("/metrics", metrics.Handler(reg))
+	handler.GET("/healthz", health.Liveness)
+	handler.GET("/readyz", checker.Readiness)
 	v1.NewRouter(handler, *uc, cfg, j, accrual, a, log)
-	httpServer := httpserver.NewServer(handler, httpserver.Port(cfg.HTTP.Port))
+	// потоки событий заказов, списание сгоревших баллов и отмена просроченных резервов
+	// останавливаются вместе с сервером
+	streamCtx, stopStreams := context.WithCancel(ctx)
+	httpServer := httpserver.NewServer(handler, httpserver.Port(cfg.HTTP.Port), httpserver.BaseContext(streamCtx))
+	httpServer.Server.RegisterOnShutdown(stopStreams)
+	go uc.ListenOrderEvents(streamCtx)
+	go uc.RunPointExpiry(streamCtx, cfg.Expiry.Interval)
+	go uc.RunHoldExpiry(streamCtx, cfg.Holds.Interval)
 
 	return &App{
 		cfg:        cfg,
 		logger:     log,
 		httpServer: httpServer.Server,
 		postgres:   pg,
+		health:     checker,
+		auditFile:  auditFile,
+
+		shutdownTracing: shutdownTracing,
 	}, nil
 }
 
+// openAuditFile открывает файл-зеркало журнала аудита на дозапись
+func openAuditFile(path string) (*os.File, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("app - NewApp - open audit file: %w", err)
+	}
+	return f, nil
+}
+
+// newTierPolicy уровни участия из конфигурации; выключенные уровни — пустая политика
+func newTierPolicy(c config.Tiers) (entity.TierPolicy, error) {
+	if c.Disabled {
+		return entity.TierPolicy{}, nil
+	}
+	tiers := make([]entity.Tier, 0, len(c.Levels))
+	for _, t := range c.Levels {
+		tiers = append(tiers, entity.Tier{
+			Name:       t.Name,
+			Threshold:  entity.PointsFromFloat(t.Threshold),
+			Multiplier: t.Multiplier,
+		})
+	}
+	policy, err := entity.NewTierPolicy(tiers)
+	if err != nil {
+		return entity.TierPolicy{}, fmt.Errorf("app - NewApp - tiers: %w", err)
+	}
+	return policy, nil
+}
+
+// auditMirror без файла зеркало не нужно: nil *os.File в io.Writer не был бы nil
+func auditMirror(f *os.File) io.Writer {
+	if f == nil {
+		return nil
+	}
+	return f
+}
+
 // Run запускает приложение
 func (a *App) Run(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
@@ -103,12 +223,22 @@ func (a *App) Run(ctx context.Context) error {
 // shutdown gracefully останавливает приложение
 func (a *App) shutdown() error {
 	a.logger.InfoCtx(context.Background(), "shutdown started")
+	a.health.Shutdown()
+	time.Sleep(readinessDrainDelay)
 
 	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 	if err := a.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("http server shutdown error: %w", err)
 	}
+	if err := a.shutdownTracing(ctx); err != nil {
+		a.logger.ErrorCtx(ctx, "tracing shutdown error", zap.Error(err))
+	}
+	if a.auditFile != nil {
+		if err := a.auditFile.Close(); err != nil {
+			a.logger.ErrorCtx(ctx, "audit file close error", zap.Error(err))
+		}
+	}
 
 	a.logger.InfoCtx(ctx, "shutdown completed")
 	return nil
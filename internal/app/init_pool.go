@@ -7,18 +7,24 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
 	numWorkers = 5
 )
 
-func NewPoolController(repo usecase.UserUseCase, address string, l *logging.ZapLogger) *accrual.OrderAccrual {
+func NewPoolController(repo usecase.UserUseCase,
+	address string,
+	l *logging.ZapLogger,
+	reg prometheus.Registerer) *accrual.OrderAccrual {
 	orderProcessor := accrual.NewOrderProcessor(
-		address,
+		accrual.NewHTTPClient(address, l),
 		numWorkers,
 		repo,
 		l,
+		accrual.WithMetrics(accrual.NewMetrics(reg)),
 	)
 	return orderProcessor
 }
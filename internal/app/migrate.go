@@ -18,7 +18,9 @@ const (
 	_countIterations = 15
 )
 
-func initPostgres(databaseURL string) {
+// initPostgres применяет миграции и возвращает версию схемы после них,
+// с которой сверяется проверка готовности
+func initPostgres(databaseURL string) uint {
 	var (
 		attempts = _defaultAttempts
 		err      error
@@ -26,7 +28,7 @@ func initPostgres(databaseURL string) {
 	)
 	currentDir, err := os.Getwd()
 	if err != nil {
-		return
+		return 0
 	}
 
 	log.Printf("Migrations path: %s", currentDir)
@@ -60,8 +62,13 @@ func initPostgres(databaseURL string) {
 	defer m.Close()
 	if errors.Is(err, migrate.ErrNoChange) {
 		log.Printf("Migrate: no change")
-		return
+	} else {
+		log.Printf("Migrate: up success")
 	}
 
-	log.Printf("Migrate: up success")
+	version, _, err := m.Version()
+	if err != nil {
+		log.Printf("Migrate: version error: %s", err)
+	}
+	return version
 }
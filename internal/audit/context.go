@@ -0,0 +1,23 @@
+package audit
+
+import "context"
+
+// Source откуда пришел запрос, вызвавший событие
+type Source struct {
+	IP        string
+	RequestID string
+}
+
+type sourceKey struct{}
+
+// WithSource запоминает источник запроса в контексте. Кладется middleware,
+// чтобы сценариям не приходилось передавать IP и ID запроса явно.
+func WithSource(ctx context.Context, s Source) context.Context {
+	return context.WithValue(ctx, sourceKey{}, s)
+}
+
+// SourceFrom источник запроса из контекста; пустой для фоновых задач
+func SourceFrom(ctx context.Context) Source {
+	s, _ := ctx.Value(sourceKey{}).(Source)
+	return s
+}
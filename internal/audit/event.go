@@ -0,0 +1,111 @@
+// Package audit ведет журнал событий, важных для безопасности и движения баллов.
+// Журнал хранится в append-only таблице audit_events и может дублироваться
+// в файл JSON lines для отправки во внешнюю систему.
+package audit
+
+import (
+	"fmt"
+	"go-loyalty-system/internal/entity"
+	"time"
+)
+
+// Type тип события журнала
+type Type string
+
+const (
+	UserRegistered     Type = "USER_REGISTERED"
+	LoginSucceeded     Type = "LOGIN_SUCCEEDED"
+	LoginFailed        Type = "LOGIN_FAILED"
+	TokenIssued        Type = "TOKEN_ISSUED"
+	WithdrawalCreated  Type = "WITHDRAWAL_CREATED"
+	AccrualCredited    Type = "ACCRUAL_CREDITED"
+	BalanceAdjusted    Type = "BALANCE_ADJUSTED"
+	WithdrawalReversed Type = "WITHDRAWAL_REVERSED"
+	PointsExpired      Type = "POINTS_EXPIRED"
+	HoldCreated        Type = "HOLD_CREATED"
+	HoldCaptured       Type = "HOLD_CAPTURED"
+	HoldReleased       Type = "HOLD_RELEASED"
+	PointsTransferred  Type = "POINTS_TRANSFERRED"
+	TierBonusCredited  Type = "TIER_BONUS_CREDITED"
+	TierChanged        Type = "TIER_CHANGED"
+)
+
+var knownTypes = map[Type]struct{}{
+	UserRegistered:     {},
+	LoginSucceeded:     {},
+	LoginFailed:        {},
+	TokenIssued:        {},
+	WithdrawalCreated:  {},
+	AccrualCredited:    {},
+	BalanceAdjusted:    {},
+	WithdrawalReversed: {},
+	PointsExpired:      {},
+	HoldCreated:        {},
+	HoldCaptured:       {},
+	HoldReleased:       {},
+	PointsTransferred:  {},
+	TierBonusCredited:  {},
+	TierChanged:        {},
+}
+
+// Valid сообщает, известен ли тип события
+func (t Type) Valid() bool {
+	_, ok := knownTypes[t]
+	return ok
+}
+
+// Event запись журнала. ActorID — кто совершил действие (nil для системы
+// и неопознанных попыток входа), UserID — чья учетная запись или счет затронуты.
+// Суммы заполняются только для событий, двигающих баллы.
+type Event struct {
+	ID          int64             `json:"id"`
+	Type        Type              `json:"type"`
+	ActorID     *uint             `json:"actor_id,omitempty"`
+	UserID      *uint             `json:"user_id,omitempty"`
+	IP          string            `json:"ip,omitempty"`
+	RequestID   string            `json:"request_id,omitempty"`
+	OrderNumber string            `json:"order,omitempty"`
+	Amount      *entity.Points    `json:"amount,omitempty"`
+	Before      *entity.Points    `json:"balance_before,omitempty"`
+	After       *entity.Points    `json:"balance_after,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// Filter выборка журнала для админки. UserID отбирает события, где пользователь
+// был исполнителем или затронутым; статусы ListFilter к журналу не применяются.
+type Filter struct {
+	entity.ListFilter
+	Types     []Type
+	UserID    uint
+	RequestID string
+}
+
+// Normalize подставляет значения по умолчанию и проверяет фильтр
+func (f Filter) Normalize() (Filter, error) {
+	if len(f.Statuses) > 0 {
+		return f, fmt.Errorf("%w: audit events have no status", entity.ErrInvalidListFilter)
+	}
+	for _, t := range f.Types {
+		if !t.Valid() {
+			return f, fmt.Errorf("%w: unknown event type %q", entity.ErrInvalidListFilter, t)
+		}
+	}
+	lf, err := f.ListFilter.Normalize()
+	if err != nil {
+		return f, err
+	}
+	f.ListFilter = lf
+	return f, nil
+}
+
+// Page страница журнала и курсор следующей
+type Page struct {
+	Events     []Event
+	NextCursor string
+}
+
+// Ptr адрес копии значения для необязательных полей события
+func Ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-loyalty-system/pkg/logging"
+	"io"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Store хранилище журнала
+type Store interface {
+	CreateAuditEvent(ctx context.Context, e *Event) error
+	GetAuditEvents(ctx context.Context, f Filter) ([]Event, error)
+}
+
+// Recorder записывает события в хранилище и, если задано, дублирует их
+// в поток JSON lines. Источник истины — хранилище: ошибка записи в него
+// возвращается, ошибка зеркала только логируется.
+type Recorder struct {
+	store  Store
+	logger *logging.ZapLogger
+
+	mu     sync.Mutex
+	mirror io.Writer
+}
+
+// NewRecorder создает журнал. mirror может быть nil.
+func NewRecorder(store Store, mirror io.Writer, l *logging.ZapLogger) *Recorder {
+	return &Recorder{store: store, mirror: mirror, logger: l}
+}
+
+// Record дополняет событие IP и ID запроса из ctx и сохраняет его;
+// ID и время записи назначает хранилище.
+// В контексте от Defer событие попадает в зеркало только после фиксации транзакции.
+func (r *Recorder) Record(ctx context.Context, e Event) error {
+	src := SourceFrom(ctx)
+	if e.IP == "" {
+		e.IP = src.IP
+	}
+	if e.RequestID == "" {
+		e.RequestID = src.RequestID
+	}
+	if err := r.store.CreateAuditEvent(ctx, &e); err != nil {
+		return fmt.Errorf("audit - Record: %w", err)
+	}
+	if p, ok := ctx.Value(pendingKey{}).(*pending); ok {
+		p.add(e)
+		return nil
+	}
+	r.writeMirror(ctx, e)
+	return nil
+}
+
+type pendingKey struct{}
+
+// pending события транзакции, которые ждут фиксации, чтобы попасть в зеркало
+type pending struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (p *pending) add(e Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, e)
+}
+
+func (p *pending) take() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	events := p.events
+	p.events = nil
+	return events
+}
+
+// Defer откладывает запись в зеркало для событий, записанных с возвращенным контекстом.
+// done(true) после фиксации транзакции пишет их в зеркало, done(false) после отката
+// отбрасывает: иначе в зеркале остались бы события, которых нет в хранилище.
+// Во вложенной транзакции события ждут внешнюю, и done ничего не делает; без зеркала тоже.
+func (r *Recorder) Defer(ctx context.Context) (context.Context, func(committed bool)) {
+	if _, ok := ctx.Value(pendingKey{}).(*pending); ok || r.mirror == nil {
+		return ctx, func(bool) {}
+	}
+	p := &pending{}
+	return context.WithValue(ctx, pendingKey{}, p), func(committed bool) {
+		events := p.take()
+		if !committed {
+			return
+		}
+		for _, e := range events {
+			r.writeMirror(ctx, e)
+		}
+	}
+}
+
+// Events страница журнала, новые события первыми
+func (r *Recorder) Events(ctx context.Context, f Filter) ([]Event, error) {
+	events, err := r.store.GetAuditEvents(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("audit - Events: %w", err)
+	}
+	return events, nil
+}
+
+func (r *Recorder) writeMirror(ctx context.Context, e Event) {
+	if r.mirror == nil {
+		return
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		r.logger.ErrorCtx(ctx, "audit - mirror marshal", zap.Error(err))
+		return
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.mirror.Write(line); err != nil {
+		r.logger.ErrorCtx(ctx, "audit - mirror write", zap.Error(err), zap.String("type", string(e.Type)))
+	}
+}
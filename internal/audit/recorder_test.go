@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	events []Event
+	err    error
+}
+
+func (s *memoryStore) CreateAuditEvent(_ context.Context, e *Event) error {
+	if s.err != nil {
+		return s.err
+	}
+	e.ID = int64(len(s.events) + 1)
+	s.events = append(s.events, *e)
+	return nil
+}
+
+func (s *memoryStore) GetAuditEvents(context.Context, Filter) ([]Event, error) {
+	return s.events, s.err
+}
+
+func TestRecorder(t *testing.T) {
+	log, _ := logging.NewZapLogger(1)
+	ctx := WithSource(context.Background(), Source{IP: "203.0.113.7", RequestID: "req-1"})
+
+	t.Run("source is taken from the context and mirrored", func(t *testing.T) {
+		store := &memoryStore{}
+		var mirror bytes.Buffer
+		rec := NewRecorder(store, &mirror, log)
+
+		require.NoError(t, rec.Record(ctx, Event{Type: TokenIssued, UserID: Ptr(uint(7))}))
+		require.Len(t, store.events, 1)
+		assert.Equal(t, "203.0.113.7", store.events[0].IP)
+		assert.Equal(t, "req-1", store.events[0].RequestID)
+		assert.JSONEq(t, `{"id":1,"type":"TOKEN_ISSUED","user_id":7,"ip":"203.0.113.7","request_id":"req-1",`+
+			`"created_at":"0001-01-01T00:00:00Z"}`, mirror.String())
+	})
+
+	t.Run("explicit source wins", func(t *testing.T) {
+		store := &memoryStore{}
+		rec := NewRecorder(store, nil, log)
+
+		require.NoError(t, rec.Record(ctx, Event{Type: LoginFailed, IP: "198.51.100.1"}))
+		assert.Equal(t, "198.51.100.1", store.events[0].IP)
+		assert.Equal(t, "req-1", store.events[0].RequestID)
+	})
+
+	t.Run("event that failed to store is not mirrored", func(t *testing.T) {
+		var mirror bytes.Buffer
+		rec := NewRecorder(&memoryStore{err: errors.New("database error")}, &mirror, log)
+
+		assert.Error(t, rec.Record(ctx, Event{Type: TokenIssued}))
+		assert.Zero(t, mirror.Len())
+	})
+}
+
+func TestRecorderDefer(t *testing.T) {
+	log, _ := logging.NewZapLogger(1)
+	ctx := context.Background()
+
+	t.Run("events reach the mirror after commit", func(t *testing.T) {
+		store := &memoryStore{}
+		var mirror bytes.Buffer
+		rec := NewRecorder(store, &mirror, log)
+
+		txCtx, done := rec.Defer(ctx)
+		require.NoError(t, rec.Record(txCtx, Event{Type: AccrualCredited}))
+		require.NoError(t, rec.Record(txCtx, Event{Type: TierBonusCredited}))
+		assert.Len(t, store.events, 2)
+		assert.Zero(t, mirror.Len())
+
+		done(true)
+		assert.Equal(t, 2, bytes.Count(mirror.Bytes(), []byte("\n")))
+		assert.Contains(t, mirror.String(), `"type":"ACCRUAL_CREDITED"`)
+	})
+
+	t.Run("rolled back events are dropped", func(t *testing.T) {
+		var mirror bytes.Buffer
+		rec := NewRecorder(&memoryStore{}, &mirror, log)
+
+		txCtx, done := rec.Defer(ctx)
+		require.NoError(t, rec.Record(txCtx, Event{Type: AccrualCredited}))
+		done(false)
+		assert.Zero(t, mirror.Len())
+	})
+
+	t.Run("nested transaction waits for the outer one", func(t *testing.T) {
+		var mirror bytes.Buffer
+		rec := NewRecorder(&memoryStore{}, &mirror, log)
+
+		outer, doneOuter := rec.Defer(ctx)
+		inner, doneInner := rec.Defer(outer)
+		require.NoError(t, rec.Record(inner, Event{Type: AccrualCredited}))
+		doneInner(true)
+		assert.Zero(t, mirror.Len())
+
+		doneOuter(true)
+		assert.Contains(t, mirror.String(), `"type":"ACCRUAL_CREDITED"`)
+	})
+}
+
+func TestFilterNormalize(t *testing.T) {
+	t.Run("known types", func(t *testing.T) {
+		f, err := Filter{Types: []Type{LoginFailed, WithdrawalCreated}}.Normalize()
+		require.NoError(t, err)
+		assert.Positive(t, f.Limit)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := Filter{Types: []Type{"DELETED"}}.Normalize()
+		assert.ErrorIs(t, err, entity.ErrInvalidListFilter)
+	})
+
+	t.Run("statuses do not apply", func(t *testing.T) {
+		f := Filter{}
+		f.Statuses = []entity.OrderStatus{entity.OrderStatusNew}
+		_, err := f.Normalize()
+		assert.ErrorIs(t, err, entity.ErrInvalidListFilter)
+	})
+}
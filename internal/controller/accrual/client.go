@@ -0,0 +1,167 @@
+package accrual
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/tracing"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+)
+
+var ErrOrderNotRegistered = errors.New("order is not registered in accrual system")
+
+// AccrualClient протокол системы расчета начислений, от которого зависит OrderAccrual.
+// При ответе 429 реализации возвращают *RateLimitError.
+type AccrualClient interface {
+	// RegisterOrder регистрирует заказ и его товары для расчета.
+	// Повторная регистрация уже известного заказа ошибкой не считается.
+	RegisterOrder(ctx context.Context, order entity.AccrualOrder) error
+	// GetOrderAccrual возвращает текущий статус расчета по заказу
+	GetOrderAccrual(ctx context.Context, orderNumber string) (*entity.AccrualResponse, error)
+}
+
+// pinger клиенты, умеющие проверить доступность системы начислений
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// HTTPClient реализует AccrualClient поверх HTTP API системы начислений
+type HTTPClient struct {
+	client  *http.Client
+	baseURL string
+	logger  *logging.ZapLogger
+}
+
+func NewHTTPClient(baseURL string, l *logging.ZapLogger) *HTTPClient {
+	return &HTTPClient{
+		client: &http.Client{
+			Timeout: defaultTimeout,
+		},
+		baseURL: baseURL,
+		logger:  l,
+	}
+}
+
+func (c *HTTPClient) RegisterOrder(ctx context.Context, order entity.AccrualOrder) error {
+	jsonData, err := json.Marshal(order)
+	if err != nil {
+		c.logger.ErrorCtx(ctx, "marshal error", zap.Error(err))
+		return err
+	}
+	c.logger.InfoCtx(ctx, "sending order data "+order.Order, zap.String("order", order.Order))
+	req, err := c.createRequest(ctx, http.MethodPost, "/api/orders", jsonData)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return c.logAndReturnError(ctx, "RegisterOrder - failed to send request", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted:
+		c.logger.InfoCtx(ctx, "The order has been successfully accepted for processing")
+		return nil
+	case http.StatusNoContent:
+		c.logger.InfoCtx(ctx, "order already registered")
+		return nil
+	case http.StatusConflict:
+		c.logger.InfoCtx(ctx, "The order is already processed")
+		return nil
+	case http.StatusTooManyRequests:
+		return rateLimitError(resp)
+	case http.StatusBadRequest:
+		return c.logAndReturnError(ctx, "RegisterOrder - bad request", errors.New("bad request"))
+	case http.StatusInternalServerError:
+		return c.logAndReturnError(ctx, "RegisterOrder - internal server error", errors.New("server error"))
+	}
+	return c.logAndReturnError(ctx, "RegisterOrder - unexpected status code", fmt.Errorf("unexpected status: %d", resp.StatusCode))
+}
+
+func (c *HTTPClient) GetOrderAccrual(ctx context.Context, orderNumber string) (*entity.AccrualResponse, error) {
+	req, err := c.createRequest(ctx, http.MethodGet, "/api/orders/"+orderNumber, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, c.logAndReturnError(ctx, "failed to get order info", err)
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var accrualResp entity.AccrualResponse
+	if err := json.NewDecoder(resp.Body).Decode(&accrualResp); err != nil {
+		return nil, c.logAndReturnError(ctx, "failed to decode response", err)
+	}
+	return &accrualResp, nil
+}
+
+func (c *HTTPClient) checkResponse(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNoContent:
+		return ErrOrderNotRegistered
+	case http.StatusTooManyRequests:
+		return rateLimitError(resp)
+	case http.StatusBadRequest:
+		return fmt.Errorf("bad request")
+	case http.StatusInternalServerError:
+		return fmt.Errorf("server error")
+	default:
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+}
+
+// Ping проверяет, что система начислений доступна. Годится любой HTTP-ответ:
+// отдельного эндпоинта здоровья у нее нет, а запросы к /api/orders
+// расходуют лимит запросов.
+func (c *HTTPClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL+"/", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("accrual system is unreachable: %w", err)
+	}
+	return resp.Body.Close()
+}
+
+func (c *HTTPClient) createRequest(ctx context.Context, method, path string, body []byte) (*http.Request, error) {
+	c.logger.InfoCtx(ctx, "creating request "+method+" "+c.baseURL+path)
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		c.logger.ErrorCtx(ctx, "create request error", zap.Error(err))
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	tracing.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return req, nil
+}
+
+func (c *HTTPClient) logAndReturnError(ctx context.Context, method string, err error) error {
+	msg := fmt.Sprintf("%s - %s: %v", "Accrual", method, err)
+	c.logger.ErrorCtx(ctx, msg, zap.Error(err))
+	return err
+}
+
+func rateLimitError(resp *http.Response) error {
+	return &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())}
+}
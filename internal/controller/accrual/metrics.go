@@ -0,0 +1,84 @@
+package accrual
+
+import (
+	"go-loyalty-system/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const subsystem = "accrual"
+
+// Metrics показатели конвейера начислений
+type Metrics struct {
+	ordersSent  prometheus.Counter
+	results     *prometheus.CounterVec
+	retries     *prometheus.CounterVec
+	failed      prometheus.Counter
+	rateLimited prometheus.Counter
+	wakeDropped prometheus.Counter
+	inFlight    prometheus.Gauge
+	processing  *prometheus.HistogramVec
+}
+
+// NewMetrics создает показатели конвейера и регистрирует их в reg.
+// С nil показатели считаются, но никуда не отдаются.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		ordersSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metrics.Namespace, Subsystem: subsystem,
+			Name: "orders_sent_total",
+			Help: "Orders registered in the accrual system.",
+		}),
+		results: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace, Subsystem: subsystem,
+			Name: "results_total",
+			Help: "Accrual system responses by order status.",
+		}, []string{"status"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace, Subsystem: subsystem,
+			Name: "retries_total",
+			Help: "Jobs rescheduled after an error, by failed stage.",
+		}, []string{"stage"}),
+		failed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metrics.Namespace, Subsystem: subsystem,
+			Name: "jobs_failed_total",
+			Help: "Jobs given up after exhausting attempts.",
+		}),
+		rateLimited: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metrics.Namespace, Subsystem: subsystem,
+			Name: "rate_limited_total",
+			Help: "429 responses from the accrual system.",
+		}),
+		wakeDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metrics.Namespace, Subsystem: subsystem,
+			Name: "wake_dropped_total",
+			Help: "Worker wakeups dropped because the wake channel was full.",
+		}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace, Subsystem: subsystem,
+			Name: "workers_in_flight",
+			Help: "Workers currently processing an order.",
+		}),
+		processing: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace, Subsystem: subsystem,
+			Name:    "order_processing_seconds",
+			Help:    "Time from order upload to the final accrual status.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+		}, []string{"status"}),
+	}
+	if reg != nil {
+		reg.MustRegister(m.ordersSent, m.results, m.retries, m.failed,
+			m.rateLimited, m.wakeDropped, m.inFlight, m.processing)
+	}
+	return m
+}
+
+// Option настраивает OrderAccrual
+type Option func(*OrderAccrual)
+
+// WithMetrics подключает показатели конвейера
+func WithMetrics(m *Metrics) Option {
+	return func(op *OrderAccrual) {
+		op.metrics = m
+	}
+}
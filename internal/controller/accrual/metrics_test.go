@@ -0,0 +1,84 @@
+package accrual
+
+import (
+	"context"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// resultClient отвечает заранее заданным результатом расчета
+type resultClient struct {
+	resp *entity.AccrualResponse
+	err  error
+}
+
+func (c *resultClient) RegisterOrder(context.Context, entity.AccrualOrder) error {
+	return nil
+}
+
+func (c *resultClient) GetOrderAccrual(context.Context, string) (*entity.AccrualResponse, error) {
+	return c.resp, c.err
+}
+
+func setupMetricsProcessor(t *testing.T, client AccrualClient) (*OrderAccrual, *mocks.MockRepository, *Metrics) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	accrualRepo := mocks.NewMockRepository(ctrl)
+	uc := usecase.NewGopherMart(accrualRepo, mocks.NewMockBalanceUseCase(ctrl),
+		mocks.NewMockOrderUseCase(ctrl), mocks.NewMockAuthUseCase(ctrl), log)
+	m := NewMetrics(prometheus.NewRegistry())
+	op := NewOrderProcessor(client, 1, *uc, log, WithMetrics(m))
+	t.Cleanup(op.cancel)
+	return op, accrualRepo, m
+}
+
+func TestAccrualMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("final status is counted with processing time", func(t *testing.T) {
+		client := &resultClient{resp: &entity.AccrualResponse{
+			Order: "12345678903", Status: entity.AccrualStatusProcessed, Accrual: entity.NewPoints(500, 0),
+		}}
+		op, repo, m := setupMetricsProcessor(t, client)
+		job := &entity.AccrualJob{ID: 1, OrderNumber: "12345678903", CreatedAt: time.Now().Add(-time.Minute)}
+		repo.EXPECT().ExistOrderAccrual(gomock.Any(), job.OrderNumber).Return(false, nil)
+		repo.EXPECT().SaveAccrual(gomock.Any(), job.OrderNumber, entity.AccrualStatusProcessed, entity.NewPoints(500, 0)).Return(nil)
+		repo.EXPECT().CompleteAccrualJob(gomock.Any(), job.ID).Return(nil)
+
+		op.processOrderResult(ctx, job)
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.results.WithLabelValues(entity.AccrualStatusProcessed)))
+		assert.Equal(t, 1, testutil.CollectAndCount(m.processing))
+		assert.Equal(t, 0.0, testutil.ToFloat64(m.retries.WithLabelValues("save accrual")))
+	})
+
+	t.Run("429 is counted and not retried", func(t *testing.T) {
+		client := &resultClient{err: &RateLimitError{RetryAfter: time.Second}}
+		op, repo, m := setupMetricsProcessor(t, client)
+		job := &entity.AccrualJob{ID: 2, OrderNumber: "12345678903"}
+		repo.EXPECT().PostponeAccrualJob(gomock.Any(), job.ID, time.Second).Return(nil)
+
+		op.processOrderResult(ctx, job)
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.rateLimited))
+		assert.Equal(t, 0.0, testutil.ToFloat64(m.retries.WithLabelValues("get result")))
+	})
+
+	t.Run("full wake channel drops the signal", func(t *testing.T) {
+		op, _, m := setupMetricsProcessor(t, &resultClient{})
+
+		op.queue.notify()
+		op.queue.notify()
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.wakeDropped))
+	})
+}
@@ -1,145 +1,138 @@
 package accrual
 
 import (
-	"container/list"
 	"context"
-	"sync"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/pkg/logging"
 	"time"
 
 	"go.uber.org/zap"
 )
 
-// OrderQueue представляет in-memory очередь заказов
-type OrderQueue struct {
-	items    *list.List
-	capacity int
-	mu       sync.RWMutex
-	notEmpty *sync.Cond
-	notFull  *sync.Cond
-	logger   *zap.Logger
+// JobQueue представляет внутрипроцессный фронт очереди заданий начисления.
+// Сами задания хранятся в таблице accrual_jobs и переживают рестарт,
+// а канал wake лишь будит локальных воркеров, чтобы новый заказ
+// не ждал очередного опроса базы.
+type JobQueue struct {
+	repo         usecase.UserUseCase
+	workerID     string
+	lease        time.Duration
+	pollInterval time.Duration
+	wake         chan struct{}
+	logger       *logging.ZapLogger
+	metrics      *Metrics
 }
 
-// QueueItem представляет элемент очереди
-type QueueItem struct {
-	OrderNumber string
-	Attempts    int
-	LastAttempt time.Time
+// NewJobQueue создает очередь заданий начисления
+func NewJobQueue(repo usecase.UserUseCase, workerID string, l *logging.ZapLogger) *JobQueue {
+	return &JobQueue{
+		repo:         repo,
+		workerID:     workerID,
+		lease:        jobLease,
+		pollInterval: pollInterval,
+		wake:         make(chan struct{}, 1),
+		logger:       l,
+		metrics:      NewMetrics(nil),
+	}
 }
 
-// NewOrderQueue создает новую очередь заказов
-func NewOrderQueue(capacity int, logger *zap.Logger) *OrderQueue {
-	q := &OrderQueue{
-		items:    list.New(),
-		capacity: capacity,
-		logger:   logger,
+// Enqueue сохраняет задание в базе и будит один из воркеров
+func (q *JobQueue) Enqueue(ctx context.Context, orderNumber string) error {
+	if err := q.repo.EnqueueAccrual(ctx, orderNumber); err != nil {
+		return err
 	}
-	q.notEmpty = sync.NewCond(&q.mu)
-	q.notFull = sync.NewCond(&q.mu)
-	return q
+	q.notify()
+	return nil
 }
 
-// Enqueue добавляет заказ в очередь
-func (q *OrderQueue) Enqueue(ctx context.Context, orderNumber string) error {
-	q.mu.Lock()
-	defer q.mu.Unlock()
+// Dequeue ждет и арендует следующее готовое к запуску задание
+func (q *JobQueue) Dequeue(ctx context.Context) (*entity.AccrualJob, error) {
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
 
-	// Проверяем, есть ли уже такой заказ в очереди
-	for e := q.items.Front(); e != nil; e = e.Next() {
-		if item := e.Value.(*QueueItem); item.OrderNumber == orderNumber {
-			q.logger.Debug("order already in queue",
-				zap.String("order", orderNumber),
-				zap.Int("attempts", item.Attempts))
-			return nil
+	for {
+		jobs, err := q.repo.ClaimAccrualJobs(ctx, q.workerID, 1, q.lease)
+		if err != nil && ctx.Err() == nil {
+			q.logger.ErrorCtx(ctx, "failed to claim accrual job", zap.Error(err))
+		}
+		if err == nil && len(jobs) > 0 {
+			// заданий может быть больше одного — будим соседа
+			q.notify()
+			return &jobs[0], nil
 		}
-	}
-
-	// Ждем, пока появится место в очереди
-	for q.items.Len() >= q.capacity {
-		done := make(chan struct{})
-		go func() {
-			q.notFull.Wait()
-			close(done)
-		}()
 
-		q.mu.Unlock()
 		select {
 		case <-ctx.Done():
-			q.mu.Lock()
-			return ctx.Err()
-		case <-done:
-			q.mu.Lock()
+			return nil, ctx.Err()
+		case <-q.wake:
+		case <-ticker.C:
 		}
 	}
-
-	item := &QueueItem{
-		OrderNumber: orderNumber,
-		Attempts:    0,
-		LastAttempt: time.Now(),
-	}
-	q.items.PushBack(item)
-	q.logger.Debug("order enqueued",
-		zap.String("order", orderNumber),
-		zap.Int("queue_size", q.items.Len()))
-
-	// Сигнализируем, что очередь не пуста
-	q.notEmpty.Signal()
-	return nil
 }
 
-// Dequeue извлекает заказ из очереди
-func (q *OrderQueue) Dequeue(ctx context.Context) (*QueueItem, error) {
-	q.mu.Lock()
-	defer q.mu.Unlock()
-
-	// Ждем, пока в очереди появятся элементы
-	for q.items.Len() == 0 {
-		done := make(chan struct{})
-		go func() {
-			q.notEmpty.Wait()
-			close(done)
-		}()
+// Complete помечает задание выполненным
+func (q *JobQueue) Complete(ctx context.Context, job *entity.AccrualJob) error {
+	return q.repo.CompleteAccrualJob(ctx, job.ID)
+}
 
-		q.mu.Unlock()
-		select {
-		case <-ctx.Done():
-			q.mu.Lock()
-			return nil, ctx.Err()
-		case <-done:
-			q.mu.Lock()
-		}
+// Retry возвращает задание в очередь с экспоненциальной задержкой,
+// а после maxAttempts попыток снимает его с обработки
+func (q *JobQueue) Retry(ctx context.Context, job *entity.AccrualJob, cause error) error {
+	if job.Attempts+1 >= maxAttempts {
+		q.metrics.failed.Inc()
+		q.logger.ErrorCtx(ctx, "accrual job failed, attempts exhausted",
+			zap.String("order", job.OrderNumber),
+			zap.Int("attempts", job.Attempts+1),
+			zap.Error(cause))
+		return q.repo.FailAccrualJob(ctx, job.ID, cause.Error())
 	}
+	return q.repo.RetryAccrualJob(ctx, job.ID, backoff(job.Attempts), cause.Error())
+}
 
-	element := q.items.Front()
-	item := element.Value.(*QueueItem)
-	q.items.Remove(element)
-
-	q.logger.Debug("order dequeued",
-		zap.String("order", item.OrderNumber),
-		zap.Int("queue_size", q.items.Len()))
+// Postpone откладывает задание, не считая это неудачной попыткой
+func (q *JobQueue) Postpone(ctx context.Context, job *entity.AccrualJob, delay time.Duration) error {
+	return q.repo.PostponeAccrualJob(ctx, job.ID, delay)
+}
 
-	// Сигнализируем, что в очереди появилось место
-	q.notFull.Signal()
-	return item, nil
+// MarkRegistered запоминает, что заказ принят системой начислений:
+// следующие опросы статуса не регистрируют его заново
+func (q *JobQueue) MarkRegistered(ctx context.Context, job *entity.AccrualJob) error {
+	if err := q.repo.SetAccrualJobRegistered(ctx, job.ID, true); err != nil {
+		return err
+	}
+	now := time.Now()
+	job.RegisteredAt = &now
+	return nil
 }
 
-// RetryOrder помещает заказ обратно в очередь с увеличенным счетчиком попыток
-func (q *OrderQueue) RetryOrder(ctx context.Context, item *QueueItem) error {
-	item.Attempts++
-	item.LastAttempt = time.Now()
-	return q.Enqueue(ctx, item.OrderNumber)
+// ResetRegistered сбрасывает отметку о регистрации, если система начислений
+// заказ не знает, чтобы следующая попытка отправила его снова
+func (q *JobQueue) ResetRegistered(ctx context.Context, job *entity.AccrualJob) error {
+	if err := q.repo.SetAccrualJobRegistered(ctx, job.ID, false); err != nil {
+		return err
+	}
+	job.RegisteredAt = nil
+	return nil
 }
 
-// Size возвращает текущий размер очереди
-func (q *OrderQueue) Size() int {
-	q.mu.RLock()
-	defer q.mu.RUnlock()
-	return q.items.Len()
+func (q *JobQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+		// воркеры уже разбужены
+		q.metrics.wakeDropped.Inc()
+	}
 }
 
-// Clear очищает очередь
-func (q *OrderQueue) Clear() {
-	q.mu.Lock()
-	defer q.mu.Unlock()
-	q.items.Init()
-	q.notFull.Broadcast()
+// backoff возвращает задержку перед попыткой номер attempts+1
+func backoff(attempts int) time.Duration {
+	delay := initialBackoff
+	for i := 0; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
 }
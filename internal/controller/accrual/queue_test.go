@@ -0,0 +1,111 @@
+package accrual
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupJobQueue(t *testing.T) (*JobQueue, *mocks.MockRepository) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	accrualRepo := mocks.NewMockRepository(ctrl)
+	uc := usecase.NewGopherMart(
+		accrualRepo,
+		mocks.NewMockBalanceUseCase(ctrl),
+		mocks.NewMockOrderUseCase(ctrl),
+		mocks.NewMockAuthUseCase(ctrl),
+		log)
+	q := NewJobQueue(*uc, "test-worker", log)
+	q.pollInterval = 10 * time.Millisecond
+	return q, accrualRepo
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, initialBackoff, backoff(0))
+	assert.Equal(t, 2*initialBackoff, backoff(1))
+	assert.Equal(t, 8*initialBackoff, backoff(3))
+	assert.Equal(t, maxBackoff, backoff(5))
+	assert.Equal(t, maxBackoff, backoff(100))
+}
+
+func TestJobQueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("enqueue wakes waiting worker", func(t *testing.T) {
+		q, repo := setupJobQueue(t)
+		job := entity.AccrualJob{ID: 1, OrderNumber: "12345678903"}
+
+		repo.EXPECT().EnqueueAccrualJob(ctx, job.OrderNumber, "").Return(nil)
+		repo.EXPECT().ClaimAccrualJobs(gomock.Any(), "test-worker", 1, jobLease).
+			Return([]entity.AccrualJob{job}, nil)
+
+		assert.NoError(t, q.Enqueue(ctx, job.OrderNumber))
+		claimed, err := q.Dequeue(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, job.OrderNumber, claimed.OrderNumber)
+	})
+
+	t.Run("dequeue polls until a job is due", func(t *testing.T) {
+		q, repo := setupJobQueue(t)
+		job := entity.AccrualJob{ID: 2, OrderNumber: "12345678903"}
+
+		gomock.InOrder(
+			repo.EXPECT().ClaimAccrualJobs(gomock.Any(), "test-worker", 1, jobLease).Return(nil, nil),
+			repo.EXPECT().ClaimAccrualJobs(gomock.Any(), "test-worker", 1, jobLease).
+				Return(nil, errors.New("database error")),
+			repo.EXPECT().ClaimAccrualJobs(gomock.Any(), "test-worker", 1, jobLease).
+				Return([]entity.AccrualJob{job}, nil),
+		)
+
+		claimed, err := q.Dequeue(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, job.ID, claimed.ID)
+	})
+
+	t.Run("dequeue stops on context cancel", func(t *testing.T) {
+		q, repo := setupJobQueue(t)
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		repo.EXPECT().ClaimAccrualJobs(gomock.Any(), "test-worker", 1, jobLease).Return(nil, nil).AnyTimes()
+
+		_, err := q.Dequeue(cctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("retry reschedules with backoff", func(t *testing.T) {
+		q, repo := setupJobQueue(t)
+		job := &entity.AccrualJob{ID: 3, OrderNumber: "12345678903", Attempts: 2}
+
+		repo.EXPECT().RetryAccrualJob(ctx, job.ID, backoff(2), "accrual unavailable").Return(nil)
+
+		assert.NoError(t, q.Retry(ctx, job, errors.New("accrual unavailable")))
+	})
+
+	t.Run("retry fails job when attempts exhausted", func(t *testing.T) {
+		q, repo := setupJobQueue(t)
+		job := &entity.AccrualJob{ID: 4, OrderNumber: "12345678903", Attempts: maxAttempts - 1}
+
+		repo.EXPECT().FailAccrualJob(ctx, job.ID, "accrual unavailable").Return(nil)
+
+		assert.NoError(t, q.Retry(ctx, job, errors.New("accrual unavailable")))
+	})
+
+	t.Run("postpone keeps attempt count", func(t *testing.T) {
+		q, repo := setupJobQueue(t)
+		job := &entity.AccrualJob{ID: 5, OrderNumber: "12345678903"}
+
+		repo.EXPECT().PostponeAccrualJob(ctx, job.ID, processingDelay).Return(nil)
+
+		assert.NoError(t, q.Postpone(ctx, job, processingDelay))
+	})
+}
@@ -0,0 +1,97 @@
+package accrual
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultRetryAfter = 60 * time.Second
+
+// RateLimitError возвращается, когда система начислений ответила 429
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %s", e.RetryAfter)
+}
+
+// Throttle общая для всех воркеров пауза. Система начислений при 429 ждет,
+// что клиент целиком прекратит запросы на время из Retry-After, поэтому
+// пауза ставится на весь OrderAccrual, а не на отдельный заказ.
+type Throttle struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+func NewThrottle() *Throttle {
+	return &Throttle{}
+}
+
+// Pause останавливает запросы на d. Более ранняя пауза уже объявленную не сокращает.
+func (t *Throttle) Pause(d time.Duration) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.After(t.until) {
+		t.until = until
+	}
+	return t.until
+}
+
+// PausedUntil сообщает, действует ли пауза и когда она закончится
+func (t *Throttle) PausedUntil() (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if time.Now().Before(t.until) {
+		return t.until, true
+	}
+	return time.Time{}, false
+}
+
+// Wait блокируется до окончания паузы. Пауза может продлиться, пока мы ждем,
+// поэтому проверяем ее заново после каждого пробуждения.
+func (t *Throttle) Wait(ctx context.Context) error {
+	for {
+		until, paused := t.PausedUntil()
+		if !paused {
+			return nil
+		}
+
+		timer := time.NewTimer(time.Until(until))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// parseRetryAfter разбирает Retry-After в секундах или в виде HTTP-даты
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultRetryAfter
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return defaultRetryAfter
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := date.Sub(now); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return defaultRetryAfter
+}
@@ -0,0 +1,182 @@
+package accrual
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accrualStub отвечает 429 с Retry-After, пока не закончатся throttled ответы
+type accrualStub struct {
+	throttled  atomic.Int32
+	retryAfter string
+	requests   atomic.Int32
+	server     *httptest.Server
+}
+
+func newAccrualStub(t *testing.T, throttled int32, retryAfter string) *accrualStub {
+	stub := &accrualStub{retryAfter: retryAfter}
+	stub.throttled.Store(throttled)
+	stub.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.requests.Add(1)
+		if stub.throttled.Add(-1) >= 0 {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Retry-After", stub.retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte("No more than 10 requests per minute allowed"))
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(entity.AccrualResponse{
+			Order:   r.URL.Path[len("/api/orders/"):],
+			Status:  entity.AccrualStatusProcessed,
+			Accrual: entity.NewPoints(500, 0),
+		})
+	}))
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func setupThrottledProcessor(t *testing.T, baseURL string) (*OrderAccrual, *mocks.MockRepository) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	accrualRepo := mocks.NewMockRepository(ctrl)
+	orderRepo := mocks.NewMockOrderUseCase(ctrl)
+	orderRepo.EXPECT().GetOrderGoods(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	uc := usecase.NewGopherMart(
+		accrualRepo,
+		mocks.NewMockBalanceUseCase(ctrl),
+		orderRepo,
+		mocks.NewMockAuthUseCase(ctrl),
+		log)
+	op := NewOrderProcessor(NewHTTPClient(baseURL, log), 2, *uc, log)
+	t.Cleanup(op.cancel)
+	return op, accrualRepo
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 60*time.Second, parseRetryAfter("60", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("0", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	assert.Equal(t, defaultRetryAfter, parseRetryAfter("", now))
+	assert.Equal(t, defaultRetryAfter, parseRetryAfter("soon", now))
+	assert.Equal(t, defaultRetryAfter, parseRetryAfter("-5", now))
+}
+
+func TestThrottle(t *testing.T) {
+	t.Run("not paused by default", func(t *testing.T) {
+		th := NewThrottle()
+		_, paused := th.PausedUntil()
+		assert.False(t, paused)
+		assert.NoError(t, th.Wait(context.Background()))
+	})
+
+	t.Run("shorter pause does not shorten longer one", func(t *testing.T) {
+		th := NewThrottle()
+		long := th.Pause(time.Minute)
+		assert.Equal(t, long, th.Pause(time.Millisecond))
+	})
+
+	t.Run("wait resumes after pause", func(t *testing.T) {
+		th := NewThrottle()
+		th.Pause(50 * time.Millisecond)
+
+		start := time.Now()
+		assert.NoError(t, th.Wait(context.Background()))
+		assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+		_, paused := th.PausedUntil()
+		assert.False(t, paused)
+	})
+
+	t.Run("wait honours context", func(t *testing.T) {
+		th := NewThrottle()
+		th.Pause(time.Minute)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, th.Wait(ctx), context.DeadlineExceeded)
+	})
+}
+
+func TestOrderAccrualRateLimit(t *testing.T) {
+	t.Run("429 pauses all workers for Retry-After", func(t *testing.T) {
+		stub := newAccrualStub(t, 1, "1")
+		op, _ := setupThrottledProcessor(t, stub.server.URL)
+		ctx := context.Background()
+
+		_, err := op.getAccrualResult(ctx, "12345678903")
+		var rateErr *RateLimitError
+		require.True(t, errors.As(err, &rateErr))
+		assert.Equal(t, time.Second, rateErr.RetryAfter)
+
+		until, paused := op.Throttled()
+		assert.True(t, paused)
+		assert.WithinDuration(t, time.Now().Add(time.Second), until, 200*time.Millisecond)
+
+		// оба воркера ждут окончания паузы и не трогают сервис
+		start := time.Now()
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := op.getAccrualResult(ctx, "12345678903")
+				assert.NoError(t, err)
+				assert.Equal(t, entity.AccrualStatusProcessed, resp.Status)
+			}()
+		}
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, int32(1), stub.requests.Load())
+
+		wg.Wait()
+		assert.GreaterOrEqual(t, time.Since(start), 800*time.Millisecond)
+		assert.Equal(t, int32(3), stub.requests.Load())
+
+		_, paused = op.Throttled()
+		assert.False(t, paused)
+	})
+
+	t.Run("429 on registration pauses the client", func(t *testing.T) {
+		stub := newAccrualStub(t, 1, "1")
+		op, _ := setupThrottledProcessor(t, stub.server.URL)
+
+		err := op.sendOrderData(context.Background(), "12345678903")
+		var rateErr *RateLimitError
+		assert.True(t, errors.As(err, &rateErr))
+
+		_, paused := op.Throttled()
+		assert.True(t, paused)
+	})
+
+	t.Run("throttled order is postponed without counting an attempt", func(t *testing.T) {
+		stub := newAccrualStub(t, 1, "1")
+		op, repo := setupThrottledProcessor(t, stub.server.URL)
+		job := &entity.AccrualJob{ID: 7, OrderNumber: "12345678903", Attempts: 3}
+
+		repo.EXPECT().PostponeAccrualJob(gomock.Any(), job.ID, time.Second).Return(nil)
+
+		op.processOrder(job)
+	})
+}
@@ -0,0 +1,90 @@
+package accrual
+
+import (
+	"context"
+	"encoding/json"
+	"go-loyalty-system/internal/entity"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestProcessOrderTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var mu sync.Mutex
+	var traceParents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceParents = append(traceParents, r.Header.Get("traceparent"))
+		mu.Unlock()
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(entity.AccrualResponse{
+			Order:   r.URL.Path[len("/api/orders/"):],
+			Status:  entity.AccrualStatusProcessed,
+			Accrual: entity.NewPoints(500, 0),
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	const uploadTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	op, repo := setupThrottledProcessor(t, server.URL)
+	job := &entity.AccrualJob{ID: 1, OrderNumber: "12345678903", TraceParent: uploadTraceParent}
+	repo.EXPECT().SetAccrualJobRegistered(gomock.Any(), job.ID, true).Return(nil)
+	repo.EXPECT().ExistOrderAccrual(gomock.Any(), job.OrderNumber).Return(false, nil)
+	repo.EXPECT().SaveAccrual(gomock.Any(), job.OrderNumber, entity.AccrualStatusProcessed, entity.NewPoints(500, 0)).
+		DoAndReturn(func(ctx context.Context, _, _ string, _ entity.Points) error {
+			assert.True(t, trace.SpanContextFromContext(ctx).IsValid(), "repository is called inside the job trace")
+			return nil
+		})
+	repo.EXPECT().CompleteAccrualJob(gomock.Any(), job.ID).Return(nil)
+
+	op.processOrder(job)
+
+	spans := exporter.GetSpans()
+	byName := make(map[string]tracetest.SpanStub, len(spans))
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	root, ok := byName["accrual.processOrder"]
+	require.True(t, ok, "job span is exported")
+
+	t.Run("job trace links to the upload request", func(t *testing.T) {
+		require.Len(t, root.Links, 1)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", root.Links[0].SpanContext.TraceID().String())
+		assert.NotEqual(t, root.Links[0].SpanContext.TraceID(), root.SpanContext.TraceID(), "each attempt is a trace of its own")
+	})
+
+	t.Run("stages are children of the job span", func(t *testing.T) {
+		for _, name := range []string{"accrual.RegisterOrder", "accrual.processOrderResult", "GopherMartUseCase.SaveAccrual"} {
+			s, ok := byName[name]
+			require.True(t, ok, name)
+			assert.Equal(t, root.SpanContext.TraceID(), s.SpanContext.TraceID(), name)
+		}
+	})
+
+	t.Run("outbound requests carry traceparent", func(t *testing.T) {
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, traceParents, 2)
+		for _, tp := range traceParents {
+			assert.Contains(t, tp, root.SpanContext.TraceID().String())
+		}
+	})
+}
@@ -1,26 +1,32 @@
 package accrual
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"go-loyalty-system/internal/entity"
 	"go-loyalty-system/internal/usecase"
 	"go-loyalty-system/pkg/logging"
-	"net/http"
-	"strings"
+	"go-loyalty-system/pkg/tracing"
+	"os"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+var tracer = otel.Tracer("go-loyalty-system/internal/controller/accrual")
+
 type OrderAccrual struct {
-	client     *http.Client
-	baseURL    string
+	client     AccrualClient
 	numWorkers int
-	ordersChan chan string
+	queue      *JobQueue
+	throttle   *Throttle
+	metrics    *Metrics
 	logger     *logging.ZapLogger
 	repo       usecase.UserUseCase
 	wg         sync.WaitGroup
@@ -31,35 +37,53 @@ type OrderAccrual struct {
 }
 
 const (
-	defaultBufferSize = 100
 	defaultTimeout    = 10 * time.Second
 	processTimeout    = 60 * time.Second
 	collectorInterval = 20 * time.Second
-	maxRetries        = 5
+	pollInterval      = 2 * time.Second
+	jobLease          = 2 * processTimeout
+	processingDelay   = 2 * time.Second
+	maxAttempts       = 10
 	initialBackoff    = time.Second
 	maxBackoff        = 30 * time.Second
 )
 
-func NewOrderProcessor(baseURL string, numWorkers int, repo usecase.UserUseCase, l *logging.ZapLogger) *OrderAccrual {
+func NewOrderProcessor(client AccrualClient,
+	numWorkers int,
+	repo usecase.UserUseCase,
+	l *logging.ZapLogger,
+	opts ...Option) *OrderAccrual {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &OrderAccrual{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		baseURL:    baseURL,
+	op := &OrderAccrual{
+		client:     client,
 		numWorkers: numWorkers,
-		ordersChan: make(chan string, defaultBufferSize),
+		queue:      NewJobQueue(repo, workerID(), l),
+		throttle:   NewThrottle(),
+		metrics:    NewMetrics(nil),
 		logger:     l,
 		repo:       repo,
 		ctx:        ctx,
 		cancel:     cancel,
 		running:    false,
 	}
+	for _, opt := range opts {
+		opt(op)
+	}
+	op.queue.metrics = op.metrics
+	return op
+}
+
+// workerID идентифицирует процесс в accrual_jobs.locked_by
+func workerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "gophermart"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
 }
 
 func (op *OrderAccrual) Start() {
 	op.logger.InfoCtx(op.ctx, "service started")
-	op.logger.InfoCtx(op.ctx, "baseURL -> "+op.baseURL)
 	op.mu.Lock()
 	defer op.mu.Unlock()
 
@@ -98,42 +122,43 @@ func (op *OrderAccrual) Stop() {
 	op.logger.InfoCtx(op.ctx, "service stopped")
 }
 
-func (op *OrderAccrual) AddOrder(orderNumber string) {
-	select {
-	case op.ordersChan <- orderNumber:
-		op.logger.InfoCtx(context.Background(), "order added to processing queue #"+orderNumber, zap.String("order", orderNumber))
-	default:
-		// Если канал переполнен, логируем это
-		op.logger.WarnCtx(context.Background(), "orders channel is full, order skipped", zap.String("order", orderNumber))
-		// Запускаем горутину для повторной попытки
-		go op.retryAddOrder(orderNumber)
+// CheckWorkers проверка готовности: воркеры запущены и не остановлены
+func (op *OrderAccrual) CheckWorkers(context.Context) error {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if !op.running {
+		return errors.New("accrual workers are not running")
+	}
+	return nil
+}
+
+// CheckAccrual проверка готовности: система начислений отвечает
+func (op *OrderAccrual) CheckAccrual(ctx context.Context) error {
+	p, ok := op.client.(pinger)
+	if !ok {
+		return nil
 	}
+	return p.Ping(ctx)
 }
 
-func (op *OrderAccrual) retryAddOrder(orderNumber string) {
-	backoff := time.Second
-	maxBackoff := time.Second * 30
+// Throttled сообщает, приостановлены ли запросы к системе начислений после 429
+// и до какого момента
+func (op *OrderAccrual) Throttled() (until time.Time, paused bool) {
+	return op.throttle.PausedUntil()
+}
 
-	for {
-		select {
-		case <-op.ctx.Done():
-			return
-		case <-time.After(backoff):
-			select {
-			case op.ordersChan <- orderNumber:
-				op.logger.InfoCtx(context.Background(), "order added to processing queue after retry",
-					zap.String("order", orderNumber))
-				return
-			default:
-				// Увеличиваем время ожидания
-				backoff *= 2
-				if backoff > maxBackoff {
-					backoff = maxBackoff
-				}
-				continue
-			}
-		}
+// AddOrder ставит заказ в очередь на начисление. Если запись в базу не удалась,
+// заказ подберет collectUnprocessedOrders. Задание запоминает трассу запроса из ctx,
+// а отмена запроса не прерывает постановку в очередь.
+func (op *OrderAccrual) AddOrder(ctx context.Context, orderNumber string) {
+	ctx = context.WithoutCancel(ctx)
+	if err := op.queue.Enqueue(ctx, orderNumber); err != nil {
+		op.logger.ErrorCtx(ctx, "failed to enqueue order, collector will retry",
+			zap.String("order", orderNumber),
+			zap.Error(err))
+		return
 	}
+	op.logger.InfoCtx(ctx, "order added to processing queue #"+orderNumber, zap.String("order", orderNumber))
 }
 
 func (op *OrderAccrual) worker(id int) {
@@ -141,237 +166,178 @@ func (op *OrderAccrual) worker(id int) {
 	op.logger.InfoCtx(context.Background(), "worker started", zap.Int("worker_id", id))
 
 	for {
-		select {
-		case orderNumber, ok := <-op.ordersChan:
-			if !ok {
-				op.logger.InfoCtx(context.Background(), "worker stopped (channel closed)", zap.Int("worker_id", id))
-				return
-			}
-			op.processOrder(orderNumber)
-		case <-op.ctx.Done():
+		// во время паузы не арендуем задания, чтобы их могли взять другие реплики
+		if err := op.throttle.Wait(op.ctx); err != nil {
+			op.logger.InfoCtx(context.Background(), "worker stopped (context canceled)", zap.Int("worker_id", id))
+			return
+		}
+		job, err := op.queue.Dequeue(op.ctx)
+		if err != nil {
 			op.logger.InfoCtx(context.Background(), "worker stopped (context canceled)", zap.Int("worker_id", id))
 			return
 		}
+		op.metrics.inFlight.Inc()
+		op.processOrder(job)
+		op.metrics.inFlight.Dec()
 	}
 }
 
-func (op *OrderAccrual) handleProcessError(ctx context.Context, stage string, err error, orderNumber string) {
+func (op *OrderAccrual) handleProcessError(ctx context.Context, stage string, err error, job *entity.AccrualJob) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err, trace.WithAttributes(attribute.String("stage", stage)))
+	span.SetStatus(codes.Error, stage)
+
+	var rateErr *RateLimitError
+	if errors.As(err, &rateErr) {
+		// 429 — не ошибка заказа, попытку не засчитываем
+		op.logger.WarnCtx(ctx, "accrual throttled, order postponed",
+			zap.String("order", job.OrderNumber),
+			zap.Duration("retry_after", rateErr.RetryAfter))
+		if err := op.queue.Postpone(op.ctx, job, rateErr.RetryAfter); err != nil {
+			op.logger.ErrorCtx(ctx, "failed to postpone accrual job", zap.String("order", job.OrderNumber), zap.Error(err))
+		}
+		return
+	}
+	op.metrics.retries.WithLabelValues(stage).Inc()
 	op.logger.ErrorCtx(ctx, "processing failed "+stage,
 		zap.String("stage", stage),
 		zap.Error(err),
-		zap.String("order", orderNumber))
-	go op.retryOrder(orderNumber)
+		zap.String("order", job.OrderNumber),
+		zap.Int("attempts", job.Attempts))
+	// ctx может быть уже отменен по таймауту, поэтому задание возвращаем через op.ctx
+	if err := op.queue.Retry(op.ctx, job, fmt.Errorf("%s: %w", stage, err)); err != nil {
+		op.logger.ErrorCtx(ctx, "failed to reschedule accrual job", zap.String("order", job.OrderNumber), zap.Error(err))
+	}
 }
 
-func (op *OrderAccrual) processOrder(orderNumber string) {
+func (op *OrderAccrual) processOrder(job *entity.AccrualJob) {
 	ctx, cancel := context.WithTimeout(op.ctx, processTimeout)
 	defer cancel()
-	op.logger.InfoCtx(ctx, "processing order", zap.String("order", orderNumber))
+	ctx, span := startJobSpan(ctx, job)
+	defer span.End()
+	op.logger.InfoCtx(ctx, "processing order", zap.String("order", job.OrderNumber))
+
+	// Заказ регистрируется один раз: повторные опросы статуса
+	// не тратят на него запросы из лимита системы начислений
+	if job.RegisteredAt == nil {
+		if err := op.sendOrderData(ctx, job.OrderNumber); err != nil {
+			op.handleProcessError(ctx, "send data", err, job)
+			return
+		}
+		op.metrics.ordersSent.Inc()
+		op.logger.InfoCtx(ctx, "order sent for processing", zap.String("order", job.OrderNumber))
 
-	// Сначала отправляем данные о заказе
-	if err := op.sendOrderData(ctx, orderNumber); err != nil {
-		op.handleProcessError(ctx, "send data ->"+err.Error(), err, orderNumber)
-		return
+		// без отметки заказ зарегистрируется повторно, что для системы начислений не ошибка
+		if err := op.queue.MarkRegistered(op.ctx, job); err != nil {
+			op.logger.ErrorCtx(ctx, "failed to mark order registered", zap.String("order", job.OrderNumber), zap.Error(err))
+		}
 	}
-
-	// Запускаем асинхронную обработку для получения и сохранения результата
-	go op.processOrderResult(orderNumber)
-
-	op.logger.InfoCtx(ctx, "order sent for processing", zap.String("order", orderNumber))
+	op.processOrderResult(ctx, job)
 }
 
-// Новый метод для асинхронной обработки результатов
-func (op *OrderAccrual) processOrderResult(orderNumber string) {
-	// Создаем новый контекст для асинхронной обработки
-	ctx, cancel := context.WithTimeout(op.ctx, maxBackoff)
-	defer cancel()
-
-	op.logger.InfoCtx(ctx, "async processing started", zap.String("order", orderNumber))
-
-	// Получаем результат обработки с повторными попытками
-	var accrualResp *entity.AccrualResponse
-	var err error
-
-	// Добавляем механизм повторных попыток с экспоненциальной задержкой
-	maxRetries := 5
-	for i := 0; i < maxRetries; i++ {
-		accrualResp, err = op.getAccrualResult(ctx, orderNumber)
-		if err == nil {
-			break
-		}
+// processOrderResult запрашивает результат расчета и сохраняет окончательный статус
+func (op *OrderAccrual) processOrderResult(ctx context.Context, job *entity.AccrualJob) {
+	ctx, span := tracer.Start(ctx, "accrual.processOrderResult")
+	defer span.End()
 
-		// Если статус PROCESSING, ждем и повторяем
-		if strings.Contains(err.Error(), "processing") {
-			waitTime := time.Duration(2<<i) * time.Second // Экспоненциальная задержка
-			op.logger.InfoCtx(ctx, "order still processing, will retry",
-				zap.String("order", orderNumber),
-				zap.Duration("wait_time", waitTime),
-				zap.Int("attempt", i+1))
-
-			select {
-			case <-time.After(waitTime):
-				continue
-			case <-ctx.Done():
-				op.handleProcessError(ctx, "context canceled during retry", ctx.Err(), orderNumber)
-				return
+	accrualResp, err := op.getAccrualResult(ctx, job.OrderNumber)
+	if err != nil {
+		if errors.Is(err, ErrOrderNotRegistered) && job.RegisteredAt != nil {
+			if err := op.queue.ResetRegistered(op.ctx, job); err != nil {
+				op.logger.ErrorCtx(ctx, "failed to reset order registration", zap.String("order", job.OrderNumber), zap.Error(err))
 			}
-		} else {
-			op.handleProcessError(ctx, "get result failed", err, orderNumber)
-			return
 		}
+		op.handleProcessError(ctx, "get result", err, job)
+		return
 	}
-
-	if err != nil {
-		op.handleProcessError(ctx, "get result failed after retries", err, orderNumber)
+	op.metrics.results.WithLabelValues(accrualResp.Status).Inc()
+	span.SetAttributes(attribute.String("accrual.status", accrualResp.Status))
+
+	// REGISTERED и PROCESSING не окончательные — проверим заказ позже
+	if accrualResp.Status == entity.AccrualStatusRegistered || accrualResp.Status == entity.AccrualStatusProcessing {
+		op.logger.InfoCtx(ctx, "order still processing, will retry",
+			zap.String("order", job.OrderNumber),
+			zap.String("status", accrualResp.Status),
+			zap.Duration("wait_time", processingDelay))
+		if err := op.queue.Postpone(op.ctx, job, processingDelay); err != nil {
+			op.logger.ErrorCtx(ctx, "failed to postpone accrual job", zap.String("order", job.OrderNumber), zap.Error(err))
+		}
 		return
 	}
 
 	// Сохраняем результат в базу
-	if err := op.repo.SaveAccrual(ctx, orderNumber, accrualResp.Status, accrualResp.Accrual); err != nil {
-		op.handleProcessError(ctx, "save accrual", err, orderNumber)
+	if err := op.repo.SaveAccrual(ctx, job.OrderNumber, accrualResp.Status, accrualResp.Accrual); err != nil {
+		op.handleProcessError(ctx, "save accrual", err, job)
 		return
 	}
+	// задание создается при загрузке заказа, поэтому его возраст — время до итогового статуса
+	op.metrics.processing.WithLabelValues(accrualResp.Status).Observe(time.Since(job.CreatedAt).Seconds())
+
+	if err := op.queue.Complete(op.ctx, job); err != nil {
+		op.logger.ErrorCtx(ctx, "failed to complete accrual job", zap.String("order", job.OrderNumber), zap.Error(err))
+	}
 
 	op.logger.InfoCtx(ctx, "order processed successfully",
-		zap.String("order", orderNumber),
+		zap.String("order", job.OrderNumber),
 		zap.String("status", accrualResp.Status))
 }
 
-func (op *OrderAccrual) checkResponse(resp *http.Response) error {
-	ctx := context.Background()
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusAccepted:
-		op.logger.InfoCtx(ctx, "order processed successfully")
-		return nil
-	case http.StatusTooManyRequests:
-		return op.logAndReturnError(ctx, "rate limit exceeded", nil)
-	case http.StatusBadRequest:
-		return fmt.Errorf("bad request")
-	case http.StatusInternalServerError:
-		return fmt.Errorf("server error")
-	case http.StatusNoContent:
-		return op.logAndReturnError(ctx, "order not found", nil)
-
-	default:
-		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
-	}
-}
-
 func (op *OrderAccrual) getAccrualResult(ctx context.Context, orderNumber string) (*entity.AccrualResponse, error) {
-	url := fmt.Sprintf("%s/api/orders/%s", op.baseURL, orderNumber)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
-	if err != nil {
-		return nil, op.logAndReturnError(ctx, "failed to create request", err)
-	}
-
-	resp, err := op.client.Do(req)
-	if err != nil {
-		return nil, op.logAndReturnError(ctx, "failed to get order info", err)
-	}
-	defer resp.Body.Close()
+	ctx, span := tracer.Start(ctx, "accrual.GetOrderAccrual", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
 
-	if err := op.checkResponse(resp); err != nil {
+	if err := op.throttle.Wait(ctx); err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, op.logAndReturnError(ctx, "unexpected status code ->"+resp.Status, nil)
-	}
-
-	var accrualResp entity.AccrualResponse
-	if err := json.NewDecoder(resp.Body).Decode(&accrualResp); err != nil {
-		return nil, op.logAndReturnError(ctx, "failed to decode response", err)
-	}
-	return &accrualResp, nil
-}
-
-func (op *OrderAccrual) createRequest(ctx context.Context, method, path string, body []byte) (*http.Request, error) {
-	op.logger.InfoCtx(ctx, "path->"+path)
-	op.logger.InfoCtx(ctx, "creating request"+op.baseURL+path)
-	req, err := http.NewRequestWithContext(ctx, method, op.baseURL+path, bytes.NewBuffer(body))
+	resp, err := op.client.GetOrderAccrual(ctx, orderNumber)
 	if err != nil {
-		op.logger.ErrorCtx(ctx, "create request error", zap.Error(err))
-		return nil, err
+		return nil, op.observeRateLimit(ctx, err)
 	}
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	return req, nil
+	return resp, nil
 }
 
 func (op *OrderAccrual) sendOrderData(ctx context.Context, orderNumber string) error {
-	orderData := entity.AccrualOrder{Order: orderNumber, Goods: []entity.Product{
-		{
-			Description: "test",
-			Price:       100,
-		},
-		{
-			Description: "test2",
-			Price:       200,
-		},
-	}}
-	jsonData, err := json.Marshal(orderData)
+	goods, err := op.repo.GetOrderGoods(ctx, orderNumber)
 	if err != nil {
-		op.logger.ErrorCtx(ctx, "marshal error", zap.Error(err))
 		return err
 	}
-	op.logger.InfoCtx(ctx, "sending order data "+orderNumber, zap.String("order", orderNumber))
-	op.logger.InfoCtx(ctx, "order data", zap.String("order", string(jsonData)))
-	req, err := op.createRequest(ctx, "POST", "/api/orders", jsonData)
-	if err != nil {
-		op.logger.ErrorCtx(ctx, "create request error ->"+err.Error(), zap.Error(err))
+	ctx, span := tracer.Start(ctx, "accrual.RegisterOrder", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	if err := op.throttle.Wait(ctx); err != nil {
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := op.client.Do(req)
-	if err != nil {
-		return op.logAndReturnError(ctx, "sendOrderData - failed to send request", err)
+	// заказ, загруженный одним номером, регистрируется без товаров
+	if goods == nil {
+		goods = []entity.Product{}
 	}
-	defer resp.Body.Close()
-
-	switch resp.StatusCode {
-	case http.StatusTooManyRequests:
-		return op.logAndReturnError(ctx, "sendOrderData - rate limit exceeded", nil)
-	case http.StatusNoContent:
-		op.logger.InfoCtx(ctx, "order already registered")
-		return nil
-	case http.StatusAccepted:
-		op.logger.InfoCtx(ctx, "The order has been successfully accepted for processing")
-		return nil
-	case http.StatusConflict:
-		op.logger.InfoCtx(ctx, "The order is already processed")
-		return nil
-	case http.StatusBadRequest:
-		return op.logAndReturnError(ctx, "sendOrderData - bad request", nil)
-	case http.StatusInternalServerError:
-		return op.logAndReturnError(ctx, "sendOrderData - internal server error", nil)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return op.logAndReturnError(ctx, "sendOrderData - unexpected status code -"+resp.Status, nil)
+	orderData := entity.AccrualOrder{Order: orderNumber, Goods: goods}
+	if err := op.client.RegisterOrder(ctx, orderData); err != nil {
+		return op.observeRateLimit(ctx, err)
 	}
 	return nil
 }
 
-func (op *OrderAccrual) retryOrder(orderNumber string) {
-	backoff := time.Second
-	maxBackoff := maxBackoff
-	maxRetries := maxRetries
-	retries := 0
-
-	for retries < maxRetries {
-		select {
-		case <-op.ctx.Done():
-			return
-		case <-time.After(backoff):
-			op.AddOrder(orderNumber)
-			retries++
-			backoff *= 2
-			if backoff > maxBackoff {
-				backoff = maxBackoff
-			}
-		}
+// startJobSpan открывает трассу попытки обработки задания. Каждая попытка —
+// отдельная трасса со ссылкой на запрос, который поставил заказ в очередь.
+func startJobSpan(ctx context.Context, job *entity.AccrualJob) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("order.number", job.OrderNumber),
+			attribute.Int64("accrual.job_id", job.ID),
+			attribute.Int("accrual.attempt", job.Attempts+1),
+		),
+	}
+	if link, ok := tracing.Link(job.TraceParent); ok {
+		opts = append(opts, trace.WithLinks(link))
 	}
+	return tracer.Start(ctx, "accrual.processOrder", opts...)
 }
 
+// collectUnprocessedOrders периодически ставит в очередь заказы без задания,
+// например если AddOrder не смог записать задание в базу
 func (op *OrderAccrual) collectUnprocessedOrders() {
 	ctx := context.Background()
 	defer op.wg.Done()
@@ -402,13 +368,22 @@ func (op *OrderAccrual) checkUnprocessedOrders() {
 	if len(orders) > 0 {
 		op.logger.InfoCtx(op.ctx, "processing unprocessed orders", zap.Int("count", len(orders)))
 		for _, order := range orders {
-			op.AddOrder(order)
+			if err := op.queue.Enqueue(op.ctx, order); err != nil {
+				op.logger.ErrorCtx(op.ctx, "failed to enqueue unprocessed order", zap.String("order", order), zap.Error(err))
+			}
 		}
 	}
 }
 
-func (op *OrderAccrual) logAndReturnError(ctx context.Context, method string, err error) error {
-	msg := fmt.Sprintf("%s - %s: %v", "Accrual", method, err)
-	op.logger.ErrorCtx(ctx, msg, zap.Error(err))
+// observeRateLimit ставит паузу для всех воркеров на время из Retry-After
+func (op *OrderAccrual) observeRateLimit(ctx context.Context, err error) error {
+	var rateErr *RateLimitError
+	if errors.As(err, &rateErr) {
+		op.metrics.rateLimited.Inc()
+		until := op.throttle.Pause(rateErr.RetryAfter)
+		op.logger.WarnCtx(ctx, "rate limit exceeded, accrual requests paused",
+			zap.Duration("retry_after", rateErr.RetryAfter),
+			zap.Time("until", until))
+	}
 	return err
 }
@@ -0,0 +1,182 @@
+package accrual
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingClient запоминает зарегистрированные заказы; с unknown
+// система начислений отвечает, что заказ ей не известен
+type recordingClient struct {
+	registered []entity.AccrualOrder
+	unknown    bool
+}
+
+func (c *recordingClient) RegisterOrder(_ context.Context, order entity.AccrualOrder) error {
+	c.registered = append(c.registered, order)
+	return nil
+}
+
+func (c *recordingClient) GetOrderAccrual(_ context.Context, orderNumber string) (*entity.AccrualResponse, error) {
+	if c.unknown {
+		return nil, ErrOrderNotRegistered
+	}
+	return &entity.AccrualResponse{Order: orderNumber, Status: entity.AccrualStatusRegistered}, nil
+}
+
+func setupRecordingProcessor(t *testing.T) (*OrderAccrual, *recordingClient, *mocks.MockOrderUseCase, *mocks.MockRepository) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	orderRepo := mocks.NewMockOrderUseCase(ctrl)
+	accrualRepo := mocks.NewMockRepository(ctrl)
+	uc := usecase.NewGopherMart(
+		accrualRepo,
+		mocks.NewMockBalanceUseCase(ctrl),
+		orderRepo,
+		mocks.NewMockAuthUseCase(ctrl),
+		log)
+	client := &recordingClient{}
+	op := NewOrderProcessor(client, 1, *uc, log)
+	t.Cleanup(op.cancel)
+	return op, client, orderRepo, accrualRepo
+}
+
+func TestSendOrderData(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends stored goods", func(t *testing.T) {
+		op, client, orderRepo, _ := setupRecordingProcessor(t)
+		goods := []entity.Product{
+			{Description: "Чайник Bork", Price: entity.NewPoints(7000, 0)},
+			{Description: "Утюг Philips", Price: entity.NewPoints(3000, 0)},
+		}
+		orderRepo.EXPECT().GetOrderGoods(ctx, "12345678903").Return(goods, nil)
+
+		require.NoError(t, op.sendOrderData(ctx, "12345678903"))
+		require.Len(t, client.registered, 1)
+		assert.Equal(t, entity.AccrualOrder{Order: "12345678903", Goods: goods}, client.registered[0])
+	})
+
+	t.Run("bare order number is sent without goods", func(t *testing.T) {
+		op, client, orderRepo, _ := setupRecordingProcessor(t)
+		orderRepo.EXPECT().GetOrderGoods(ctx, "12345678903").Return(nil, nil)
+
+		require.NoError(t, op.sendOrderData(ctx, "12345678903"))
+		require.Len(t, client.registered, 1)
+
+		body, err := json.Marshal(client.registered[0])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"order":"12345678903","goods":[]}`, string(body))
+	})
+
+	t.Run("goods lookup error is returned", func(t *testing.T) {
+		op, client, orderRepo, _ := setupRecordingProcessor(t)
+		orderRepo.EXPECT().GetOrderGoods(ctx, "12345678903").Return(nil, errors.New("database error"))
+
+		assert.Error(t, op.sendOrderData(ctx, "12345678903"))
+		assert.Empty(t, client.registered)
+	})
+}
+
+func TestProcessOrderRegistration(t *testing.T) {
+	const order = "12345678903"
+
+	t.Run("new order is registered and marked", func(t *testing.T) {
+		op, client, orderRepo, repo := setupRecordingProcessor(t)
+		job := &entity.AccrualJob{ID: 1, OrderNumber: order}
+		orderRepo.EXPECT().GetOrderGoods(gomock.Any(), order).Return(nil, nil)
+		gomock.InOrder(
+			repo.EXPECT().SetAccrualJobRegistered(gomock.Any(), job.ID, true).Return(nil),
+			repo.EXPECT().PostponeAccrualJob(gomock.Any(), job.ID, processingDelay).Return(nil),
+		)
+
+		op.processOrder(job)
+		assert.Len(t, client.registered, 1)
+		assert.NotNil(t, job.RegisteredAt)
+	})
+
+	t.Run("registered order is only polled", func(t *testing.T) {
+		op, client, _, repo := setupRecordingProcessor(t)
+		registeredAt := time.Now().Add(-time.Minute)
+		job := &entity.AccrualJob{ID: 2, OrderNumber: order, RegisteredAt: &registeredAt}
+		repo.EXPECT().PostponeAccrualJob(gomock.Any(), job.ID, processingDelay).Return(nil)
+
+		op.processOrder(job)
+		assert.Empty(t, client.registered)
+	})
+
+	t.Run("order unknown to the accrual system is registered again next time", func(t *testing.T) {
+		op, client, _, repo := setupRecordingProcessor(t)
+		client.unknown = true
+		registeredAt := time.Now().Add(-time.Minute)
+		job := &entity.AccrualJob{ID: 3, OrderNumber: order, RegisteredAt: &registeredAt}
+		gomock.InOrder(
+			repo.EXPECT().SetAccrualJobRegistered(gomock.Any(), job.ID, false).Return(nil),
+			repo.EXPECT().RetryAccrualJob(gomock.Any(), job.ID, backoff(0), gomock.Any()).Return(nil),
+		)
+
+		op.processOrder(job)
+		assert.Empty(t, client.registered)
+		assert.Nil(t, job.RegisteredAt)
+	})
+}
+
+func TestProcessOrderResult(t *testing.T) {
+	t.Run("accrual with three decimals is rounded to hundredths", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"order":"12345678903","status":"PROCESSED","accrual":12.345}`))
+		}))
+		t.Cleanup(server.Close)
+		op, repo := setupThrottledProcessor(t, server.URL)
+		job := &entity.AccrualJob{ID: 8, OrderNumber: "12345678903"}
+
+		repo.EXPECT().ExistOrderAccrual(gomock.Any(), job.OrderNumber).Return(false, nil)
+		repo.EXPECT().SaveAccrual(gomock.Any(), job.OrderNumber, entity.AccrualStatusProcessed, entity.NewPoints(12, 35)).
+			Return(nil)
+		repo.EXPECT().CompleteAccrualJob(gomock.Any(), job.ID).Return(nil)
+
+		op.processOrderResult(context.Background(), job)
+	})
+}
+
+func TestReadinessChecks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stopped workers are not ready", func(t *testing.T) {
+		op, _, _, _ := setupRecordingProcessor(t)
+		assert.Error(t, op.CheckWorkers(ctx))
+
+		op.running = true
+		assert.NoError(t, op.CheckWorkers(ctx))
+	})
+
+	t.Run("any response means the accrual system is reachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(server.Close)
+		op, _ := setupThrottledProcessor(t, server.URL)
+
+		assert.NoError(t, op.CheckAccrual(ctx))
+	})
+
+	t.Run("unreachable accrual system", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		op, _ := setupThrottledProcessor(t, server.URL)
+
+		assert.Error(t, op.CheckAccrual(ctx))
+	})
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Search users
+// @Description Find users by a part of login or email
+// @Tags admin
+// @Produce json
+// @Param q query string false "Part of login or email"
+// @Success 200 {array} entity.User
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Support or admin role required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/users [get]
+func (g *GopherMartRoutes) AdminSearchUsers(c *gin.Context) {
+	actorID, ok := g.adminActor(c)
+	if !ok {
+		return
+	}
+	users, err := g.u.SearchUsers(c.Request.Context(), actorID, c.Query("q"))
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to search users", err)
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// @Summary User orders
+// @Description Orders uploaded by the user, paginated like /api/user/orders
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Param limit query int false "Page size"
+// @Param cursor query string false "Cursor from X-Next-Cursor"
+// @Param status query string false "Comma separated statuses"
+// @Param from query string false "Uploaded at or after"
+// @Param to query string false "Uploaded before"
+// @Param sort query string false "desc or asc"
+// @Success 200 {array} entity.OrderResponse
+// @Failure 400 {object} ErrorResponse "Invalid user ID or filter"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Support or admin role required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/users/{id}/orders [get]
+func (g *GopherMartRoutes) AdminUserOrders(c *gin.Context) {
+	actorID, userID, ok := g.adminTarget(c)
+	if !ok {
+		return
+	}
+	f, ok := g.listFilter(c)
+	if !ok {
+		return
+	}
+	page, err := g.u.ViewUserOrders(c.Request.Context(), actorID, userID, f)
+	if errors.Is(err, entity.ErrInvalidListFilter) {
+		g.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to get orders", err)
+		return
+	}
+	writePageHeaders(c, f.Limit, page.NextCursor)
+	c.JSON(http.StatusOK, page.Orders)
+}
+
+// @Summary User withdrawals
+// @Description Withdrawals made by the user, paginated like /api/user/withdrawals
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Param limit query int false "Page size"
+// @Param cursor query string false "Cursor from X-Next-Cursor"
+// @Param from query string false "Processed at or after"
+// @Param to query string false "Processed before"
+// @Param sort query string false "desc or asc"
+// @Success 200 {array} entity.Withdrawal
+// @Failure 400 {object} ErrorResponse "Invalid user ID or filter"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Support or admin role required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/users/{id}/withdrawals [get]
+func (g *GopherMartRoutes) AdminUserWithdrawals(c *gin.Context) {
+	actorID, userID, ok := g.adminTarget(c)
+	if !ok {
+		return
+	}
+	f, ok := g.listFilter(c)
+	if !ok {
+		return
+	}
+	page, err := g.u.ViewUserWithdrawals(c.Request.Context(), actorID, userID, f)
+	if errors.Is(err, entity.ErrInvalidListFilter) {
+		g.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to get withdrawals", err)
+		return
+	}
+	writePageHeaders(c, f.Limit, page.NextCursor)
+	c.JSON(http.StatusOK, page.Withdrawals)
+}
+
+// @Summary User balance history
+// @Description Current balance and ledger entries of the user
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} entity.BalanceHistory
+// @Failure 400 {object} ErrorResponse "Invalid user ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Support or admin role required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/users/{id}/balance [get]
+func (g *GopherMartRoutes) AdminBalanceHistory(c *gin.Context) {
+	actorID, userID, ok := g.adminTarget(c)
+	if !ok {
+		return
+	}
+	history, err := g.u.ViewBalanceHistory(c.Request.Context(), actorID, userID)
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to get balance history", err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// @Summary Adjust balance
+// @Description Manual balance adjustment. Positive amount credits, negative debits. Reason is mandatory
+// @Tags admin
+// @Accept json
+// @Param id path int true "User ID"
+// @Param request body entity.BalanceAdjustment true "Amount and reason"
+// @Success 204 "Balance adjusted"
+// @Failure 400 {object} ErrorResponse "Invalid request or missing reason"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 402 {object} ErrorResponse "Debit exceeds the balance"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/users/{id}/balance/adjust [post]
+func (g *GopherMartRoutes) AdminAdjustBalance(c *gin.Context) {
+	actorID, userID, ok := g.adminTarget(c)
+	if !ok {
+		return
+	}
+	var request entity.BalanceAdjustment
+	if err := c.ShouldBindJSON(&request); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "amount and reason are required", err)
+		return
+	}
+	if err := g.u.AdminAdjustBalance(c.Request.Context(), actorID, userID, request); err != nil {
+		g.adminErrorResponse(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Lock account
+// @Description Lock the account and revoke all of its sessions
+// @Tags admin
+// @Accept json
+// @Param id path int true "User ID"
+// @Param request body entity.AccountLock true "Reason"
+// @Success 204 "Account locked"
+// @Failure 400 {object} ErrorResponse "Missing reason or own account"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/users/{id}/lock [post]
+func (g *GopherMartRoutes) AdminLockUser(c *gin.Context) {
+	g.setUserLocked(c, true)
+}
+
+// @Summary Unlock account
+// @Description Unlock a previously locked account
+// @Tags admin
+// @Accept json
+// @Param id path int true "User ID"
+// @Param request body entity.AccountLock true "Reason"
+// @Success 204 "Account unlocked"
+// @Failure 400 {object} ErrorResponse "Missing reason"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/users/{id}/unlock [post]
+func (g *GopherMartRoutes) AdminUnlockUser(c *gin.Context) {
+	g.setUserLocked(c, false)
+}
+
+func (g *GopherMartRoutes) setUserLocked(c *gin.Context, locked bool) {
+	actorID, userID, ok := g.adminTarget(c)
+	if !ok {
+		return
+	}
+	var request entity.AccountLock
+	if err := c.ShouldBindJSON(&request); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "reason is required", err)
+		return
+	}
+	var err error
+	if locked {
+		err = g.u.LockUser(c.Request.Context(), actorID, userID, request.Reason)
+	} else {
+		err = g.u.UnlockUser(c.Request.Context(), actorID, userID, request.Reason)
+	}
+	if err != nil {
+		g.adminErrorResponse(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Force logout
+// @Description Revoke all access and refresh tokens of the user
+// @Tags admin
+// @Param id path int true "User ID"
+// @Success 204 "Sessions revoked"
+// @Failure 400 {object} ErrorResponse "Invalid user ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/users/{id}/logout [post]
+func (g *GopherMartRoutes) AdminForceLogout(c *gin.Context) {
+	actorID, userID, ok := g.adminTarget(c)
+	if !ok {
+		return
+	}
+	if err := g.u.ForceLogout(c.Request.Context(), actorID, userID); err != nil {
+		g.adminErrorResponse(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Admin audit trail
+// @Description Latest actions of support staff, optionally for one user
+// @Tags admin
+// @Produce json
+// @Param user_id query int false "User ID"
+// @Success 200 {array} entity.AdminAuditEntry
+// @Failure 400 {object} ErrorResponse "Invalid user ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Support or admin role required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/audit [get]
+func (g *GopherMartRoutes) AdminAudit(c *gin.Context) {
+	actorID, ok := g.adminActor(c)
+	if !ok {
+		return
+	}
+	var userID uint64
+	if raw := c.Query("user_id"); raw != "" {
+		var err error
+		if userID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			g.ErrorResponse(c, http.StatusBadRequest, "invalid user ID", err)
+			return
+		}
+	}
+	entries, err := g.u.GetAdminAudit(c.Request.Context(), actorID, uint(userID))
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to get audit trail", err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// adminActor ID сотрудника из токена
+func (g *GopherMartRoutes) adminActor(c *gin.Context) (uint, bool) {
+	actorID, err := strconv.ParseUint(c.GetString("userID"), 10, 32)
+	if err != nil {
+		g.ErrorResponse(c, http.StatusUnauthorized, "user not authenticated", err)
+		return 0, false
+	}
+	return uint(actorID), true
+}
+
+// adminTarget ID сотрудника из токена и ID пользователя из пути
+func (g *GopherMartRoutes) adminTarget(c *gin.Context) (actorID, userID uint, ok bool) {
+	if actorID, ok = g.adminActor(c); !ok {
+		return 0, 0, false
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		g.ErrorResponse(c, http.StatusBadRequest, "invalid user ID", err)
+		return 0, 0, false
+	}
+	return actorID, uint(id), true
+}
+
+func (g *GopherMartRoutes) adminErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, entity.ErrReasonRequired):
+		g.ErrorResponse(c, http.StatusBadRequest, "reason is required", err)
+	case errors.Is(err, entity.ErrInvalidAdjustment):
+		g.ErrorResponse(c, http.StatusBadRequest, "adjustment amount must not be zero", err)
+	case errors.Is(err, entity.ErrInvalidUser):
+		g.ErrorResponse(c, http.StatusBadRequest, "cannot lock own account", err)
+	case errors.Is(err, entity.ErrUserDoesNotExist):
+		g.ErrorResponse(c, http.StatusNotFound, "user not found", err)
+	case errors.Is(err, entity.ErrInsufficientFunds):
+		g.ErrorResponse(c, http.StatusPaymentRequired, "insufficient funds", err)
+	default:
+		g.ErrorResponse(c, http.StatusInternalServerError, "internal server error", err)
+	}
+}
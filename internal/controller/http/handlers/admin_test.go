@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bytes"
+	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAdminHandler(t *testing.T) (*gin.Engine, *mocks.MockAuthUseCase, *mocks.MockBalanceUseCase,
+	*mocks.MockAdminAuditRepository) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+
+	userRepo := mocks.NewMockAuthUseCase(ctrl)
+	balanceRepo := mocks.NewMockBalanceUseCase(ctrl)
+	audit := mocks.NewMockAdminAuditRepository(ctrl)
+	cfg := NewTestConfig()
+	uc := usecase.NewGopherMart(mocks.NewMockRepository(ctrl), balanceRepo,
+		mocks.NewMockOrderUseCase(ctrl), userRepo, log,
+		usecase.WithTokens(mocks.NewMockTokenRepository(ctrl)), usecase.WithAdminAudit(audit))
+	h := NewHandler(gin.New(), *uc, cfg, security.NewJwtToken(cfg.Jwt.EncryptionKey, *uc), nil, log)
+
+	router := gin.New()
+	admin := router.Group("/api/admin", func(c *gin.Context) {
+		c.Set("userID", "1")
+	})
+	admin.POST("/users/:id/balance/adjust", h.AdminAdjustBalance)
+	admin.POST("/users/:id/lock", h.AdminLockUser)
+	admin.POST("/withdrawals/reverse", h.AdminReverseWithdrawal)
+	return router, userRepo, balanceRepo, audit
+}
+
+func TestAdminAdjustBalanceHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	call := func(r *gin.Engine, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("credit with reason", func(t *testing.T) {
+		r, userRepo, balanceRepo, audit := setupAdminHandler(t)
+		userRepo.EXPECT().GetUserByID(gomock.Any(), uint(7)).Return(&entity.User{ID: 7}, nil)
+		balanceRepo.EXPECT().GetBalanceForUpdate(gomock.Any(), uint(7)).Return(&entity.Balance{}, nil)
+		balanceRepo.EXPECT().AdjustBalance(gomock.Any(), uint(7), entity.NewPoints(25, 50), "lost receipt").Return(nil)
+		audit.EXPECT().CreateAdminAudit(gomock.Any(), gomock.Any()).Return(nil)
+
+		w := call(r, "/api/admin/users/7/balance/adjust", `{"amount": 25.5, "reason": "lost receipt"}`)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		r, _, _, _ := setupAdminHandler(t)
+
+		w := call(r, "/api/admin/users/7/balance/adjust", `{"amount": 25}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("debit exceeds the balance", func(t *testing.T) {
+		r, userRepo, balanceRepo, _ := setupAdminHandler(t)
+		userRepo.EXPECT().GetUserByID(gomock.Any(), uint(7)).Return(&entity.User{ID: 7}, nil)
+		balanceRepo.EXPECT().GetBalanceForUpdate(gomock.Any(), uint(7)).
+			Return(&entity.Balance{Current: entity.NewPoints(10, 0)}, nil)
+
+		w := call(r, "/api/admin/users/7/balance/adjust", `{"amount": -25, "reason": "fraud"}`)
+		assert.Equal(t, http.StatusPaymentRequired, w.Code)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		r, _, _, _ := setupAdminHandler(t)
+
+		w := call(r, "/api/admin/users/abc/balance/adjust", `{"amount": 25, "reason": "goodwill"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("lock unknown user", func(t *testing.T) {
+		r, userRepo, _, _ := setupAdminHandler(t)
+		userRepo.EXPECT().SetUserLocked(gomock.Any(), uint(9), true).Return(entity.ErrUserDoesNotExist)
+
+		w := call(r, "/api/admin/users/9/lock", `{"reason": "chargeback"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("own account cannot be locked", func(t *testing.T) {
+		r, _, _, _ := setupAdminHandler(t)
+
+		w := call(r, "/api/admin/users/1/lock", `{"reason": "test"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAdminReverseWithdrawalHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	call := func(r *gin.Engine, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/withdrawals/reverse", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	withdrawal := func(reversed entity.Points) *entity.Withdrawal {
+		w := &entity.Withdrawal{ID: 3, UserID: 7, OrderNumber: "2377225624", Amount: entity.NewPoints(100, 0)}
+		w.SetReversed(reversed)
+		return w
+	}
+
+	t.Run("reversed withdrawal is returned", func(t *testing.T) {
+		r, _, balanceRepo, audit := setupAdminHandler(t)
+		balanceRepo.EXPECT().GetWithdrawalForUpdate(gomock.Any(), "2377225624").Return(withdrawal(0), nil)
+		balanceRepo.EXPECT().GetBalanceForUpdate(gomock.Any(), uint(7)).Return(&entity.Balance{}, nil)
+		balanceRepo.EXPECT().ReverseWithdrawal(gomock.Any(), gomock.Any(), entity.NewPoints(40, 0), "one item returned").
+			Return(nil)
+		audit.EXPECT().CreateAdminAudit(gomock.Any(), gomock.Any()).Return(nil)
+
+		w := call(r, `{"order": "2377225624", "sum": 40, "reason": "one item returned"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"PARTIALLY_REVERSED"`)
+		assert.Contains(t, w.Body.String(), `"reversed_sum":40`)
+	})
+
+	t.Run("double reversal", func(t *testing.T) {
+		r, _, balanceRepo, _ := setupAdminHandler(t)
+		balanceRepo.EXPECT().GetWithdrawalForUpdate(gomock.Any(), "2377225624").
+			Return(withdrawal(entity.NewPoints(100, 0)), nil)
+
+		w := call(r, `{"order": "2377225624", "reason": "order cancelled"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("unknown withdrawal", func(t *testing.T) {
+		r, _, balanceRepo, _ := setupAdminHandler(t)
+		balanceRepo.EXPECT().GetWithdrawalForUpdate(gomock.Any(), "2377225624").Return(nil, entity.ErrWithdrawalNotFound)
+
+		w := call(r, `{"order": "2377225624", "reason": "order cancelled"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		r, _, _, _ := setupAdminHandler(t)
+
+		w := call(r, `{"order": "2377225624"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"go-loyalty-system/internal/audit"
+	"go-loyalty-system/internal/entity"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Audit events
+// @Description Security and money events: registrations, logins, issued tokens, withdrawals, accruals and balance adjustments.
+// @Description Paginated like /api/user/orders, newest first by default
+// @Tags admin
+// @Produce json
+// @Param type query string false "Comma separated event types"
+// @Param user_id query int false "Events where the user is the actor or the affected account"
+// @Param request_id query string false "X-Request-ID of the request that caused the events"
+// @Param limit query int false "Page size"
+// @Param cursor query string false "Cursor from X-Next-Cursor"
+// @Param from query string false "Recorded at or after"
+// @Param to query string false "Recorded before"
+// @Param sort query string false "desc or asc"
+// @Success 200 {array} audit.Event
+// @Failure 400 {object} ErrorResponse "Invalid filter"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Support or admin role required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Failure 503 {object} ErrorResponse "Audit log is disabled"
+// @Router /api/admin/audit/events [get]
+func (g *GopherMartRoutes) AdminAuditEvents(c *gin.Context) {
+	actorID, ok := g.adminActor(c)
+	if !ok {
+		return
+	}
+	lf, ok := g.listFilter(c)
+	if !ok {
+		return
+	}
+	f := audit.Filter{ListFilter: lf, RequestID: c.Query("request_id")}
+	for _, raw := range c.QueryArray("type") {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				f.Types = append(f.Types, audit.Type(strings.ToUpper(t)))
+			}
+		}
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			g.ErrorResponse(c, http.StatusBadRequest, "invalid user ID", err)
+			return
+		}
+		f.UserID = uint(userID)
+	}
+
+	page, err := g.u.GetAuditEvents(c.Request.Context(), actorID, f)
+	switch {
+	case errors.Is(err, entity.ErrInvalidListFilter):
+		g.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
+		return
+	case errors.Is(err, entity.ErrAuditUnavailable):
+		g.ErrorResponse(c, http.StatusServiceUnavailable, "audit log is not available", err)
+		return
+	case err != nil:
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to get audit events", err)
+		return
+	}
+	writePageHeaders(c, f.Limit, page.NextCursor)
+	if page.Events == nil {
+		page.Events = []audit.Event{}
+	}
+	c.JSON(http.StatusOK, page.Events)
+}
@@ -1,18 +1,22 @@
 package handlers
 
 import (
+	"errors"
+	"go-loyalty-system/internal/entity"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetUserBalance godoc
 // @Summary Get user balance
-// @Description Get current balance and total withdrawn amount for authorized user
+// @Description Get current balance and total withdrawn amount for authorized user.
+// @Description When points expire, expiring_soon lists the lots that expire within the notice period
 // @Tags balance
 // @Accept json
 // @Produce json
-// @Success 200 {object} entity.Balance
+// @Success 200 {object} entity.BalanceSummary
 // @Failure 401 {object} response
 // @Failure 500 {object} response
 // @Router /api/user/balance [get]
@@ -31,3 +35,47 @@ func (g *GopherMartRoutes) GetUserBalance(c *gin.Context) {
 
 	c.JSON(http.StatusOK, balance)
 }
+
+// @Summary Get balance history
+// @Description Get a page of balance changes: accruals, withdrawals, adjustments, reversals, expiries, holds and transfers.
+// @Description Each entry carries the signed amount, the related order number and the available balance right after it.
+// @Description Newest first by default; the next page cursor is returned in X-Next-Cursor and Link headers
+// @Tags balance
+// @Produce json
+// @Param limit query int false "Page size, 100 by default, at most 1000"
+// @Param cursor query string false "Cursor from X-Next-Cursor of the previous page"
+// @Param from query string false "Created at or after, RFC 3339 or YYYY-MM-DD"
+// @Param to query string false "Created before, RFC 3339 or YYYY-MM-DD"
+// @Param sort query string false "Sort direction: desc (default) or asc"
+// @Success 200 {array} entity.BalanceHistoryEntry
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid filter"
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/user/balance/history [get]
+func (g *GopherMartRoutes) GetBalanceHistory(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.MustGet("userID").(string), 10, 64)
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to parse userID", err)
+		return
+	}
+	f, ok := g.listFilter(c)
+	if !ok {
+		return
+	}
+	page, err := g.u.GetBalanceHistory(c.Request.Context(), uint(userID), f)
+	if errors.Is(err, entity.ErrInvalidListFilter) {
+		g.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to get balance history", err)
+		return
+	}
+	if len(page.Entries) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	writePageHeaders(c, f.Limit, page.NextCursor)
+	c.JSON(http.StatusOK, page.Entries)
+}
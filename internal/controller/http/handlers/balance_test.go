@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupBalanceHistoryHandler(t *testing.T) (*gin.Engine, *mocks.MockBalanceUseCase) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+
+	balanceRepo := mocks.NewMockBalanceUseCase(ctrl)
+	cfg := NewTestConfig()
+	uc := usecase.NewGopherMart(mocks.NewMockRepository(ctrl), balanceRepo,
+		mocks.NewMockOrderUseCase(ctrl), mocks.NewMockAuthUseCase(ctrl), log)
+	h := NewHandler(gin.New(), *uc, cfg, security.NewJwtToken(cfg.Jwt.EncryptionKey, *uc), nil, log)
+
+	router := gin.New()
+	api := router.Group("/api/user", func(c *gin.Context) {
+		c.Set("userID", "7")
+	})
+	api.GET("/balance/history", h.GetBalanceHistory)
+	return router, balanceRepo
+}
+
+func TestGetBalanceHistoryHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	get := func(r *gin.Engine, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/user/balance/history"+query, nil))
+		return w
+	}
+
+	t.Run("entries with running balance", func(t *testing.T) {
+		r, balanceRepo := setupBalanceHistoryHandler(t)
+		at := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+		balanceRepo.EXPECT().GetBalanceHistory(gomock.Any(), uint(7), gomock.Any()).DoAndReturn(
+			func(_ any, _ uint, f entity.ListFilter) ([]entity.BalanceHistoryEntry, error) {
+				assert.True(t, at.Equal(*f.From))
+				return []entity.BalanceHistoryEntry{{ID: 3, Type: entity.LedgerWithdrawal,
+					Amount: entity.NewPoints(-40, 0), OrderNumber: "2377225624",
+					Balance: entity.NewPoints(60, 0), CreatedAt: at}}, nil
+			})
+
+		w := get(r, "?from=2025-02-01T12:00:00Z")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[{"type":"WITHDRAWAL","amount":-40.00,"order":"2377225624","balance":60.00,
+			"created_at":"2025-02-01T12:00:00Z"}]`, w.Body.String())
+	})
+
+	t.Run("empty history", func(t *testing.T) {
+		r, balanceRepo := setupBalanceHistoryHandler(t)
+		balanceRepo.EXPECT().GetBalanceHistory(gomock.Any(), uint(7), gomock.Any()).Return(nil, nil)
+
+		assert.Equal(t, http.StatusNoContent, get(r, "").Code)
+	})
+
+	t.Run("status filter is rejected", func(t *testing.T) {
+		r, _ := setupBalanceHistoryHandler(t)
+
+		assert.Equal(t, http.StatusBadRequest, get(r, "?status=NEW").Code)
+	})
+}
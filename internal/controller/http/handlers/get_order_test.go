@@ -27,7 +27,7 @@ func setupOrderHandler(t *testing.T) (*GopherMartRoutes, *mocks.MockOrderUseCase
 	accrualRepo := mocks.NewMockRepository(ctrl)
 	balanceRepo := mocks.NewMockBalanceUseCase(ctrl)
 	orderRepo := mocks.NewMockOrderUseCase(ctrl)
-	userRepo := mocks.NewMockUserService(ctrl)
+	userRepo := mocks.NewMockAuthUseCase(ctrl)
 	cfg := NewTestConfig()
 
 	uc := usecase.NewGopherMart(accrualRepo, balanceRepo, orderRepo, userRepo, log)
@@ -49,7 +49,7 @@ func TestGetOrders(t *testing.T) {
 	t.Run("successful orders retrieval", func(t *testing.T) {
 		userID := uint(1)
 		now := time.Now()
-		accrual1 := float64(500.50)
+		accrual1 := entity.NewPoints(500, 50)
 
 		expectedOrders := []entity.OrderResponse{
 			{
@@ -67,7 +67,7 @@ func TestGetOrders(t *testing.T) {
 		}
 
 		mockUseCase.EXPECT().
-			GetUserOrders(ctx, userID).
+			GetUserOrders(ctx, userID, gomock.Any()).
 			Return(expectedOrders, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/api/user/orders", nil)
@@ -90,7 +90,7 @@ func TestGetOrders(t *testing.T) {
 		userID := uint(1)
 
 		mockUseCase.EXPECT().
-			GetUserOrders(ctx, userID).
+			GetUserOrders(ctx, userID, gomock.Any()).
 			Return([]entity.OrderResponse{}, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/api/user/orders", nil)
@@ -105,7 +105,7 @@ func TestGetOrders(t *testing.T) {
 		userID := uint(1)
 
 		mockUseCase.EXPECT().
-			GetUserOrders(ctx, userID).
+			GetUserOrders(ctx, userID, gomock.Any()).
 			Return(nil, errors.New("database error"))
 
 		req := httptest.NewRequest(http.MethodGet, "/api/user/orders", nil)
@@ -114,3 +114,76 @@ func TestGetOrders(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, resp.Code)
 	})
 }
+
+func TestGetOrdersPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setup := func(t *testing.T) (*gin.Engine, *mocks.MockOrderUseCase) {
+		h, orderRepo := setupOrderHandler(t)
+		router := gin.New()
+		router.GET("/api/user/orders", func(c *gin.Context) {
+			c.Set("userID", "1")
+			h.GetOrders(c)
+		})
+		return router, orderRepo
+	}
+	get := func(router *gin.Engine, url string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, url, nil))
+		return resp
+	}
+
+	t.Run("next page cursor in headers", func(t *testing.T) {
+		router, orderRepo := setup(t)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		orders := []entity.OrderResponse{
+			{ID: 3, Number: "3", Status: "NEW", UploadedAt: now},
+			{ID: 2, Number: "2", Status: "NEW", UploadedAt: now.Add(-time.Minute)},
+			{ID: 1, Number: "1", Status: "NEW", UploadedAt: now.Add(-2 * time.Minute)},
+		}
+		orderRepo.EXPECT().GetUserOrders(gomock.Any(), uint(1), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, f entity.ListFilter) ([]entity.OrderResponse, error) {
+				assert.Equal(t, 2, f.Limit)
+				assert.Equal(t, []entity.OrderStatus{entity.OrderStatusNew, entity.OrderStatusProcessing}, f.Statuses)
+				return orders, nil
+			})
+
+		resp := get(router, "/api/user/orders?limit=2&status=new,processing")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var body []entity.OrderResponse
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		assert.Len(t, body, 2)
+		assert.Equal(t, "2", resp.Header().Get("X-Page-Limit"))
+
+		next := resp.Header().Get("X-Next-Cursor")
+		cursor, err := entity.DecodePageCursor(next)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), cursor.ID)
+		assert.True(t, orders[1].UploadedAt.Equal(cursor.At))
+		assert.Contains(t, resp.Header().Get("Link"), "cursor="+next)
+	})
+
+	t.Run("last page has no cursor", func(t *testing.T) {
+		router, orderRepo := setup(t)
+		orderRepo.EXPECT().GetUserOrders(gomock.Any(), uint(1), gomock.Any()).
+			Return([]entity.OrderResponse{{ID: 1, Number: "1"}}, nil)
+
+		resp := get(router, "/api/user/orders?sort=asc&from=2025-01-01")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Empty(t, resp.Header().Get("X-Next-Cursor"))
+		assert.Equal(t, "100", resp.Header().Get("X-Page-Limit"))
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		router, _ := setup(t)
+		for _, url := range []string{
+			"/api/user/orders?limit=abc",
+			"/api/user/orders?limit=5000",
+			"/api/user/orders?cursor=***",
+			"/api/user/orders?status=DONE",
+			"/api/user/orders?from=yesterday",
+			"/api/user/orders?sort=up",
+		} {
+			assert.Equal(t, http.StatusBadRequest, get(router, url).Code, url)
+		}
+	})
+}
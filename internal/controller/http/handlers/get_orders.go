@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"errors"
+	"go-loyalty-system/internal/entity"
 	"net/http"
 	"strconv"
 
@@ -10,12 +11,19 @@ import (
 )
 
 // @Summary Get user orders
-// @Description Get list of user orders sorted by upload time
+// @Description Get a page of user orders sorted by upload time. The next page cursor is returned in X-Next-Cursor and Link headers
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Param limit query int false "Page size, 100 by default, at most 1000"
+// @Param cursor query string false "Cursor from X-Next-Cursor of the previous page"
+// @Param status query string false "Comma separated statuses: NEW, PROCESSING, INVALID, PROCESSED"
+// @Param from query string false "Uploaded at or after, RFC 3339 or YYYY-MM-DD"
+// @Param to query string false "Uploaded before, RFC 3339 or YYYY-MM-DD"
+// @Param sort query string false "Sort direction: desc (default) or asc"
 // @Success 200 {array} entity.OrderResponse
 // @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid filter"
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/user/orders [get]
@@ -27,8 +35,16 @@ func (g *GopherMartRoutes) GetOrders(c *gin.Context) {
 		return
 	}
 
-	orders, err := g.u.GetUserOrders(c.Request.Context(), uint(userID))
+	f, ok := g.listFilter(c)
+	if !ok {
+		return
+	}
+	page, err := g.u.GetUserOrders(c.Request.Context(), uint(userID), f)
 	if err != nil {
+		if errors.Is(err, entity.ErrInvalidListFilter) {
+			g.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
 		if errors.Is(err, context.DeadlineExceeded) {
 			g.ErrorResponse(c, http.StatusGatewayTimeout, "request timeout", err)
 			return
@@ -37,10 +53,11 @@ func (g *GopherMartRoutes) GetOrders(c *gin.Context) {
 		return
 	}
 
-	if len(orders) == 0 {
+	writePageHeaders(c, f.Limit, page.NextCursor)
+	if len(page.Orders) == 0 {
 		c.Status(http.StatusNoContent)
 		return
 	}
 
-	c.JSON(http.StatusOK, orders)
+	c.JSON(http.StatusOK, page.Orders)
 }
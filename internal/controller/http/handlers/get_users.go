@@ -6,6 +6,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// @Summary List users
+// @Description List logins, emails and roles of all users. Admin only
+// @Tags admin
+// @Produce json
+// @Success 200 {object} userResponse "Users"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/GetUser [get]
 func (g *GopherMartRoutes) GetUsers(c *gin.Context) {
 	u, err := g.u.GetUsers(c.Request.Context())
 
@@ -37,7 +37,7 @@ func NewTestConfig() *config.Config {
 	}
 }
 
-func setupGetUserHandler(t *testing.T) (*GopherMartRoutes, *mocks.MockUserService) {
+func setupGetUserHandler(t *testing.T) (*GopherMartRoutes, *mocks.MockAuthUseCase) {
 	ctrl := gomock.NewController(t)
 	log, _ := logging.NewZapLogger(1)
 
@@ -45,7 +45,7 @@ func setupGetUserHandler(t *testing.T) (*GopherMartRoutes, *mocks.MockUserServic
 	accrualRepo := mocks.NewMockRepository(ctrl)
 	balanceRepo := mocks.NewMockBalanceUseCase(ctrl)
 	orderRepo := mocks.NewMockOrderUseCase(ctrl)
-	userRepo := mocks.NewMockUserService(ctrl)
+	userRepo := mocks.NewMockAuthUseCase(ctrl)
 
 	cfg, _ := config.NewConfig()
 	uc := usecase.NewGopherMart(accrualRepo, balanceRepo, orderRepo, userRepo, log)
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"go-loyalty-system/internal/entity"
 	"net/http"
 	"strconv"
 
@@ -8,12 +10,19 @@ import (
 )
 
 // @Summary Get user withdrawals
-// @Description Get list of user withdrawals sorted by processed time
+// @Description Get a page of user withdrawals sorted by processed time. The next page cursor is returned in X-Next-Cursor and Link headers.
+// @Description Reversed withdrawals have status PARTIALLY_REVERSED or REVERSED and the returned reversed_sum
 // @Tags withdrawals
 // @Accept json
 // @Produce json
-// @Success 200 {array} entity.WithdrawalResponse
+// @Param limit query int false "Page size, 100 by default, at most 1000"
+// @Param cursor query string false "Cursor from X-Next-Cursor of the previous page"
+// @Param from query string false "Processed at or after, RFC 3339 or YYYY-MM-DD"
+// @Param to query string false "Processed before, RFC 3339 or YYYY-MM-DD"
+// @Param sort query string false "Sort direction: desc (default) or asc"
+// @Success 200 {array} entity.Withdrawal
 // @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid filter"
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/user/withdrawals [get]
@@ -36,14 +45,23 @@ func (g *GopherMartRoutes) GetWithdrawals(c *gin.Context) {
 		return
 	}
 
-	withdrawals, err := g.u.GetUserWithdrawals(c.Request.Context(), uint(userID))
+	f, ok := g.listFilter(c)
+	if !ok {
+		return
+	}
+	page, err := g.u.GetUserWithdrawals(c.Request.Context(), uint(userID), f)
+	if errors.Is(err, entity.ErrInvalidListFilter) {
+		g.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
 	if err != nil {
 		g.ErrorResponse(c, http.StatusInternalServerError, "failed to get withdrawals", err)
 		return
 	}
 
+	writePageHeaders(c, f.Limit, page.NextCursor)
 	c.Header("Content-Type", "application/json")
-	c.JSON(http.StatusOK, withdrawals)
+	c.JSON(http.StatusOK, page.Withdrawals)
 }
 
 func (g *GopherMartRoutes) GetWithdrawalsHandler() gin.HandlerFunc {
@@ -30,10 +30,6 @@ type userRequest struct {
 	Password string `json:"password" binding:"required,min=8"`
 }
 
-const (
-	_time = 3600
-)
-
 func NewHandler(handler *gin.Engine,
 	u usecase.UserUseCase,
 	c *config.Config,
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Hold balance
+// @Description Reserve points for an order that is not paid yet. Held points are no longer available
+// @Description but are not withdrawn until the hold is captured; a hold that is neither captured
+// @Description nor released in time is released automatically
+// @Tags balance
+// @Accept json
+// @Produce json
+// @Param request body entity.HoldRequest true "Order number and sum to hold"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 201 {object} entity.Hold
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 402 {object} ErrorResponse "Insufficient funds"
+// @Failure 409 {object} ErrorResponse "Order already has an active hold"
+// @Failure 422 {object} ErrorResponse "Invalid order number"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Failure 503 {object} ErrorResponse "Holds are disabled"
+// @Router /api/user/balance/holds [post]
+func (g *GopherMartRoutes) HoldBalance(c *gin.Context) {
+	var request entity.HoldRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "failed to bind request", err)
+		return
+	}
+	userID, err := strconv.ParseUint(c.MustGet("userID").(string), 10, 64)
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to parse userID", err)
+		return
+	}
+	if !g.isValidOrderNumber(request.Order) {
+		g.ErrorResponse(c, http.StatusUnprocessableEntity, "validation - invalid order number", nil)
+		return
+	}
+
+	hold, err := g.u.HoldBalance(c.Request.Context(), uint(userID), request)
+	if err != nil {
+		g.holdErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, hold)
+}
+
+// @Summary Capture hold
+// @Description Withdraw held points once the order is paid. Without sum the whole hold is withdrawn,
+// @Description a smaller sum is withdrawn and the rest returns to the balance
+// @Tags balance
+// @Accept json
+// @Produce json
+// @Param id path int true "Hold ID"
+// @Param request body entity.HoldCapture false "Sum to withdraw"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} entity.Hold
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Hold not found"
+// @Failure 409 {object} ErrorResponse "Hold is not active, sum exceeds the hold or order already exists"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Failure 503 {object} ErrorResponse "Holds are disabled"
+// @Router /api/user/balance/holds/{id}/capture [post]
+func (g *GopherMartRoutes) CaptureHold(c *gin.Context) {
+	userID, holdID, ok := g.holdTarget(c)
+	if !ok {
+		return
+	}
+	var request entity.HoldCapture
+	// тело необязательно: без него списывается весь резерв
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		g.ErrorResponse(c, http.StatusBadRequest, "failed to bind request", err)
+		return
+	}
+
+	hold, err := g.u.CaptureHold(c.Request.Context(), userID, holdID, request)
+	if err != nil {
+		g.holdErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, hold)
+}
+
+// @Summary Release hold
+// @Description Cancel a hold and return the held points to the balance
+// @Tags balance
+// @Produce json
+// @Param id path int true "Hold ID"
+// @Success 200 {object} entity.Hold
+// @Failure 400 {object} ErrorResponse "Invalid hold ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Hold not found"
+// @Failure 409 {object} ErrorResponse "Hold is not active"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Failure 503 {object} ErrorResponse "Holds are disabled"
+// @Router /api/user/balance/holds/{id}/release [post]
+func (g *GopherMartRoutes) ReleaseHold(c *gin.Context) {
+	userID, holdID, ok := g.holdTarget(c)
+	if !ok {
+		return
+	}
+	hold, err := g.u.ReleaseHold(c.Request.Context(), userID, holdID)
+	if err != nil {
+		g.holdErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, hold)
+}
+
+// holdTarget ID пользователя из токена и ID резерва из пути
+func (g *GopherMartRoutes) holdTarget(c *gin.Context) (userID uint, holdID int64, ok bool) {
+	id, err := strconv.ParseUint(c.MustGet("userID").(string), 10, 64)
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to parse userID", err)
+		return 0, 0, false
+	}
+	holdID, err = strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || holdID <= 0 {
+		g.ErrorResponse(c, http.StatusBadRequest, "invalid hold id", err)
+		return 0, 0, false
+	}
+	return uint(id), holdID, true
+}
+
+func (g *GopherMartRoutes) holdErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, entity.ErrHoldsUnavailable):
+		g.ErrorResponse(c, http.StatusServiceUnavailable, "holds are not available", err)
+	case errors.Is(err, entity.ErrInvalidHold):
+		g.ErrorResponse(c, http.StatusBadRequest, "hold sum must be positive", err)
+	case errors.Is(err, entity.ErrInsufficientFunds):
+		g.ErrorResponse(c, http.StatusPaymentRequired, "insufficient funds", err)
+	case errors.Is(err, entity.ErrHoldNotFound):
+		g.ErrorResponse(c, http.StatusNotFound, "hold not found", err)
+	case errors.Is(err, entity.ErrHoldExists):
+		g.ErrorResponse(c, http.StatusConflict, "order already has an active hold", err)
+	case errors.Is(err, entity.ErrHoldNotActive):
+		g.ErrorResponse(c, http.StatusConflict, "hold is not active", err)
+	case errors.Is(err, entity.ErrCaptureExceeded):
+		g.ErrorResponse(c, http.StatusConflict, "capture exceeds the held amount", err)
+	case errors.Is(err, entity.ErrOrderExists):
+		g.ErrorResponse(c, http.StatusConflict, "order number already exists", err)
+	default:
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to process hold", err)
+	}
+}
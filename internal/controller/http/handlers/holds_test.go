@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupHoldHandler(t *testing.T) (*gin.Engine, *mocks.MockBalanceUseCase, *mocks.MockHoldRepository) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+
+	balanceRepo := mocks.NewMockBalanceUseCase(ctrl)
+	holds := mocks.NewMockHoldRepository(ctrl)
+	cfg := NewTestConfig()
+	uc := usecase.NewGopherMart(mocks.NewMockRepository(ctrl), balanceRepo,
+		mocks.NewMockOrderUseCase(ctrl), mocks.NewMockAuthUseCase(ctrl), log,
+		usecase.WithHolds(holds, time.Minute))
+	h := NewHandler(gin.New(), *uc, cfg, security.NewJwtToken(cfg.Jwt.EncryptionKey, *uc), nil, log)
+
+	router := gin.New()
+	api := router.Group("/api/user", func(c *gin.Context) {
+		c.Set("userID", "7")
+	})
+	api.POST("/balance/holds", h.HoldBalance)
+	api.POST("/balance/holds/:id/capture", h.CaptureHold)
+	api.POST("/balance/holds/:id/release", h.ReleaseHold)
+	return router, balanceRepo, holds
+}
+
+func TestHoldHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	call := func(r *gin.Engine, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("hold is created", func(t *testing.T) {
+		r, balanceRepo, holds := setupHoldHandler(t)
+		balanceRepo.EXPECT().GetBalanceForUpdate(gomock.Any(), uint(7)).
+			Return(&entity.Balance{Current: entity.NewPoints(200, 0)}, nil)
+		holds.EXPECT().CreateHold(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, h entity.Hold) (*entity.Hold, error) {
+				h.ID, h.Status = 5, entity.HoldActive
+				return &h, nil
+			})
+
+		w := call(r, "/api/user/balance/holds", `{"order": "2377225624", "sum": 150.5}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"ACTIVE"`)
+		assert.Contains(t, w.Body.String(), `"sum":150.50`)
+	})
+
+	t.Run("invalid order number", func(t *testing.T) {
+		r, _, _ := setupHoldHandler(t)
+
+		w := call(r, "/api/user/balance/holds", `{"order": "12ab", "sum": 10}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		r, balanceRepo, _ := setupHoldHandler(t)
+		balanceRepo.EXPECT().GetBalanceForUpdate(gomock.Any(), uint(7)).
+			Return(&entity.Balance{Current: entity.NewPoints(10, 0)}, nil)
+
+		w := call(r, "/api/user/balance/holds", `{"order": "2377225624", "sum": 150}`)
+		assert.Equal(t, http.StatusPaymentRequired, w.Code)
+	})
+
+	t.Run("capture of an unknown hold", func(t *testing.T) {
+		r, balanceRepo, holds := setupHoldHandler(t)
+		balanceRepo.EXPECT().GetBalanceForUpdate(gomock.Any(), uint(7)).Return(&entity.Balance{}, nil)
+		holds.EXPECT().GetHoldForUpdate(gomock.Any(), uint(7), int64(5)).Return(nil, entity.ErrHoldNotFound)
+
+		w := call(r, "/api/user/balance/holds/5/capture", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("release of a settled hold", func(t *testing.T) {
+		r, balanceRepo, holds := setupHoldHandler(t)
+		balanceRepo.EXPECT().GetBalanceForUpdate(gomock.Any(), uint(7)).Return(&entity.Balance{}, nil)
+		holds.EXPECT().GetHoldForUpdate(gomock.Any(), uint(7), int64(5)).
+			Return(&entity.Hold{ID: 5, UserID: 7, Status: entity.HoldReleased}, nil)
+
+		w := call(r, "/api/user/balance/holds/5/release", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("invalid hold id", func(t *testing.T) {
+		r, _, _ := setupHoldHandler(t)
+
+		w := call(r, "/api/user/balance/holds/abc/release", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -1,12 +1,25 @@
 package handlers
 
 import (
+	"errors"
 	"go-loyalty-system/internal/entity"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+// @Summary User login
+// @Description Checks login and password and issues a token
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body UserRegistrationRequest true "Credentials"
+// @Success 200 "Token issued"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Invalid login or password"
+// @Failure 403 {object} ErrorResponse "Password reset required or account locked"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/user/login [post]
 func (g *GopherMartRoutes) LoginUser(c *gin.Context) {
 	var request userRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -14,26 +27,28 @@ func (g *GopherMartRoutes) LoginUser(c *gin.Context) {
 
 		return
 	}
-	user, err := g.u.GetUserByLogin(c.Request.Context(), entity.User{
-		Login:    request.Login,
-		Password: request.Password,
-	})
-	if err != nil {
-		g.ErrorResponse(c, http.StatusUnauthorized, "StatusUnauthorized", err)
+	user, err := g.u.AuthenticateUser(c.Request.Context(), request.Login, request.Password)
+	switch {
+	case errors.Is(err, entity.ErrInvalidCredentials):
+		g.ErrorResponse(c, http.StatusUnauthorized, "invalid login or password", err)
+		return
+	case errors.Is(err, entity.ErrPasswordResetNeeded):
+		g.ErrorResponse(c, http.StatusForbidden, "password reset required", err)
+		return
+	case errors.Is(err, entity.ErrUserLocked):
+		g.ErrorResponse(c, http.StatusForbidden, "account is locked", err)
+		return
+	case err != nil:
+		g.ErrorResponse(c, http.StatusInternalServerError, "internal server error", err)
 		return
 	}
 
-	token, err := g.token.GenerateToken(user)
+	tokens, err := g.token.IssueTokens(c.Request.Context(), user)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err})
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to issue token", err)
 		return
 	}
-
-	c.Set("Accept", "application/json")
-	c.Set("Content-Type", "application/json")
-	c.Header("Content-Type", "application/json")
-	c.SetCookie("token", token, _time, "/", "localhost", false, true)
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	g.writeTokens(c, tokens)
 }
 
 func (g *GopherMartRoutes) LoginUserHandler() gin.HandlerFunc {
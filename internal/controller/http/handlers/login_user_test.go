@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go-loyalty-system/internal/entity"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoginUser(t *testing.T) {
+	h, mockUseCase := setupUserHandler(t)
+	ctx := context.Background()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/user/login", h.LoginUserHandler())
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("piqQJ5SihA264dO324j132"), bcrypt.DefaultCost)
+	stored := &entity.User{ID: 1, Login: "test24e7", Password: string(hash)}
+
+	login := func(password string) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(map[string]string{"login": "test24e7", "password": password})
+		req := httptest.NewRequest(http.MethodPost, "/api/user/login", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("successful login", func(t *testing.T) {
+		mockUseCase.EXPECT().
+			GetUserByLogin(ctx, entity.User{Login: "test24e7"}).
+			Return(stored, nil)
+		mockUseCase.EXPECT().
+			CreateToken(ctx, gomock.Any()).
+			Return(nil).Times(2)
+
+		resp := login("piqQJ5SihA264dO324j132")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var tokens entity.TokenPair
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &tokens))
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEmpty(t, tokens.RefreshToken)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		mockUseCase.EXPECT().
+			GetUserByLogin(ctx, entity.User{Login: "test24e7"}).
+			Return(stored, nil)
+
+		resp := login("wrong-password")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("unknown login", func(t *testing.T) {
+		mockUseCase.EXPECT().
+			GetUserByLogin(ctx, entity.User{Login: "test24e7"}).
+			Return(nil, entity.ErrUserDoesNotExist)
+
+		resp := login("piqQJ5SihA264dO324j132")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("legacy plaintext password requires reset", func(t *testing.T) {
+		mockUseCase.EXPECT().
+			GetUserByLogin(ctx, entity.User{Login: "test24e7"}).
+			Return(&entity.User{ID: 1, Login: "test24e7", Password: "piqQJ5SihA264dO324j132", PasswordResetRequired: true}, nil)
+
+		resp := login("piqQJ5SihA264dO324j132")
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+}
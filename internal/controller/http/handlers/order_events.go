@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	orderEventName = "order"
+	// orderEventsHeartbeat период комментариев, которые держат соединение
+	// открытым через прокси с таймаутом простоя
+	orderEventsHeartbeat = 15 * time.Second
+	// orderEventsRetry через сколько миллисекунд браузер переподключается после обрыва
+	orderEventsRetry = 3000
+)
+
+// @Summary Stream order status changes
+// @Description Server-Sent Events stream of status and accrual updates of the user's orders.
+// @Description Every event has an id; reconnect with Last-Event-ID to receive the events missed since then.
+// @Description Without Last-Event-ID only new events are sent
+// @Tags orders
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "ID of the last received event"
+// @Success 200 {object} entity.OrderEvent "event: order"
+// @Failure 400 {object} ErrorResponse "Invalid Last-Event-ID"
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse "Event stream is disabled"
+// @Router /api/user/orders/events [get]
+func (g *GopherMartRoutes) OrderEvents(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.MustGet("userID").(string), 10, 64)
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to parse userID", err)
+		return
+	}
+	ctx := c.Request.Context()
+
+	// подписка раньше чтения, чтобы не пропустить событие между ними
+	wakeup, unsubscribe, err := g.u.SubscribeOrderEvents(uint(userID))
+	if err != nil {
+		g.ErrorResponse(c, http.StatusServiceUnavailable, "event stream is not available", err)
+		return
+	}
+	defer unsubscribe()
+
+	var lastID int64
+	if raw := c.GetHeader("Last-Event-ID"); raw != "" {
+		lastID, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || lastID < 0 {
+			g.ErrorResponse(c, http.StatusBadRequest, "invalid Last-Event-ID", err)
+			return
+		}
+	} else {
+		lastID, err = g.u.GetLastOrderEventID(ctx, uint(userID))
+		if err != nil {
+			g.ErrorResponse(c, http.StatusInternalServerError, "failed to get order events", err)
+			return
+		}
+	}
+
+	// поток живет дольше WriteTimeout сервера
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(orderEventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		if lastID, err = g.writeOrderEvents(c, uint(userID), lastID); err != nil {
+			if !errors.Is(err, ctx.Err()) {
+				g.l.ErrorCtx(ctx, "OrderEvents - stream aborted", zap.Error(err))
+			}
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-wakeup:
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeOrderEvents отправляет все события после afterID и возвращает id последнего
+func (g *GopherMartRoutes) writeOrderEvents(c *gin.Context, userID uint, afterID int64) (int64, error) {
+	for {
+		events, err := g.u.GetOrderEvents(c.Request.Context(), userID, afterID)
+		if err != nil {
+			return afterID, err
+		}
+		if len(events) == 0 {
+			return afterID, nil
+		}
+		for _, e := range events {
+			c.Render(-1, sse.Event{
+				Id:    strconv.FormatInt(e.ID, 10),
+				Event: orderEventName,
+				Retry: orderEventsRetry,
+				Data:  e,
+			})
+			afterID = e.ID
+		}
+		c.Writer.Flush()
+	}
+}
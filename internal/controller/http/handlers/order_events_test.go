@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupOrderEventsHandler(t *testing.T, opts ...usecase.Option) *gin.Engine {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	cfg := NewTestConfig()
+
+	uc := usecase.NewGopherMart(mocks.NewMockRepository(ctrl), mocks.NewMockBalanceUseCase(ctrl),
+		mocks.NewMockOrderUseCase(ctrl), mocks.NewMockAuthUseCase(ctrl), log, opts...)
+	h := NewHandler(gin.New(), *uc, cfg, security.NewJwtToken(cfg.Jwt.EncryptionKey, *uc), nil, log)
+
+	router := gin.New()
+	router.GET("/api/user/orders/events", func(c *gin.Context) {
+		c.Set("userID", "7")
+		h.OrderEvents(c)
+	})
+	return router
+}
+
+func TestOrderEventsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	accrual := entity.NewPoints(500, 0)
+	created := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("missed events are replayed after Last-Event-ID", func(t *testing.T) {
+		events := mocks.NewMockOrderEventRepository(gomock.NewController(t))
+		router := setupOrderEventsHandler(t, usecase.WithOrderEvents(events))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		gomock.InOrder(
+			events.EXPECT().GetOrderEvents(gomock.Any(), uint(7), int64(5), gomock.Any()).Return([]entity.OrderEvent{
+				{ID: 6, UserID: 7, Number: "12345678903", Status: "PROCESSED", Accrual: &accrual, CreatedAt: created},
+			}, nil),
+			events.EXPECT().GetOrderEvents(gomock.Any(), uint(7), int64(6), gomock.Any()).DoAndReturn(
+				func(context.Context, uint, int64, int) ([]entity.OrderEvent, error) {
+					cancel()
+					return nil, nil
+				}),
+		)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/user/orders/events", nil).WithContext(ctx)
+		req.Header.Set("Last-Event-ID", "5")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Equal(t, "id:6\nevent:order\nretry:3000\n"+
+			`data:{"number":"12345678903","status":"PROCESSED","accrual":500.00,"created_at":"2025-02-01T10:00:00Z"}`+"\n\n",
+			w.Body.String())
+	})
+
+	t.Run("new stream starts after the latest event", func(t *testing.T) {
+		events := mocks.NewMockOrderEventRepository(gomock.NewController(t))
+		router := setupOrderEventsHandler(t, usecase.WithOrderEvents(events))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events.EXPECT().GetLastOrderEventID(gomock.Any(), uint(7)).Return(int64(42), nil)
+		events.EXPECT().GetOrderEvents(gomock.Any(), uint(7), int64(42), gomock.Any()).DoAndReturn(
+			func(context.Context, uint, int64, int) ([]entity.OrderEvent, error) {
+				cancel()
+				return nil, nil
+			})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/user/orders/events", nil).WithContext(ctx))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("invalid Last-Event-ID", func(t *testing.T) {
+		events := mocks.NewMockOrderEventRepository(gomock.NewController(t))
+		router := setupOrderEventsHandler(t, usecase.WithOrderEvents(events))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/user/orders/events", nil)
+		req.Header.Set("Last-Event-ID", "abc")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("stream is disabled", func(t *testing.T) {
+		router := setupOrderEventsHandler(t)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/user/orders/events", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"go-loyalty-system/internal/entity"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	headerNextCursor = "X-Next-Cursor"
+	headerPageLimit  = "X-Page-Limit"
+	dateLayout       = "2006-01-02"
+)
+
+// listFilter разбирает параметры limit, cursor, status, from, to и sort.
+// status можно передать несколько раз или через запятую, from и to —
+// в RFC 3339 или датой YYYY-MM-DD.
+func (g *GopherMartRoutes) listFilter(c *gin.Context) (entity.ListFilter, bool) {
+	var f entity.ListFilter
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			g.ErrorResponse(c, http.StatusBadRequest, "limit must be a positive integer", err)
+			return f, false
+		}
+		f.Limit = limit
+	}
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := entity.DecodePageCursor(raw)
+		if err != nil {
+			g.ErrorResponse(c, http.StatusBadRequest, "invalid cursor", err)
+			return f, false
+		}
+		f.Cursor = cursor
+	}
+	for _, raw := range c.QueryArray("status") {
+		for _, s := range strings.Split(raw, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				f.Statuses = append(f.Statuses, entity.OrderStatus(s))
+			}
+		}
+	}
+	var err error
+	if f.From, err = parseQueryTime(c.Query("from")); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "invalid from date", err)
+		return f, false
+	}
+	if f.To, err = parseQueryTime(c.Query("to")); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "invalid to date", err)
+		return f, false
+	}
+	f.Sort = entity.SortDirection(strings.ToLower(c.Query("sort")))
+	return f, true
+}
+
+// parseQueryTime время хранится без часового пояса по часам сервера,
+// поэтому границы периода переводятся в его пояс. Пустая строка — без границы.
+func parseQueryTime(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.ParseInLocation(dateLayout, raw, time.Local); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	t = t.In(time.Local)
+	return &t, nil
+}
+
+// writePageHeaders метаданные страницы передаются в заголовках, чтобы тело
+// ответа осталось прежним массивом
+func writePageHeaders(c *gin.Context, limit int, next string) {
+	if limit == 0 {
+		limit = entity.DefaultPageLimit
+	}
+	c.Header(headerPageLimit, strconv.Itoa(limit))
+	if next == "" {
+		return
+	}
+	c.Header(headerNextCursor, next)
+	q := c.Request.URL.Query()
+	q.Set("cursor", next)
+	link := url.URL{Path: c.Request.URL.Path, RawQuery: q.Encode()}
+	c.Header("Link", "<"+link.String()+`>; rel="next"`)
+}
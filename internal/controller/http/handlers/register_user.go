@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"go-loyalty-system/internal/entity"
 	"net/http"
 
@@ -31,23 +32,29 @@ func (g *GopherMartRoutes) RegisterUser(c *gin.Context) {
 			Password: request.Password,
 		},
 	)
-	if err != nil {
+	switch {
+	case errors.Is(err, entity.ErrUserExists):
+		g.ErrorResponse(c, http.StatusConflict, "login already taken", err)
+		return
+	case errors.Is(err, entity.ErrInvalidPassword):
+		g.ErrorResponse(c, http.StatusBadRequest, "password is too long", err)
+		return
+	case err != nil:
 		g.ErrorResponse(c, http.StatusInternalServerError, "database problems", err)
 		return
 	}
 	c.Set("Accept", "application/json")
 	c.Set("Content-Type", "application/json")
 
-	user, _ := g.u.GetUserByLogin(c.Request.Context(), entity.User{
-		Login:    request.Login,
-		Password: request.Password,
-	})
-	token, err := g.token.GenerateToken(user)
+	user, err := g.u.GetUserByLogin(c.Request.Context(), entity.User{Login: request.Login})
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "database problems", err)
+		return
+	}
+	tokens, err := g.token.IssueTokens(c.Request.Context(), user)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err})
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to issue token", err)
 		return
 	}
-	c.Header("Content-Type", "application/json")
-	c.SetCookie("token", token, _time, "/", "localhost", false, true)
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	g.writeTokens(c, tokens)
 }
@@ -16,9 +16,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
-func setupUserHandler(t *testing.T) (*GopherMartRoutes, *mocks.MockUserService) {
+func setupUserHandler(t *testing.T) (*GopherMartRoutes, *mocks.MockAuthUseCase) {
 	ctrl := gomock.NewController(t)
 	log, _ := logging.NewZapLogger(1)
 
@@ -26,7 +27,7 @@ func setupUserHandler(t *testing.T) (*GopherMartRoutes, *mocks.MockUserService)
 	accrualRepo := mocks.NewMockRepository(ctrl)
 	balanceRepo := mocks.NewMockBalanceUseCase(ctrl)
 	orderRepo := mocks.NewMockOrderUseCase(ctrl)
-	userRepo := mocks.NewMockUserService(ctrl)
+	userRepo := mocks.NewMockAuthUseCase(ctrl)
 
 	cfg := NewTestConfig()
 
@@ -51,16 +52,21 @@ func TestRegisterUser(t *testing.T) {
 		reqBody, _ := json.Marshal(userReq)
 
 		mockUseCase.EXPECT().
-			RegisterUser(ctx, entity.User{Login: "test24e7", Password: "piqQJ5SihA264dO324j132"}).
-			Return(nil).Times(1)
+			RegisterUser(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, u entity.User) error {
+				assert.Equal(t, "test24e7", u.Login)
+				assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("piqQJ5SihA264dO324j132")))
+				return nil
+			}).Times(1)
 
 		mockUseCase.EXPECT().
-			GetUserByLogin(ctx, entity.User{Login: "test24e7", Password: "piqQJ5SihA264dO324j132"}).
-			Return(&entity.User{Login: "test24e7", Password: "piqQJ5SihA264dO324j132"}, nil).Times(1)
+			GetUserByLogin(ctx, entity.User{Login: "test24e7"}).
+			Return(&entity.User{Login: "test24e7"}, nil).Times(1)
 
+		// access- и refresh-токен
 		mockUseCase.EXPECT().
 			CreateToken(ctx, gomock.Any()).
-			Return(nil).Times(1)
+			Return(nil).Times(2)
 
 		req := httptest.NewRequest(http.MethodPost, "/api/user/register", bytes.NewBuffer(reqBody))
 		req.Header.Set("Content-Type", "application/json")
@@ -71,6 +77,25 @@ func TestRegisterUser(t *testing.T) {
 		assert.Equal(t, http.StatusOK, resp.Code)
 	})
 
+	t.Run("login already taken", func(t *testing.T) {
+		reqBody, _ := json.Marshal(map[string]string{
+			"login":    "test24e7",
+			"password": "piqQJ5SihA264dO324j132",
+		})
+
+		mockUseCase.EXPECT().
+			RegisterUser(ctx, gomock.Any()).
+			Return(entity.ErrUserExists).Times(1)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/user/register", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
 	t.Run("invalid request body", func(t *testing.T) {
 		reqBody := []byte(`{invalid json}`)
 
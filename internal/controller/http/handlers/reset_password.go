@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Reset password
+// @Description Replaces the password after checking the current one. Accounts migrated from plaintext passwords must do this before logging in.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body entity.PasswordReset true "Current and new password"
+// @Success 200 "Password changed"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Invalid login or password"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/user/password/reset [post]
+func (g *GopherMartRoutes) ResetPassword(c *gin.Context) {
+	var request entity.PasswordReset
+	if err := c.ShouldBindJSON(&request); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	err := g.u.ResetPassword(c.Request.Context(), request)
+	switch {
+	case errors.Is(err, entity.ErrInvalidCredentials):
+		g.ErrorResponse(c, http.StatusUnauthorized, "invalid login or password", err)
+		return
+	case errors.Is(err, entity.ErrInvalidPassword):
+		g.ErrorResponse(c, http.StatusBadRequest, "password is too long", err)
+		return
+	case err != nil:
+		g.ErrorResponse(c, http.StatusInternalServerError, "internal server error", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "password changed"})
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Reverse withdrawal
+// @Description Return points of a withdrawal, e.g. when the order they were spent on is cancelled.
+// @Description Without sum the whole remainder is returned. Reversals never exceed the withdrawn amount in total
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entity.WithdrawalReversal true "Order of the withdrawal, optional sum and reason"
+// @Success 200 {object} entity.Withdrawal "Withdrawal after the reversal"
+// @Failure 400 {object} ErrorResponse "Invalid request or missing reason"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Withdrawal not found"
+// @Failure 409 {object} ErrorResponse "Reversal exceeds the withdrawn amount"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/withdrawals/reverse [post]
+func (g *GopherMartRoutes) AdminReverseWithdrawal(c *gin.Context) {
+	actorID, ok := g.adminActor(c)
+	if !ok {
+		return
+	}
+	var request entity.WithdrawalReversal
+	if err := c.ShouldBindJSON(&request); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "order and reason are required", err)
+		return
+	}
+	w, err := g.u.AdminReverseWithdrawal(c.Request.Context(), actorID, request)
+	if err != nil {
+		g.reversalErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+// @Summary Reverse withdrawal by merchant
+// @Description Return points of a withdrawal when the merchant cancels the order they were spent on.
+// @Description Only orders this merchant uploaded for the same buyer can be reversed; other withdrawals are not found.
+// @Description Without sum the whole remainder is returned. Reversals never exceed the withdrawn amount in total
+// @Tags merchant
+// @Accept json
+// @Produce json
+// @Param request body entity.WithdrawalReversal true "Order of the withdrawal, optional sum and reason"
+// @Success 200 {object} entity.Withdrawal "Withdrawal after the reversal"
+// @Failure 400 {object} ErrorResponse "Invalid request or missing reason"
+// @Failure 401 {object} ErrorResponse "Invalid merchant key or token"
+// @Failure 403 {object} ErrorResponse "Merchant account required"
+// @Failure 404 {object} ErrorResponse "Withdrawal not found among this merchant's orders"
+// @Failure 409 {object} ErrorResponse "Reversal exceeds the withdrawn amount"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/merchant/withdrawals/reverse [post]
+func (g *GopherMartRoutes) MerchantReverseWithdrawal(c *gin.Context) {
+	// общий ключ API не говорит, какой это магазин, поэтому возврат доступен только по токену
+	merchantID, err := strconv.ParseUint(c.GetString("userID"), 10, 32)
+	if err != nil || merchantID == 0 {
+		g.ErrorResponse(c, http.StatusForbidden, "merchant account required", err)
+		return
+	}
+	var request entity.WithdrawalReversal
+	if err := c.ShouldBindJSON(&request); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "order and reason are required", err)
+		return
+	}
+	w, err := g.u.MerchantReverseWithdrawal(c.Request.Context(), uint(merchantID), request)
+	if err != nil {
+		g.reversalErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+func (g *GopherMartRoutes) reversalErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, entity.ErrReasonRequired):
+		g.ErrorResponse(c, http.StatusBadRequest, "reason is required", err)
+	case errors.Is(err, entity.ErrInvalidReversal):
+		g.ErrorResponse(c, http.StatusBadRequest, "reversal sum must be positive", err)
+	case errors.Is(err, entity.ErrWithdrawalNotFound):
+		g.ErrorResponse(c, http.StatusNotFound, "withdrawal not found", err)
+	case errors.Is(err, entity.ErrReversalExceeded):
+		g.ErrorResponse(c, http.StatusConflict, "reversal exceeds the withdrawn amount", err)
+	default:
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to reverse withdrawal", err)
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"go-loyalty-system/internal/entity"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Upload order by merchant
+// @Description Register a buyer's order together with its basket on behalf of the merchant
+// @Tags merchant
+// @Accept json
+// @Produce json
+// @Param X-Merchant-Key header string false "Merchant API key, not needed with a merchant role token"
+// @Param request body entity.MerchantOrderRequest true "Order with goods"
+// @Success 200 "Order already uploaded by this buyer"
+// @Success 202 "Order accepted for processing"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Invalid merchant key or token"
+// @Failure 403 {object} ErrorResponse "Merchant role required"
+// @Failure 404 {object} ErrorResponse "Buyer not found"
+// @Failure 409 {object} ErrorResponse "Order uploaded by another user"
+// @Failure 422 {object} ErrorResponse "Invalid order number"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/merchant/orders [post]
+func (g *GopherMartRoutes) SetMerchantOrder(c *gin.Context) {
+	var request entity.MerchantOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	user, err := g.u.GetUserByLogin(c.Request.Context(), entity.User{Login: request.Login})
+	if err != nil {
+		g.ErrorResponse(c, http.StatusNotFound, "buyer not found", err)
+		return
+	}
+
+	order := entity.Order{Number: request.Order, Goods: request.Goods}
+	// по токену запоминаем магазин: возвращать баллы он сможет только по своим заказам
+	if id, err := strconv.ParseUint(c.GetString("userID"), 10, 32); err == nil && id != 0 {
+		merchantID := uint(id)
+		order.MerchantID = &merchantID
+	}
+	if err := g.u.SetOrders(c.Request.Context(), user.ID, order); err != nil {
+		g.orderErrorResponse(c, err)
+		return
+	}
+	g.accrual.AddOrder(c.Request.Context(), order.Number)
+	c.Status(http.StatusAccepted)
+}
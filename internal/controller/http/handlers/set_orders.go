@@ -6,21 +6,29 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// @Summary Upload order
+// @Description Upload an order number as text/plain or an order with its basket as JSON
+// @Tags orders
+// @Accept plain,json
+// @Produce json
+// @Param request body entity.OrderRequest false "Order with goods"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 "Order already uploaded by this user"
+// @Success 202 "Order accepted for processing"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Order uploaded by another user or Idempotency-Key in progress"
+// @Failure 422 {object} ErrorResponse "Invalid order number or Idempotency-Key reused with a different request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/user/orders [post]
 func (g *GopherMartRoutes) SetOrders(c *gin.Context) {
-	body, err := io.ReadAll(c.Request.Body)
-	if err != nil {
-		g.ErrorResponse(c, http.StatusBadRequest, "invalid userId", err)
-		return
-	}
-	defer c.Request.Body.Close()
-
-	orderNumber := string(body)
-	if orderNumber == "" {
-		g.ErrorResponse(c, http.StatusBadRequest, "empty order number", nil)
+	order, ok := g.bindOrder(c)
+	if !ok {
 		return
 	}
 
@@ -30,28 +38,60 @@ func (g *GopherMartRoutes) SetOrders(c *gin.Context) {
 		return
 	}
 
-	err = g.u.SetOrders(c.Request.Context(), uint(userID), entity.Order{Number: orderNumber})
-	if err != nil {
-		status := http.StatusInternalServerError
-		errMsg := "failed to process order"
-		switch {
-		case errors.Is(err, entity.ErrInvalidOrder):
-			status = http.StatusUnprocessableEntity
-			errMsg = "invalid order number format"
-		case errors.Is(err, entity.ErrOrderExistsThisUser):
-			status = http.StatusOK
-			errMsg = "order already uploaded by this user"
-		case errors.Is(err, entity.ErrOrderExistsOtherUser):
-			status = http.StatusConflict
-			errMsg = "order already uploaded by another user"
-		}
-		g.ErrorResponse(c, status, errMsg, err)
+	if err := g.u.SetOrders(c.Request.Context(), uint(userID), order); err != nil {
+		g.orderErrorResponse(c, err)
 		return
 	}
-	g.accrual.AddOrder(orderNumber)
+	g.accrual.AddOrder(c.Request.Context(), order.Number)
 	c.Status(http.StatusAccepted)
 }
 
+// bindOrder читает заказ из тела запроса: номер текстом или JSON с корзиной
+func (g *GopherMartRoutes) bindOrder(c *gin.Context) (entity.Order, bool) {
+	if c.ContentType() == gin.MIMEJSON {
+		var request entity.OrderRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			g.ErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+			return entity.Order{}, false
+		}
+		return entity.Order{Number: request.Order, Goods: request.Goods}, true
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "invalid userId", err)
+		return entity.Order{}, false
+	}
+	defer c.Request.Body.Close()
+
+	orderNumber := strings.TrimSpace(string(body))
+	if orderNumber == "" {
+		g.ErrorResponse(c, http.StatusBadRequest, "empty order number", nil)
+		return entity.Order{}, false
+	}
+	return entity.Order{Number: orderNumber}, true
+}
+
+func (g *GopherMartRoutes) orderErrorResponse(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	errMsg := "failed to process order"
+	switch {
+	case errors.Is(err, entity.ErrInvalidOrder):
+		status = http.StatusUnprocessableEntity
+		errMsg = "invalid order number format"
+	case errors.Is(err, entity.ErrInvalidOrderGoods):
+		status = http.StatusBadRequest
+		errMsg = "invalid order goods"
+	case errors.Is(err, entity.ErrOrderExistsThisUser):
+		status = http.StatusOK
+		errMsg = "order already uploaded by this user"
+	case errors.Is(err, entity.ErrOrderExistsOtherUser):
+		status = http.StatusConflict
+		errMsg = "order already uploaded by another user"
+	}
+	g.ErrorResponse(c, status, errMsg, err)
+}
+
 func (g *GopherMartRoutes) SetOrdersHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		g.SetOrders(c)
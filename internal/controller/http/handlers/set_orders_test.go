@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"errors"
+	"go-loyalty-system/internal/controller/accrual"
+	"go-loyalty-system/internal/controller/http/middleware"
+	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type setOrdersMocks struct {
+	accrual *mocks.MockRepository
+	balance *mocks.MockBalanceUseCase
+	order   *mocks.MockOrderUseCase
+	user    *mocks.MockAuthUseCase
+}
+
+func setupSetOrdersRouter(t *testing.T) (*gin.Engine, setOrdersMocks) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	m := setOrdersMocks{
+		accrual: mocks.NewMockRepository(ctrl),
+		balance: mocks.NewMockBalanceUseCase(ctrl),
+		order:   mocks.NewMockOrderUseCase(ctrl),
+		user:    mocks.NewMockAuthUseCase(ctrl),
+	}
+	cfg := NewTestConfig()
+	cfg.Merchant.APIKey = "merchant-key"
+
+	uc := usecase.NewGopherMart(m.accrual, m.balance, m.order, m.user, log)
+	token := security.NewJwtToken(cfg.Jwt.EncryptionKey, *uc)
+	oa := accrual.NewOrderProcessor(nil, 1, *uc, log)
+	h := NewHandler(gin.New(), *uc, cfg, token, oa, log)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/user/orders", func(c *gin.Context) {
+		c.Set("userID", "1")
+		h.SetOrders(c)
+	})
+	// без ключа магазин входит токеном; здесь вместо токена сразу учетная запись магазина 42
+	merchantAuth := middleware.MerchantAuth(cfg.Merchant.APIKey, func(c *gin.Context) {
+		c.Set("userID", "42")
+	})
+	router.POST("/api/merchant/orders", merchantAuth, h.SetMerchantOrder)
+	router.POST("/api/merchant/withdrawals/reverse", merchantAuth, h.MerchantReverseWithdrawal)
+	return router, m
+}
+
+func TestSetOrders(t *testing.T) {
+	goods := []entity.Product{
+		{Description: "Чайник Bork", Price: entity.NewPoints(7000, 0)},
+		{Description: "Утюг Philips", Price: entity.NewPoints(3000, 0)},
+	}
+
+	t.Run("bare order number", func(t *testing.T) {
+		router, m := setupSetOrdersRouter(t)
+		order := entity.Order{Number: "12345678903"}
+
+		m.order.EXPECT().ValidateOrder(order, uint(1)).Return(nil)
+		m.order.EXPECT().SetOrders(gomock.Any(), uint(1), gomock.Any()).
+			DoAndReturn(func(_ any, _ uint, o entity.Order) error {
+				assert.Equal(t, order.Number, o.Number)
+				assert.Empty(t, o.Goods)
+				return nil
+			})
+		m.accrual.EXPECT().EnqueueAccrualJob(gomock.Any(), order.Number, "").Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader("12345678903"))
+		req.Header.Set("Content-Type", "text/plain")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+	})
+
+	t.Run("order with basket", func(t *testing.T) {
+		router, m := setupSetOrdersRouter(t)
+		order := entity.Order{Number: "12345678903", Goods: goods}
+
+		m.order.EXPECT().ValidateOrder(order, uint(1)).Return(nil)
+		m.order.EXPECT().SetOrders(gomock.Any(), uint(1), gomock.Any()).
+			DoAndReturn(func(_ any, _ uint, o entity.Order) error {
+				assert.Equal(t, goods, o.Goods)
+				return nil
+			})
+		m.accrual.EXPECT().EnqueueAccrualJob(gomock.Any(), order.Number, "").Return(nil)
+
+		body := `{"order":"12345678903","goods":[{"description":"Чайник Bork","price":7000},{"description":"Утюг Philips","price":3000}]}`
+		req := httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+	})
+
+	t.Run("invalid goods", func(t *testing.T) {
+		router, _ := setupSetOrdersRouter(t)
+
+		body := `{"order":"12345678903","goods":[{"description":"","price":100}]}`
+		req := httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("malformed json", func(t *testing.T) {
+		router, _ := setupSetOrdersRouter(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader(`{"order":`))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("order of another user", func(t *testing.T) {
+		router, m := setupSetOrdersRouter(t)
+
+		m.order.EXPECT().ValidateOrder(gomock.Any(), uint(1)).Return(entity.ErrOrderExistsOtherUser)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader("12345678903"))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+}
+
+func TestSetMerchantOrder(t *testing.T) {
+	body := `{"order":"12345678903","login":"buyer","goods":[{"description":"Чайник Bork","price":7000}]}`
+
+	t.Run("order registered for buyer", func(t *testing.T) {
+		router, m := setupSetOrdersRouter(t)
+		buyer := &entity.User{ID: 7, Login: "buyer"}
+
+		m.user.EXPECT().GetUserByLogin(gomock.Any(), entity.User{Login: "buyer"}).Return(buyer, nil)
+		m.order.EXPECT().ValidateOrder(gomock.Any(), buyer.ID).Return(nil)
+		m.order.EXPECT().SetOrders(gomock.Any(), buyer.ID, gomock.Any()).
+			DoAndReturn(func(_ any, _ uint, o entity.Order) error {
+				assert.Equal(t, []entity.Product{{Description: "Чайник Bork", Price: entity.NewPoints(7000, 0)}}, o.Goods)
+				assert.Nil(t, o.MerchantID)
+				return nil
+			})
+		m.accrual.EXPECT().EnqueueAccrualJob(gomock.Any(), "12345678903", "").Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/merchant/orders", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Merchant-Key", "merchant-key")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+	})
+
+	t.Run("merchant account is remembered on the order", func(t *testing.T) {
+		router, m := setupSetOrdersRouter(t)
+		buyer := &entity.User{ID: 7, Login: "buyer"}
+
+		m.user.EXPECT().GetUserByLogin(gomock.Any(), entity.User{Login: "buyer"}).Return(buyer, nil)
+		m.order.EXPECT().ValidateOrder(gomock.Any(), buyer.ID).Return(nil)
+		m.order.EXPECT().SetOrders(gomock.Any(), buyer.ID, gomock.Any()).
+			DoAndReturn(func(_ any, _ uint, o entity.Order) error {
+				require.NotNil(t, o.MerchantID)
+				assert.Equal(t, uint(42), *o.MerchantID)
+				return nil
+			})
+		m.accrual.EXPECT().EnqueueAccrualJob(gomock.Any(), "12345678903", "").Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/merchant/orders", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+	})
+
+	t.Run("invalid merchant key", func(t *testing.T) {
+		router, _ := setupSetOrdersRouter(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/merchant/orders", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Merchant-Key", "wrong")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("unknown buyer", func(t *testing.T) {
+		router, m := setupSetOrdersRouter(t)
+
+		m.user.EXPECT().GetUserByLogin(gomock.Any(), entity.User{Login: "buyer"}).
+			Return(nil, errors.New("no rows in result set"))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/merchant/orders", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Merchant-Key", "merchant-key")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func TestMerchantReverseWithdrawal(t *testing.T) {
+	const order = "2377225624"
+	body := `{"order":"` + order + `","reason":"order cancelled"}`
+	call := func(router *gin.Engine, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/merchant/withdrawals/reverse", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("X-Merchant-Key", key)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	withdrawal := func() *entity.Withdrawal {
+		return &entity.Withdrawal{ID: 3, UserID: 7, OrderNumber: order, Amount: entity.NewPoints(100, 0)}
+	}
+
+	t.Run("merchant reverses a withdrawal on its own order", func(t *testing.T) {
+		router, m := setupSetOrdersRouter(t)
+		m.balance.EXPECT().GetWithdrawalForUpdate(gomock.Any(), order).Return(withdrawal(), nil)
+		m.order.EXPECT().IsMerchantOrder(gomock.Any(), order, uint(7), uint(42)).Return(true, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(gomock.Any(), uint(7)).Return(&entity.Balance{}, nil)
+		m.balance.EXPECT().ReverseWithdrawal(gomock.Any(), gomock.Any(), entity.NewPoints(100, 0), "order cancelled").
+			Return(nil)
+
+		resp := call(router, "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"status":"REVERSED"`)
+	})
+
+	t.Run("withdrawal of another merchant or a customer is not found", func(t *testing.T) {
+		router, m := setupSetOrdersRouter(t)
+		m.balance.EXPECT().GetWithdrawalForUpdate(gomock.Any(), order).Return(withdrawal(), nil)
+		m.order.EXPECT().IsMerchantOrder(gomock.Any(), order, uint(7), uint(42)).Return(false, nil)
+
+		assert.Equal(t, http.StatusNotFound, call(router, "").Code)
+	})
+
+	t.Run("shared API key cannot reverse", func(t *testing.T) {
+		router, _ := setupSetOrdersRouter(t)
+
+		assert.Equal(t, http.StatusForbidden, call(router, "merchant-key").Code)
+	})
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	statementOpening = "OPENING_BALANCE"
+	statementClosing = "CLOSING_BALANCE"
+)
+
+var statementHeader = []string{"date", "type", "order", "status", "amount", "balance"}
+
+// @Summary Export account statement
+// @Description Download orders and balance changes for the period with the opening and closing balance.
+// @Description The statement is streamed: csv is RFC 4180 with a header row, OPENING_BALANCE as the first
+// @Description and CLOSING_BALANCE as the last row; json is an object with entries between the two balances.
+// @Description A statement without the closing balance was interrupted and must be requested again
+// @Tags balance
+// @Produce text/csv
+// @Produce json
+// @Param from query string false "Period start inclusive, RFC 3339 or YYYY-MM-DD; from the first operation by default"
+// @Param to query string false "Period end exclusive, RFC 3339 or YYYY-MM-DD; now by default"
+// @Param format query string false "csv (default) or json"
+// @Success 200 {object} entity.Statement
+// @Failure 400 {object} ErrorResponse "Invalid period or format"
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/user/statement [get]
+func (g *GopherMartRoutes) GetStatement(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.MustGet("userID").(string), 10, 64)
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to parse userID", err)
+		return
+	}
+	var w entity.StatementWriter
+	switch entity.StatementFormat(strings.ToLower(c.DefaultQuery("format", string(entity.StatementCSV)))) {
+	case entity.StatementCSV:
+		w = &csvStatementWriter{c: c}
+	case entity.StatementJSON:
+		w = &jsonStatementWriter{c: c}
+	default:
+		g.ErrorResponse(c, http.StatusBadRequest, "format must be csv or json", nil)
+		return
+	}
+	var p entity.StatementPeriod
+	if p.From, err = parseQueryTime(c.Query("from")); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "invalid from date", err)
+		return
+	}
+	if p.To, err = parseQueryTime(c.Query("to")); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "invalid to date", err)
+		return
+	}
+
+	err = g.u.ExportStatement(c.Request.Context(), uint(userID), p, w)
+	switch {
+	case err == nil:
+	case c.Writer.Written():
+		// выписка уже частично отдана: статус не поменять, клиент увидит ее без итога
+		if !errors.Is(err, c.Request.Context().Err()) {
+			g.l.ErrorCtx(c, "GetStatement - stream aborted", zap.Error(err))
+		}
+	case errors.Is(err, entity.ErrInvalidStatement):
+		g.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
+	default:
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to export statement", err)
+	}
+}
+
+// startStatement заголовки ответа выписки; большая выписка может писаться
+// дольше WriteTimeout сервера
+func startStatement(c *gin.Context, contentType, filename string) {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+}
+
+// csvStatementWriter выписка в CSV по RFC 4180: строки через CRLF,
+// поля с запятыми и кавычками экранируются
+type csvStatementWriter struct {
+	c *gin.Context
+	w *csv.Writer
+}
+
+func (s *csvStatementWriter) Begin(st entity.Statement) error {
+	startStatement(s.c, "text/csv; charset=utf-8; header=present", "statement.csv")
+	s.w = csv.NewWriter(s.c.Writer)
+	s.w.UseCRLF = true
+	if err := s.w.Write(statementHeader); err != nil {
+		return err
+	}
+	var from string
+	if st.From != nil {
+		from = st.From.Format(time.RFC3339)
+	}
+	return s.w.Write([]string{from, statementOpening, "", "", "", st.OpeningBalance.String()})
+}
+
+func (s *csvStatementWriter) Line(l entity.StatementLine) error {
+	var amount, balance string
+	if l.Amount != nil {
+		amount = l.Amount.String()
+	}
+	if l.Balance != nil {
+		balance = l.Balance.String()
+	}
+	return s.w.Write([]string{l.At.Format(time.RFC3339), l.Type, l.OrderNumber, string(l.Status), amount, balance})
+}
+
+func (s *csvStatementWriter) End(st entity.Statement) error {
+	err := s.w.Write([]string{st.To.Format(time.RFC3339), statementClosing, "", "", "", st.ClosingBalance.String()})
+	if err != nil {
+		return err
+	}
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// jsonStatementWriter выписка одним JSON-объектом: entries пишутся по одной
+// записи, а closing_balance дописывается последним полем
+type jsonStatementWriter struct {
+	c       *gin.Context
+	entries int
+}
+
+func (s *jsonStatementWriter) Begin(st entity.Statement) error {
+	startStatement(s.c, "application/json; charset=utf-8", "statement.json")
+	head, err := json.Marshal(struct {
+		From           *time.Time    `json:"from,omitempty"`
+		To             time.Time     `json:"to"`
+		OpeningBalance entity.Points `json:"opening_balance"`
+	}{st.From, st.To, st.OpeningBalance})
+	if err != nil {
+		return err
+	}
+	// объект остается открытым до End
+	_, err = s.c.Writer.Write(append(head[:len(head)-1], `,"entries":[`...))
+	return err
+}
+
+func (s *jsonStatementWriter) Line(l entity.StatementLine) error {
+	entry, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if s.entries > 0 {
+		entry = append([]byte{','}, entry...)
+	}
+	s.entries++
+	_, err = s.c.Writer.Write(entry)
+	return err
+}
+
+func (s *jsonStatementWriter) End(st entity.Statement) error {
+	closing, err := json.Marshal(st.ClosingBalance)
+	if err != nil {
+		return err
+	}
+	_, err = s.c.Writer.WriteString(`],"closing_balance":` + string(closing) + "}")
+	return err
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupStatementHandler(t *testing.T) (*gin.Engine, *mocks.MockBalanceUseCase) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+
+	balanceRepo := mocks.NewMockBalanceUseCase(ctrl)
+	cfg := NewTestConfig()
+	uc := usecase.NewGopherMart(mocks.NewMockRepository(ctrl), balanceRepo,
+		mocks.NewMockOrderUseCase(ctrl), mocks.NewMockAuthUseCase(ctrl), log)
+	h := NewHandler(gin.New(), *uc, cfg, security.NewJwtToken(cfg.Jwt.EncryptionKey, *uc), nil, log)
+
+	router := gin.New()
+	api := router.Group("/api/user", func(c *gin.Context) {
+		c.Set("userID", "7")
+	})
+	api.GET("/statement", h.GetStatement)
+	return router, balanceRepo
+}
+
+func TestGetStatementHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	at := from.Add(12 * time.Hour)
+	accrual := entity.NewPoints(100, 50)
+	withdrawal := entity.NewPoints(-30, 0)
+	get := func(r *gin.Engine, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/user/statement"+query, nil))
+		return w
+	}
+	expectStatement := func(balanceRepo *mocks.MockBalanceUseCase) {
+		balanceRepo.EXPECT().GetBalanceAt(gomock.Any(), uint(7), gomock.Any()).Return(entity.NewPoints(10, 0), nil)
+		balanceRepo.EXPECT().StreamStatement(gomock.Any(), uint(7), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uint, _ entity.StatementPeriod, fn func(entity.StatementLine) error) error {
+				for _, l := range []entity.StatementLine{
+					{Type: entity.StatementOrder, OrderNumber: "12345678903", Status: entity.OrderStatusProcessed, At: at},
+					{Type: string(entity.LedgerAccrual), OrderNumber: "12345678903", Amount: &accrual, At: at},
+					{Type: string(entity.LedgerWithdrawal), OrderNumber: "2377225624", Amount: &withdrawal, At: at},
+				} {
+					if err := fn(l); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+	}
+
+	t.Run("csv statement", func(t *testing.T) {
+		r, balanceRepo := setupStatementHandler(t)
+		expectStatement(balanceRepo)
+
+		w := get(r, "?from=2025-02-01T00:00:00Z&to=2025-03-01T00:00:00Z")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+		stamp := at.In(time.Local).Format(time.RFC3339)
+		assert.Equal(t, "date,type,order,status,amount,balance\r\n"+
+			from.In(time.Local).Format(time.RFC3339)+",OPENING_BALANCE,,,,10.00\r\n"+
+			stamp+",ORDER,12345678903,PROCESSED,,\r\n"+
+			stamp+",ACCRUAL,12345678903,,100.50,110.50\r\n"+
+			stamp+",WITHDRAWAL,2377225624,,-30.00,80.50\r\n"+
+			time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC).In(time.Local).Format(time.RFC3339)+
+			",CLOSING_BALANCE,,,,80.50\r\n", w.Body.String())
+	})
+
+	t.Run("json statement", func(t *testing.T) {
+		r, balanceRepo := setupStatementHandler(t)
+		expectStatement(balanceRepo)
+
+		w := get(r, "?from=2025-02-01T00:00:00Z&to=2025-03-01T00:00:00Z&format=json")
+		assert.Equal(t, http.StatusOK, w.Code)
+		stamp := `"` + at.In(time.Local).Format(time.RFC3339) + `"`
+		assert.JSONEq(t, `{
+			"from": "`+from.In(time.Local).Format(time.RFC3339)+`",
+			"to": "`+time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC).In(time.Local).Format(time.RFC3339)+`",
+			"opening_balance": 10.00,
+			"entries": [
+				{"type": "ORDER", "order": "12345678903", "status": "PROCESSED", "date": `+stamp+`},
+				{"type": "ACCRUAL", "order": "12345678903", "amount": 100.50, "balance": 110.50, "date": `+stamp+`},
+				{"type": "WITHDRAWAL", "order": "2377225624", "amount": -30.00, "balance": 80.50, "date": `+stamp+`}
+			],
+			"closing_balance": 80.50
+		}`, w.Body.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		r, _ := setupStatementHandler(t)
+
+		assert.Equal(t, http.StatusBadRequest, get(r, "?format=xml").Code)
+	})
+
+	t.Run("from after to", func(t *testing.T) {
+		r, _ := setupStatementHandler(t)
+
+		assert.Equal(t, http.StatusBadRequest, get(r, "?from=2025-03-01&to=2025-02-01").Code)
+	})
+
+	t.Run("opening balance failure", func(t *testing.T) {
+		r, balanceRepo := setupStatementHandler(t)
+		balanceRepo.EXPECT().GetBalanceAt(gomock.Any(), uint(7), gomock.Any()).
+			Return(entity.NewPoints(0, 0), errors.New("db down"))
+
+		assert.Equal(t, http.StatusInternalServerError, get(r, "?from=2025-02-01").Code)
+	})
+
+	t.Run("interrupted stream has no closing balance", func(t *testing.T) {
+		r, balanceRepo := setupStatementHandler(t)
+		balanceRepo.EXPECT().StreamStatement(gomock.Any(), uint(7), gomock.Any(), gomock.Any()).
+			Return(errors.New("conn reset"))
+
+		w := get(r, "?format=json")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "closing_balance")
+	})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Refresh tokens
+// @Description Exchanges a refresh token for a new access and refresh token pair. Every refresh token can be used once; presenting a used one revokes the whole session.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body entity.RefreshRequest true "Refresh token"
+// @Success 200 {object} entity.TokenPair "New tokens"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Refresh token is invalid, revoked or reused"
+// @Failure 403 {object} ErrorResponse "Account locked"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/user/token/refresh [post]
+func (g *GopherMartRoutes) RefreshToken(c *gin.Context) {
+	var request entity.RefreshRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	tokens, err := g.token.RefreshTokens(c.Request.Context(), request.RefreshToken)
+	switch {
+	case errors.Is(err, security.ErrInvalidToken), errors.Is(err, entity.ErrTokenRevoked):
+		g.ErrorResponse(c, http.StatusUnauthorized, "invalid refresh token", err)
+		return
+	case errors.Is(err, entity.ErrTokenReused):
+		g.ErrorResponse(c, http.StatusUnauthorized, "refresh token reuse detected, session revoked", err)
+		return
+	case errors.Is(err, entity.ErrUserLocked):
+		g.ErrorResponse(c, http.StatusForbidden, "account is locked", err)
+		return
+	case err != nil:
+		g.ErrorResponse(c, http.StatusInternalServerError, "internal server error", err)
+		return
+	}
+	g.writeTokens(c, tokens)
+}
+
+// @Summary Logout
+// @Description Revokes the access and refresh tokens of the current session
+// @Tags users
+// @Produce json
+// @Success 200 "Logged out"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/user/logout [post]
+func (g *GopherMartRoutes) Logout(c *gin.Context) {
+	familyID, err := uuid.Parse(c.GetString("familyID"))
+	if err != nil {
+		g.ErrorResponse(c, http.StatusUnauthorized, "invalid token claims", err)
+		return
+	}
+	if err := g.u.RevokeTokenFamily(c.Request.Context(), familyID); err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "internal server error", err)
+		return
+	}
+	g.clearTokens(c)
+}
+
+// @Summary Logout everywhere
+// @Description Revokes every token of the user on all devices
+// @Tags users
+// @Produce json
+// @Success 200 "Logged out on all devices"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/user/logout/all [post]
+func (g *GopherMartRoutes) LogoutAll(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.MustGet("userID").(string), 10, 64)
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to parse userID", err)
+		return
+	}
+	if err := g.u.RevokeUserTokens(c.Request.Context(), uint(userID)); err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "internal server error", err)
+		return
+	}
+	g.clearTokens(c)
+}
+
+func (g *GopherMartRoutes) writeTokens(c *gin.Context, tokens *entity.TokenPair) {
+	c.Header("Content-Type", "application/json")
+	c.SetCookie("token", tokens.AccessToken, int(g.token.AccessTTL().Seconds()), "/", "localhost", false, true)
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (g *GopherMartRoutes) clearTokens(c *gin.Context) {
+	c.SetCookie("token", "", -1, "/", "localhost", false, true)
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTokenHandler(t *testing.T) (*GopherMartRoutes, *mocks.MockAuthUseCase, *mocks.MockTokenRepository) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+
+	userRepo := mocks.NewMockAuthUseCase(ctrl)
+	tokenRepo := mocks.NewMockTokenRepository(ctrl)
+	cfg := NewTestConfig()
+	uc := usecase.NewGopherMart(mocks.NewMockRepository(ctrl), mocks.NewMockBalanceUseCase(ctrl),
+		mocks.NewMockOrderUseCase(ctrl), userRepo, log, usecase.WithTokens(tokenRepo))
+	token := security.NewJwtToken(cfg.Jwt.EncryptionKey, *uc)
+	h := NewHandler(gin.New(), *uc, cfg, token, nil, log)
+	return h, userRepo, tokenRepo
+}
+
+func TestRefreshToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &entity.User{ID: 7, Login: "test24e7", Access: entity.RoleCustomer}
+
+	refresh := func(router *gin.Engine, token string) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(entity.RefreshRequest{RefreshToken: token})
+		req := httptest.NewRequest(http.MethodPost, "/api/user/token/refresh", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// issue выдает пару и возвращает сохраненные токены в порядке access, refresh
+	issue := func(t *testing.T, h *GopherMartRoutes, userRepo *mocks.MockAuthUseCase) (*entity.TokenPair, []entity.Token) {
+		var persisted []entity.Token
+		userRepo.EXPECT().CreateToken(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, tok *entity.Token) error {
+				persisted = append(persisted, *tok)
+				return nil
+			}).Times(2)
+		pair, err := h.token.IssueTokens(context.Background(), user)
+		require.NoError(t, err)
+		require.Len(t, persisted, 2)
+		return pair, persisted
+	}
+
+	t.Run("refresh token is rotated within the family", func(t *testing.T) {
+		h, userRepo, tokenRepo := setupTokenHandler(t)
+		router := gin.New()
+		router.POST("/api/user/token/refresh", h.RefreshToken)
+		pair, persisted := issue(t, h, userRepo)
+		old := persisted[1]
+		assert.Equal(t, entity.TokenRefresh, old.Kind)
+
+		tokenRepo.EXPECT().UseRefreshToken(gomock.Any(), old.ID).Return(&old, nil)
+		promoted := *user
+		promoted.Access = entity.RoleSupport
+		userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(&promoted, nil)
+		userRepo.EXPECT().CreateToken(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+		resp := refresh(router, pair.RefreshToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var next entity.TokenPair
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &next))
+		claims, err := h.token.Parse(next.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, old.FamilyID.String(), claims["family"])
+		assert.Equal(t, "7", claims["id"])
+		assert.Equal(t, "test24e7", claims["login"])
+		assert.Equal(t, entity.RoleSupport, claims["access"], "role is re-read from the database")
+	})
+
+	t.Run("reused refresh token revokes the session", func(t *testing.T) {
+		h, userRepo, tokenRepo := setupTokenHandler(t)
+		router := gin.New()
+		router.POST("/api/user/token/refresh", h.RefreshToken)
+		pair, persisted := issue(t, h, userRepo)
+		used := persisted[1]
+		now := time.Now()
+		used.UsedAt = &now
+
+		tokenRepo.EXPECT().UseRefreshToken(gomock.Any(), used.ID).Return(nil, nil)
+		tokenRepo.EXPECT().GetToken(gomock.Any(), used.ID).Return(&used, nil)
+		tokenRepo.EXPECT().RevokeTokenFamily(gomock.Any(), used.FamilyID).Return(nil)
+
+		resp := refresh(router, pair.RefreshToken)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("access token cannot refresh", func(t *testing.T) {
+		h, userRepo, _ := setupTokenHandler(t)
+		router := gin.New()
+		router.POST("/api/user/token/refresh", h.RefreshToken)
+		pair, _ := issue(t, h, userRepo)
+
+		resp := refresh(router, pair.AccessToken)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("garbage token", func(t *testing.T) {
+		h, _, _ := setupTokenHandler(t)
+		router := gin.New()
+		router.POST("/api/user/token/refresh", h.RefreshToken)
+
+		resp := refresh(router, "not-a-jwt")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	family := uuid.New()
+
+	t.Run("logout revokes the current session", func(t *testing.T) {
+		h, _, tokenRepo := setupTokenHandler(t)
+		router := gin.New()
+		router.POST("/api/user/logout", func(c *gin.Context) {
+			c.Set("userID", "7")
+			c.Set("familyID", family.String())
+		}, h.Logout)
+		tokenRepo.EXPECT().RevokeTokenFamily(gomock.Any(), family).Return(nil)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/api/user/logout", nil))
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Header().Get("Set-Cookie"), "token=;")
+	})
+
+	t.Run("logout everywhere revokes all user tokens", func(t *testing.T) {
+		h, _, tokenRepo := setupTokenHandler(t)
+		router := gin.New()
+		router.POST("/api/user/logout/all", func(c *gin.Context) {
+			c.Set("userID", "7")
+		}, h.LogoutAll)
+		tokenRepo.EXPECT().RevokeUserTokens(gomock.Any(), uint(7)).Return(nil)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/api/user/logout/all", nil))
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Transfer points
+// @Description Transfer points to another user by login. The sender is debited and the recipient
+// @Description credited in one transaction; daily limits apply to the points sent in the last 24 hours
+// @Tags balance
+// @Accept json
+// @Produce json
+// @Param request body entity.TransferRequest true "Recipient login and sum"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} entity.Transfer
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 402 {object} ErrorResponse "Insufficient funds"
+// @Failure 403 {object} ErrorResponse "Sender or recipient account is locked"
+// @Failure 404 {object} ErrorResponse "Recipient not found"
+// @Failure 409 {object} ErrorResponse "Request with this Idempotency-Key is in progress"
+// @Failure 422 {object} ErrorResponse "Transfer to yourself or Idempotency-Key reused with a different request"
+// @Failure 429 {object} ErrorResponse "Daily transfer limit exceeded"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/user/balance/transfer [post]
+func (g *GopherMartRoutes) TransferPoints(c *gin.Context) {
+	var request entity.TransferRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		g.ErrorResponse(c, http.StatusBadRequest, "failed to bind request", err)
+		return
+	}
+	if !request.Sum.IsPositive() {
+		g.ErrorResponse(c, http.StatusBadRequest, "transfer sum must be positive", nil)
+		return
+	}
+	userID, err := strconv.ParseUint(c.MustGet("userID").(string), 10, 64)
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to parse userID", err)
+		return
+	}
+
+	transfer, err := g.u.TransferPoints(c.Request.Context(), uint(userID), request)
+	if err != nil {
+		g.debitErrorResponse(c, err, "failed to transfer points")
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
+
+// @Summary Get user transfers
+// @Description Get a page of points sent and received by the user, newest first by default.
+// @Description direction is OUT for sent and IN for received transfers, login is the other side.
+// @Description The next page cursor is returned in X-Next-Cursor and Link headers
+// @Tags balance
+// @Produce json
+// @Param limit query int false "Page size, 100 by default, at most 1000"
+// @Param cursor query string false "Cursor from X-Next-Cursor of the previous page"
+// @Param from query string false "Created at or after, RFC 3339 or YYYY-MM-DD"
+// @Param to query string false "Created before, RFC 3339 or YYYY-MM-DD"
+// @Param sort query string false "Sort direction: desc (default) or asc"
+// @Success 200 {array} entity.Transfer
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid filter"
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/user/transfers [get]
+func (g *GopherMartRoutes) GetTransfers(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.MustGet("userID").(string), 10, 64)
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to parse userID", err)
+		return
+	}
+	f, ok := g.listFilter(c)
+	if !ok {
+		return
+	}
+	page, err := g.u.GetUserTransfers(c.Request.Context(), uint(userID), f)
+	if errors.Is(err, entity.ErrInvalidListFilter) {
+		g.ErrorResponse(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err != nil {
+		g.ErrorResponse(c, http.StatusInternalServerError, "failed to get transfers", err)
+		return
+	}
+	if len(page.Transfers) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	writePageHeaders(c, f.Limit, page.NextCursor)
+	c.JSON(http.StatusOK, page.Transfers)
+}
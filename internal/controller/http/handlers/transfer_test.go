@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTransferHandler(t *testing.T) (*gin.Engine, *mocks.MockBalanceUseCase, *mocks.MockAuthUseCase) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+
+	balanceRepo := mocks.NewMockBalanceUseCase(ctrl)
+	userRepo := mocks.NewMockAuthUseCase(ctrl)
+	cfg := NewTestConfig()
+	uc := usecase.NewGopherMart(mocks.NewMockRepository(ctrl), balanceRepo,
+		mocks.NewMockOrderUseCase(ctrl), userRepo, log,
+		usecase.WithTransferLimits(entity.TransferLimits{DailyCount: 1}))
+	h := NewHandler(gin.New(), *uc, cfg, security.NewJwtToken(cfg.Jwt.EncryptionKey, *uc), nil, log)
+
+	router := gin.New()
+	api := router.Group("/api/user", func(c *gin.Context) {
+		c.Set("userID", "7")
+	})
+	api.POST("/balance/transfer", h.TransferPoints)
+	api.GET("/transfers", h.GetTransfers)
+	return router, balanceRepo, userRepo
+}
+
+func TestTransferHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sender := &entity.User{ID: 7, Login: "alice"}
+	recipient := &entity.User{ID: 8, Login: "bob"}
+	transfer := func(r *gin.Engine, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/user/balance/transfer", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	lockBalances := func(balanceRepo *mocks.MockBalanceUseCase, current entity.Points) {
+		balanceRepo.EXPECT().GetBalanceForUpdate(gomock.Any(), sender.ID).Return(&entity.Balance{Current: current}, nil).Times(2)
+		balanceRepo.EXPECT().GetBalanceForUpdate(gomock.Any(), recipient.ID).Return(&entity.Balance{}, nil)
+	}
+
+	t.Run("points are transferred", func(t *testing.T) {
+		r, balanceRepo, userRepo := setupTransferHandler(t)
+		userRepo.EXPECT().GetUserByID(gomock.Any(), sender.ID).Return(sender, nil)
+		userRepo.EXPECT().GetUserByLogin(gomock.Any(), entity.User{Login: "bob"}).Return(recipient, nil)
+		lockBalances(balanceRepo, entity.NewPoints(100, 0))
+		balanceRepo.EXPECT().GetSentTransfers(gomock.Any(), sender.ID, gomock.Any()).Return(entity.NewPoints(0, 0), 0, nil)
+		balanceRepo.EXPECT().CreateTransferTx(gomock.Any(), gomock.Any()).
+			Return(&entity.Transfer{ID: 3, Amount: entity.NewPoints(40, 0)}, nil)
+
+		w := transfer(r, `{"login": "bob", "sum": 40}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"direction":"OUT"`)
+		assert.Contains(t, w.Body.String(), `"login":"bob"`)
+		assert.Contains(t, w.Body.String(), `"sum":40.00`)
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		r, balanceRepo, userRepo := setupTransferHandler(t)
+		userRepo.EXPECT().GetUserByID(gomock.Any(), sender.ID).Return(sender, nil)
+		userRepo.EXPECT().GetUserByLogin(gomock.Any(), entity.User{Login: "bob"}).Return(recipient, nil)
+		lockBalances(balanceRepo, entity.NewPoints(10, 0))
+
+		w := transfer(r, `{"login": "bob", "sum": 40}`)
+		assert.Equal(t, http.StatusPaymentRequired, w.Code)
+	})
+
+	t.Run("daily limit reached", func(t *testing.T) {
+		r, balanceRepo, userRepo := setupTransferHandler(t)
+		userRepo.EXPECT().GetUserByID(gomock.Any(), sender.ID).Return(sender, nil)
+		userRepo.EXPECT().GetUserByLogin(gomock.Any(), entity.User{Login: "bob"}).Return(recipient, nil)
+		lockBalances(balanceRepo, entity.NewPoints(100, 0))
+		balanceRepo.EXPECT().GetSentTransfers(gomock.Any(), sender.ID, gomock.Any()).
+			Return(entity.NewPoints(5, 0), 1, nil)
+
+		w := transfer(r, `{"login": "bob", "sum": 40}`)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+
+	t.Run("transfer to yourself", func(t *testing.T) {
+		r, _, userRepo := setupTransferHandler(t)
+		userRepo.EXPECT().GetUserByID(gomock.Any(), sender.ID).Return(sender, nil)
+		userRepo.EXPECT().GetUserByLogin(gomock.Any(), entity.User{Login: "alice"}).Return(sender, nil)
+
+		w := transfer(r, `{"login": "alice", "sum": 40}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("unknown recipient", func(t *testing.T) {
+		r, _, userRepo := setupTransferHandler(t)
+		userRepo.EXPECT().GetUserByID(gomock.Any(), sender.ID).Return(sender, nil)
+		userRepo.EXPECT().GetUserByLogin(gomock.Any(), entity.User{Login: "carol"}).
+			Return(nil, entity.ErrUserDoesNotExist)
+
+		w := transfer(r, `{"login": "carol", "sum": 40}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("sum must be positive", func(t *testing.T) {
+		r, _, _ := setupTransferHandler(t)
+
+		w := transfer(r, `{"login": "bob", "sum": 0}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("login is required", func(t *testing.T) {
+		r, _, _ := setupTransferHandler(t)
+
+		w := transfer(r, `{"sum": 40}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("empty history", func(t *testing.T) {
+		r, balanceRepo, _ := setupTransferHandler(t)
+		balanceRepo.EXPECT().GetUserTransfers(gomock.Any(), sender.ID, gomock.Any()).Return(nil, nil)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/user/transfers", nil))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
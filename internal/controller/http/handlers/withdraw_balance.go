@@ -16,10 +16,12 @@ import (
 // @Accept json
 // @Produce json
 // @Param request body entity.WithdrawalRequest true "Withdrawal request"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
 // @Success 200 "Successful withdrawal"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 402 {object} ErrorResponse "Insufficient funds"
-// @Failure 422 {object} ErrorResponse "Invalid order number"
+// @Failure 409 {object} ErrorResponse "Request with this Idempotency-Key is in progress"
+// @Failure 422 {object} ErrorResponse "Invalid order number or Idempotency-Key reused with a different request"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/user/balance/withdraw [post]
 func (g *GopherMartRoutes) WithdrawBalance(c *gin.Context) {
@@ -28,6 +30,10 @@ func (g *GopherMartRoutes) WithdrawBalance(c *gin.Context) {
 		g.ErrorResponse(c, http.StatusBadRequest, "failed to bind request", err)
 		return
 	}
+	if !request.Sum.IsPositive() {
+		g.ErrorResponse(c, http.StatusBadRequest, "withdrawal sum must be positive", nil)
+		return
+	}
 	userID, err := strconv.ParseUint(c.MustGet("userID").(string), 10, 64)
 	if err != nil {
 		g.ErrorResponse(c, http.StatusInternalServerError, "failed to parse userID", err)
@@ -48,22 +54,35 @@ func (g *GopherMartRoutes) WithdrawBalance(c *gin.Context) {
 
 	err = g.u.WithdrawBalance(c.Request.Context(), withdrawal)
 	if err != nil {
-		switch {
-		case errors.Is(err, entity.ErrInsufficientFunds):
-			g.ErrorResponse(c, http.StatusPaymentRequired, "insufficient funds", err)
-		case errors.Is(err, entity.ErrInvalidOrder):
-			g.ErrorResponse(c, http.StatusUnprocessableEntity, "invalid order number", err)
-		case errors.Is(err, entity.ErrOrderExists):
-			g.ErrorResponse(c, http.StatusConflict, "order number already exists", err)
-		default:
-			g.ErrorResponse(c, http.StatusInternalServerError, "failed to withdraw balance", err)
-		}
+		g.debitErrorResponse(c, err, "failed to withdraw balance")
 		return
 	}
 
 	c.Status(http.StatusOK)
 }
 
+// debitErrorResponse общие ответы операций, которые списывают баллы со счета пользователя
+func (g *GopherMartRoutes) debitErrorResponse(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, entity.ErrInsufficientFunds):
+		g.ErrorResponse(c, http.StatusPaymentRequired, "insufficient funds", err)
+	case errors.Is(err, entity.ErrInvalidOrder):
+		g.ErrorResponse(c, http.StatusUnprocessableEntity, "invalid order number", err)
+	case errors.Is(err, entity.ErrSelfTransfer):
+		g.ErrorResponse(c, http.StatusUnprocessableEntity, "cannot transfer points to yourself", err)
+	case errors.Is(err, entity.ErrOrderExists):
+		g.ErrorResponse(c, http.StatusConflict, "order number already exists", err)
+	case errors.Is(err, entity.ErrUserLocked):
+		g.ErrorResponse(c, http.StatusForbidden, "account is locked", err)
+	case errors.Is(err, entity.ErrUserDoesNotExist):
+		g.ErrorResponse(c, http.StatusNotFound, "user not found", err)
+	case errors.Is(err, entity.ErrTransferLimit):
+		g.ErrorResponse(c, http.StatusTooManyRequests, "daily transfer limit exceeded", err)
+	default:
+		g.ErrorResponse(c, http.StatusInternalServerError, failure, err)
+	}
+}
+
 func (g *GopherMartRoutes) isValidOrderNumber(number string) bool {
 	if len(number) < 5 || len(number) > 20 {
 		return false
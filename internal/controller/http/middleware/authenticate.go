@@ -1,18 +1,14 @@
 package middleware
 
 import (
-	"context"
-	"go-loyalty-system/internal/entity"
 	"go-loyalty-system/internal/usecase"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 )
 
 func Authenticate(u usecase.UserUseCase) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx := context.Background()
 		username, password, hasAuth := c.Request.BasicAuth()
 
 		if !hasAuth {
@@ -21,11 +17,7 @@ func Authenticate(u usecase.UserUseCase) gin.HandlerFunc {
 			return
 		}
 
-		user, err := u.GetUserByEmail(ctx, entity.User{
-			Login:    username,
-			Password: password,
-		})
-		if err != nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		if _, err := u.AuthenticateUser(c.Request.Context(), username, password); err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			c.Abort()
 			return
@@ -2,8 +2,12 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go-loyalty-system/config"
+	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
 	"go-loyalty-system/pkg/logging"
 	"net/http"
 
@@ -12,6 +16,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
 )
 
 type UserSession struct {
@@ -21,11 +26,13 @@ type UserSession struct {
 }
 
 type Authorizer struct {
+	u usecase.UserService
 	l *logging.ZapLogger
 }
 
-func NewAuthorizer(l *logging.ZapLogger) *Authorizer {
+func NewAuthorizer(u usecase.UserService, l *logging.ZapLogger) *Authorizer {
 	return &Authorizer{
+		u: u,
 		l: l,
 	}
 }
@@ -94,9 +101,46 @@ func (a Authorizer) Authorize(cfg *config.Config) gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+
+		// refresh-токен годится только для /token/refresh
+		if typ, _ := claims["typ"].(string); typ == security.TypeRefresh {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token cannot be used for authorization"})
+			c.Abort()
+			return
+		}
+		tokenID, err := security.ClaimUUID(claims, "token")
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			c.Abort()
+			return
+		}
+		// у токенов, выданных до появления семей, семья совпадает с самим токеном
+		familyID, err := security.ClaimUUID(claims, "family")
+		if err != nil {
+			familyID = tokenID
+		}
+		err = a.u.ValidateAccessToken(c.Request.Context(), tokenID)
+		if errors.Is(err, entity.ErrTokenRevoked) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			a.l.ErrorCtx(ctx, "Authorize - ValidateAccessToken", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			c.Abort()
+			return
+		}
 		a.l.InfoCtx(ctx, "userID ->"+userID)
 
 		c.Set("userID", userID)
-		c.Next()
+		c.Set("tokenID", tokenID.String())
+		c.Set("familyID", familyID.String())
+		// токены, выданные до появления ролей, принадлежат покупателям
+		role, _ := claims["access"].(string)
+		if role == "" {
+			role = entity.RoleCustomer
+		}
+		c.Set("role", role)
 	}
 }
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/config"
+	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	cfg := &config.Config{Jwt: config.Jwt{EncryptionKey: "secret"}}
+
+	userRepo := mocks.NewMockAuthUseCase(ctrl)
+	userRepo.EXPECT().CreateToken(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	uc := usecase.NewGopherMart(mocks.NewMockRepository(ctrl), mocks.NewMockBalanceUseCase(ctrl),
+		mocks.NewMockOrderUseCase(ctrl), userRepo, log)
+	pair, err := security.NewJwtToken(cfg.Jwt.EncryptionKey, *uc).IssueTokens(context.Background(), &entity.User{ID: 7, Login: "user"})
+	require.NoError(t, err)
+
+	setup := func(t *testing.T) (*gin.Engine, *mocks.MockUserService) {
+		u := mocks.NewMockUserService(gomock.NewController(t))
+		r := gin.New()
+		r.GET("/api/user/orders", NewAuthorizer(u, log).Authorize(cfg), func(c *gin.Context) {
+			c.String(http.StatusOK, c.GetString("userID")+" "+c.GetString("role")+" "+c.GetString("familyID"))
+		})
+		return r, u
+	}
+	call := func(r *gin.Engine, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/user/orders", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("active access token", func(t *testing.T) {
+		r, u := setup(t)
+		u.EXPECT().ValidateAccessToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		w := call(r, pair.AccessToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "7 ")
+	})
+
+	t.Run("token without role belongs to a customer", func(t *testing.T) {
+		r, u := setup(t)
+		u.EXPECT().ValidateAccessToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		w := call(r, pair.AccessToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "7 "+entity.RoleCustomer+" ")
+	})
+
+	t.Run("revoked access token", func(t *testing.T) {
+		r, u := setup(t)
+		u.EXPECT().ValidateAccessToken(gomock.Any(), gomock.Any()).Return(entity.ErrTokenRevoked)
+
+		w := call(r, pair.AccessToken)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("refresh token is rejected", func(t *testing.T) {
+		r, _ := setup(t)
+
+		w := call(r, pair.RefreshToken)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("token store unavailable", func(t *testing.T) {
+		r, u := setup(t)
+		u.EXPECT().ValidateAccessToken(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+
+		w := call(r, pair.AccessToken)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/pkg/logging"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+// idempotencyRenewInterval как часто продлевается аренда ключа; должен быть
+// заметно короче аренды в usecase, чтобы ключ не освободился посреди запроса
+var idempotencyRenewInterval = 30 * time.Second
+
+// responseRecorder копирует тело ответа, чтобы сохранить его для повторов
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency обрабатывает заголовок Idempotency-Key. Повтор с тем же ключом и телом
+// получает сохраненный ответ, тот же ключ с другим запросом отклоняется с 422.
+// Ответы 5xx не сохраняются, такой запрос можно повторить с тем же ключом.
+// Пока обработчик работает, аренда ключа продлевается; ключ без ответа (например, после
+// падения процесса) освобождается, когда аренда истекает.
+// Должен стоять после Authorize: ключи хранятся отдельно для каждого пользователя.
+func Idempotency(u usecase.UserService, l *logging.ZapLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "idempotency key is too long"})
+			return
+		}
+		userID, err := strconv.ParseUint(c.GetString("userID"), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		req := entity.IdempotencyKey{
+			UserID: uint(userID),
+			Key:    key,
+			// путь запроса, а не шаблон маршрута: захват резерва 2 — другой запрос, чем захват резерва 1
+			Fingerprint: fingerprint(c.Request.Method, c.Request.URL.Path, body),
+		}
+		stored, err := u.BeginIdempotentRequest(ctx, req)
+		switch {
+		case errors.Is(err, entity.ErrIdempotencyKeyReused):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, entity.ErrIdempotencyConflict):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			l.ErrorCtx(ctx, "Idempotency - BeginIdempotentRequest", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		case stored != nil:
+			c.Header(IdempotencyReplayedHeader, "true")
+			if stored.ContentType != "" {
+				c.Header("Content-Type", stored.ContentType)
+			}
+			c.Status(stored.StatusCode)
+			_, _ = c.Writer.Write(stored.Body)
+			c.Abort()
+			return
+		}
+
+		stop := renewIdempotencyLease(context.WithoutCancel(ctx), u, req, l)
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		stop()
+
+		// ответ сохраняется, даже если клиент уже отключился: иначе ключ
+		// так и останется занятым, и повтор получит 409 вместо ответа
+		ctx = context.WithoutCancel(ctx)
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			if err := u.ReleaseIdempotentRequest(ctx, req.UserID, req.Key); err != nil {
+				l.ErrorCtx(ctx, "Idempotency - ReleaseIdempotentRequest", zap.Error(err))
+			}
+			return
+		}
+		req.StatusCode = status
+		req.ContentType = c.Writer.Header().Get("Content-Type")
+		req.Body = recorder.body.Bytes()
+		if err := u.CompleteIdempotentRequest(ctx, req); err != nil {
+			l.ErrorCtx(ctx, "Idempotency - CompleteIdempotentRequest", zap.Error(err))
+		}
+	}
+}
+
+// renewIdempotencyLease продлевает аренду ключа, пока не вызвана возвращенная функция.
+// Функция дожидается остановки, чтобы продление не шло после сохранения ответа.
+func renewIdempotencyLease(ctx context.Context,
+	u usecase.UserService,
+	req entity.IdempotencyKey,
+	l *logging.ZapLogger) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(idempotencyRenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := u.RenewIdempotentRequest(ctx, req); err != nil && ctx.Err() == nil {
+					l.ErrorCtx(ctx, "Idempotency - RenewIdempotentRequest", zap.Error(err))
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// fingerprint отпечаток запроса: метод, путь и тело
+func fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupIdempotencyRouter(t *testing.T) (*gin.Engine, *mocks.MockUserService, *int) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	u := mocks.NewMockUserService(ctrl)
+	calls := 0
+
+	r := gin.New()
+	r.POST("/api/user/balance/withdraw", func(c *gin.Context) {
+		c.Set("userID", "7")
+	}, Idempotency(u, log), func(c *gin.Context) {
+		calls++
+		if c.Query("slow") != "" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		if c.Query("fail") != "" {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Withdrawal successful"})
+	})
+	r.POST("/api/user/balance/holds/:id/capture", func(c *gin.Context) {
+		c.Set("userID", "7")
+	}, Idempotency(u, log), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "status": "CAPTURED"})
+	})
+	return r, u, &calls
+}
+
+func doIdempotent(r *gin.Engine, target, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	const path = "/api/user/balance/withdraw"
+	body := `{"order":"2377225624","sum":751}`
+	fp := fingerprint(http.MethodPost, path, []byte(body))
+
+	t.Run("request without key passes through", func(t *testing.T) {
+		r, _, calls := setupIdempotencyRouter(t)
+
+		w := doIdempotent(r, path, "", body)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("first request stores response", func(t *testing.T) {
+		r, u, calls := setupIdempotencyRouter(t)
+		req := entity.IdempotencyKey{UserID: 7, Key: "k-1", Fingerprint: fp}
+		u.EXPECT().BeginIdempotentRequest(gomock.Any(), req).Return(nil, nil)
+		u.EXPECT().CompleteIdempotentRequest(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ interface{}, k entity.IdempotencyKey) error {
+				assert.Equal(t, http.StatusOK, k.StatusCode)
+				assert.JSONEq(t, `{"message":"Withdrawal successful"}`, string(k.Body))
+				assert.Contains(t, k.ContentType, "application/json")
+				return nil
+			})
+
+		w := doIdempotent(r, path, "k-1", body)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("repeated key replays response", func(t *testing.T) {
+		r, u, calls := setupIdempotencyRouter(t)
+		u.EXPECT().BeginIdempotentRequest(gomock.Any(), gomock.Any()).Return(&entity.IdempotencyKey{
+			State:       entity.IdempotencyCompleted,
+			StatusCode:  http.StatusOK,
+			ContentType: "application/json; charset=utf-8",
+			Body:        []byte(`{"message":"Withdrawal successful"}`),
+		}, nil)
+
+		w := doIdempotent(r, path, "k-1", body)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "true", w.Header().Get(IdempotencyReplayedHeader))
+		assert.JSONEq(t, `{"message":"Withdrawal successful"}`, w.Body.String())
+		assert.Equal(t, 0, *calls)
+	})
+
+	t.Run("key reused with different payload", func(t *testing.T) {
+		r, u, calls := setupIdempotencyRouter(t)
+		u.EXPECT().BeginIdempotentRequest(gomock.Any(), gomock.Any()).Return(nil, entity.ErrIdempotencyKeyReused)
+
+		w := doIdempotent(r, path, "k-1", `{"order":"2377225624","sum":1}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, 0, *calls)
+	})
+
+	t.Run("same key and body on another hold is a different request", func(t *testing.T) {
+		r, u, calls := setupIdempotencyRouter(t)
+		var first *entity.IdempotencyKey
+		u.EXPECT().BeginIdempotentRequest(gomock.Any(), gomock.Any()).Times(2).
+			DoAndReturn(func(_ interface{}, k entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+				if first != nil && first.Fingerprint != k.Fingerprint {
+					return nil, entity.ErrIdempotencyKeyReused
+				}
+				return nil, nil
+			})
+		u.EXPECT().CompleteIdempotentRequest(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ interface{}, k entity.IdempotencyKey) error {
+				first = &k
+				return nil
+			})
+
+		w := doIdempotent(r, "/api/user/balance/holds/1/capture", "k-1", `{"sum":100}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = doIdempotent(r, "/api/user/balance/holds/2/capture", "k-1", `{"sum":100}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("key in progress", func(t *testing.T) {
+		r, u, _ := setupIdempotencyRouter(t)
+		u.EXPECT().BeginIdempotentRequest(gomock.Any(), gomock.Any()).Return(nil, entity.ErrIdempotencyConflict)
+
+		w := doIdempotent(r, path, "k-1", body)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("response is stored after the client goes away", func(t *testing.T) {
+		r, u, calls := setupIdempotencyRouter(t)
+		u.EXPECT().BeginIdempotentRequest(gomock.Any(), gomock.Any()).Return(nil, nil)
+		u.EXPECT().CompleteIdempotentRequest(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, k entity.IdempotencyKey) error {
+				assert.NoError(t, ctx.Err())
+				assert.Equal(t, http.StatusOK, k.StatusCode)
+				return nil
+			})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)).WithContext(ctx)
+		req.Header.Set(IdempotencyKeyHeader, "k-1")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("lease is renewed while the handler runs", func(t *testing.T) {
+		r, u, _ := setupIdempotencyRouter(t)
+		interval := idempotencyRenewInterval
+		idempotencyRenewInterval = 5 * time.Millisecond
+		t.Cleanup(func() { idempotencyRenewInterval = interval })
+		req := entity.IdempotencyKey{UserID: 7, Key: "k-1", Fingerprint: fp}
+		u.EXPECT().BeginIdempotentRequest(gomock.Any(), req).Return(nil, nil)
+		u.EXPECT().RenewIdempotentRequest(gomock.Any(), req).MinTimes(1).Return(nil)
+		u.EXPECT().CompleteIdempotentRequest(gomock.Any(), gomock.Any()).Return(nil)
+
+		w := doIdempotent(r, path+"?slow=1", "k-1", body)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("server error releases key", func(t *testing.T) {
+		r, u, _ := setupIdempotencyRouter(t)
+		u.EXPECT().BeginIdempotentRequest(gomock.Any(), gomock.Any()).Return(nil, nil)
+		u.EXPECT().ReleaseIdempotentRequest(gomock.Any(), uint(7), "k-1").Return(nil)
+
+		w := doIdempotent(r, path+"?fail=1", "k-1", body)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		r, u, calls := setupIdempotencyRouter(t)
+		u.EXPECT().BeginIdempotentRequest(gomock.Any(), gomock.Any()).Return(nil, errors.New("database error"))
+
+		w := doIdempotent(r, path, "k-1", body)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, 0, *calls)
+	})
+
+	t.Run("key is too long", func(t *testing.T) {
+		r, _, _ := setupIdempotencyRouter(t)
+
+		w := doIdempotent(r, path, strings.Repeat("k", maxIdempotencyKeyLength+1), body)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
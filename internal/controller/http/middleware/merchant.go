@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const merchantKeyHeader = "X-Merchant-Key"
+
+// MerchantAuth пропускает запросы магазина с ключом из конфигурации.
+// Пока ключ не задан, вход по ключу отключен. Запрос без ключа проходит через
+// tokenAuth, если они переданы: так магазин может войти учетной записью с ролью merchant.
+func MerchantAuth(key string, tokenAuth ...gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader(merchantKeyHeader)
+		if provided == "" && len(tokenAuth) > 0 {
+			for _, h := range tokenAuth {
+				if h(c); c.IsAborted() {
+					return
+				}
+			}
+			return
+		}
+		if key == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "merchant API is disabled"})
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(key)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid merchant key"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reg := prometheus.NewRegistry()
+	router := gin.New()
+	router.Use(Metrics(reg))
+	router.GET("/api/admin/users/:id/orders", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, path := range []string{"/api/admin/users/1/orders", "/api/admin/users/2/orders", "/wp-login.php"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	var series []string
+	for _, f := range families {
+		if f.GetName() != "gophermart_http_request_duration_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := make([]string, 0, len(m.GetLabel()))
+			for _, l := range m.GetLabel() {
+				labels = append(labels, l.GetName()+"="+l.GetValue())
+			}
+			series = append(series, strings.Join(labels, ","))
+			if strings.Contains(series[len(series)-1], "status=204") {
+				assert.Equal(t, uint64(2), m.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"method=GET,route=/api/admin/users/:id/orders,status=204",
+		"method=GET,route=unmatched,status=404",
+	}, series, "paths are labelled by route template")
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"go-loyalty-system/internal/audit"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID заголовок с ID запроса
+const HeaderRequestID = "X-Request-ID"
+
+// requestIDPattern ID от прокси принимается, только если он не сломает логи и журнал
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID берет ID запроса из X-Request-ID или назначает новый и возвращает его
+// в ответе. ID и IP клиента кладутся в контекст для журнала аудита.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderRequestID)
+		if !requestIDPattern.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Header(HeaderRequestID, id)
+		c.Set("requestID", id)
+		ctx := audit.WithSource(c.Request.Context(), audit.Source{IP: c.ClientIP(), RequestID: id})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
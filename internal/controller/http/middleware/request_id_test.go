@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"go-loyalty-system/internal/audit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var source audit.Source
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) {
+		source = audit.SourceFrom(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.7:4321"
+		if id != "" {
+			req.Header.Set(HeaderRequestID, id)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("incoming id is kept", func(t *testing.T) {
+		w := serve("edge-42.a_b")
+
+		assert.Equal(t, "edge-42.a_b", w.Header().Get(HeaderRequestID))
+		assert.Equal(t, audit.Source{IP: "203.0.113.7", RequestID: "edge-42.a_b"}, source)
+	})
+
+	t.Run("missing id is generated", func(t *testing.T) {
+		w := serve("")
+
+		id := w.Header().Get(HeaderRequestID)
+		assert.Len(t, id, 36)
+		assert.Equal(t, id, source.RequestID)
+	})
+
+	t.Run("unsafe id is replaced", func(t *testing.T) {
+		w := serve("bad id\nwith newline")
+
+		assert.NotEqual(t, "bad id\nwith newline", w.Header().Get(HeaderRequestID))
+		assert.Len(t, source.RequestID, 36)
+	})
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole пропускает только пользователей с одной из перечисленных ролей.
+// Ставится после Authorizer.Authorize, который кладет роль из токена в контекст.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(roles))
+	for _, r := range roles {
+		allowed[r] = struct{}{}
+	}
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		if role == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+			return
+		}
+		if _, ok := allowed[role]; !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "access denied for role " + role})
+			return
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"go-loyalty-system/internal/entity"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	call := func(role string) int {
+		r := gin.New()
+		r.GET("/api/GetUser", func(c *gin.Context) {
+			if role != "" {
+				c.Set("role", role)
+			}
+		}, RequireRole(entity.RoleAdmin, entity.RoleSupport), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/GetUser", nil))
+		return w.Code
+	}
+
+	t.Run("allowed roles pass", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, call(entity.RoleAdmin))
+		assert.Equal(t, http.StatusOK, call(entity.RoleSupport))
+	})
+
+	t.Run("other roles are forbidden", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, call(entity.RoleCustomer))
+		assert.Equal(t, http.StatusForbidden, call(entity.RoleMerchant))
+	})
+
+	t.Run("missing role is unauthorized", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, call(""))
+	})
+}
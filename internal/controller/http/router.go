@@ -2,6 +2,7 @@ package http
 
 import (
 	"go-loyalty-system/config"
+	"go-loyalty-system/internal/entity"
 	"go-loyalty-system/internal/usecase"
 	"go-loyalty-system/pkg/logging"
 	"net/http"
@@ -17,12 +18,22 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// Роли, которым открыты группы маршрутов. Учетные записи магазинов
+// не участвуют в программе лояльности и к счету покупателя доступа не имеют.
+var (
+	accountRoles  = []string{entity.RoleCustomer, entity.RoleSupport, entity.RoleAdmin}
+	adminRoles    = []string{entity.RoleAdmin}
+	supportRoles  = []string{entity.RoleSupport, entity.RoleAdmin}
+	merchantRoles = []string{entity.RoleMerchant}
+)
+
 type GopherMartRoutes struct {
 	cfg     *config.Config
 	handler *gin.Engine
 	token   *security.TokenModel
 	l       *logging.ZapLogger
 	a       *middleware.Authorizer
+	u       usecase.UserService
 }
 
 func NewRouter(handler *gin.Engine,
@@ -37,6 +48,7 @@ func NewRouter(handler *gin.Engine,
 		token:   token,
 		l:       l,
 		a:       a,
+		u:       &u,
 	}
 	h := handlers.NewHandler(handler, u, c, token, ac, l)
 	g.InitRouting(*h)
@@ -71,15 +83,50 @@ func (g GopherMartRoutes) InitRouting(h handlers.GopherMartRoutes) {
 			"message": "pong",
 		})
 	})
-	g.handler.GET("/api/GetUser", g.a.Authorize(g.cfg), h.GetUsers)
+	g.handler.GET("/api/GetUser", g.a.Authorize(g.cfg), middleware.RequireRole(adminRoles...), h.GetUsers)
 	g.handler.POST("/api/user/login", h.LoginUserHandler())
 	g.handler.POST("/api/user/register", h.RegisterUser)
+	g.handler.POST("/api/user/password/reset", h.ResetPassword)
+	g.handler.POST("/api/user/token/refresh", h.RefreshToken)
 
 	api := g.handler.Group("/api/user")
-	api.Use(g.a.Authorize(g.cfg))
-	api.POST("/orders", h.SetOrdersHandler())
+	api.Use(g.a.Authorize(g.cfg), middleware.RequireRole(accountRoles...))
+	idempotent := middleware.Idempotency(g.u, g.l)
+	api.POST("/orders", idempotent, h.SetOrdersHandler())
 	api.GET("/orders", h.GetOrders)
+	api.GET("/orders/events", h.OrderEvents)
 	api.GET("/balance", h.GetUserBalance)
-	api.POST("/balance/withdraw", h.WithdrawBalance)
+	api.GET("/balance/history", h.GetBalanceHistory)
+	api.GET("/statement", h.GetStatement)
+	api.POST("/balance/withdraw", idempotent, h.WithdrawBalance)
+	api.POST("/balance/holds", idempotent, h.HoldBalance)
+	api.POST("/balance/holds/:id/capture", idempotent, h.CaptureHold)
+	api.POST("/balance/holds/:id/release", h.ReleaseHold)
+	api.POST("/balance/transfer", idempotent, h.TransferPoints)
 	api.GET("/withdrawals", h.GetWithdrawalsHandler())
+	api.GET("/transfers", h.GetTransfers)
+	api.POST("/logout", h.Logout)
+	api.POST("/logout/all", h.LogoutAll)
+
+	// поддержка только смотрит; баллы и доступ к учетным записям меняет администратор
+	admin := g.handler.Group("/api/admin")
+	admin.Use(g.a.Authorize(g.cfg), middleware.RequireRole(supportRoles...))
+	adminOnly := middleware.RequireRole(adminRoles...)
+	admin.GET("/users", h.AdminSearchUsers)
+	admin.GET("/users/:id/orders", h.AdminUserOrders)
+	admin.GET("/users/:id/withdrawals", h.AdminUserWithdrawals)
+	admin.GET("/users/:id/balance", h.AdminBalanceHistory)
+	admin.POST("/users/:id/balance/adjust", adminOnly, h.AdminAdjustBalance)
+	admin.POST("/users/:id/lock", adminOnly, h.AdminLockUser)
+	admin.POST("/users/:id/unlock", adminOnly, h.AdminUnlockUser)
+	admin.POST("/users/:id/logout", adminOnly, h.AdminForceLogout)
+	admin.GET("/audit", h.AdminAudit)
+	admin.GET("/audit/events", h.AdminAuditEvents)
+	admin.POST("/withdrawals/reverse", adminOnly, h.AdminReverseWithdrawal)
+
+	merchant := g.handler.Group("/api/merchant")
+	merchant.Use(middleware.MerchantAuth(g.cfg.Merchant.APIKey,
+		g.a.Authorize(g.cfg), middleware.RequireRole(merchantRoles...)))
+	merchant.POST("/orders", h.SetMerchantOrder)
+	merchant.POST("/withdrawals/reverse", h.MerchantReverseWithdrawal)
 }
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"go-loyalty-system/config"
+	"go-loyalty-system/internal/controller/http/middleware"
+	"go-loyalty-system/internal/controller/http/security"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	cfg := &config.Config{
+		Jwt:      config.Jwt{EncryptionKey: "secret"},
+		Merchant: config.Merchant{APIKey: "merchant-key"},
+	}
+
+	accrualRepo := mocks.NewMockRepository(ctrl)
+	balanceRepo := mocks.NewMockBalanceUseCase(ctrl)
+	orderRepo := mocks.NewMockOrderUseCase(ctrl)
+	userRepo := mocks.NewMockAuthUseCase(ctrl)
+	userRepo.EXPECT().CreateToken(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	userRepo.EXPECT().GetUsers(gomock.Any()).Return([]entity.User{}, nil).AnyTimes()
+	userRepo.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Return(nil, entity.ErrUserDoesNotExist).AnyTimes()
+	userRepo.EXPECT().SearchUsers(gomock.Any(), gomock.Any(), gomock.Any()).Return([]entity.User{}, nil).AnyTimes()
+	orderRepo.EXPECT().GetUserOrders(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	balanceRepo.EXPECT().GetBalance(gomock.Any(), gomock.Any()).Return(&entity.Balance{}, nil).AnyTimes()
+	balanceRepo.EXPECT().GetUserWithdrawals(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	balanceRepo.EXPECT().GetUserTransfers(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	balanceRepo.EXPECT().GetBalanceHistory(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	balanceRepo.EXPECT().StreamStatement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	uc := usecase.NewGopherMart(accrualRepo, balanceRepo, orderRepo, userRepo, log)
+	token := security.NewJwtToken(cfg.Jwt.EncryptionKey, *uc)
+	engine := gin.New()
+	NewRouter(engine, *uc, cfg, token, nil, middleware.NewAuthorizer(uc, log), log)
+
+	call := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	routes := []struct {
+		method  string
+		path    string
+		allowed []string
+	}{
+		{http.MethodGet, "/api/user/orders", accountRoles},
+		{http.MethodGet, "/api/user/balance", accountRoles},
+		{http.MethodGet, "/api/user/balance/history", accountRoles},
+		{http.MethodGet, "/api/user/statement", accountRoles},
+		{http.MethodGet, "/api/user/withdrawals", accountRoles},
+		{http.MethodGet, "/api/user/orders/events", accountRoles},
+		{http.MethodPost, "/api/user/balance/holds", accountRoles},
+		{http.MethodPost, "/api/user/balance/transfer", accountRoles},
+		{http.MethodGet, "/api/user/transfers", accountRoles},
+		{http.MethodGet, "/api/GetUser", adminRoles},
+		{http.MethodGet, "/api/admin/users", supportRoles},
+		{http.MethodGet, "/api/admin/users/2/orders", supportRoles},
+		{http.MethodPost, "/api/admin/users/2/balance/adjust", adminRoles},
+		{http.MethodPost, "/api/admin/users/2/lock", adminRoles},
+		{http.MethodPost, "/api/admin/users/2/unlock", adminRoles},
+		{http.MethodPost, "/api/admin/users/2/logout", adminRoles},
+		{http.MethodGet, "/api/admin/audit/events", supportRoles},
+		{http.MethodPost, "/api/admin/withdrawals/reverse", adminRoles},
+		{http.MethodPost, "/api/merchant/orders", merchantRoles},
+		{http.MethodPost, "/api/merchant/withdrawals/reverse", merchantRoles},
+	}
+	roles := []string{entity.RoleCustomer, entity.RoleSupport, entity.RoleAdmin, entity.RoleMerchant}
+
+	for _, role := range roles {
+		pair, err := token.IssueTokens(context.Background(), &entity.User{ID: 1, Login: role, Access: role})
+		require.NoError(t, err)
+
+		for _, rt := range routes {
+			allowed := false
+			for _, r := range rt.allowed {
+				allowed = allowed || r == role
+			}
+			t.Run(role+" "+rt.method+" "+rt.path, func(t *testing.T) {
+				w := call(rt.method, rt.path, pair.AccessToken)
+				if allowed {
+					assert.NotEqual(t, http.StatusUnauthorized, w.Code)
+					assert.NotEqual(t, http.StatusForbidden, w.Code)
+				} else {
+					assert.Equal(t, http.StatusForbidden, w.Code)
+				}
+			})
+		}
+	}
+
+	t.Run("anonymous requests are unauthorized", func(t *testing.T) {
+		for _, rt := range routes {
+			w := call(rt.method, rt.path, "")
+			assert.Equal(t, http.StatusUnauthorized, w.Code, rt.path)
+		}
+	})
+
+	t.Run("merchant key still works without a token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/merchant/orders", strings.NewReader("{}"))
+		req.Header.Set("X-Merchant-Key", cfg.Merchant.APIKey)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
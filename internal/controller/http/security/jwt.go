@@ -2,6 +2,8 @@ package security
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"go-loyalty-system/internal/entity"
 	"go-loyalty-system/internal/usecase"
 	"strconv"
@@ -12,27 +14,160 @@ import (
 	"time"
 )
 
+const (
+	TypeAccess  = "access"
+	TypeRefresh = "refresh"
+
+	defaultAccessTTL  = 15 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
 type TokenModel struct {
 	EncryptionKey string `yaml:"jwt"`
 	u             usecase.UserUseCase
+	accessTTL     time.Duration
+	refreshTTL    time.Duration
+}
+
+type Option func(*TokenModel)
+
+func AccessTTL(d time.Duration) Option {
+	return func(j *TokenModel) {
+		if d > 0 {
+			j.accessTTL = d
+		}
+	}
+}
+
+func RefreshTTL(d time.Duration) Option {
+	return func(j *TokenModel) {
+		if d > 0 {
+			j.refreshTTL = d
+		}
+	}
 }
 
-func NewJwtToken(key string, u usecase.UserUseCase) *TokenModel {
-	return &TokenModel{
+func NewJwtToken(key string, u usecase.UserUseCase, opts ...Option) *TokenModel {
+	j := &TokenModel{
 		EncryptionKey: key,
 		u:             u,
+		accessTTL:     defaultAccessTTL,
+		refreshTTL:    defaultRefreshTTL,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// AccessTTL время жизни access-токена, им же ограничивается cookie
+func (j TokenModel) AccessTTL() time.Duration {
+	return j.accessTTL
+}
+
+// IssueTokens начинает новый сеанс: выдает access- и refresh-токены новой семьи
+func (j TokenModel) IssueTokens(ctx context.Context, user *entity.User) (*entity.TokenPair, error) {
+	return j.issuePair(ctx, user, uuid.New())
+}
+
+// RefreshTokens погашает refresh-токен и выдает новую пару в той же семье
+func (j TokenModel) RefreshTokens(ctx context.Context, refreshToken string) (*entity.TokenPair, error) {
+	claims, err := j.Parse(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if typ, _ := claims["typ"].(string); typ != TypeRefresh {
+		return nil, ErrInvalidToken
+	}
+	tokenID, err := ClaimUUID(claims, "token")
+	if err != nil {
+		return nil, err
 	}
+	old, err := j.u.RotateRefreshToken(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	// роль перечитывается из базы, чтобы ее смена вступала в силу при обновлении пары
+	user, err := j.u.GetUserByID(ctx, old.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.LockedAt != nil {
+		return nil, entity.ErrUserLocked
+	}
+	return j.issuePair(ctx, user, old.FamilyID)
+}
+
+// Parse проверяет подпись и срок токена и возвращает его claims
+func (j TokenModel) Parse(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(j.EncryptionKey), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
 }
 
-func (j TokenModel) GenerateToken(user *entity.User) (string, error) {
-	tokenID := uuid.New()
+// ClaimUUID читает UUID из claims
+func ClaimUUID(claims jwt.MapClaims, name string) (uuid.UUID, error) {
+	raw, ok := claims[name].(string)
+	if !ok {
+		return uuid.Nil, ErrInvalidToken
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+	return id, nil
+}
+
+func (j TokenModel) issuePair(ctx context.Context, user *entity.User, familyID uuid.UUID) (*entity.TokenPair, error) {
+	now := time.Now()
+	access, err := j.sign(ctx, user, entity.Token{
+		ID: uuid.New(), UserID: user.ID, Kind: entity.TokenAccess, FamilyID: familyID,
+		CreationDate: now, ExpiresAt: now.Add(j.accessTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := j.sign(ctx, user, entity.Token{
+		ID: uuid.New(), UserID: user.ID, Kind: entity.TokenRefresh, FamilyID: familyID,
+		CreationDate: now, ExpiresAt: now.Add(j.refreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entity.TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(j.accessTTL.Seconds()),
+	}, nil
+}
+
+func (j TokenModel) sign(ctx context.Context, user *entity.User, t entity.Token) (string, error) {
+	typ := TypeAccess
+	if t.Kind == entity.TokenRefresh {
+		typ = TypeRefresh
+	}
 	claims := jwt.MapClaims{
 		"sub":    user.Email,
 		"login":  user.Login,
 		"access": user.Access,
 		"id":     strconv.FormatUint(uint64(user.ID), 10),
-		"token":  tokenID,
-		"exp":    time.Now().Add(time.Hour).Unix(),
+		"token":  t.ID.String(),
+		"family": t.FamilyID.String(),
+		"typ":    typ,
+		"exp":    t.ExpiresAt.Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -41,20 +176,13 @@ func (j TokenModel) GenerateToken(user *entity.User) (string, error) {
 		return "", err
 	}
 
-	err = j.PersistToken(user, tokenID)
-	if err != nil {
+	if err = j.PersistToken(ctx, &t); err != nil {
 		return "", err
 	}
 	return tokenString, nil
 }
 
-func (j TokenModel) PersistToken(user *entity.User, tokenID uuid.UUID) error {
-	ctx := context.Background()
-	tokenStruct := entity.Token{
-		CreationDate: time.Now(),
-		ID:           tokenID,
-		UserID:       user.ID,
-	}
-	err := j.u.CreateToken(ctx, &tokenStruct)
-	return err
+// PersistToken сохраняет выданный токен; ctx запроса несет источник для журнала аудита
+func (j TokenModel) PersistToken(ctx context.Context, t *entity.Token) error {
+	return j.u.CreateToken(ctx, t)
 }
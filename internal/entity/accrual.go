@@ -1,9 +1,15 @@
 package entity
 
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
 type Accrual struct {
-	ID                int64   `json:"id"`
-	AccrualStatusesID int64   `json:"accrual_statuses_id"`
-	Accrual           float64 `json:"accrual"`
+	ID                int64  `json:"id"`
+	AccrualStatusesID int64  `json:"accrual_statuses_id"`
+	Accrual           Points `json:"accrual"`
 }
 
 type AccrualOrder struct {
@@ -12,12 +18,70 @@ type AccrualOrder struct {
 }
 
 type Product struct {
-	Description string  `json:"description"`
-	Price       float32 `json:"price"`
+	Description string `json:"description"`
+	Price       Points `json:"price"`
 }
 
 type AccrualResponse struct {
-	Order   string  `json:"order"`
-	Status  string  `json:"status"`
-	Accrual float32 `json:"accrual,omitempty"`
+	Order   string `json:"order"`
+	Status  string `json:"status"`
+	Accrual Points `json:"accrual,omitempty"`
+}
+
+// UnmarshalJSON читает ответ системы начислений. Начисление в отличие от
+// сумм из запросов пользователя не отклоняется из-за лишних знаков,
+// а округляется до сотых.
+func (r *AccrualResponse) UnmarshalJSON(data []byte) error {
+	type response AccrualResponse
+	var raw struct {
+		response
+		Accrual json.RawMessage `json:"accrual,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = AccrualResponse(raw.response)
+	s := string(raw.Accrual)
+	if s == "" || s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	accrual, err := RoundPoints(s)
+	if err != nil {
+		return err
+	}
+	r.Accrual = accrual
+	return nil
+}
+
+const (
+	AccrualStatusRegistered = "REGISTERED"
+	AccrualStatusInvalid    = "INVALID"
+	AccrualStatusProcessing = "PROCESSING"
+	AccrualStatusProcessed  = "PROCESSED"
+)
+
+type AccrualJobState string
+
+const (
+	AccrualJobPending AccrualJobState = "PENDING"
+	AccrualJobRunning AccrualJobState = "RUNNING"
+	AccrualJobDone    AccrualJobState = "DONE"
+	AccrualJobFailed  AccrualJobState = "FAILED"
+)
+
+// AccrualJob задание на получение начисления по заказу из таблицы accrual_jobs.
+// RegisteredAt — когда заказ принят системой начислений; nil — еще не отправлен.
+type AccrualJob struct {
+	ID           int64           `json:"id"`
+	OrderNumber  string          `json:"order"`
+	State        AccrualJobState `json:"state"`
+	Attempts     int             `json:"attempts"`
+	NextRunAt    time.Time       `json:"next_run_at"`
+	LastError    string          `json:"last_error,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	RegisteredAt *time.Time      `json:"registered_at,omitempty"`
+	TraceParent  string          `json:"-"`
 }
@@ -0,0 +1,47 @@
+package entity
+
+import "time"
+
+// AdminAction действие сотрудника в админке
+type AdminAction string
+
+const (
+	AdminSearchUsers       AdminAction = "SEARCH_USERS"
+	AdminViewOrders        AdminAction = "VIEW_ORDERS"
+	AdminViewWithdrawals   AdminAction = "VIEW_WITHDRAWALS"
+	AdminViewBalance       AdminAction = "VIEW_BALANCE_HISTORY"
+	AdminAdjustBalance     AdminAction = "ADJUST_BALANCE"
+	AdminLockUser          AdminAction = "LOCK_USER"
+	AdminUnlockUser        AdminAction = "UNLOCK_USER"
+	AdminForceLogout       AdminAction = "FORCE_LOGOUT"
+	AdminViewAuditEntries  AdminAction = "VIEW_AUDIT"
+	AdminViewAuditEvents   AdminAction = "VIEW_AUDIT_EVENTS"
+	AdminReverseWithdrawal AdminAction = "REVERSE_WITHDRAWAL"
+)
+
+// AdminAuditEntry запись журнала действий сотрудников
+type AdminAuditEntry struct {
+	ID           int64       `json:"id"`
+	ActorID      uint        `json:"actor_id"`
+	Action       AdminAction `json:"action"`
+	TargetUserID *uint       `json:"target_user_id,omitempty"`
+	Details      string      `json:"details,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// BalanceAdjustment ручная корректировка баланса, причина обязательна
+type BalanceAdjustment struct {
+	Amount Points `json:"amount" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// AccountLock блокировка учетной записи с указанием причины
+type AccountLock struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// BalanceHistory текущий баланс и журнал проводок пользователя
+type BalanceHistory struct {
+	Balance Balance       `json:"balance"`
+	Entries []LedgerEntry `json:"entries"`
+}
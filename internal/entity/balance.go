@@ -1,6 +1,28 @@
 package entity
 
+import "time"
+
+// Balance состояние счета: Current доступно для трат,
+// Held зарезервировано под неоплаченные заказы
 type Balance struct {
-	Current   float32 `json:"current"`
-	Withdrawn float32 `json:"withdrawn"`
+	Current   Points `json:"current"`
+	Held      Points `json:"held"`
+	Withdrawn Points `json:"withdrawn"`
+}
+
+// BalanceHistoryEntry движение доступного баланса: проводка по счету USER
+// и баланс сразу после нее
+type BalanceHistoryEntry struct {
+	ID          int64      `json:"-"`
+	Type        LedgerKind `json:"type"`
+	Amount      Points     `json:"amount"`
+	OrderNumber string     `json:"order,omitempty"`
+	Balance     Points     `json:"balance"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// BalanceHistoryPage страница истории баланса. NextCursor пуст на последней странице.
+type BalanceHistoryPage struct {
+	Entries    []BalanceHistoryEntry
+	NextCursor string
 }
@@ -13,4 +13,33 @@ var (
 	ErrInvalidOrderNumber   = errors.New("invalid order number")
 	ErrOrderExistsThisUser  = errors.New("order already uploaded by this user")
 	ErrOrderExistsOtherUser = errors.New("order already uploaded by another user")
+	ErrInvalidOrderGoods    = errors.New("invalid order goods")
+	ErrInvalidAdjustment    = errors.New("adjustment amount must not be zero")
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+	ErrIdempotencyConflict  = errors.New("request with this idempotency key is in progress")
+	ErrInvalidCredentials   = errors.New("invalid login or password")
+	ErrPasswordResetNeeded  = errors.New("password reset required")
+	ErrInvalidPassword      = errors.New("invalid password")
+	ErrTokenRevoked         = errors.New("token is revoked or expired")
+	ErrTokenReused          = errors.New("refresh token reuse detected")
+	ErrUserLocked           = errors.New("user account is locked")
+	ErrReasonRequired       = errors.New("reason is required")
+	ErrEventsUnavailable    = errors.New("event stream is not available")
+	ErrStatsUnavailable     = errors.New("loyalty stats are not available")
+	ErrAuditUnavailable     = errors.New("audit log is not available")
+	ErrOrderNotFound        = errors.New("order not found")
+	ErrWithdrawalNotFound   = errors.New("withdrawal not found")
+	ErrInvalidReversal      = errors.New("reversal sum must be positive")
+	ErrReversalExceeded     = errors.New("reversal exceeds the withdrawn amount")
+	ErrHoldsUnavailable     = errors.New("balance holds are not available")
+	ErrInvalidHold          = errors.New("hold sum must be positive")
+	ErrHoldExists           = errors.New("order already has an active hold")
+	ErrHoldNotFound         = errors.New("hold not found")
+	ErrHoldNotActive        = errors.New("hold is already captured, released or expired")
+	ErrCaptureExceeded      = errors.New("capture exceeds the held amount")
+	ErrInvalidTransfer      = errors.New("transfer sum must be positive")
+	ErrSelfTransfer         = errors.New("cannot transfer points to yourself")
+	ErrTransferLimit        = errors.New("daily transfer limit exceeded")
+	ErrInvalidStatement     = errors.New("invalid statement period")
+	ErrInvalidTiers         = errors.New("invalid tiers configuration")
 )
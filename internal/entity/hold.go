@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// HoldStatus состояние резерва баллов
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "ACTIVE"
+	HoldCaptured HoldStatus = "CAPTURED"
+	HoldReleased HoldStatus = "RELEASED"
+	HoldExpired  HoldStatus = "EXPIRED"
+)
+
+// HoldRequest резерв баллов под заказ, который еще не оплачен
+type HoldRequest struct {
+	Order string `json:"order" binding:"required"`
+	Sum   Points `json:"sum"`
+}
+
+// HoldCapture списание по резерву. Sum не задан — списывается весь резерв,
+// иначе остаток возвращается на счет.
+type HoldCapture struct {
+	Sum Points `json:"sum"`
+}
+
+// Hold резерв баллов: баллы уже не доступны для трат, но еще не списаны.
+// PointsExpireAt — ближайший срок сгорания зарезервированных баллов,
+// с ним они возвращаются в партии при отмене.
+type Hold struct {
+	ID             int64      `json:"id"`
+	UserID         uint       `json:"-"`
+	OrderNumber    string     `json:"order"`
+	Amount         Points     `json:"sum"`
+	Captured       Points     `json:"captured_sum,omitempty"`
+	Status         HoldStatus `json:"status"`
+	PointsExpireAt *time.Time `json:"-"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Active можно ли еще списать или отменить резерв в момент now
+func (h Hold) Active(now time.Time) bool {
+	return h.Status == HoldActive && now.Before(h.ExpiresAt)
+}
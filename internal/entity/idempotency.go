@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+type IdempotencyState string
+
+const (
+	IdempotencyInProgress IdempotencyState = "IN_PROGRESS"
+	IdempotencyCompleted  IdempotencyState = "COMPLETED"
+)
+
+// IdempotencyKey запрос с заголовком Idempotency-Key и сохраненный ответ на него
+type IdempotencyKey struct {
+	UserID      uint
+	Key         string
+	Fingerprint string
+	State       IdempotencyState
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,58 @@
+package entity
+
+import "time"
+
+// LedgerKind вид операции в журнале баллов
+type LedgerKind string
+
+const (
+	LedgerAccrual    LedgerKind = "ACCRUAL"
+	LedgerWithdrawal LedgerKind = "WITHDRAWAL"
+	LedgerAdjustment LedgerKind = "ADJUSTMENT"
+	LedgerReversal   LedgerKind = "REVERSAL"
+	LedgerExpiry     LedgerKind = "EXPIRY"
+	LedgerHold       LedgerKind = "HOLD"
+	LedgerRelease    LedgerKind = "RELEASE"
+	LedgerTransfer   LedgerKind = "TRANSFER"
+	LedgerTierBonus  LedgerKind = "TIER_BONUS"
+)
+
+// LedgerAccount счет, по которому проходит проводка. Баланс пользователя —
+// сумма по счету USER, списанное за все время — сумма по счету REDEEMED,
+// зарезервированное — сумма по счету HELD.
+type LedgerAccount string
+
+const (
+	LedgerAccountUser       LedgerAccount = "USER"
+	LedgerAccountIssued     LedgerAccount = "ISSUED"
+	LedgerAccountRedeemed   LedgerAccount = "REDEEMED"
+	LedgerAccountAdjustment LedgerAccount = "ADJUSTMENT"
+	LedgerAccountExpired    LedgerAccount = "EXPIRED"
+	LedgerAccountHeld       LedgerAccount = "HELD"
+	LedgerAccountTransfer   LedgerAccount = "TRANSFER"
+	LedgerAccountBonus      LedgerAccount = "BONUS"
+)
+
+// LedgerEntry одна сторона проводки. Проводка из двух записей с общим
+// TransactionID всегда дает в сумме ноль.
+type LedgerEntry struct {
+	ID            int64         `json:"id"`
+	TransactionID string        `json:"transaction_id"`
+	Account       LedgerAccount `json:"account"`
+	UserID        uint          `json:"user_id"`
+	Kind          LedgerKind    `json:"kind"`
+	Amount        Points        `json:"amount"`
+	OrderNumber   string        `json:"order,omitempty"`
+	WithdrawalID  *int64        `json:"withdrawal_id,omitempty"`
+	Description   string        `json:"description,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// BalanceCheck результат сверки сохраненного баланса с журналом
+type BalanceCheck struct {
+	UserID     uint    `json:"user_id"`
+	Ledger     Balance `json:"ledger"`
+	Projection Balance `json:"projection"`
+	Consistent bool    `json:"consistent"`
+	Repaired   bool    `json:"repaired"`
+}
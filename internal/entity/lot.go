@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+// PointLot партия начисленных баллов. Списания расходуют партии начиная с тех,
+// что сгорают раньше (FIFO); остаток партии сгорает в ExpiresAt, nil — бессрочно.
+type PointLot struct {
+	ID          int64      `json:"-"`
+	UserID      uint       `json:"-"`
+	OrderNumber string     `json:"order,omitempty"`
+	Amount      Points     `json:"amount"`
+	Remaining   Points     `json:"remaining"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ExpiryPolicy правила сгорания баллов. Партия сгорает через Months месяцев
+// после начисления, но списать ее можно еще Grace. Баллы, начисленные при
+// выключенной политике, бессрочные.
+type ExpiryPolicy struct {
+	Months   int
+	Grace    time.Duration
+	Notice   time.Duration
+	Disabled bool
+}
+
+// Enabled сгорают ли баллы по этой политике
+func (p ExpiryPolicy) Enabled() bool {
+	return !p.Disabled && p.Months > 0
+}
+
+// ExpiresAt когда сгорит партия, начисленная в at; nil — не сгорит
+func (p ExpiryPolicy) ExpiresAt(at time.Time) *time.Time {
+	if !p.Enabled() {
+		return nil
+	}
+	expiresAt := at.AddDate(0, p.Months, 0)
+	return &expiresAt
+}
+
+// Cutoff партии со сроком не позже этого момента уже сгорели с учетом отсрочки
+func (p ExpiryPolicy) Cutoff(now time.Time) time.Time {
+	return now.Add(-p.Grace)
+}
+
+// ExpiringPoints баллы, которые сгорят в ближайшее время
+type ExpiringPoints struct {
+	Total Points     `json:"total"`
+	Until time.Time  `json:"until"`
+	Lots  []PointLot `json:"lots"`
+}
+
+// BalanceSummary баланс пользователя вместе с баллами, которые скоро сгорят,
+// и уровнем участия
+type BalanceSummary struct {
+	Balance
+	ExpiringSoon *ExpiringPoints `json:"expiring_soon,omitempty"`
+	Tier         *TierStatus     `json:"tier,omitempty"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiryPolicy(t *testing.T) {
+	at := time.Date(2025, time.January, 31, 12, 0, 0, 0, time.UTC)
+
+	t.Run("lot expires after the configured months", func(t *testing.T) {
+		p := ExpiryPolicy{Months: 1, Grace: 48 * time.Hour}
+		expiresAt := p.ExpiresAt(at)
+		require.NotNil(t, expiresAt)
+		assert.Equal(t, time.Date(2025, time.March, 3, 12, 0, 0, 0, time.UTC), *expiresAt)
+		assert.Equal(t, at.Add(-48*time.Hour), p.Cutoff(at))
+	})
+
+	t.Run("disabled or zero months never expire", func(t *testing.T) {
+		assert.Nil(t, ExpiryPolicy{}.ExpiresAt(at))
+		assert.Nil(t, ExpiryPolicy{Months: 12, Disabled: true}.ExpiresAt(at))
+	})
+}
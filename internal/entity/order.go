@@ -15,6 +15,10 @@ type Order struct {
 	Number       string        `json:"Number"`
 	CreatedAt    time.Time     `json:"CreatedAt"`
 	UploadedAt   time.Time     `json:"Uploaded"`
+	Goods        []Product     `json:"goods,omitempty"`
+	// MerchantID учетная запись магазина, загрузившего заказ; nil — заказ загрузил покупатель
+	// или магазин по ключу API
+	MerchantID *uint `json:"-"`
 }
 
 const (
@@ -31,17 +35,43 @@ const (
 	OrderStatusProcessedID  OrderStatusID = 4
 )
 
+var orderStatusIDs = map[OrderStatus]OrderStatusID{
+	OrderStatusNew:        OrderStatusNewID,
+	OrderStatusProcessing: OrderStatusProcessingID,
+	OrderStatusInvalid:    OrderStatusInvalidID,
+	OrderStatusProcessed:  OrderStatusProcessedID,
+}
+
+// ID идентификатор статуса в справочнике statuses
+func (s OrderStatus) ID() (OrderStatusID, bool) {
+	id, ok := orderStatusIDs[s]
+	return id, ok
+}
+
 type OrderResponse struct {
 	ID         uint      `json:"id"`
 	Number     string    `json:"number"`
 	Status     string    `json:"status"`
-	Accrual    *float64  `json:"accrual"`
+	Accrual    *Points   `json:"accrual"`
 	UploadedAt time.Time `json:"uploaded_at"`
 }
 
 type OrderResponseDto struct {
 	Number     int       `json:"number"`
 	Status     string    `json:"status"`
-	Accrual    *float64  `json:"accrual"`
+	Accrual    *Points   `json:"accrual"`
 	UploadedAt time.Time `json:"uploaded_at"`
 }
+
+// OrderRequest JSON-вариант загрузки заказа с корзиной товаров
+type OrderRequest struct {
+	Order string    `json:"order" binding:"required"`
+	Goods []Product `json:"goods"`
+}
+
+// MerchantOrderRequest заказ, переданный магазином от имени покупателя
+type MerchantOrderRequest struct {
+	Order string    `json:"order" binding:"required"`
+	Login string    `json:"login" binding:"required"`
+	Goods []Product `json:"goods"`
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// OrderEvent изменение статуса заказа, которое доставляется владельцу по SSE.
+// ID растет монотонно и служит Last-Event-ID при переподключении.
+type OrderEvent struct {
+	ID        int64     `json:"-"`
+	UserID    uint      `json:"-"`
+	Number    string    `json:"number"`
+	Status    string    `json:"status"`
+	Accrual   *Points   `json:"accrual,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 100
+	MaxPageLimit     = 1000
+)
+
+var ErrInvalidListFilter = errors.New("invalid list filter")
+
+// SortDirection направление сортировки списков по времени
+type SortDirection string
+
+const (
+	SortDesc SortDirection = "desc"
+	SortAsc  SortDirection = "asc"
+)
+
+// PageCursor позиция в списке: время и ID последней отданной записи.
+// Пара уникальна, поэтому страницы не пропускают и не повторяют строки
+// при совпадающих временах.
+type PageCursor struct {
+	At time.Time
+	ID int64
+}
+
+// Encode непрозрачная строка курсора для клиента
+func (c PageCursor) Encode() string {
+	raw := strconv.FormatInt(c.At.UnixMicro(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodePageCursor разбирает строку, полученную от Encode
+func DecodePageCursor(s string) (*PageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor", ErrInvalidListFilter)
+	}
+	at, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, fmt.Errorf("%w: cursor", ErrInvalidListFilter)
+	}
+	micros, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor", ErrInvalidListFilter)
+	}
+	rowID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor", ErrInvalidListFilter)
+	}
+	return &PageCursor{At: time.UnixMicro(micros).UTC(), ID: rowID}, nil
+}
+
+// ListFilter параметры постраничной выдачи заказов и списаний.
+// Statuses применяется только к заказам; From включительно, To — нет.
+type ListFilter struct {
+	Limit    int
+	Cursor   *PageCursor
+	Sort     SortDirection
+	Statuses []OrderStatus
+	From     *time.Time
+	To       *time.Time
+}
+
+// Normalize подставляет значения по умолчанию и проверяет фильтр
+func (f ListFilter) Normalize() (ListFilter, error) {
+	switch {
+	case f.Limit == 0:
+		f.Limit = DefaultPageLimit
+	case f.Limit < 0 || f.Limit > MaxPageLimit:
+		return f, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidListFilter, MaxPageLimit)
+	}
+	switch f.Sort {
+	case "":
+		f.Sort = SortDesc
+	case SortDesc, SortAsc:
+	default:
+		return f, fmt.Errorf("%w: sort must be asc or desc", ErrInvalidListFilter)
+	}
+	statuses := make([]OrderStatus, 0, len(f.Statuses))
+	for _, s := range f.Statuses {
+		s = OrderStatus(strings.ToUpper(strings.TrimSpace(string(s))))
+		if _, ok := s.ID(); !ok {
+			return f, fmt.Errorf("%w: unknown status %q", ErrInvalidListFilter, s)
+		}
+		statuses = append(statuses, s)
+	}
+	f.Statuses = statuses
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return f, fmt.Errorf("%w: from must be before to", ErrInvalidListFilter)
+	}
+	return f, nil
+}
+
+// OrderPage страница заказов. NextCursor пуст на последней странице.
+type OrderPage struct {
+	Orders     []OrderResponse
+	NextCursor string
+}
+
+// WithdrawalPage страница списаний. NextCursor пуст на последней странице.
+type WithdrawalPage struct {
+	Withdrawals []Withdrawal
+	NextCursor  string
+}
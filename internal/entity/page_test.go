@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageCursor(t *testing.T) {
+	t.Run("round trip keeps microseconds and id", func(t *testing.T) {
+		c := PageCursor{At: time.Date(2025, 2, 1, 10, 17, 53, 123456000, time.UTC), ID: 42}
+
+		got, err := DecodePageCursor(c.Encode())
+		require.NoError(t, err)
+		assert.True(t, c.At.Equal(got.At))
+		assert.Equal(t, c.ID, got.ID)
+	})
+
+	t.Run("garbage is rejected", func(t *testing.T) {
+		for _, s := range []string{"***", "bm90LWEtY3Vyc29y", "MTIzOmFiYw"} {
+			_, err := DecodePageCursor(s)
+			assert.ErrorIs(t, err, ErrInvalidListFilter, s)
+		}
+	})
+}
+
+func TestListFilterNormalize(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		f, err := ListFilter{}.Normalize()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultPageLimit, f.Limit)
+		assert.Equal(t, SortDesc, f.Sort)
+	})
+
+	t.Run("statuses are case insensitive", func(t *testing.T) {
+		f, err := ListFilter{Statuses: []OrderStatus{"new", " Processed "}}.Normalize()
+		require.NoError(t, err)
+		assert.Equal(t, []OrderStatus{OrderStatusNew, OrderStatusProcessed}, f.Statuses)
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		from := time.Now()
+		to := from.Add(-time.Hour)
+		for name, f := range map[string]ListFilter{
+			"limit too big":    {Limit: MaxPageLimit + 1},
+			"negative limit":   {Limit: -1},
+			"unknown sort":     {Sort: "up"},
+			"unknown status":   {Statuses: []OrderStatus{"DONE"}},
+			"empty date range": {From: &from, To: &to},
+		} {
+			_, err := f.Normalize()
+			assert.ErrorIs(t, err, ErrInvalidListFilter, name)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Points сумма баллов с точностью до сотых. Хранится целым числом сотых,
+// поэтому сложение и сравнение точные, а в JSON и в Postgres значение
+// передается десятичной строкой без преобразования во float.
+type Points int64
+
+const pointsScale = 100
+
+var (
+	ErrPointsPrecision = errors.New("points must have at most two decimal places")
+	ErrPointsRange     = errors.New("points value out of range")
+	ErrPointsFormat    = errors.New("invalid points format")
+)
+
+// NewPoints собирает сумму из целой части и сотых: NewPoints(12, 34) == 12.34
+func NewPoints(units, cents int64) Points {
+	if units < 0 {
+		return Points(units*pointsScale - cents)
+	}
+	return Points(units*pointsScale + cents)
+}
+
+// PointsFromFloat округляет float до сотых. Только для значений, которые
+// изначально приходят как float (проценты, конфигурация), но не для денег из API.
+func PointsFromFloat(f float64) Points {
+	return Points(math.Round(f * pointsScale))
+}
+
+// ParsePoints разбирает десятичную запись без потери точности:
+// "100", "100.5", "100.50", "1e2". Больше двух знаков после запятой — ошибка.
+func ParsePoints(s string) (Points, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrPointsFormat
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrPointsFormat, s)
+	}
+	return pointsFromRat(r)
+}
+
+// RoundPoints разбирает десятичную запись и округляет ее до сотых, половина —
+// от нуля. Для сумм, которые считает внешняя система (начисление в процентах
+// может прийти с тремя знаками), а не для сумм, введенных пользователем.
+func RoundPoints(s string) (Points, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrPointsFormat
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrPointsFormat, s)
+	}
+	r.Mul(r, big.NewRat(pointsScale, 1))
+	n, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	// |rem| / denom >= 1/2
+	if rem.Sign() != 0 && new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		n.Add(n, big.NewInt(int64(rem.Sign())))
+	}
+	if !n.IsInt64() {
+		return 0, ErrPointsRange
+	}
+	return Points(n.Int64()), nil
+}
+
+func pointsFromRat(r *big.Rat) (Points, error) {
+	r = new(big.Rat).Mul(r, big.NewRat(pointsScale, 1))
+	if !r.IsInt() {
+		return 0, ErrPointsPrecision
+	}
+	n := r.Num()
+	if !n.IsInt64() {
+		return 0, ErrPointsRange
+	}
+	return Points(n.Int64()), nil
+}
+
+// String всегда возвращает два знака после запятой: "500.50", "-0.01"
+func (p Points) String() string {
+	sign := ""
+	v := uint64(p)
+	if p < 0 {
+		sign = "-"
+		v = uint64(-p)
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/pointsScale, v%pointsScale)
+}
+
+func (p Points) Add(q Points) Points { return p + q }
+
+func (p Points) Sub(q Points) Points { return p - q }
+
+func (p Points) Neg() Points { return -p }
+
+// Cmp возвращает -1, 0 или 1
+func (p Points) Cmp(q Points) int {
+	switch {
+	case p < q:
+		return -1
+	case p > q:
+		return 1
+	}
+	return 0
+}
+
+func (p Points) IsZero() bool { return p == 0 }
+
+func (p Points) IsNegative() bool { return p < 0 }
+
+func (p Points) IsPositive() bool { return p > 0 }
+
+// Percent возвращает pct процентов от суммы, округленные до сотых
+func (p Points) Percent(pct float64) Points {
+	return Points(math.Round(float64(p) * pct / 100))
+}
+
+// Float64 для логов и метрик, не для расчетов
+func (p Points) Float64() float64 {
+	return float64(p) / pointsScale
+}
+
+func (p Points) MarshalJSON() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalJSON принимает число или число в кавычках
+func (p *Points) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := ParsePoints(s)
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// NumericValue кодирует сумму в Postgres NUMERIC
+func (p Points) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(p)), Exp: -2, Valid: true}, nil
+}
+
+// ScanNumeric читает NUMERIC. NULL читается как ноль.
+func (p *Points) ScanNumeric(n pgtype.Numeric) error {
+	if !n.Valid {
+		*p = 0
+		return nil
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("%w: %v", ErrPointsFormat, n)
+	}
+	r := new(big.Rat).SetInt(n.Int)
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(n.Exp))), nil)
+	if n.Exp < 0 {
+		r.Quo(r, new(big.Rat).SetInt(exp))
+	} else {
+		r.Mul(r, new(big.Rat).SetInt(exp))
+	}
+	v, err := pointsFromRat(r)
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+func abs(x int32) int32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,182 @@
+package entity
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+	"testing/quick"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointsString(t *testing.T) {
+	assert.Equal(t, "0.00", Points(0).String())
+	assert.Equal(t, "500.50", NewPoints(500, 50).String())
+	assert.Equal(t, "0.01", Points(1).String())
+	assert.Equal(t, "-0.01", Points(-1).String())
+	assert.Equal(t, "-12.34", NewPoints(-12, 34).String())
+	assert.Equal(t, "-92233720368547758.08", Points(math.MinInt64).String())
+}
+
+func TestParsePoints(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Points
+		err  error
+	}{
+		{"100", NewPoints(100, 0), nil},
+		{"100.5", NewPoints(100, 50), nil},
+		{"100.50", NewPoints(100, 50), nil},
+		{"100.500", NewPoints(100, 50), nil},
+		{"0.01", 1, nil},
+		{"-0.01", -1, nil},
+		{"1e2", NewPoints(100, 0), nil},
+		{"729.98", NewPoints(729, 98), nil},
+		{"0.001", 0, ErrPointsPrecision},
+		{"1e30", 0, ErrPointsRange},
+		{"abc", 0, ErrPointsFormat},
+		{"", 0, ErrPointsFormat},
+	}
+	for _, tt := range tests {
+		got, err := ParsePoints(tt.in)
+		if tt.err != nil {
+			assert.ErrorIs(t, err, tt.err, tt.in)
+			continue
+		}
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+}
+
+func TestRoundPoints(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Points
+	}{
+		{"12.345", NewPoints(12, 35)},
+		{"12.344", NewPoints(12, 34)},
+		{"-12.345", NewPoints(-12, 35)},
+		{"0.005", 1},
+		{"0.0049", 0},
+		{"100.5", NewPoints(100, 50)},
+		{"1e2", NewPoints(100, 0)},
+	}
+	for _, tt := range tests {
+		got, err := RoundPoints(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+	_, err := RoundPoints("abc")
+	assert.ErrorIs(t, err, ErrPointsFormat)
+	_, err = RoundPoints("1e30")
+	assert.ErrorIs(t, err, ErrPointsRange)
+}
+
+func TestPointsJSON(t *testing.T) {
+	balance := Balance{Current: NewPoints(500, 50), Held: NewPoints(10, 0), Withdrawn: NewPoints(42, 0)}
+	data, err := json.Marshal(balance)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"current":500.50,"held":10.00,"withdrawn":42.00}`, string(data))
+	assert.Equal(t, `{"current":500.50,"held":10.00,"withdrawn":42.00}`, string(data))
+
+	var req WithdrawalRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"order":"2377225624","sum":751.1}`), &req))
+	assert.Equal(t, NewPoints(751, 10), req.Sum)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"sum":"0.99"}`), &req))
+	assert.Equal(t, NewPoints(0, 99), req.Sum)
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"sum":0.999}`), &req), ErrPointsPrecision)
+
+	// начисление от внешней системы округляется, а не отклоняется
+	var resp AccrualResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"order":"1","status":"PROCESSED","accrual":12.345}`), &resp))
+	assert.Equal(t, AccrualResponse{Order: "1", Status: AccrualStatusProcessed, Accrual: NewPoints(12, 35)}, resp)
+
+	resp = AccrualResponse{}
+	require.NoError(t, json.Unmarshal([]byte(`{"order":"1","status":"REGISTERED"}`), &resp))
+	assert.Equal(t, AccrualResponse{Order: "1", Status: AccrualStatusRegistered}, resp)
+
+	// omitempty у начисления сохраняется
+	data, err = json.Marshal(AccrualResponse{Order: "1", Status: AccrualStatusInvalid})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"order":"1","status":"INVALID"}`, string(data))
+}
+
+func TestPointsArithmetic(t *testing.T) {
+	assert.Equal(t, NewPoints(0, 30), NewPoints(0, 10).Add(NewPoints(0, 20)))
+	assert.Equal(t, NewPoints(-1, 0), NewPoints(1, 0).Sub(NewPoints(2, 0)))
+	assert.Equal(t, -1, NewPoints(1, 0).Cmp(NewPoints(1, 1)))
+	assert.Equal(t, 0, NewPoints(1, 1).Cmp(Points(101)))
+	assert.True(t, Points(-1).IsNegative())
+	assert.True(t, Points(1).IsPositive())
+	assert.Equal(t, NewPoints(700, 0), NewPoints(7000, 0).Percent(10))
+	assert.Equal(t, NewPoints(0, 33), NewPoints(1, 0).Percent(33.333))
+	assert.Equal(t, NewPoints(0, 10), PointsFromFloat(0.1))
+	assert.Equal(t, NewPoints(729, 98), PointsFromFloat(float64(float32(729.98))))
+}
+
+// Свойства: значение без потерь проходит через строку, JSON и NUMERIC Postgres.
+func TestPointsRoundTripProperties(t *testing.T) {
+	cfg := &quick.Config{MaxCount: 5000}
+
+	stringRoundTrip := func(v int64) bool {
+		p := Points(v)
+		got, err := ParsePoints(p.String())
+		return err == nil && got == p
+	}
+	require.NoError(t, quick.Check(stringRoundTrip, cfg))
+
+	jsonRoundTrip := func(v int64) bool {
+		in := Withdrawal{Amount: Points(v)}
+		data, err := json.Marshal(in)
+		if err != nil {
+			return false
+		}
+		var out Withdrawal
+		return json.Unmarshal(data, &out) == nil && out.Amount == in.Amount
+	}
+	require.NoError(t, quick.Check(jsonRoundTrip, cfg))
+
+	numericRoundTrip := func(v int64) bool {
+		p := Points(v)
+		n, err := p.NumericValue()
+		if err != nil {
+			return false
+		}
+		var got Points
+		return got.ScanNumeric(n) == nil && got == p
+	}
+	require.NoError(t, quick.Check(numericRoundTrip, cfg))
+
+	// Postgres может вернуть то же значение с другой экспонентой: 1050e-3 или 105e-1
+	numericScale := func(v int32, shift uint8) bool {
+		exp := int32(shift % 6)
+		mul := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+		n := pgtype.Numeric{Int: new(big.Int).Mul(big.NewInt(int64(v)), mul), Exp: -2 - exp, Valid: true}
+		var got Points
+		return got.ScanNumeric(n) == nil && got == Points(v)
+	}
+	require.NoError(t, quick.Check(numericScale, cfg))
+
+	addSub := func(a, b int32) bool {
+		p, q := Points(a), Points(b)
+		return p.Add(q).Sub(q) == p && p.Add(q) == q.Add(p)
+	}
+	require.NoError(t, quick.Check(addSub, cfg))
+}
+
+func TestPointsScanNumeric(t *testing.T) {
+	var p Points = 5
+	require.NoError(t, p.ScanNumeric(pgtype.Numeric{}))
+	assert.Equal(t, Points(0), p)
+
+	require.NoError(t, p.ScanNumeric(pgtype.Numeric{Int: big.NewInt(15), Exp: 1, Valid: true}))
+	assert.Equal(t, NewPoints(150, 0), p)
+
+	assert.ErrorIs(t, p.ScanNumeric(pgtype.Numeric{Int: big.NewInt(1), Exp: -3, Valid: true}), ErrPointsPrecision)
+	assert.Error(t, p.ScanNumeric(pgtype.Numeric{NaN: true, Valid: true}))
+}
@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// StatementFormat формат выгрузки выписки
+type StatementFormat string
+
+const (
+	StatementCSV  StatementFormat = "csv"
+	StatementJSON StatementFormat = "json"
+)
+
+// StatementOrder тип строки выписки для загруженного заказа; у проводок
+// тип совпадает с видом проводки в журнале
+const StatementOrder = "ORDER"
+
+// StatementPeriod период выписки [From, To). Без From выписка начинается
+// с первой операции, без To заканчивается моментом запроса.
+type StatementPeriod struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// Normalize подставляет now вместо пустого To и проверяет границы
+func (p StatementPeriod) Normalize(now time.Time) (StatementPeriod, error) {
+	if p.To == nil {
+		p.To = &now
+	}
+	if p.From != nil && !p.From.Before(*p.To) {
+		return p, fmt.Errorf("%w: from must be before to", ErrInvalidStatement)
+	}
+	return p, nil
+}
+
+// Statement шапка выписки: период и баланс на его начало и конец
+type Statement struct {
+	From           *time.Time `json:"from,omitempty"`
+	To             time.Time  `json:"to"`
+	OpeningBalance Points     `json:"opening_balance"`
+	ClosingBalance Points     `json:"closing_balance"`
+}
+
+// StatementLine строка выписки: заказ или проводка по счету пользователя.
+// У заказа нет суммы, у проводки — статуса; Balance — баланс после проводки.
+type StatementLine struct {
+	ID          int64       `json:"-"`
+	Type        string      `json:"type"`
+	OrderNumber string      `json:"order,omitempty"`
+	Status      OrderStatus `json:"status,omitempty"`
+	Amount      *Points     `json:"amount,omitempty"`
+	Balance     *Points     `json:"balance,omitempty"`
+	At          time.Time   `json:"date"`
+}
+
+// StatementWriter получает выписку по частям: шапку с балансом на начало,
+// строки по порядку и итог с балансом на конец периода
+type StatementWriter interface {
+	Begin(s Statement) error
+	Line(l StatementLine) error
+	End(s Statement) error
+}
@@ -0,0 +1,10 @@
+package entity
+
+// LoyaltyStats сводные показатели программы лояльности для метрик
+type LoyaltyStats struct {
+	// Outstanding баллы на счетах пользователей, которые еще можно потратить
+	Outstanding Points
+	Withdrawn   Points
+	// AccrualJobs незавершенные задания начисления по состояниям
+	AccrualJobs map[AccrualJobState]int64
+}
@@ -0,0 +1,130 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TierWindowMonths за сколько последних месяцев суммируются начисления для уровня
+const TierWindowMonths = 12
+
+// TierBase уровень участника, не достигшего ни одного порога: начисления без множителя
+const TierBase = "BASE"
+
+// Tier уровень участия: когда сумма начислений за окно достигает Threshold,
+// каждое следующее начисление умножается на Multiplier
+type Tier struct {
+	Name       string  `json:"name"`
+	Threshold  Points  `json:"threshold"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// Bonus надбавка уровня к начислению сверх самого начисления; у базового уровня ноль
+func (t *Tier) Bonus(accrual Points) Points {
+	if t == nil || t.Multiplier <= 1 {
+		return 0
+	}
+	return accrual.Percent((t.Multiplier - 1) * 100)
+}
+
+// TierName имя уровня, TierBase для nil
+func (t *Tier) TierName() string {
+	if t == nil {
+		return TierBase
+	}
+	return t.Name
+}
+
+// TierPolicy уровни участия по возрастанию порога; пустая политика отключает уровни
+type TierPolicy struct {
+	Tiers []Tier
+}
+
+// NewTierPolicy упорядочивает уровни по порогу и проверяет, что пороги положительны
+// и различны, а множитель не меньше 1 и не убывает с ростом уровня
+func NewTierPolicy(tiers []Tier) (TierPolicy, error) {
+	sorted := append([]Tier(nil), tiers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Threshold < sorted[j].Threshold })
+	names := make(map[string]struct{}, len(sorted))
+	for i, t := range sorted {
+		name := strings.ToUpper(strings.TrimSpace(t.Name))
+		if name == "" || name == TierBase {
+			return TierPolicy{}, fmt.Errorf("%w: tier name %q", ErrInvalidTiers, t.Name)
+		}
+		if _, ok := names[name]; ok {
+			return TierPolicy{}, fmt.Errorf("%w: duplicate tier %s", ErrInvalidTiers, name)
+		}
+		names[name] = struct{}{}
+		if !t.Threshold.IsPositive() {
+			return TierPolicy{}, fmt.Errorf("%w: %s threshold must be positive", ErrInvalidTiers, name)
+		}
+		if t.Multiplier < 1 {
+			return TierPolicy{}, fmt.Errorf("%w: %s multiplier must be at least 1", ErrInvalidTiers, name)
+		}
+		if i > 0 {
+			prev := sorted[i-1]
+			if prev.Threshold == t.Threshold || prev.Multiplier > t.Multiplier {
+				return TierPolicy{}, fmt.Errorf("%w: %s must have a higher threshold and multiplier than %s",
+					ErrInvalidTiers, name, prev.Name)
+			}
+		}
+		sorted[i].Name = name
+	}
+	return TierPolicy{Tiers: sorted}, nil
+}
+
+// Enabled есть ли уровни в политике
+func (p TierPolicy) Enabled() bool {
+	return len(p.Tiers) > 0
+}
+
+// Since начало окна, за которое считаются начисления на момент now
+func (p TierPolicy) Since(now time.Time) time.Time {
+	return now.AddDate(0, -TierWindowMonths, 0)
+}
+
+// TierFor наивысший уровень, порог которого достигнут; nil — базовый уровень
+func (p TierPolicy) TierFor(accrued Points) *Tier {
+	var tier *Tier
+	for i := range p.Tiers {
+		if accrued.Cmp(p.Tiers[i].Threshold) < 0 {
+			break
+		}
+		tier = &p.Tiers[i]
+	}
+	return tier
+}
+
+// Status уровень участника с суммой начислений accrued и прогресс до следующего
+func (p TierPolicy) Status(accrued Points) *TierStatus {
+	tier := p.TierFor(accrued)
+	status := &TierStatus{Name: tier.TierName(), Multiplier: 1, Accrued: accrued}
+	if tier != nil {
+		status.Multiplier = tier.Multiplier
+	}
+	for _, t := range p.Tiers {
+		if accrued.Cmp(t.Threshold) < 0 {
+			status.Next = &TierProgress{Name: t.Name, Threshold: t.Threshold, Remaining: t.Threshold.Sub(accrued)}
+			break
+		}
+	}
+	return status
+}
+
+// TierStatus текущий уровень участника: Accrued — сумма начислений за последние
+// TierWindowMonths месяцев, Next — следующий уровень, nil на высшем
+type TierStatus struct {
+	Name       string        `json:"name"`
+	Multiplier float64       `json:"multiplier"`
+	Accrued    Points        `json:"rolling_accrual"`
+	Next       *TierProgress `json:"next,omitempty"`
+}
+
+// TierProgress сколько осталось начислить до следующего уровня
+type TierProgress struct {
+	Name      string `json:"name"`
+	Threshold Points `json:"threshold"`
+	Remaining Points `json:"remaining"`
+}
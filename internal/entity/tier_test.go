@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTierPolicy(t *testing.T) {
+	policy, err := NewTierPolicy([]Tier{
+		{Name: "gold", Threshold: NewPoints(5000, 0), Multiplier: 1.25},
+		{Name: "SILVER", Threshold: NewPoints(1000, 0), Multiplier: 1.1},
+		{Name: "Platinum", Threshold: NewPoints(15000, 0), Multiplier: 1.5},
+	})
+	require.NoError(t, err)
+
+	t.Run("tiers are sorted by threshold", func(t *testing.T) {
+		require.Len(t, policy.Tiers, 3)
+		assert.Equal(t, "SILVER", policy.Tiers[0].Name)
+		assert.Equal(t, "GOLD", policy.Tiers[1].Name)
+		assert.Equal(t, "PLATINUM", policy.Tiers[2].Name)
+	})
+
+	t.Run("highest reached threshold wins", func(t *testing.T) {
+		assert.Nil(t, policy.TierFor(NewPoints(999, 99)))
+		assert.Equal(t, "SILVER", policy.TierFor(NewPoints(1000, 0)).Name)
+		assert.Equal(t, "GOLD", policy.TierFor(NewPoints(14999, 0)).Name)
+		assert.Equal(t, "PLATINUM", policy.TierFor(NewPoints(20000, 0)).Name)
+	})
+
+	t.Run("bonus is the part above the accrual", func(t *testing.T) {
+		assert.Equal(t, NewPoints(25, 13), policy.TierFor(NewPoints(5000, 0)).Bonus(NewPoints(100, 50)))
+		var base *Tier
+		assert.True(t, base.Bonus(NewPoints(100, 0)).IsZero())
+		assert.Equal(t, TierBase, base.TierName())
+	})
+
+	t.Run("status shows progress to the next tier", func(t *testing.T) {
+		status := policy.Status(NewPoints(1200, 0))
+		assert.Equal(t, "SILVER", status.Name)
+		assert.Equal(t, 1.1, status.Multiplier)
+		assert.Equal(t, &TierProgress{Name: "GOLD", Threshold: NewPoints(5000, 0), Remaining: NewPoints(3800, 0)}, status.Next)
+
+		base := policy.Status(0)
+		assert.Equal(t, TierBase, base.Name)
+		assert.Equal(t, 1.0, base.Multiplier)
+		assert.Equal(t, "SILVER", base.Next.Name)
+
+		assert.Nil(t, policy.Status(NewPoints(15000, 0)).Next)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		for name, tiers := range map[string][]Tier{
+			"empty name":          {{Threshold: NewPoints(1, 0), Multiplier: 1.1}},
+			"duplicate name":      {{Name: "GOLD", Threshold: NewPoints(1, 0), Multiplier: 1.1}, {Name: "gold", Threshold: NewPoints(2, 0), Multiplier: 1.2}},
+			"zero threshold":      {{Name: "GOLD", Multiplier: 1.1}},
+			"multiplier below 1":  {{Name: "GOLD", Threshold: NewPoints(1, 0), Multiplier: 0.9}},
+			"same threshold":      {{Name: "A", Threshold: NewPoints(1, 0), Multiplier: 1.1}, {Name: "B", Threshold: NewPoints(1, 0), Multiplier: 1.2}},
+			"decreasing multiple": {{Name: "A", Threshold: NewPoints(1, 0), Multiplier: 1.5}, {Name: "B", Threshold: NewPoints(2, 0), Multiplier: 1.2}},
+		} {
+			_, err := NewTierPolicy(tiers)
+			assert.ErrorIs(t, err, ErrInvalidTiers, name)
+		}
+	})
+
+	t.Run("no tiers disables the policy", func(t *testing.T) {
+		empty, err := NewTierPolicy(nil)
+		require.NoError(t, err)
+		assert.False(t, empty.Enabled())
+		assert.True(t, policy.Enabled())
+	})
+}
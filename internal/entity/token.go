@@ -6,9 +6,39 @@ import (
 	"github.com/google/uuid"
 )
 
+type TokenKind string
+
+const (
+	TokenAccess  TokenKind = "ACCESS"
+	TokenRefresh TokenKind = "REFRESH"
+)
+
+// Token выданный JWT. Токены одного входа (access и все refresh после ротаций)
+// связаны общим FamilyID.
 type Token struct {
 	ID           uuid.UUID `pg:"type:uuid,pk"`
 	UserID       uint
+	Kind         TokenKind
+	FamilyID     uuid.UUID
 	CreationDate time.Time
-	UsedAt       time.Time
+	ExpiresAt    time.Time
+	UsedAt       *time.Time
+	RevokedAt    *time.Time
+}
+
+// Active токен не отозван и не истек
+func (t Token) Active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// TokenPair ответ на вход и обновление токенов
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// RefreshRequest запрос на обновление токенов
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+// TransferDirection сторона перевода с точки зрения пользователя
+type TransferDirection string
+
+const (
+	TransferOut TransferDirection = "OUT"
+	TransferIn  TransferDirection = "IN"
+)
+
+// TransferRequest перевод баллов другому пользователю по логину
+type TransferRequest struct {
+	Login string `json:"login" binding:"required"`
+	Sum   Points `json:"sum"`
+}
+
+// Transfer перевод баллов. В истории пользователя Direction и Login
+// описывают вторую сторону перевода.
+type Transfer struct {
+	ID          int64             `json:"id"`
+	SenderID    uint              `json:"-"`
+	RecipientID uint              `json:"-"`
+	Direction   TransferDirection `json:"direction,omitempty"`
+	Login       string            `json:"login"`
+	Amount      Points            `json:"sum"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// TransferPage страница переводов. NextCursor пуст на последней странице.
+type TransferPage struct {
+	Transfers  []Transfer
+	NextCursor string
+}
+
+// TransferLimits ограничения на отправленные за сутки переводы; ноль — без ограничения
+type TransferLimits struct {
+	DailyAmount Points
+	DailyCount  int
+}
+
+// Allows можно ли отправить amount, если за сутки уже отправлено sent
+// в count переводах
+func (l TransferLimits) Allows(sent Points, count int, amount Points) bool {
+	if l.DailyAmount.IsPositive() && sent.Add(amount).Cmp(l.DailyAmount) > 0 {
+		return false
+	}
+	return l.DailyCount <= 0 || count < l.DailyCount
+}
@@ -1,9 +1,31 @@
 package entity
 
+import "time"
+
+// Роли пользователей, хранятся в users.role
+const (
+	RoleCustomer = "customer"
+	RoleSupport  = "support"
+	RoleAdmin    = "admin"
+	RoleMerchant = "merchant"
+)
+
 type User struct {
 	ID       uint   `json:"ID"`
 	Login    string `json:"Login"`
 	Email    string `json:"Email"`
-	Password string `json:"Password"`
-	Access   string
+	Password string `json:"-"`
+	// Access роль пользователя, попадает в claim access токена
+	Access string
+	// PasswordResetRequired пароль хранится открытым текстом со старых версий и должен быть сменен
+	PasswordResetRequired bool `json:"-"`
+	// LockedAt время блокировки учетной записи администратором
+	LockedAt *time.Time `json:"LockedAt,omitempty"`
+}
+
+// PasswordReset смена пароля по текущему паролю
+type PasswordReset struct {
+	Login       string `json:"login" binding:"required"`
+	Password    string `json:"password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
 }
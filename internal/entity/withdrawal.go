@@ -2,22 +2,59 @@ package entity
 
 import "time"
 
+// WithdrawalStatus состояние списания с учетом возвратов
+type WithdrawalStatus string
+
+const (
+	WithdrawalCompleted         WithdrawalStatus = "COMPLETED"
+	WithdrawalPartiallyReversed WithdrawalStatus = "PARTIALLY_REVERSED"
+	WithdrawalReversed          WithdrawalStatus = "REVERSED"
+)
+
 type WithdrawalRequest struct {
-	Order string  `json:"order"`
-	Sum   float32 `json:"sum" `
+	Order string `json:"order"`
+	Sum   Points `json:"sum" `
 }
 
 type Withdrawal struct {
-	ID          uint      `json:"id"`
-	UserID      uint      `json:"user_id"`
-	OrderNumber string    `json:"order"`
-	Amount      float32   `json:"sum"`
-	CreatedAt   time.Time `json:"created_at"`
-	ProcessedAt time.Time `json:"processed_at"`
+	ID          uint             `json:"id"`
+	UserID      uint             `json:"user_id"`
+	OrderNumber string           `json:"order"`
+	Amount      Points           `json:"sum"`
+	Reversed    Points           `json:"reversed_sum,omitempty"`
+	Status      WithdrawalStatus `json:"status,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ProcessedAt time.Time        `json:"processed_at"`
+}
+
+// Remaining сколько баллов списания еще можно вернуть
+func (w Withdrawal) Remaining() Points {
+	return w.Amount.Sub(w.Reversed)
+}
+
+// SetReversed запоминает возвращенную сумму и пересчитывает статус
+func (w *Withdrawal) SetReversed(reversed Points) {
+	w.Reversed = reversed
+	switch {
+	case reversed.IsZero():
+		w.Status = WithdrawalCompleted
+	case reversed.Cmp(w.Amount) < 0:
+		w.Status = WithdrawalPartiallyReversed
+	default:
+		w.Status = WithdrawalReversed
+	}
 }
 
 type WithdrawalResponse struct {
 	Order       string    `json:"order"`
-	Sum         float32   `json:"sum"`
+	Sum         Points    `json:"sum"`
 	ProcessedAt time.Time `json:"processed_at"`
 }
+
+// WithdrawalReversal возврат баллов по списанию, например когда магазин отменил заказ.
+// Sum не задан — возвращается весь остаток; причина обязательна.
+type WithdrawalReversal struct {
+	Order  string `json:"order" binding:"required"`
+	Sum    Points `json:"sum"`
+	Reason string `json:"reason" binding:"required"`
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithdrawalSetReversed(t *testing.T) {
+	tests := []struct {
+		reversed  Points
+		status    WithdrawalStatus
+		remaining Points
+	}{
+		{0, WithdrawalCompleted, NewPoints(100, 0)},
+		{NewPoints(0, 1), WithdrawalPartiallyReversed, NewPoints(99, 99)},
+		{NewPoints(100, 0), WithdrawalReversed, 0},
+	}
+	for _, tt := range tests {
+		w := Withdrawal{Amount: NewPoints(100, 0)}
+		w.SetReversed(tt.reversed)
+		assert.Equal(t, tt.status, w.Status, tt.reversed.String())
+		assert.Equal(t, tt.remaining, w.Remaining(), tt.reversed.String())
+	}
+}
@@ -0,0 +1,30 @@
+package health
+
+import (
+	"context"
+	"fmt"
+)
+
+// MigrationSource отдает версию схемы, записанную golang-migrate
+type MigrationSource interface {
+	GetMigrationVersion(ctx context.Context) (version uint, dirty bool, err error)
+}
+
+// Migrations проверяет, что схема не старее миграций, с которыми собран сервис,
+// и что последняя миграция не упала на середине. Более новая схема допустима:
+// во время выкатки старые реплики работают рядом с уже мигрировавшей базой.
+func Migrations(src MigrationSource, want uint) Check {
+	return func(ctx context.Context) error {
+		version, dirty, err := src.GetMigrationVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("migration %d is dirty", version)
+		}
+		if version < want {
+			return fmt.Errorf("schema version %d is older than %d", version, want)
+		}
+		return nil
+	}
+}
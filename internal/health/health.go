@@ -0,0 +1,120 @@
+// Package health отвечает на проверки живости и готовности.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Статусы проверок
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// checkShutdown проверка, которая проваливается с началом остановки сервера
+const checkShutdown = "shutdown"
+
+// Check проверяет одну зависимость. nil означает, что зависимость в порядке.
+type Check func(ctx context.Context) error
+
+// CheckResult итог одной проверки
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report ответ /readyz
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker собирает проверки готовности. Проверки запускаются параллельно,
+// каждая ограничена таймаутом, поэтому зависшая зависимость не держит пробу.
+type Checker struct {
+	timeout      time.Duration
+	checks       []namedCheck
+	shuttingDown atomic.Bool
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add регистрирует проверку. Вызывается до того, как сервер начал принимать запросы.
+func (h *Checker) Add(name string, check Check) {
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// Shutdown переводит готовность в отказ, чтобы балансировщик снял трафик
+// раньше, чем сервер начнет закрывать соединения
+func (h *Checker) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// Check выполняет все проверки
+func (h *Checker) Check(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(h.checks))}
+	if h.shuttingDown.Load() {
+		report.Status = StatusFail
+		report.Checks[checkShutdown] = CheckResult{Status: StatusFail, Error: "server is shutting down", Duration: "0s"}
+		return report
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range h.checks {
+		wg.Add(1)
+		go func(c namedCheck) {
+			defer wg.Done()
+			start := time.Now()
+			err := c.check(ctx)
+			res := CheckResult{Status: StatusOK, Duration: time.Since(start).Round(time.Microsecond).String()}
+			if err != nil {
+				res.Status = StatusFail
+				res.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = res
+			if err != nil {
+				report.Status = StatusFail
+			}
+		}(c)
+	}
+	wg.Wait()
+	return report
+}
+
+// Liveness отвечает, пока процесс способен обслуживать запросы.
+// Зависимости не проверяются: их отказ не лечится перезапуском.
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": StatusOK})
+}
+
+// Readiness отвечает 200, если все проверки прошли, иначе 503 с подробностями
+func (h *Checker) Readiness(c *gin.Context) {
+	report := h.Check(c.Request.Context())
+	code := http.StatusOK
+	if report.Status != StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(code, report)
+}
@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveReadiness(t *testing.T, h *Checker) (int, Report) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/readyz", h.Readiness)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	return w.Code, report
+}
+
+func TestReadiness(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+
+	t.Run("all checks pass", func(t *testing.T) {
+		h := NewChecker(time.Second)
+		h.Add("postgres", ok)
+		h.Add("accrual_workers", ok)
+
+		code, report := serveReadiness(t, h)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, StatusOK, report.Status)
+		assert.Len(t, report.Checks, 2)
+		assert.Equal(t, StatusOK, report.Checks["postgres"].Status)
+	})
+
+	t.Run("failed check is reported", func(t *testing.T) {
+		h := NewChecker(time.Second)
+		h.Add("postgres", ok)
+		h.Add("accrual_system", func(context.Context) error { return errors.New("connection refused") })
+
+		code, report := serveReadiness(t, h)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, StatusFail, report.Status)
+		assert.Equal(t, StatusOK, report.Checks["postgres"].Status)
+		assert.Equal(t, StatusFail, report.Checks["accrual_system"].Status)
+		assert.Equal(t, "connection refused", report.Checks["accrual_system"].Error)
+	})
+
+	t.Run("hanging check is cut by timeout", func(t *testing.T) {
+		h := NewChecker(10 * time.Millisecond)
+		h.Add("postgres", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		code, report := serveReadiness(t, h)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["postgres"].Error)
+	})
+
+	t.Run("shutdown fails readiness without running checks", func(t *testing.T) {
+		h := NewChecker(time.Second)
+		h.Add("postgres", func(context.Context) error {
+			t.Error("checks must not run during shutdown")
+			return nil
+		})
+		h.Shutdown()
+
+		code, report := serveReadiness(t, h)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, StatusFail, report.Checks[checkShutdown].Status)
+	})
+}
+
+func TestLiveness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/healthz", Liveness)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	const want = uint(20250201101810)
+
+	tests := []struct {
+		name    string
+		version uint
+		dirty   bool
+		err     error
+		wantErr bool
+	}{
+		{name: "current schema", version: want},
+		{name: "newer schema during rollout", version: want + 1},
+		{name: "older schema", version: want - 1, wantErr: true},
+		{name: "dirty migration", version: want, dirty: true, wantErr: true},
+		{name: "schema_migrations unreadable", err: errors.New("relation does not exist"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := mocks.NewMockHealthRepository(gomock.NewController(t))
+			src.EXPECT().GetMigrationVersion(ctx).Return(tt.version, tt.dirty, tt.err)
+
+			err := Migrations(src, want)(ctx)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
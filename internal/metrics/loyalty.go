@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"go-loyalty-system/internal/entity"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statsTimeout ограничивает запрос показателей, чтобы медленная база
+// не держала сбор метрик дольше таймаута Prometheus
+const statsTimeout = 5 * time.Second
+
+// StatsSource источник сводных показателей программы
+type StatsSource interface {
+	GetLoyaltyStats(ctx context.Context) (*entity.LoyaltyStats, error)
+}
+
+// loyaltyCollector читает показатели программы из базы при каждом сборе
+type loyaltyCollector struct {
+	src         StatsSource
+	outstanding *prometheus.Desc
+	withdrawn   *prometheus.Desc
+	accrualJobs *prometheus.Desc
+}
+
+// NewLoyaltyCollector сборщик бизнес-показателей: баллы на счетах,
+// списанные баллы и глубина очереди начислений
+func NewLoyaltyCollector(src StatsSource) prometheus.Collector {
+	return &loyaltyCollector{
+		src: src,
+		outstanding: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "loyalty", "outstanding_points"),
+			"Points on user balances that can still be spent.", nil, nil),
+		withdrawn: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "loyalty", "withdrawn_points"),
+			"Points withdrawn by users in total.", nil, nil),
+		accrualJobs: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "accrual", "jobs"),
+			"Unfinished accrual jobs by state.", []string{"state"}, nil),
+	}
+}
+
+func (c *loyaltyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.outstanding
+	ch <- c.withdrawn
+	ch <- c.accrualJobs
+}
+
+func (c *loyaltyCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), statsTimeout)
+	defer cancel()
+	stats, err := c.src.GetLoyaltyStats(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.outstanding, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.outstanding, prometheus.GaugeValue, stats.Outstanding.Float64())
+	ch <- prometheus.MustNewConstMetric(c.withdrawn, prometheus.GaugeValue, stats.Withdrawn.Float64())
+	// состояния без заданий отдаются нулем, чтобы ряд не пропадал из графиков
+	for _, state := range []entity.AccrualJobState{entity.AccrualJobPending, entity.AccrualJobRunning, entity.AccrualJobFailed} {
+		ch <- prometheus.MustNewConstMetric(c.accrualJobs, prometheus.GaugeValue, float64(stats.AccrualJobs[state]), string(state))
+	}
+}
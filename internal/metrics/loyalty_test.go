@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubStats struct {
+	stats *entity.LoyaltyStats
+	err   error
+}
+
+func (s stubStats) GetLoyaltyStats(context.Context) (*entity.LoyaltyStats, error) {
+	return s.stats, s.err
+}
+
+func TestLoyaltyCollector(t *testing.T) {
+	t.Run("stats are exported as gauges", func(t *testing.T) {
+		c := NewLoyaltyCollector(stubStats{stats: &entity.LoyaltyStats{
+			Outstanding: entity.NewPoints(1250, 50),
+			Withdrawn:   entity.NewPoints(300, 0),
+			AccrualJobs: map[entity.AccrualJobState]int64{entity.AccrualJobPending: 4},
+		}})
+
+		expected := `
+# HELP gophermart_accrual_jobs Unfinished accrual jobs by state.
+# TYPE gophermart_accrual_jobs gauge
+gophermart_accrual_jobs{state="FAILED"} 0
+gophermart_accrual_jobs{state="PENDING"} 4
+gophermart_accrual_jobs{state="RUNNING"} 0
+# HELP gophermart_loyalty_outstanding_points Points on user balances that can still be spent.
+# TYPE gophermart_loyalty_outstanding_points gauge
+gophermart_loyalty_outstanding_points 1250.5
+# HELP gophermart_loyalty_withdrawn_points Points withdrawn by users in total.
+# TYPE gophermart_loyalty_withdrawn_points gauge
+gophermart_loyalty_withdrawn_points 300
+`
+		assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+	})
+
+	t.Run("database error is reported", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(NewLoyaltyCollector(stubStats{err: errors.New("database error")}))
+
+		_, err := reg.Gather()
+		assert.ErrorContains(t, err, "database error")
+	})
+}
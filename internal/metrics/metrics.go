@@ -0,0 +1,33 @@
+// Package metrics отдает метрики сервиса в формате Prometheus.
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace общий префикс метрик сервиса
+const Namespace = "gophermart"
+
+// NewRegistry создает реестр с метриками рантайма Go и процесса.
+// Отдельный реестр вместо глобального не дает тестам и пакетам
+// регистрировать метрики повторно.
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return reg
+}
+
+// Handler отдает метрики реестра. Ошибка одного сборщика, например недоступная
+// база, не ломает ответ: остальные метрики отдаются как есть.
+func Handler(reg *prometheus.Registry) gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(reg, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+		Registry:      reg,
+	}))
+}
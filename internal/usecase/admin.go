@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-loyalty-system/internal/audit"
+	"go-loyalty-system/internal/entity"
+	"strings"
+)
+
+const (
+	adminSearchLimit = 50
+	adminAuditLimit  = 100
+)
+
+// SearchUsers ищет пользователей по логину или email
+func (uc *UserUseCase) SearchUsers(ctx context.Context, actorID uint, query string) ([]entity.User, error) {
+	query = strings.TrimSpace(query)
+	users, err := uc.user.SearchUsers(ctx, query, adminSearchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - SearchUsers: %w", err)
+	}
+	if err := uc.auditAdmin(ctx, actorID, entity.AdminSearchUsers, nil, map[string]any{"query": query}); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ViewUserOrders заказы пользователя для сотрудника поддержки
+func (uc *UserUseCase) ViewUserOrders(ctx context.Context,
+	actorID, userID uint,
+	f entity.ListFilter) (*entity.OrderPage, error) {
+	orders, err := uc.GetUserOrders(ctx, userID, f)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.auditAdmin(ctx, actorID, entity.AdminViewOrders, &userID, nil); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ViewUserWithdrawals списания пользователя для сотрудника поддержки
+func (uc *UserUseCase) ViewUserWithdrawals(ctx context.Context,
+	actorID, userID uint,
+	f entity.ListFilter) (*entity.WithdrawalPage, error) {
+	withdrawals, err := uc.GetUserWithdrawals(ctx, userID, f)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.auditAdmin(ctx, actorID, entity.AdminViewWithdrawals, &userID, nil); err != nil {
+		return nil, err
+	}
+	return withdrawals, nil
+}
+
+// ViewBalanceHistory баланс пользователя вместе с журналом проводок
+func (uc *UserUseCase) ViewBalanceHistory(ctx context.Context, actorID, userID uint) (*entity.BalanceHistory, error) {
+	balance, err := uc.balance.GetBalance(ctx, fmt.Sprint(userID))
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - ViewBalanceHistory: %w", err)
+	}
+	entries, err := uc.GetLedgerEntries(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.auditAdmin(ctx, actorID, entity.AdminViewBalance, &userID, nil); err != nil {
+		return nil, err
+	}
+	return &entity.BalanceHistory{Balance: *balance, Entries: entries}, nil
+}
+
+// AdminAdjustBalance ручная корректировка баланса сотрудником. Проводка и запись
+// в журнал действий сохраняются одной транзакцией; списание не уводит баланс в минус.
+func (uc *UserUseCase) AdminAdjustBalance(ctx context.Context, actorID, userID uint, adj entity.BalanceAdjustment) error {
+	adj.Reason = strings.TrimSpace(adj.Reason)
+	if adj.Reason == "" {
+		return entity.ErrReasonRequired
+	}
+	if adj.Amount.IsZero() {
+		return entity.ErrInvalidAdjustment
+	}
+	return uc.withinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := uc.user.GetUserByID(ctx, userID); err != nil {
+			return fmt.Errorf("GopherMartUseCase - AdminAdjustBalance: %w", err)
+		}
+		if adj.Amount.IsNegative() {
+			if err := uc.expireBeforeDebit(ctx, userID); err != nil {
+				return err
+			}
+		}
+		balance, err := uc.balance.GetBalanceForUpdate(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("GopherMartUseCase - AdminAdjustBalance: %w", err)
+		}
+		if balance.Current.Add(adj.Amount).IsNegative() {
+			return entity.ErrInsufficientFunds
+		}
+		if err := uc.AdjustUserBalance(ctx, userID, adj.Amount, adj.Reason); err != nil {
+			return err
+		}
+		after := balance.Current.Add(adj.Amount)
+		if err := uc.auditAdmin(ctx, actorID, entity.AdminAdjustBalance, &userID, map[string]any{
+			"amount": adj.Amount,
+			"reason": adj.Reason,
+			"before": balance.Current,
+			"after":  after,
+		}); err != nil {
+			return err
+		}
+		return uc.recordAudit(ctx, audit.Event{
+			Type:    audit.BalanceAdjusted,
+			ActorID: &actorID,
+			UserID:  &userID,
+			Amount:  &adj.Amount,
+			Before:  &balance.Current,
+			After:   &after,
+			Details: map[string]string{"reason": adj.Reason},
+		})
+	})
+}
+
+// LockUser блокирует учетную запись и завершает все ее сеансы
+func (uc *UserUseCase) LockUser(ctx context.Context, actorID, userID uint, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return entity.ErrReasonRequired
+	}
+	if actorID == userID {
+		return fmt.Errorf("GopherMartUseCase - LockUser: cannot lock own account: %w", entity.ErrInvalidUser)
+	}
+	return uc.withinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.user.SetUserLocked(ctx, userID, true); err != nil {
+			return fmt.Errorf("GopherMartUseCase - LockUser: %w", err)
+		}
+		if err := uc.RevokeUserTokens(ctx, userID); err != nil {
+			return err
+		}
+		return uc.auditAdmin(ctx, actorID, entity.AdminLockUser, &userID, map[string]any{"reason": reason})
+	})
+}
+
+// UnlockUser снимает блокировку учетной записи
+func (uc *UserUseCase) UnlockUser(ctx context.Context, actorID, userID uint, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return entity.ErrReasonRequired
+	}
+	return uc.withinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.user.SetUserLocked(ctx, userID, false); err != nil {
+			return fmt.Errorf("GopherMartUseCase - UnlockUser: %w", err)
+		}
+		return uc.auditAdmin(ctx, actorID, entity.AdminUnlockUser, &userID, map[string]any{"reason": reason})
+	})
+}
+
+// ForceLogout отзывает все токены пользователя
+func (uc *UserUseCase) ForceLogout(ctx context.Context, actorID, userID uint) error {
+	return uc.withinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := uc.user.GetUserByID(ctx, userID); err != nil {
+			return fmt.Errorf("GopherMartUseCase - ForceLogout: %w", err)
+		}
+		if err := uc.RevokeUserTokens(ctx, userID); err != nil {
+			return err
+		}
+		return uc.auditAdmin(ctx, actorID, entity.AdminForceLogout, &userID, nil)
+	})
+}
+
+// GetAdminAudit последние действия сотрудников; userID = 0 — по всем пользователям
+func (uc *UserUseCase) GetAdminAudit(ctx context.Context, actorID, userID uint) ([]entity.AdminAuditEntry, error) {
+	if uc.adminAudit == nil {
+		return []entity.AdminAuditEntry{}, nil
+	}
+	entries, err := uc.adminAudit.GetAdminAudit(ctx, userID, adminAuditLimit)
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - GetAdminAudit: %w", err)
+	}
+	var target *uint
+	if userID != 0 {
+		target = &userID
+	}
+	if err := uc.auditAdmin(ctx, actorID, entity.AdminViewAuditEntries, target, nil); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// auditAdmin записывает действие сотрудника. Без журнала данные не отдаются
+// и изменения не сохраняются, поэтому ошибка записи возвращается вызывающему.
+func (uc *UserUseCase) auditAdmin(ctx context.Context,
+	actorID uint,
+	action entity.AdminAction,
+	target *uint,
+	details map[string]any) error {
+	if uc.adminAudit == nil {
+		return nil
+	}
+	e := entity.AdminAuditEntry{ActorID: actorID, Action: action, TargetUserID: target}
+	if len(details) > 0 {
+		raw, err := json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("GopherMartUseCase - auditAdmin: %w", err)
+		}
+		e.Details = string(raw)
+	}
+	if err := uc.adminAudit.CreateAdminAudit(ctx, e); err != nil {
+		return fmt.Errorf("GopherMartUseCase - auditAdmin: %w", err)
+	}
+	return nil
+}
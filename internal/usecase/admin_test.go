@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type adminMocks struct {
+	balance *mocks.MockBalanceUseCase
+	order   *mocks.MockOrderUseCase
+	user    *mocks.MockAuthUseCase
+	tokens  *mocks.MockTokenRepository
+	audit   *mocks.MockAdminAuditRepository
+}
+
+func setupAdminUseCase(t *testing.T) (*UserUseCase, adminMocks) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	m := adminMocks{
+		balance: mocks.NewMockBalanceUseCase(ctrl),
+		order:   mocks.NewMockOrderUseCase(ctrl),
+		user:    mocks.NewMockAuthUseCase(ctrl),
+		tokens:  mocks.NewMockTokenRepository(ctrl),
+		audit:   mocks.NewMockAdminAuditRepository(ctrl),
+	}
+	uc := NewGopherMart(mocks.NewMockRepository(ctrl), m.balance, m.order, m.user, log,
+		WithTokens(m.tokens), WithAdminAudit(m.audit))
+	return uc, m
+}
+
+func TestAdminAdjustBalance(t *testing.T) {
+	ctx := context.Background()
+	const actorID, userID = uint(1), uint(7)
+
+	t.Run("adjustment is posted and audited", func(t *testing.T) {
+		uc, m := setupAdminUseCase(t)
+		adj := entity.BalanceAdjustment{Amount: entity.NewPoints(-30, 0), Reason: " duplicate accrual "}
+		gomock.InOrder(
+			m.user.EXPECT().GetUserByID(ctx, userID).Return(&entity.User{ID: userID}, nil),
+			m.balance.EXPECT().GetBalanceForUpdate(ctx, userID).
+				Return(&entity.Balance{Current: entity.NewPoints(100, 0)}, nil),
+			m.balance.EXPECT().AdjustBalance(ctx, userID, adj.Amount, "duplicate accrual").Return(nil),
+			m.audit.EXPECT().CreateAdminAudit(ctx, gomock.Any()).DoAndReturn(
+				func(_ context.Context, e entity.AdminAuditEntry) error {
+					assert.Equal(t, actorID, e.ActorID)
+					assert.Equal(t, entity.AdminAdjustBalance, e.Action)
+					require.NotNil(t, e.TargetUserID)
+					assert.Equal(t, userID, *e.TargetUserID)
+					assert.JSONEq(t, `{"amount":-30,"reason":"duplicate accrual","before":100,"after":70}`, e.Details)
+					return nil
+				}),
+		)
+
+		require.NoError(t, uc.AdminAdjustBalance(ctx, actorID, userID, adj))
+	})
+
+	t.Run("reason is mandatory", func(t *testing.T) {
+		uc, _ := setupAdminUseCase(t)
+		adj := entity.BalanceAdjustment{Amount: entity.NewPoints(10, 0), Reason: "   "}
+
+		assert.ErrorIs(t, uc.AdminAdjustBalance(ctx, actorID, userID, adj), entity.ErrReasonRequired)
+	})
+
+	t.Run("debit cannot exceed the balance", func(t *testing.T) {
+		uc, m := setupAdminUseCase(t)
+		adj := entity.BalanceAdjustment{Amount: entity.NewPoints(-150, 0), Reason: "fraud"}
+		m.user.EXPECT().GetUserByID(ctx, userID).Return(&entity.User{ID: userID}, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, userID).
+			Return(&entity.Balance{Current: entity.NewPoints(100, 0)}, nil)
+
+		assert.ErrorIs(t, uc.AdminAdjustBalance(ctx, actorID, userID, adj), entity.ErrInsufficientFunds)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		uc, m := setupAdminUseCase(t)
+		adj := entity.BalanceAdjustment{Amount: entity.NewPoints(10, 0), Reason: "goodwill"}
+		m.user.EXPECT().GetUserByID(ctx, userID).Return(nil, entity.ErrUserDoesNotExist)
+
+		assert.ErrorIs(t, uc.AdminAdjustBalance(ctx, actorID, userID, adj), entity.ErrUserDoesNotExist)
+	})
+}
+
+func TestLockUser(t *testing.T) {
+	ctx := context.Background()
+	const actorID, userID = uint(1), uint(7)
+
+	t.Run("lock revokes sessions and is audited", func(t *testing.T) {
+		uc, m := setupAdminUseCase(t)
+		gomock.InOrder(
+			m.user.EXPECT().SetUserLocked(ctx, userID, true).Return(nil),
+			m.tokens.EXPECT().RevokeUserTokens(ctx, userID).Return(nil),
+			m.audit.EXPECT().CreateAdminAudit(ctx, gomock.Any()).DoAndReturn(
+				func(_ context.Context, e entity.AdminAuditEntry) error {
+					assert.Equal(t, entity.AdminLockUser, e.Action)
+					assert.JSONEq(t, `{"reason":"chargeback"}`, e.Details)
+					return nil
+				}),
+		)
+
+		require.NoError(t, uc.LockUser(ctx, actorID, userID, "chargeback"))
+	})
+
+	t.Run("own account cannot be locked", func(t *testing.T) {
+		uc, _ := setupAdminUseCase(t)
+
+		assert.ErrorIs(t, uc.LockUser(ctx, actorID, actorID, "oops"), entity.ErrInvalidUser)
+	})
+
+	t.Run("unlock is audited", func(t *testing.T) {
+		uc, m := setupAdminUseCase(t)
+		m.user.EXPECT().SetUserLocked(ctx, userID, false).Return(nil)
+		m.audit.EXPECT().CreateAdminAudit(ctx, gomock.Any()).Return(nil)
+
+		require.NoError(t, uc.UnlockUser(ctx, actorID, userID, "resolved"))
+	})
+
+	t.Run("locked user cannot log in", func(t *testing.T) {
+		uc, m := setupAdminUseCase(t)
+		hash, err := uc.hashPassword("password123")
+		require.NoError(t, err)
+		now := time.Now()
+		m.user.EXPECT().GetUserByLogin(ctx, entity.User{Login: "user"}).
+			Return(&entity.User{ID: userID, Login: "user", Password: hash, LockedAt: &now}, nil)
+
+		_, err = uc.AuthenticateUser(ctx, "user", "password123")
+		assert.ErrorIs(t, err, entity.ErrUserLocked)
+	})
+}
+
+func TestAdminViews(t *testing.T) {
+	ctx := context.Background()
+	const actorID, userID = uint(1), uint(7)
+
+	t.Run("viewing orders is audited", func(t *testing.T) {
+		uc, m := setupAdminUseCase(t)
+		orders := []entity.OrderResponse{{Number: "12345678903"}}
+		m.order.EXPECT().GetUserOrders(ctx, userID, gomock.Any()).Return(orders, nil)
+		m.audit.EXPECT().CreateAdminAudit(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, e entity.AdminAuditEntry) error {
+				assert.Equal(t, entity.AdminViewOrders, e.Action)
+				return nil
+			})
+
+		got, err := uc.ViewUserOrders(ctx, actorID, userID, entity.ListFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, orders, got.Orders)
+	})
+
+	t.Run("data is not returned when audit fails", func(t *testing.T) {
+		uc, m := setupAdminUseCase(t)
+		m.user.EXPECT().SearchUsers(ctx, "alice", adminSearchLimit).Return([]entity.User{{Login: "alice"}}, nil)
+		m.audit.EXPECT().CreateAdminAudit(ctx, gomock.Any()).Return(errors.New("database error"))
+
+		users, err := uc.SearchUsers(ctx, actorID, " alice ")
+		assert.Error(t, err)
+		assert.Nil(t, users)
+	})
+
+	t.Run("force logout revokes all tokens", func(t *testing.T) {
+		uc, m := setupAdminUseCase(t)
+		m.user.EXPECT().GetUserByID(ctx, userID).Return(&entity.User{ID: userID}, nil)
+		m.tokens.EXPECT().RevokeUserTokens(ctx, userID).Return(nil)
+		m.audit.EXPECT().CreateAdminAudit(ctx, gomock.Any()).Return(nil)
+
+		require.NoError(t, uc.ForceLogout(ctx, actorID, userID))
+	})
+}
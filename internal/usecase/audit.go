@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/audit"
+	"go-loyalty-system/internal/entity"
+
+	"go.uber.org/zap"
+)
+
+// Причины неудачного входа в журнале
+const (
+	loginUnknownUser     = "unknown_login"
+	loginInvalidPassword = "invalid_password"
+	loginResetRequired   = "password_reset_required"
+	loginLocked          = "locked"
+)
+
+// recordAudit пишет событие движения баллов. Вызывается внутри транзакции
+// операции: без записи в журнале операция откатывается.
+func (uc *UserUseCase) recordAudit(ctx context.Context, e audit.Event) error {
+	if uc.auditLog == nil {
+		return nil
+	}
+	if err := uc.auditLog.Record(ctx, e); err != nil {
+		return fmt.Errorf("GopherMartUseCase - recordAudit: %w", err)
+	}
+	return nil
+}
+
+// recordSecurityAudit пишет событие входа и выдачи токенов. Сбой журнала
+// не мешает пользователю войти, но попадает в лог.
+func (uc *UserUseCase) recordSecurityAudit(ctx context.Context, e audit.Event) {
+	if err := uc.recordAudit(ctx, e); err != nil {
+		uc.Logger.ErrorCtx(ctx, "audit event lost", zap.String("type", string(e.Type)), zap.Error(err))
+	}
+}
+
+func (uc *UserUseCase) auditLoginFailed(ctx context.Context, login string, userID *uint, reason string) {
+	uc.recordSecurityAudit(ctx, audit.Event{
+		Type:    audit.LoginFailed,
+		UserID:  userID,
+		Details: map[string]string{"login": login, "reason": reason},
+	})
+}
+
+// GetAuditEvents страница журнала событий для сотрудника. Просмотр журнала
+// сам записывается в журнал действий сотрудников.
+func (uc *UserUseCase) GetAuditEvents(ctx context.Context, actorID uint, f audit.Filter) (*audit.Page, error) {
+	if uc.auditLog == nil {
+		return nil, entity.ErrAuditUnavailable
+	}
+	f, err := f.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	events, err := uc.auditLog.Events(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - GetAuditEvents: %w", err)
+	}
+	var target *uint
+	if f.UserID != 0 {
+		target = &f.UserID
+	}
+	if err := uc.auditAdmin(ctx, actorID, entity.AdminViewAuditEvents, target, nil); err != nil {
+		return nil, err
+	}
+
+	page := &audit.Page{}
+	page.Events, page.NextCursor = trimPage(events, f.Limit, func(e audit.Event) entity.PageCursor {
+		return entity.PageCursor{At: e.CreatedAt, ID: e.ID}
+	})
+	return page, nil
+}
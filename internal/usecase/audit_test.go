@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"go-loyalty-system/internal/audit"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type auditMocks struct {
+	accrual *mocks.MockRepository
+	balance *mocks.MockBalanceUseCase
+	order   *mocks.MockOrderUseCase
+	user    *mocks.MockAuthUseCase
+	store   *mocks.MockAuditRepository
+	admin   *mocks.MockAdminAuditRepository
+}
+
+func setupAuditUseCase(t *testing.T) (*UserUseCase, auditMocks) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	m := auditMocks{
+		accrual: mocks.NewMockRepository(ctrl),
+		balance: mocks.NewMockBalanceUseCase(ctrl),
+		order:   mocks.NewMockOrderUseCase(ctrl),
+		user:    mocks.NewMockAuthUseCase(ctrl),
+		store:   mocks.NewMockAuditRepository(ctrl),
+		admin:   mocks.NewMockAdminAuditRepository(ctrl),
+	}
+	tx := mocks.NewMockTransactor(ctrl)
+	tx.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	uc := NewGopherMart(m.accrual, m.balance, m.order, m.user, log,
+		WithTransactor(tx), WithAdminAudit(m.admin), WithAudit(audit.NewRecorder(m.store, nil, log)))
+	return uc, m
+}
+
+func TestAuditMoneyEvents(t *testing.T) {
+	ctx := audit.WithSource(context.Background(), audit.Source{IP: "203.0.113.7", RequestID: "req-1"})
+
+	t.Run("withdrawal records balance before and after", func(t *testing.T) {
+		uc, m := setupAuditUseCase(t)
+		withdrawal := entity.Withdrawal{UserID: 7, OrderNumber: "2377225624", Amount: entity.NewPoints(100, 0)}
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(150, 0)}, nil)
+		m.order.EXPECT().SetOrders(ctx, uint(7), gomock.Any()).Return(nil)
+		m.order.EXPECT().GetOrderByNumber(ctx, withdrawal.OrderNumber).Return(&entity.OrderResponse{ID: 3}, nil)
+		m.balance.EXPECT().CreateWithdrawalTx(ctx, withdrawal, gomock.Any()).Return(nil)
+		m.balance.EXPECT().UpdateBalanceTx(ctx, uint(7), withdrawal.Amount).Return(nil)
+		m.store.EXPECT().CreateAuditEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, e *audit.Event) error {
+			assert.Equal(t, audit.WithdrawalCreated, e.Type)
+			assert.Equal(t, uint(7), *e.UserID)
+			assert.Equal(t, "203.0.113.7", e.IP)
+			assert.Equal(t, "req-1", e.RequestID)
+			assert.Equal(t, entity.NewPoints(150, 0), *e.Before)
+			assert.Equal(t, entity.NewPoints(50, 0), *e.After)
+			return nil
+		})
+
+		require.NoError(t, uc.WithdrawBalance(ctx, withdrawal))
+	})
+
+	t.Run("withdrawal fails when the event cannot be stored", func(t *testing.T) {
+		uc, m := setupAuditUseCase(t)
+		withdrawal := entity.Withdrawal{UserID: 7, OrderNumber: "2377225624", Amount: entity.NewPoints(100, 0)}
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(150, 0)}, nil)
+		m.order.EXPECT().SetOrders(ctx, uint(7), gomock.Any()).Return(nil)
+		m.order.EXPECT().GetOrderByNumber(ctx, withdrawal.OrderNumber).Return(&entity.OrderResponse{ID: 3}, nil)
+		m.balance.EXPECT().CreateWithdrawalTx(ctx, withdrawal, gomock.Any()).Return(nil)
+		m.balance.EXPECT().UpdateBalanceTx(ctx, uint(7), withdrawal.Amount).Return(nil)
+		m.store.EXPECT().CreateAuditEvent(ctx, gomock.Any()).Return(errors.New("database error"))
+
+		assert.Error(t, uc.WithdrawBalance(ctx, withdrawal))
+	})
+
+	t.Run("accrual credit records the owner's balance", func(t *testing.T) {
+		uc, m := setupAuditUseCase(t)
+		accrual := entity.NewPoints(500, 0)
+		m.accrual.EXPECT().ExistOrderAccrual(gomock.Any(), "12345678903").Return(false, nil)
+		m.order.EXPECT().CheckOrderExistence(gomock.Any(), "12345678903", uint(0)).Return(true, uint(7), nil)
+		m.balance.EXPECT().GetBalanceForUpdate(gomock.Any(), uint(7)).Return(&entity.Balance{Current: entity.NewPoints(20, 0)}, nil)
+		m.accrual.EXPECT().SaveAccrual(gomock.Any(), "12345678903", entity.AccrualStatusProcessed, accrual).Return(nil)
+		m.store.EXPECT().CreateAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *audit.Event) error {
+			assert.Equal(t, audit.AccrualCredited, e.Type)
+			assert.Nil(t, e.ActorID, "accruals are credited by the system")
+			assert.Equal(t, "12345678903", e.OrderNumber)
+			assert.Equal(t, entity.NewPoints(520, 0), *e.After)
+			return nil
+		})
+
+		require.NoError(t, uc.SaveAccrual(ctx, "12345678903", entity.AccrualStatusProcessed, accrual))
+	})
+
+	t.Run("invalid order is not audited", func(t *testing.T) {
+		uc, m := setupAuditUseCase(t)
+		m.accrual.EXPECT().ExistOrderAccrual(gomock.Any(), "12345678903").Return(false, nil)
+		m.accrual.EXPECT().SaveAccrual(gomock.Any(), "12345678903", entity.AccrualStatusInvalid, entity.Points(0)).Return(nil)
+
+		require.NoError(t, uc.SaveAccrual(ctx, "12345678903", entity.AccrualStatusInvalid, 0))
+	})
+
+	t.Run("admin adjustment records actor and reason", func(t *testing.T) {
+		uc, m := setupAuditUseCase(t)
+		adj := entity.BalanceAdjustment{Amount: entity.NewPoints(-30, 0), Reason: "duplicate accrual"}
+		m.user.EXPECT().GetUserByID(ctx, uint(7)).Return(&entity.User{ID: 7}, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(100, 0)}, nil)
+		m.balance.EXPECT().AdjustBalance(ctx, uint(7), adj.Amount, adj.Reason).Return(nil)
+		m.admin.EXPECT().CreateAdminAudit(ctx, gomock.Any()).Return(nil)
+		m.store.EXPECT().CreateAuditEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, e *audit.Event) error {
+			assert.Equal(t, audit.BalanceAdjusted, e.Type)
+			assert.Equal(t, uint(1), *e.ActorID)
+			assert.Equal(t, uint(7), *e.UserID)
+			assert.Equal(t, entity.NewPoints(70, 0), *e.After)
+			assert.Equal(t, "duplicate accrual", e.Details["reason"])
+			return nil
+		})
+
+		require.NoError(t, uc.AdminAdjustBalance(ctx, 1, 7, adj))
+	})
+}
+
+func TestAuditLogins(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unknown login", func(t *testing.T) {
+		uc, m := setupAuditUseCase(t)
+		m.user.EXPECT().GetUserByLogin(ctx, entity.User{Login: "ghost"}).Return(nil, entity.ErrUserDoesNotExist)
+		m.store.EXPECT().CreateAuditEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, e *audit.Event) error {
+			assert.Equal(t, audit.LoginFailed, e.Type)
+			assert.Nil(t, e.UserID)
+			assert.Equal(t, map[string]string{"login": "ghost", "reason": loginUnknownUser}, e.Details)
+			return nil
+		})
+
+		_, err := uc.AuthenticateUser(ctx, "ghost", "password123")
+		assert.ErrorIs(t, err, entity.ErrInvalidCredentials)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		uc, m := setupAuditUseCase(t)
+		hash, err := uc.hashPassword("password123")
+		require.NoError(t, err)
+		m.user.EXPECT().GetUserByLogin(ctx, entity.User{Login: "user"}).
+			Return(&entity.User{ID: 7, Login: "user", Password: hash}, nil)
+		m.store.EXPECT().CreateAuditEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, e *audit.Event) error {
+			assert.Equal(t, uint(7), *e.UserID)
+			assert.Equal(t, loginInvalidPassword, e.Details["reason"])
+			return nil
+		})
+
+		_, err = uc.AuthenticateUser(ctx, "user", "wrong-password")
+		assert.ErrorIs(t, err, entity.ErrInvalidCredentials)
+	})
+
+	t.Run("success survives an audit outage", func(t *testing.T) {
+		uc, m := setupAuditUseCase(t)
+		hash, err := uc.hashPassword("password123")
+		require.NoError(t, err)
+		m.user.EXPECT().GetUserByLogin(ctx, entity.User{Login: "user"}).
+			Return(&entity.User{ID: 7, Login: "user", Password: hash}, nil)
+		m.store.EXPECT().CreateAuditEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, e *audit.Event) error {
+			assert.Equal(t, audit.LoginSucceeded, e.Type)
+			return errors.New("database error")
+		})
+
+		user, err := uc.AuthenticateUser(ctx, "user", "password123")
+		require.NoError(t, err)
+		assert.Equal(t, uint(7), user.ID)
+	})
+}
+
+func TestAuditMirror(t *testing.T) {
+	ctx := context.Background()
+	const orderNumber = "12345678903"
+
+	t.Run("rolled back accrual credit is not mirrored", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		log, _ := logging.NewZapLogger(1)
+		accrualRepo := mocks.NewMockRepository(ctrl)
+		balanceRepo := mocks.NewMockBalanceUseCase(ctrl)
+		orderRepo := mocks.NewMockOrderUseCase(ctrl)
+		tiers := mocks.NewMockTierRepository(ctrl)
+		store := mocks.NewMockAuditRepository(ctrl)
+		tx := mocks.NewMockTransactor(ctrl)
+		var mirror bytes.Buffer
+		uc := NewGopherMart(accrualRepo, balanceRepo, orderRepo, mocks.NewMockAuthUseCase(ctrl), log,
+			WithTransactor(tx), WithAudit(audit.NewRecorder(store, &mirror, log)),
+			WithTiers(tiers, entity.TierPolicy{Tiers: []entity.Tier{
+				{Name: "SILVER", Threshold: entity.NewPoints(1000, 0), Multiplier: 1.1},
+			}}))
+		expectedErr := errors.New("database error")
+
+		tx.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+		accrualRepo.EXPECT().ExistOrderAccrual(gomock.Any(), orderNumber).Return(false, nil)
+		orderRepo.EXPECT().CheckOrderExistence(gomock.Any(), orderNumber, uint(0)).Return(true, uint(7), nil)
+		balanceRepo.EXPECT().GetBalanceForUpdate(gomock.Any(), uint(7)).Return(&entity.Balance{}, nil)
+		accrualRepo.EXPECT().SaveAccrual(gomock.Any(), orderNumber, entity.AccrualStatusProcessed, gomock.Any()).Return(nil)
+		store.EXPECT().CreateAuditEvent(gomock.Any(), gomock.Any()).Return(nil)
+		tiers.EXPECT().GetRollingAccrual(gomock.Any(), uint(7), gomock.Any()).Return(entity.Points(0), expectedErr)
+
+		err := uc.SaveAccrual(ctx, orderNumber, entity.AccrualStatusProcessed, entity.NewPoints(200, 0))
+		assert.ErrorIs(t, err, expectedErr)
+		assert.Zero(t, mirror.Len(), "ACCRUAL_CREDITED was rolled back with the transaction")
+	})
+}
+
+func TestGetAuditEvents(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled audit log", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		log, _ := logging.NewZapLogger(1)
+		uc := NewGopherMart(mocks.NewMockRepository(ctrl), mocks.NewMockBalanceUseCase(ctrl),
+			mocks.NewMockOrderUseCase(ctrl), mocks.NewMockAuthUseCase(ctrl), log)
+
+		_, err := uc.GetAuditEvents(ctx, 1, audit.Filter{})
+		assert.ErrorIs(t, err, entity.ErrAuditUnavailable)
+	})
+
+	t.Run("page is trimmed and the view is audited", func(t *testing.T) {
+		uc, m := setupAuditUseCase(t)
+		now := time.Now()
+		events := []audit.Event{
+			{ID: 3, Type: audit.LoginFailed, CreatedAt: now},
+			{ID: 2, Type: audit.LoginFailed, CreatedAt: now.Add(-time.Second)},
+			{ID: 1, Type: audit.LoginFailed, CreatedAt: now.Add(-2 * time.Second)},
+		}
+		m.store.EXPECT().GetAuditEvents(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, f audit.Filter) ([]audit.Event, error) {
+			assert.Equal(t, 2, f.Limit)
+			assert.Equal(t, uint(7), f.UserID)
+			return events, nil
+		})
+		m.admin.EXPECT().CreateAdminAudit(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, e entity.AdminAuditEntry) error {
+			assert.Equal(t, entity.AdminViewAuditEvents, e.Action)
+			assert.Equal(t, uint(7), *e.TargetUserID)
+			return nil
+		})
+
+		f := audit.Filter{Types: []audit.Type{audit.LoginFailed}, UserID: 7}
+		f.Limit = 2
+		page, err := uc.GetAuditEvents(ctx, 1, f)
+		require.NoError(t, err)
+		assert.Len(t, page.Events, 2)
+		assert.Equal(t, entity.PageCursor{At: events[1].CreatedAt, ID: 2}.Encode(), page.NextCursor)
+	})
+
+	t.Run("unknown event type", func(t *testing.T) {
+		uc, _ := setupAuditUseCase(t)
+
+		_, err := uc.GetAuditEvents(ctx, 1, audit.Filter{Types: []audit.Type{"DELETED"}})
+		assert.ErrorIs(t, err, entity.ErrInvalidListFilter)
+	})
+}
+
+func TestAuditReversal(t *testing.T) {
+	ctx := context.Background()
+	uc, m := setupAuditUseCase(t)
+	w := &entity.Withdrawal{ID: 3, UserID: 7, OrderNumber: "2377225624", Amount: entity.NewPoints(100, 0)}
+	m.balance.EXPECT().GetWithdrawalForUpdate(ctx, w.OrderNumber).Return(w, nil)
+	m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(5, 0)}, nil)
+	m.balance.EXPECT().ReverseWithdrawal(ctx, *w, entity.NewPoints(100, 0), "order cancelled").Return(nil)
+	m.admin.EXPECT().CreateAdminAudit(ctx, gomock.Any()).Return(nil)
+	m.store.EXPECT().CreateAuditEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, e *audit.Event) error {
+		assert.Equal(t, audit.WithdrawalReversed, e.Type)
+		assert.Equal(t, uint(1), *e.ActorID)
+		assert.Equal(t, entity.NewPoints(105, 0), *e.After)
+		assert.Equal(t, string(entity.WithdrawalReversed), e.Details["status"])
+		return nil
+	})
+
+	_, err := uc.AdminReverseWithdrawal(ctx, 1, entity.WithdrawalReversal{Order: w.OrderNumber, Reason: "order cancelled"})
+	require.NoError(t, err)
+}
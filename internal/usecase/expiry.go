@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/audit"
+	"go-loyalty-system/internal/entity"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// expiryBatch сколько пользователей со сгоревшими баллами выбирается за раз
+const expiryBatch = 100
+
+// RunPointExpiry списывает сгоревшие баллы раз в interval, пока не отменен ctx.
+// При выключенной политике ничего не делает.
+func (uc *UserUseCase) RunPointExpiry(ctx context.Context, interval time.Duration) {
+	if uc.lots == nil || !uc.expiry.Enabled() {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := uc.ExpirePoints(ctx); err != nil {
+			uc.Logger.ErrorCtx(ctx, "RunPointExpiry - expire points", zap.Error(err))
+		} else if n > 0 {
+			uc.Logger.InfoCtx(ctx, "points expired", zap.Int("users", n))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpirePoints списывает сгоревшие партии всех пользователей и возвращает,
+// у скольких пользователей что-то сгорело. Каждый пользователь обрабатывается
+// своей транзакцией; на первой ошибке проход прерывается до следующего запуска.
+func (uc *UserUseCase) ExpirePoints(ctx context.Context) (int, error) {
+	if uc.lots == nil || !uc.expiry.Enabled() {
+		return 0, nil
+	}
+	cutoff := uc.expiry.Cutoff(time.Now())
+	expired := 0
+	for {
+		users, err := uc.lots.GetUsersWithExpiredLots(ctx, cutoff, expiryBatch)
+		if err != nil {
+			return expired, fmt.Errorf("GopherMartUseCase - ExpirePoints: %w", err)
+		}
+		for _, userID := range users {
+			if err := ctx.Err(); err != nil {
+				return expired, err
+			}
+			var amount entity.Points
+			err := uc.withinTransaction(ctx, func(ctx context.Context) (err error) {
+				amount, err = uc.expireUserPoints(ctx, userID, cutoff)
+				return err
+			})
+			if err != nil {
+				return expired, err
+			}
+			// другой экземпляр мог успеть списать эти партии раньше
+			if amount.IsPositive() {
+				expired++
+			}
+		}
+		if len(users) < expiryBatch {
+			return expired, nil
+		}
+	}
+}
+
+// expireUserPoints списывает сгоревшие партии пользователя в текущей транзакции
+// и возвращает сгоревшую сумму
+func (uc *UserUseCase) expireUserPoints(ctx context.Context, userID uint, cutoff time.Time) (entity.Points, error) {
+	balance, err := uc.balance.GetBalanceForUpdate(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("GopherMartUseCase - expireUserPoints: %w", err)
+	}
+	amount, err := uc.lots.ExpireUserLots(ctx, userID, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("GopherMartUseCase - expireUserPoints: %w", err)
+	}
+	if amount.IsZero() {
+		return 0, nil
+	}
+	return amount, uc.recordAudit(ctx, audit.Event{
+		Type:    audit.PointsExpired,
+		UserID:  &userID,
+		Amount:  &amount,
+		Before:  &balance.Current,
+		After:   audit.Ptr(balance.Current.Sub(amount)),
+		Details: map[string]string{"cutoff": cutoff.UTC().Format(time.RFC3339)},
+	})
+}
+
+// creditLot заводит партию на начисленные баллы со сроком по текущей политике
+func (uc *UserUseCase) creditLot(ctx context.Context, userID uint, orderNumber string, amount entity.Points) error {
+	if uc.lots == nil {
+		return nil
+	}
+	return uc.restoreLot(ctx, userID, orderNumber, amount, uc.expiry.ExpiresAt(time.Now()))
+}
+
+// restoreLot заводит партию с заданным сроком, например на баллы снятого резерва
+func (uc *UserUseCase) restoreLot(ctx context.Context,
+	userID uint,
+	orderNumber string,
+	amount entity.Points,
+	expiresAt *time.Time) error {
+	if uc.lots == nil || !amount.IsPositive() {
+		return nil
+	}
+	err := uc.lots.CreatePointLot(ctx, entity.PointLot{
+		UserID:      userID,
+		OrderNumber: orderNumber,
+		Amount:      amount,
+		Remaining:   amount,
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("GopherMartUseCase - restoreLot: %w", err)
+	}
+	return nil
+}
+
+// debitLots расходует партии на списанные баллы, начиная с ближайших к сгоранию
+func (uc *UserUseCase) debitLots(ctx context.Context, userID uint, amount entity.Points) error {
+	_, err := uc.consumeLots(ctx, userID, amount)
+	return err
+}
+
+// consumeLots как debitLots, но возвращает израсходованные части партий
+func (uc *UserUseCase) consumeLots(ctx context.Context, userID uint, amount entity.Points) ([]entity.PointLot, error) {
+	if uc.lots == nil || !amount.IsPositive() {
+		return nil, nil
+	}
+	consumed, err := uc.lots.ConsumePointLots(ctx, userID, amount, uc.expiry.Cutoff(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - debitLots: %w", err)
+	}
+	return consumed, nil
+}
+
+// earliestExpiry ближайший срок сгорания среди партий, nil — все они бессрочные
+func earliestExpiry(lots []entity.PointLot) *time.Time {
+	var earliest *time.Time
+	for _, l := range lots {
+		if l.ExpiresAt != nil && (earliest == nil || l.ExpiresAt.Before(*earliest)) {
+			earliest = l.ExpiresAt
+		}
+	}
+	return earliest
+}
+
+// expireBeforeDebit списывает сгоревшие баллы пользователя до проверки остатка,
+// чтобы их нельзя было потратить в промежутке между запусками RunPointExpiry
+func (uc *UserUseCase) expireBeforeDebit(ctx context.Context, userID uint) error {
+	if uc.lots == nil || !uc.expiry.Enabled() {
+		return nil
+	}
+	_, err := uc.expireUserPoints(ctx, userID, uc.expiry.Cutoff(time.Now()))
+	return err
+}
+
+// expiringSoon баллы пользователя, которые сгорят в пределах Notice
+func (uc *UserUseCase) expiringSoon(ctx context.Context, userID uint) (*entity.ExpiringPoints, error) {
+	if uc.lots == nil || !uc.expiry.Enabled() {
+		return nil, nil
+	}
+	now := time.Now()
+	soon := &entity.ExpiringPoints{Until: now.Add(uc.expiry.Notice)}
+	lots, err := uc.lots.GetExpiringPointLots(ctx, userID, uc.expiry.Cutoff(now), soon.Until)
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - expiringSoon: %w", err)
+	}
+	soon.Lots = lots
+	for _, l := range lots {
+		soon.Total = soon.Total.Add(l.Remaining)
+	}
+	return soon, nil
+}
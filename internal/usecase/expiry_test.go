@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type expiryMocks struct {
+	accrual *mocks.MockRepository
+	balance *mocks.MockBalanceUseCase
+	order   *mocks.MockOrderUseCase
+	user    *mocks.MockAuthUseCase
+	lots    *mocks.MockPointLotRepository
+}
+
+func setupExpiryUseCase(t *testing.T, policy entity.ExpiryPolicy) (*UserUseCase, expiryMocks) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	m := expiryMocks{
+		accrual: mocks.NewMockRepository(ctrl),
+		balance: mocks.NewMockBalanceUseCase(ctrl),
+		order:   mocks.NewMockOrderUseCase(ctrl),
+		user:    mocks.NewMockAuthUseCase(ctrl),
+		lots:    mocks.NewMockPointLotRepository(ctrl),
+	}
+	uc := NewGopherMart(m.accrual, m.balance, m.order, m.user, log,
+		WithPointExpiry(m.lots, policy))
+	return uc, m
+}
+
+var yearPolicy = entity.ExpiryPolicy{Months: 12, Grace: 24 * time.Hour, Notice: 30 * 24 * time.Hour}
+
+func TestExpirePoints(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("expired lots are debited per user", func(t *testing.T) {
+		uc, m := setupExpiryUseCase(t, yearPolicy)
+		var cutoff time.Time
+		m.lots.EXPECT().GetUsersWithExpiredLots(ctx, gomock.Any(), expiryBatch).
+			DoAndReturn(func(_ context.Context, c time.Time, _ int) ([]uint, error) {
+				cutoff = c
+				return []uint{7, 8}, nil
+			})
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(50, 0)}, nil)
+		m.lots.EXPECT().ExpireUserLots(ctx, uint(7), gomock.Any()).Return(entity.NewPoints(20, 0), nil)
+		// партии пользователя 8 уже списал другой экземпляр
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(8)).Return(&entity.Balance{}, nil)
+		m.lots.EXPECT().ExpireUserLots(ctx, uint(8), gomock.Any()).Return(entity.Points(0), nil)
+
+		n, err := uc.ExpirePoints(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.WithinDuration(t, time.Now().Add(-yearPolicy.Grace), cutoff, time.Minute, "grace delays expiry")
+	})
+
+	t.Run("error stops the run", func(t *testing.T) {
+		uc, m := setupExpiryUseCase(t, yearPolicy)
+		expectedErr := errors.New("database error")
+		m.lots.EXPECT().GetUsersWithExpiredLots(ctx, gomock.Any(), expiryBatch).Return([]uint{7, 8}, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{}, nil)
+		m.lots.EXPECT().ExpireUserLots(ctx, uint(7), gomock.Any()).Return(entity.Points(0), expectedErr)
+
+		_, err := uc.ExpirePoints(ctx)
+		assert.ErrorIs(t, err, expectedErr)
+	})
+
+	t.Run("disabled policy expires nothing", func(t *testing.T) {
+		uc, _ := setupExpiryUseCase(t, entity.ExpiryPolicy{Months: 12, Disabled: true})
+
+		n, err := uc.ExpirePoints(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
+
+func TestPointLots(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("accrual opens a lot with the policy expiry", func(t *testing.T) {
+		uc, m := setupExpiryUseCase(t, yearPolicy)
+		accrual := entity.NewPoints(500, 0)
+		m.accrual.EXPECT().ExistOrderAccrual(gomock.Any(), "12345678903").Return(false, nil)
+		m.order.EXPECT().CheckOrderExistence(gomock.Any(), "12345678903", uint(0)).Return(true, uint(7), nil)
+		m.balance.EXPECT().GetBalanceForUpdate(gomock.Any(), uint(7)).Return(&entity.Balance{}, nil)
+		m.accrual.EXPECT().SaveAccrual(gomock.Any(), "12345678903", entity.AccrualStatusProcessed, accrual).Return(nil)
+		m.lots.EXPECT().CreatePointLot(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, l entity.PointLot) error {
+			assert.Equal(t, uint(7), l.UserID)
+			assert.Equal(t, "12345678903", l.OrderNumber)
+			assert.Equal(t, accrual, l.Remaining)
+			require.NotNil(t, l.ExpiresAt)
+			assert.WithinDuration(t, time.Now().AddDate(1, 0, 0), *l.ExpiresAt, time.Minute)
+			return nil
+		})
+
+		require.NoError(t, uc.SaveAccrual(ctx, "12345678903", entity.AccrualStatusProcessed, accrual))
+	})
+
+	t.Run("points accrued while expiry is disabled never expire", func(t *testing.T) {
+		uc, m := setupExpiryUseCase(t, entity.ExpiryPolicy{Months: 12, Disabled: true})
+		m.balance.EXPECT().AdjustBalance(ctx, uint(7), entity.NewPoints(10, 0), "goodwill").Return(nil)
+		m.lots.EXPECT().CreatePointLot(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, l entity.PointLot) error {
+			assert.Nil(t, l.ExpiresAt)
+			return nil
+		})
+
+		require.NoError(t, uc.AdjustUserBalance(ctx, 7, entity.NewPoints(10, 0), "goodwill"))
+	})
+
+	t.Run("withdrawal expires overdue points first and consumes lots", func(t *testing.T) {
+		uc, m := setupExpiryUseCase(t, yearPolicy)
+		withdrawal := entity.Withdrawal{UserID: 7, OrderNumber: "2377225624", Amount: entity.NewPoints(100, 0)}
+		order := &entity.OrderResponse{ID: 3, Number: withdrawal.OrderNumber}
+		gomock.InOrder(
+			m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(180, 0)}, nil),
+			m.lots.EXPECT().ExpireUserLots(ctx, uint(7), gomock.Any()).Return(entity.NewPoints(30, 0), nil),
+			m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(150, 0)}, nil),
+			m.order.EXPECT().SetOrders(ctx, uint(7), gomock.Any()).Return(nil),
+			m.order.EXPECT().GetOrderByNumber(ctx, withdrawal.OrderNumber).Return(order, nil),
+			m.balance.EXPECT().CreateWithdrawalTx(ctx, withdrawal, order).Return(nil),
+			m.balance.EXPECT().UpdateBalanceTx(ctx, uint(7), withdrawal.Amount).Return(nil),
+			m.lots.EXPECT().ConsumePointLots(ctx, uint(7), withdrawal.Amount, gomock.Any()).Return(nil, nil),
+		)
+
+		require.NoError(t, uc.WithdrawBalance(ctx, withdrawal))
+	})
+
+	t.Run("expired points cannot be withdrawn", func(t *testing.T) {
+		uc, m := setupExpiryUseCase(t, yearPolicy)
+		withdrawal := entity.Withdrawal{UserID: 7, OrderNumber: "2377225624", Amount: entity.NewPoints(100, 0)}
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(120, 0)}, nil)
+		m.lots.EXPECT().ExpireUserLots(ctx, uint(7), gomock.Any()).Return(entity.NewPoints(30, 0), nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(90, 0)}, nil)
+
+		assert.ErrorIs(t, uc.WithdrawBalance(ctx, withdrawal), entity.ErrInsufficientFunds)
+	})
+
+	t.Run("admin debit expires overdue points first", func(t *testing.T) {
+		uc, m := setupExpiryUseCase(t, yearPolicy)
+		adj := entity.BalanceAdjustment{Amount: entity.NewPoints(-100, 0), Reason: "fraud"}
+		gomock.InOrder(
+			m.user.EXPECT().GetUserByID(ctx, uint(7)).Return(&entity.User{ID: 7}, nil),
+			m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(180, 0)}, nil),
+			m.lots.EXPECT().ExpireUserLots(ctx, uint(7), gomock.Any()).Return(entity.NewPoints(30, 0), nil),
+			m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(150, 0)}, nil),
+			m.balance.EXPECT().AdjustBalance(ctx, uint(7), adj.Amount, adj.Reason).Return(nil),
+			m.lots.EXPECT().ConsumePointLots(ctx, uint(7), entity.NewPoints(100, 0), gomock.Any()).Return(nil, nil),
+		)
+
+		require.NoError(t, uc.AdminAdjustBalance(ctx, 1, 7, adj))
+	})
+
+	t.Run("expired points cannot be debited by an admin", func(t *testing.T) {
+		uc, m := setupExpiryUseCase(t, yearPolicy)
+		adj := entity.BalanceAdjustment{Amount: entity.NewPoints(-100, 0), Reason: "fraud"}
+		m.user.EXPECT().GetUserByID(ctx, uint(7)).Return(&entity.User{ID: 7}, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(120, 0)}, nil)
+		m.lots.EXPECT().ExpireUserLots(ctx, uint(7), gomock.Any()).Return(entity.NewPoints(30, 0), nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(90, 0)}, nil)
+
+		assert.ErrorIs(t, uc.AdminAdjustBalance(ctx, 1, 7, adj), entity.ErrInsufficientFunds)
+	})
+
+	t.Run("debit adjustment consumes lots", func(t *testing.T) {
+		uc, m := setupExpiryUseCase(t, yearPolicy)
+		m.balance.EXPECT().AdjustBalance(ctx, uint(7), entity.NewPoints(-10, 0), "fraud").Return(nil)
+		m.lots.EXPECT().ConsumePointLots(ctx, uint(7), entity.NewPoints(10, 0), gomock.Any()).Return(nil, nil)
+
+		require.NoError(t, uc.AdjustUserBalance(ctx, 7, entity.NewPoints(-10, 0), "fraud"))
+	})
+}
+
+func TestBalanceExpiringSoon(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("lots within the notice period are listed", func(t *testing.T) {
+		uc, m := setupExpiryUseCase(t, yearPolicy)
+		soon := time.Now().Add(10 * 24 * time.Hour)
+		later := time.Now().Add(20 * 24 * time.Hour)
+		lots := []entity.PointLot{
+			{Amount: entity.NewPoints(100, 0), Remaining: entity.NewPoints(40, 0), ExpiresAt: &soon},
+			{Amount: entity.NewPoints(25, 50), Remaining: entity.NewPoints(25, 50), ExpiresAt: &later},
+		}
+		m.balance.EXPECT().GetBalance(ctx, "7").Return(&entity.Balance{Current: entity.NewPoints(300, 0)}, nil)
+		m.lots.EXPECT().GetExpiringPointLots(ctx, uint(7), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, cutoff, until time.Time) ([]entity.PointLot, error) {
+				assert.True(t, cutoff.Before(until))
+				assert.WithinDuration(t, time.Now().Add(yearPolicy.Notice), until, time.Minute)
+				return lots, nil
+			})
+
+		got, err := uc.GetUserBalance(ctx, "7")
+		require.NoError(t, err)
+		assert.Equal(t, entity.NewPoints(300, 0), got.Current)
+		require.NotNil(t, got.ExpiringSoon)
+		assert.Equal(t, entity.NewPoints(65, 50), got.ExpiringSoon.Total)
+		assert.Equal(t, lots, got.ExpiringSoon.Lots)
+	})
+
+	t.Run("no section while points do not expire", func(t *testing.T) {
+		uc, m := setupExpiryUseCase(t, entity.ExpiryPolicy{})
+		m.balance.EXPECT().GetBalance(ctx, "7").Return(&entity.Balance{Current: entity.NewPoints(300, 0)}, nil)
+
+		got, err := uc.GetUserBalance(ctx, "7")
+		require.NoError(t, err)
+		assert.Nil(t, got.ExpiringSoon)
+	})
+}
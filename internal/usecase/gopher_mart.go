@@ -3,12 +3,17 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"go-loyalty-system/internal/audit"
 	"go-loyalty-system/internal/entity"
 	"go-loyalty-system/internal/usecase/repo"
 	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/tracing"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type UserUseCase struct {
@@ -17,6 +22,25 @@ type UserUseCase struct {
 	order   repo.OrderUseCase
 	user    repo.AuthUseCase
 	Logger  *logging.ZapLogger
+
+	idempotency  repo.IdempotencyRepository
+	tx           repo.Transactor
+	passwordCost int
+	tokens       repo.TokenRepository
+	tokenCache   *tokenCache
+	adminAudit   repo.AdminAuditRepository
+	orderEvents  repo.OrderEventRepository
+	eventHub     *orderEventHub
+	stats        repo.StatsRepository
+	auditLog     *audit.Recorder
+	lots         repo.PointLotRepository
+	expiry       entity.ExpiryPolicy
+	holds        repo.HoldRepository
+	holdTTL      time.Duration
+
+	transferLimits entity.TransferLimits
+	tiers          repo.TierRepository
+	tierPolicy     entity.TierPolicy
 }
 
 func NewGopherMart(
@@ -24,14 +48,22 @@ func NewGopherMart(
 	b repo.BalanceUseCase,
 	o repo.OrderUseCase,
 	u repo.AuthUseCase,
-	l *logging.ZapLogger) *UserUseCase {
-	return &UserUseCase{
+	l *logging.ZapLogger,
+	opts ...Option) *UserUseCase {
+	uc := &UserUseCase{
 		balance: b,
 		user:    u,
 		order:   o,
 		accrual: a,
 		Logger:  l,
+
+		passwordCost: bcrypt.DefaultCost,
+		tokenCache:   newTokenCache(),
 	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
 }
 
 func (uc *UserUseCase) GetUserByEmail(ctx context.Context, u entity.User) (*entity.User, error) {
@@ -52,6 +84,15 @@ func (uc *UserUseCase) GetUserByLogin(ctx context.Context, u entity.User) (*enti
 	return user, nil
 }
 
+func (uc *UserUseCase) GetUserByID(ctx context.Context, userID uint) (*entity.User, error) {
+	user, err := uc.user.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - GetUserByID: %w", err)
+	}
+
+	return user, nil
+}
+
 func (uc *UserUseCase) GetUsers(ctx context.Context) ([]entity.User, error) {
 	users, err := uc.user.GetUsers(ctx)
 	if err != nil {
@@ -61,71 +102,163 @@ func (uc *UserUseCase) GetUsers(ctx context.Context) ([]entity.User, error) {
 	return users, nil
 }
 
-func (uc *UserUseCase) RegisterUser(ctx context.Context, u entity.User) error {
-	if err := uc.user.RegisterUser(ctx, u); err != nil {
-		return fmt.Errorf("GopherMartUseCase - RegisterUser: %w", err)
-	}
-	return nil
-}
+func (uc *UserUseCase) SetOrders(ctx context.Context, userID uint, o entity.Order) (err error) {
+	ctx, span := startSpan(ctx, "SetOrders")
+	defer func() { endSpan(span, err) }()
 
-func (uc *UserUseCase) SetOrders(ctx context.Context, userID uint, o entity.Order) error {
+	if err := validateGoods(o.Goods); err != nil {
+		uc.Logger.ErrorCtx(ctx, "Order goods validation failed", zap.Error(err))
+		return err
+	}
 	if err := uc.order.ValidateOrder(o, userID); err != nil {
-		uc.Logger.ErrorCtx(ctx, "Order validation failed: %w"+err.Error(), zap.Error(err))
+		uc.Logger.ErrorCtx(ctx, "SetOrders - order validation failed", zap.Error(err))
 		return err
 	}
 	o.StatusID = entity.OrderStatusNewID
 	o.CreatedAt = time.Now()
 	o.UploadedAt = time.Now()
 	if err := uc.order.SetOrders(ctx, userID, o); err != nil {
-		uc.Logger.ErrorCtx(ctx, "Failed to create order: %w", zap.Error(err))
+		uc.Logger.ErrorCtx(ctx, "SetOrders - failed to create order", zap.Error(err))
 		return fmt.Errorf("failed to create order: %w", err)
 	}
 	return nil
 }
 
+// validateGoods корзина необязательна, но если передана, у каждого товара
+// должно быть описание и неотрицательная цена
+func validateGoods(goods []entity.Product) error {
+	for _, p := range goods {
+		if strings.TrimSpace(p.Description) == "" || p.Price < 0 {
+			return entity.ErrInvalidOrderGoods
+		}
+	}
+	return nil
+}
+
+func (uc *UserUseCase) GetOrderGoods(ctx context.Context, orderNumber string) ([]entity.Product, error) {
+	goods, err := uc.order.GetOrderGoods(ctx, orderNumber)
+	if err != nil {
+		return nil, fmt.Errorf("GetOrderGoods: %w", err)
+	}
+	return goods, nil
+}
+
 func (uc *UserUseCase) CreateToken(ctx context.Context, t *entity.Token) error {
 	if err := uc.user.CreateToken(ctx, t); err != nil {
 		return fmt.Errorf("GopherMartUseCase - CreateToken: %w", err)
 	}
+	uc.recordSecurityAudit(ctx, audit.Event{
+		Type:    audit.TokenIssued,
+		ActorID: &t.UserID,
+		UserID:  &t.UserID,
+		Details: map[string]string{
+			"kind":       string(t.Kind),
+			"family":     t.FamilyID.String(),
+			"expires_at": t.ExpiresAt.UTC().Format(time.RFC3339),
+		},
+	})
 	return nil
 }
 
-func (uc *UserUseCase) GetUserBalance(ctx context.Context, userID string) (*entity.Balance, error) {
-	return uc.balance.GetBalance(ctx, userID)
+// GetUserBalance баланс пользователя; при включенном сгорании — вместе с баллами,
+// которые сгорят в ближайшее время, при включенных уровнях — с уровнем участия
+func (uc *UserUseCase) GetUserBalance(ctx context.Context, userID string) (*entity.BalanceSummary, error) {
+	balance, err := uc.balance.GetBalance(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	summary := &entity.BalanceSummary{Balance: *balance}
+	expiry := uc.lots != nil && uc.expiry.Enabled()
+	tiers := uc.tiers != nil && uc.tierPolicy.Enabled()
+	if !expiry && !tiers {
+		return summary, nil
+	}
+	id, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - GetUserBalance: %w", err)
+	}
+	if expiry {
+		if summary.ExpiringSoon, err = uc.expiringSoon(ctx, uint(id)); err != nil {
+			return nil, err
+		}
+	}
+	if tiers {
+		if summary.Tier, err = uc.tierStatus(ctx, uint(id)); err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
 }
 
-func (uc *UserUseCase) GetUserOrders(ctx context.Context, userID uint) ([]entity.OrderResponse, error) {
+// GetUserOrders страница заказов пользователя
+func (uc *UserUseCase) GetUserOrders(ctx context.Context,
+	userID uint,
+	f entity.ListFilter) (*entity.OrderPage, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
 	}
 
-	orders, err := uc.order.GetUserOrders(ctx, userID)
+	f, err := f.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	orders, err := uc.order.GetUserOrders(ctx, userID, f)
 	if err != nil {
 		return nil, fmt.Errorf("GetUserOrders: %w", err)
 	}
 
-	return orders, nil
+	page := &entity.OrderPage{}
+	page.Orders, page.NextCursor = trimPage(orders, f.Limit, func(o entity.OrderResponse) entity.PageCursor {
+		return entity.PageCursor{At: o.UploadedAt, ID: int64(o.ID)}
+	})
+	return page, nil
 }
 
+// WithdrawBalance создает заказ списания и списывает баллы одной транзакцией.
+// Баланс блокируется первым, поэтому параллельные списания не уводят его в минус.
 func (uc *UserUseCase) WithdrawBalance(ctx context.Context, withdrawal entity.Withdrawal) error {
-	tx, err := uc.balance.BeginTx(ctx)
-	if err != nil {
-		uc.Logger.ErrorCtx(ctx, "WithdrawBalance - begin transaction", zap.Error(err))
-		return err
-	}
-	defer func() {
-		_ = tx.Rollback(ctx)
-	}()
+	return uc.withinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.expireBeforeDebit(ctx, withdrawal.UserID); err != nil {
+			return err
+		}
+		balance, err := uc.balance.GetBalanceForUpdate(ctx, withdrawal.UserID)
+		if err != nil {
+			uc.Logger.ErrorCtx(ctx, "WithdrawBalance - lock balance", zap.Error(err))
+			return err
+		}
+		if balance.Current.Cmp(withdrawal.Amount) < 0 {
+			return entity.ErrInsufficientFunds
+		}
+
+		if err := uc.createWithdrawal(ctx, withdrawal); err != nil {
+			return err
+		}
+		if err := uc.debitLots(ctx, withdrawal.UserID, withdrawal.Amount); err != nil {
+			return err
+		}
+		return uc.recordAudit(ctx, audit.Event{
+			Type:        audit.WithdrawalCreated,
+			ActorID:     &withdrawal.UserID,
+			UserID:      &withdrawal.UserID,
+			OrderNumber: withdrawal.OrderNumber,
+			Amount:      &withdrawal.Amount,
+			Before:      &balance.Current,
+			After:       audit.Ptr(balance.Current.Sub(withdrawal.Amount)),
+		})
+	})
+}
 
+// createWithdrawal заводит заказ списания, записывает списание и проводку
+// и обновляет проекцию баланса. Баланс уже должен быть заблокирован.
+func (uc *UserUseCase) createWithdrawal(ctx context.Context, withdrawal entity.Withdrawal) error {
 	order := entity.Order{
 		Number:     withdrawal.OrderNumber,
 		StatusID:   entity.OrderStatusNewID,
 		CreatedAt:  time.Now(),
 		UploadedAt: time.Now(),
 	}
-
 	if err := uc.order.SetOrders(ctx, withdrawal.UserID, order); err != nil {
 		uc.Logger.ErrorCtx(ctx, "WithdrawBalance - create order", zap.Error(err))
 		return err
@@ -142,51 +275,190 @@ func (uc *UserUseCase) WithdrawBalance(ctx context.Context, withdrawal entity.Wi
 		return err
 	}
 
-	if err := uc.balance.UpdateBalanceTx(ctx, tx, withdrawal.UserID, withdrawal.Amount); err != nil {
+	if err := uc.balance.UpdateBalanceTx(ctx, withdrawal.UserID, withdrawal.Amount); err != nil {
 		uc.Logger.ErrorCtx(ctx, "WithdrawBalance - update balance", zap.Error(err))
 		return err
 	}
+	return nil
+}
 
-	if err := tx.Commit(ctx); err != nil {
-		uc.Logger.ErrorCtx(ctx, "WithdrawBalance - commit transaction", zap.Error(err))
-		return err
+// withinTransaction выполняет fn в транзакции, если менеджер транзакций подключен
+func (uc *UserUseCase) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if uc.tx == nil {
+		return fn(ctx)
 	}
-
-	return nil
+	if uc.auditLog == nil {
+		return uc.tx.WithinTransaction(ctx, fn)
+	}
+	// зеркало журнала получает события транзакции только после ее фиксации
+	ctx, done := uc.auditLog.Defer(ctx)
+	err := uc.tx.WithinTransaction(ctx, fn)
+	done(err == nil)
+	return err
 }
 
-func (uc *UserUseCase) GetUserWithdrawals(ctx context.Context, userID uint) ([]entity.Withdrawal, error) {
-	withdrawals, err := uc.balance.GetUserWithdrawals(ctx, userID)
+// GetUserWithdrawals страница истории списаний пользователя
+func (uc *UserUseCase) GetUserWithdrawals(ctx context.Context,
+	userID uint,
+	f entity.ListFilter) (*entity.WithdrawalPage, error) {
+	f, err := f.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Statuses) > 0 {
+		return nil, fmt.Errorf("%w: withdrawals have no status", entity.ErrInvalidListFilter)
+	}
+	withdrawals, err := uc.balance.GetUserWithdrawals(ctx, userID, f)
 	if err != nil {
-		uc.Logger.ErrorCtx(ctx, "GetUserWithdrawals: %w", zap.Error(err))
+		uc.Logger.ErrorCtx(ctx, "GetUserWithdrawals", zap.Error(err))
 		return nil, fmt.Errorf("GetUserWithdrawals: %w", err)
 	}
-	return withdrawals, nil
+	page := &entity.WithdrawalPage{}
+	page.Withdrawals, page.NextCursor = trimPage(withdrawals, f.Limit, func(w entity.Withdrawal) entity.PageCursor {
+		return entity.PageCursor{At: w.CreatedAt, ID: int64(w.ID)}
+	})
+	return page, nil
+}
+
+// trimPage отрезает лишнюю строку, выбранную репозиторием сверх лимита,
+// и по последней оставшейся строке строит курсор следующей страницы
+func trimPage[T any](items []T, limit int, cursor func(T) entity.PageCursor) ([]T, string) {
+	if len(items) <= limit {
+		return items, ""
+	}
+	items = items[:limit]
+	return items, cursor(items[limit-1]).Encode()
 }
 
 func (uc *UserUseCase) GetUnprocessedOrders(ctx context.Context) ([]string, error) {
 	orders, err := uc.accrual.GetUnprocessedOrders(ctx)
 	if err != nil {
-		uc.Logger.ErrorCtx(ctx, "GetUnprocessedOrders: %w", zap.Error(err))
+		uc.Logger.ErrorCtx(ctx, "GetUnprocessedOrders", zap.Error(err))
 		return nil, fmt.Errorf("GetUnprocessedOrders: %w", err)
 	}
 	return orders, nil
 }
 
-func (uc *UserUseCase) SaveAccrual(ctx context.Context, orderNumber, status string, accrual float32) error {
+func (uc *UserUseCase) SaveAccrual(ctx context.Context, orderNumber, status string, accrual entity.Points) (err error) {
+	ctx, span := startSpan(ctx, "SaveAccrual")
+	defer func() { endSpan(span, err) }()
+
 	exist, err := uc.accrual.ExistOrderAccrual(ctx, orderNumber)
 	if err != nil {
-		uc.Logger.ErrorCtx(ctx, "SetOrderStatus: %w", zap.Error(err))
+		uc.Logger.ErrorCtx(ctx, "SaveAccrual - check accrual", zap.Error(err))
 		return fmt.Errorf("SetOrderStatus: %w", err)
 	}
 	if exist {
 		return nil
 	}
 
+	if status == entity.AccrualStatusProcessed && accrual.IsPositive() && uc.creditNeedsTx() {
+		return uc.saveCreditedAccrual(ctx, orderNumber, accrual)
+	}
 	if err := uc.accrual.SaveAccrual(ctx, orderNumber, status, accrual); err != nil {
-		uc.Logger.ErrorCtx(ctx, "SetOrderStatus: %w", zap.Error(err))
+		uc.Logger.ErrorCtx(ctx, "SaveAccrual - save accrual", zap.Error(err))
 		return fmt.Errorf("SetOrderStatus: %w", err)
 	}
 
 	return nil
 }
+
+// creditNeedsTx нужно ли зачислять баллы транзакцией: кроме самого начисления
+// пишутся событие аудита, партия баллов или надбавка уровня
+func (uc *UserUseCase) creditNeedsTx() bool {
+	tiers := uc.tiers != nil && uc.tierPolicy.Enabled()
+	return uc.auditLog != nil || uc.lots != nil || tiers
+}
+
+// saveCreditedAccrual зачисляет баллы, заводит на них партию, начисляет надбавку
+// уровня и пишет события в журнал одной транзакцией. Баланс блокируется до зачисления, чтобы в событии были точные
+// суммы до и после.
+func (uc *UserUseCase) saveCreditedAccrual(ctx context.Context, orderNumber string, accrual entity.Points) error {
+	return uc.withinTransaction(ctx, func(ctx context.Context) error {
+		exists, userID, err := uc.order.CheckOrderExistence(ctx, orderNumber, 0)
+		if err != nil {
+			return fmt.Errorf("SetOrderStatus: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("SetOrderStatus: %w", entity.ErrOrderNotFound)
+		}
+		balance, err := uc.balance.GetBalanceForUpdate(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("SetOrderStatus: %w", err)
+		}
+		if err := uc.accrual.SaveAccrual(ctx, orderNumber, entity.AccrualStatusProcessed, accrual); err != nil {
+			uc.Logger.ErrorCtx(ctx, "SaveAccrual - save accrual", zap.Error(err))
+			return fmt.Errorf("SetOrderStatus: %w", err)
+		}
+		if err := uc.creditLot(ctx, userID, orderNumber, accrual); err != nil {
+			return err
+		}
+		err = uc.recordAudit(ctx, audit.Event{
+			Type:        audit.AccrualCredited,
+			UserID:      &userID,
+			OrderNumber: orderNumber,
+			Amount:      &accrual,
+			Before:      &balance.Current,
+			After:       audit.Ptr(balance.Current.Add(accrual)),
+		})
+		if err != nil {
+			return err
+		}
+		return uc.applyTier(ctx, userID, orderNumber, accrual, balance.Current.Add(accrual))
+	})
+}
+
+// EnqueueAccrual ставит заказ в очередь начислений и запоминает traceparent
+// запроса, чтобы трасса воркера ссылалась на загрузку заказа
+func (uc *UserUseCase) EnqueueAccrual(ctx context.Context, orderNumber string) error {
+	if err := uc.accrual.EnqueueAccrualJob(ctx, orderNumber, tracing.TraceParent(ctx)); err != nil {
+		return fmt.Errorf("EnqueueAccrual: %w", err)
+	}
+	return nil
+}
+
+func (uc *UserUseCase) ClaimAccrualJobs(ctx context.Context,
+	workerID string,
+	limit int,
+	lease time.Duration) ([]entity.AccrualJob, error) {
+	jobs, err := uc.accrual.ClaimAccrualJobs(ctx, workerID, limit, lease)
+	if err != nil {
+		return nil, fmt.Errorf("ClaimAccrualJobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (uc *UserUseCase) CompleteAccrualJob(ctx context.Context, jobID int64) error {
+	if err := uc.accrual.CompleteAccrualJob(ctx, jobID); err != nil {
+		return fmt.Errorf("CompleteAccrualJob: %w", err)
+	}
+	return nil
+}
+
+func (uc *UserUseCase) RetryAccrualJob(ctx context.Context, jobID int64, delay time.Duration, lastErr string) error {
+	if err := uc.accrual.RetryAccrualJob(ctx, jobID, delay, lastErr); err != nil {
+		return fmt.Errorf("RetryAccrualJob: %w", err)
+	}
+	return nil
+}
+
+func (uc *UserUseCase) PostponeAccrualJob(ctx context.Context, jobID int64, delay time.Duration) error {
+	if err := uc.accrual.PostponeAccrualJob(ctx, jobID, delay); err != nil {
+		return fmt.Errorf("PostponeAccrualJob: %w", err)
+	}
+	return nil
+}
+
+func (uc *UserUseCase) FailAccrualJob(ctx context.Context, jobID int64, lastErr string) error {
+	if err := uc.accrual.FailAccrualJob(ctx, jobID, lastErr); err != nil {
+		return fmt.Errorf("FailAccrualJob: %w", err)
+	}
+	return nil
+}
+
+func (uc *UserUseCase) SetAccrualJobRegistered(ctx context.Context, jobID int64, registered bool) error {
+	if err := uc.accrual.SetAccrualJobRegistered(ctx, jobID, registered); err != nil {
+		return fmt.Errorf("SetAccrualJobRegistered: %w", err)
+	}
+	return nil
+}
@@ -116,8 +116,8 @@ func TestMockGopherMartUseCase(t *testing.T) {
 	t.Run("GetUserBalance", func(t *testing.T) {
 		userID := "1"
 		expectedBalance := &entity.Balance{
-			Current:   100.50,
-			Withdrawn: 50.25,
+			Current:   entity.NewPoints(100, 50),
+			Withdrawn: entity.NewPoints(50, 25),
 		}
 
 		mockUC.EXPECT().
@@ -132,7 +132,7 @@ func TestMockGopherMartUseCase(t *testing.T) {
 
 	t.Run("GetUserOrders", func(t *testing.T) {
 		userID := uint(1)
-		accrual := float64(500.50)
+		accrual := entity.NewPoints(500, 50)
 		expectedOrders := []entity.OrderResponse{
 			{
 				Number:     "123456789",
@@ -162,7 +162,7 @@ func TestMockGopherMartUseCase(t *testing.T) {
 		withdrawal := entity.Withdrawal{
 			UserID:      1,
 			OrderNumber: "123456789",
-			Amount:      50.25,
+			Amount:      entity.NewPoints(50, 25),
 		}
 
 		mockUC.EXPECT().
@@ -180,13 +180,13 @@ func TestMockGopherMartUseCase(t *testing.T) {
 			{
 				UserID:      1,
 				OrderNumber: "123456789",
-				Amount:      50.25,
+				Amount:      entity.NewPoints(50, 25),
 				ProcessedAt: time.Now(),
 			},
 			{
 				UserID:      1,
 				OrderNumber: "987654321",
-				Amount:      25.75,
+				Amount:      entity.NewPoints(25, 75),
 				ProcessedAt: time.Now(),
 			},
 		}
@@ -217,7 +217,7 @@ func TestMockGopherMartUseCase(t *testing.T) {
 	t.Run("SaveAccrual", func(t *testing.T) {
 		orderNumber := "123456789"
 		status := "PROCESSED"
-		accrual := float32(100.50)
+		accrual := entity.NewPoints(100, 50)
 
 		mockUC.EXPECT().
 			SaveAccrual(gomock.Any(), orderNumber, status, accrual).
@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/audit"
+	"go-loyalty-system/internal/entity"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// holdExpiryBatch сколько просроченных резервов выбирается за раз
+const holdExpiryBatch = 100
+
+// HoldBalance резервирует баллы под заказ: они перестают быть доступны для трат,
+// но списываются только при CaptureHold. Резерв, который не подтвердили
+// за holdTTL, отменяется сам.
+func (uc *UserUseCase) HoldBalance(ctx context.Context, userID uint, r entity.HoldRequest) (*entity.Hold, error) {
+	if uc.holds == nil {
+		return nil, entity.ErrHoldsUnavailable
+	}
+	if !r.Sum.IsPositive() {
+		return nil, entity.ErrInvalidHold
+	}
+
+	var hold *entity.Hold
+	err := uc.withinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.expireBeforeDebit(ctx, userID); err != nil {
+			return err
+		}
+		balance, err := uc.balance.GetBalanceForUpdate(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("GopherMartUseCase - HoldBalance: %w", err)
+		}
+		if balance.Current.Cmp(r.Sum) < 0 {
+			return entity.ErrInsufficientFunds
+		}
+		// зарезервированные баллы уходят из партий сразу, иначе они могли бы сгореть в резерве
+		consumed, err := uc.consumeLots(ctx, userID, r.Sum)
+		if err != nil {
+			return err
+		}
+		hold, err = uc.holds.CreateHold(ctx, entity.Hold{
+			UserID:         userID,
+			OrderNumber:    r.Order,
+			Amount:         r.Sum,
+			PointsExpireAt: earliestExpiry(consumed),
+			ExpiresAt:      time.Now().Add(uc.holdTTL),
+		})
+		if err != nil {
+			return fmt.Errorf("GopherMartUseCase - HoldBalance: %w", err)
+		}
+		return uc.recordAudit(ctx, audit.Event{
+			Type:        audit.HoldCreated,
+			ActorID:     &userID,
+			UserID:      &userID,
+			OrderNumber: r.Order,
+			Amount:      &r.Sum,
+			Before:      &balance.Current,
+			After:       audit.Ptr(balance.Current.Sub(r.Sum)),
+			Details:     map[string]string{"hold_id": strconv.FormatInt(hold.ID, 10)},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// CaptureHold списывает резерв по заказу обычным списанием. Без суммы списывается
+// весь резерв; меньшая сумма списывается, а остаток возвращается на счет.
+func (uc *UserUseCase) CaptureHold(ctx context.Context,
+	userID uint,
+	holdID int64,
+	r entity.HoldCapture) (*entity.Hold, error) {
+	if uc.holds == nil {
+		return nil, entity.ErrHoldsUnavailable
+	}
+	if r.Sum.IsNegative() {
+		return nil, entity.ErrInvalidHold
+	}
+
+	var captured *entity.Hold
+	err := uc.withinTransaction(ctx, func(ctx context.Context) error {
+		balance, hold, err := uc.lockActiveHold(ctx, userID, holdID)
+		if err != nil {
+			return err
+		}
+		amount := r.Sum
+		if amount.IsZero() {
+			amount = hold.Amount
+		}
+		if amount.Cmp(hold.Amount) > 0 {
+			return fmt.Errorf("GopherMartUseCase - CaptureHold: %w: %s of %s held",
+				entity.ErrCaptureExceeded, amount, hold.Amount)
+		}
+
+		if err := uc.holds.SettleHold(ctx, *hold, entity.HoldCaptured, amount); err != nil {
+			return fmt.Errorf("GopherMartUseCase - CaptureHold: %w", err)
+		}
+		err = uc.createWithdrawal(ctx, entity.Withdrawal{
+			UserID:      userID,
+			OrderNumber: hold.OrderNumber,
+			Amount:      amount,
+			CreatedAt:   time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		rest := hold.Amount.Sub(amount)
+		if err := uc.restoreLot(ctx, userID, "", rest, hold.PointsExpireAt); err != nil {
+			return err
+		}
+
+		hold.Status = entity.HoldCaptured
+		hold.Captured = amount
+		if err := uc.recordAudit(ctx, audit.Event{
+			Type:        audit.HoldCaptured,
+			ActorID:     &userID,
+			UserID:      &userID,
+			OrderNumber: hold.OrderNumber,
+			Amount:      &amount,
+			Before:      &balance.Current,
+			After:       audit.Ptr(balance.Current.Add(rest)),
+			Details:     map[string]string{"hold_id": strconv.FormatInt(hold.ID, 10), "held": hold.Amount.String()},
+		}); err != nil {
+			return err
+		}
+		captured = hold
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return captured, nil
+}
+
+// ReleaseHold отменяет резерв и возвращает баллы на счет
+func (uc *UserUseCase) ReleaseHold(ctx context.Context, userID uint, holdID int64) (*entity.Hold, error) {
+	if uc.holds == nil {
+		return nil, entity.ErrHoldsUnavailable
+	}
+	var released *entity.Hold
+	err := uc.withinTransaction(ctx, func(ctx context.Context) error {
+		balance, hold, err := uc.lockActiveHold(ctx, userID, holdID)
+		if err != nil {
+			return err
+		}
+		if err := uc.settleReleased(ctx, &userID, balance, hold, entity.HoldReleased); err != nil {
+			return err
+		}
+		released = hold
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return released, nil
+}
+
+// lockActiveHold блокирует баланс и резерв пользователя в этом порядке, как и списание,
+// и проверяет, что резерв еще действует
+func (uc *UserUseCase) lockActiveHold(ctx context.Context,
+	userID uint,
+	holdID int64) (*entity.Balance, *entity.Hold, error) {
+	balance, err := uc.balance.GetBalanceForUpdate(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GopherMartUseCase - lockActiveHold: %w", err)
+	}
+	hold, err := uc.holds.GetHoldForUpdate(ctx, userID, holdID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GopherMartUseCase - lockActiveHold: %w", err)
+	}
+	// просроченный резерв снимет RunHoldExpiry, подтверждать его уже поздно
+	if !hold.Active(time.Now()) {
+		return nil, nil, entity.ErrHoldNotActive
+	}
+	return balance, hold, nil
+}
+
+// settleReleased закрывает резерв без списания и возвращает баллы в партии
+// с прежним сроком сгорания, чтобы резерв не продлевал им жизнь
+func (uc *UserUseCase) settleReleased(ctx context.Context,
+	actorID *uint,
+	balance *entity.Balance,
+	hold *entity.Hold,
+	status entity.HoldStatus) error {
+	if err := uc.holds.SettleHold(ctx, *hold, status, 0); err != nil {
+		return fmt.Errorf("GopherMartUseCase - ReleaseHold: %w", err)
+	}
+	if err := uc.restoreLot(ctx, hold.UserID, "", hold.Amount, hold.PointsExpireAt); err != nil {
+		return err
+	}
+	hold.Status = status
+	return uc.recordAudit(ctx, audit.Event{
+		Type:        audit.HoldReleased,
+		ActorID:     actorID,
+		UserID:      &hold.UserID,
+		OrderNumber: hold.OrderNumber,
+		Amount:      &hold.Amount,
+		Before:      &balance.Current,
+		After:       audit.Ptr(balance.Current.Add(hold.Amount)),
+		Details:     map[string]string{"hold_id": strconv.FormatInt(hold.ID, 10), "status": string(status)},
+	})
+}
+
+// RunHoldExpiry отменяет просроченные резервы раз в interval, пока не отменен ctx
+func (uc *UserUseCase) RunHoldExpiry(ctx context.Context, interval time.Duration) {
+	if uc.holds == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := uc.ExpireHolds(ctx); err != nil {
+			uc.Logger.ErrorCtx(ctx, "RunHoldExpiry - expire holds", zap.Error(err))
+		} else if n > 0 {
+			uc.Logger.InfoCtx(ctx, "holds expired", zap.Int("holds", n))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireHolds отменяет резервы с истекшим сроком и возвращает их число.
+// Каждый резерв снимается своей транзакцией; на первой ошибке проход
+// прерывается до следующего запуска.
+func (uc *UserUseCase) ExpireHolds(ctx context.Context) (int, error) {
+	if uc.holds == nil {
+		return 0, nil
+	}
+	expired := 0
+	for {
+		holds, err := uc.holds.GetExpiredHolds(ctx, time.Now(), holdExpiryBatch)
+		if err != nil {
+			return expired, fmt.Errorf("GopherMartUseCase - ExpireHolds: %w", err)
+		}
+		for _, h := range holds {
+			if err := ctx.Err(); err != nil {
+				return expired, err
+			}
+			done := false
+			err := uc.withinTransaction(ctx, func(ctx context.Context) error {
+				balance, err := uc.balance.GetBalanceForUpdate(ctx, h.UserID)
+				if err != nil {
+					return fmt.Errorf("GopherMartUseCase - ExpireHolds: %w", err)
+				}
+				hold, err := uc.holds.GetHoldForUpdate(ctx, h.UserID, h.ID)
+				if err != nil {
+					return fmt.Errorf("GopherMartUseCase - ExpireHolds: %w", err)
+				}
+				// резерв успели подтвердить или снять, пока он ждал блокировки
+				if hold.Status != entity.HoldActive {
+					return nil
+				}
+				done = true
+				return uc.settleReleased(ctx, nil, balance, hold, entity.HoldExpired)
+			})
+			if err != nil {
+				return expired, err
+			}
+			if done {
+				expired++
+			}
+		}
+		if len(holds) < holdExpiryBatch {
+			return expired, nil
+		}
+	}
+}
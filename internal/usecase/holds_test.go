@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"context"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type holdMocks struct {
+	balance *mocks.MockBalanceUseCase
+	order   *mocks.MockOrderUseCase
+	lots    *mocks.MockPointLotRepository
+	holds   *mocks.MockHoldRepository
+}
+
+func setupHoldUseCase(t *testing.T) (*UserUseCase, holdMocks) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	m := holdMocks{
+		balance: mocks.NewMockBalanceUseCase(ctrl),
+		order:   mocks.NewMockOrderUseCase(ctrl),
+		lots:    mocks.NewMockPointLotRepository(ctrl),
+		holds:   mocks.NewMockHoldRepository(ctrl),
+	}
+	uc := NewGopherMart(mocks.NewMockRepository(ctrl), m.balance, m.order, mocks.NewMockAuthUseCase(ctrl), log,
+		WithPointExpiry(m.lots, entity.ExpiryPolicy{}), WithHolds(m.holds, 15*time.Minute))
+	return uc, m
+}
+
+func activeHold(amount entity.Points, pointsExpireAt *time.Time) *entity.Hold {
+	return &entity.Hold{
+		ID:             5,
+		UserID:         7,
+		OrderNumber:    "2377225624",
+		Amount:         amount,
+		Status:         entity.HoldActive,
+		PointsExpireAt: pointsExpireAt,
+		ExpiresAt:      time.Now().Add(time.Minute),
+	}
+}
+
+func TestHoldBalance(t *testing.T) {
+	ctx := context.Background()
+	request := entity.HoldRequest{Order: "2377225624", Sum: entity.NewPoints(100, 0)}
+
+	t.Run("points are reserved from the lots that expire first", func(t *testing.T) {
+		uc, m := setupHoldUseCase(t)
+		expiresAt := time.Now().Add(48 * time.Hour)
+		gomock.InOrder(
+			m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Current: entity.NewPoints(150, 0)}, nil),
+			m.lots.EXPECT().ConsumePointLots(ctx, uint(7), request.Sum, gomock.Any()).Return([]entity.PointLot{
+				{Amount: entity.NewPoints(60, 0), ExpiresAt: &expiresAt},
+				{Amount: entity.NewPoints(40, 0)},
+			}, nil),
+			m.holds.EXPECT().CreateHold(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, h entity.Hold) (*entity.Hold, error) {
+				assert.Equal(t, uint(7), h.UserID)
+				assert.Equal(t, request.Order, h.OrderNumber)
+				assert.Equal(t, request.Sum, h.Amount)
+				assert.Equal(t, &expiresAt, h.PointsExpireAt)
+				assert.WithinDuration(t, time.Now().Add(15*time.Minute), h.ExpiresAt, time.Minute)
+				h.ID, h.Status = 5, entity.HoldActive
+				return &h, nil
+			}),
+		)
+
+		hold, err := uc.HoldBalance(ctx, 7, request)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), hold.ID)
+		assert.Equal(t, entity.HoldActive, hold.Status)
+	})
+
+	t.Run("held points cannot exceed the available balance", func(t *testing.T) {
+		uc, m := setupHoldUseCase(t)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).
+			Return(&entity.Balance{Current: entity.NewPoints(50, 0), Held: entity.NewPoints(100, 0)}, nil)
+
+		_, err := uc.HoldBalance(ctx, 7, request)
+		assert.ErrorIs(t, err, entity.ErrInsufficientFunds)
+	})
+
+	t.Run("sum must be positive", func(t *testing.T) {
+		uc, _ := setupHoldUseCase(t)
+
+		_, err := uc.HoldBalance(ctx, 7, entity.HoldRequest{Order: "2377225624"})
+		assert.ErrorIs(t, err, entity.ErrInvalidHold)
+	})
+
+	t.Run("holds are disabled without a store", func(t *testing.T) {
+		uc, _ := setupExpiryUseCase(t, entity.ExpiryPolicy{})
+
+		_, err := uc.HoldBalance(ctx, 7, request)
+		assert.ErrorIs(t, err, entity.ErrHoldsUnavailable)
+	})
+}
+
+func TestCaptureHold(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("whole hold is withdrawn", func(t *testing.T) {
+		uc, m := setupHoldUseCase(t)
+		hold := activeHold(entity.NewPoints(100, 0), nil)
+		order := &entity.OrderResponse{ID: 3, Number: hold.OrderNumber}
+		gomock.InOrder(
+			m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Held: hold.Amount}, nil),
+			m.holds.EXPECT().GetHoldForUpdate(ctx, uint(7), int64(5)).Return(hold, nil),
+			m.holds.EXPECT().SettleHold(ctx, *hold, entity.HoldCaptured, hold.Amount).Return(nil),
+			m.order.EXPECT().SetOrders(ctx, uint(7), gomock.Any()).Return(nil),
+			m.order.EXPECT().GetOrderByNumber(ctx, hold.OrderNumber).Return(order, nil),
+			m.balance.EXPECT().CreateWithdrawalTx(ctx, gomock.Any(), order).Return(nil),
+			m.balance.EXPECT().UpdateBalanceTx(ctx, uint(7), hold.Amount).Return(nil),
+		)
+
+		got, err := uc.CaptureHold(ctx, 7, 5, entity.HoldCapture{})
+		require.NoError(t, err)
+		assert.Equal(t, entity.HoldCaptured, got.Status)
+		assert.Equal(t, hold.Amount, got.Captured)
+	})
+
+	t.Run("rest of a partial capture returns with the original expiry", func(t *testing.T) {
+		uc, m := setupHoldUseCase(t)
+		expiresAt := time.Now().Add(48 * time.Hour)
+		hold := activeHold(entity.NewPoints(100, 0), &expiresAt)
+		capture := entity.NewPoints(60, 0)
+		order := &entity.OrderResponse{ID: 3, Number: hold.OrderNumber}
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Held: hold.Amount}, nil)
+		m.holds.EXPECT().GetHoldForUpdate(ctx, uint(7), int64(5)).Return(hold, nil)
+		m.holds.EXPECT().SettleHold(ctx, *hold, entity.HoldCaptured, capture).Return(nil)
+		m.order.EXPECT().SetOrders(ctx, uint(7), gomock.Any()).Return(nil)
+		m.order.EXPECT().GetOrderByNumber(ctx, hold.OrderNumber).Return(order, nil)
+		m.balance.EXPECT().CreateWithdrawalTx(ctx, gomock.Any(), order).DoAndReturn(
+			func(_ context.Context, w entity.Withdrawal, _ *entity.OrderResponse) error {
+				assert.Equal(t, capture, w.Amount)
+				assert.Equal(t, hold.OrderNumber, w.OrderNumber)
+				return nil
+			})
+		m.balance.EXPECT().UpdateBalanceTx(ctx, uint(7), capture).Return(nil)
+		m.lots.EXPECT().CreatePointLot(ctx, entity.PointLot{
+			UserID:    7,
+			Amount:    entity.NewPoints(40, 0),
+			Remaining: entity.NewPoints(40, 0),
+			ExpiresAt: &expiresAt,
+		}).Return(nil)
+
+		got, err := uc.CaptureHold(ctx, 7, 5, entity.HoldCapture{Sum: capture})
+		require.NoError(t, err)
+		assert.Equal(t, capture, got.Captured)
+	})
+
+	t.Run("capture cannot exceed the hold", func(t *testing.T) {
+		uc, m := setupHoldUseCase(t)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{}, nil)
+		m.holds.EXPECT().GetHoldForUpdate(ctx, uint(7), int64(5)).Return(activeHold(entity.NewPoints(100, 0), nil), nil)
+
+		_, err := uc.CaptureHold(ctx, 7, 5, entity.HoldCapture{Sum: entity.NewPoints(101, 0)})
+		assert.ErrorIs(t, err, entity.ErrCaptureExceeded)
+	})
+
+	t.Run("expired hold cannot be captured", func(t *testing.T) {
+		uc, m := setupHoldUseCase(t)
+		hold := activeHold(entity.NewPoints(100, 0), nil)
+		hold.ExpiresAt = time.Now().Add(-time.Second)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{}, nil)
+		m.holds.EXPECT().GetHoldForUpdate(ctx, uint(7), int64(5)).Return(hold, nil)
+
+		_, err := uc.CaptureHold(ctx, 7, 5, entity.HoldCapture{})
+		assert.ErrorIs(t, err, entity.ErrHoldNotActive)
+	})
+
+	t.Run("hold of another user is not found", func(t *testing.T) {
+		uc, m := setupHoldUseCase(t)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(8)).Return(&entity.Balance{}, nil)
+		m.holds.EXPECT().GetHoldForUpdate(ctx, uint(8), int64(5)).Return(nil, entity.ErrHoldNotFound)
+
+		_, err := uc.CaptureHold(ctx, 8, 5, entity.HoldCapture{})
+		assert.ErrorIs(t, err, entity.ErrHoldNotFound)
+	})
+}
+
+func TestReleaseHold(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("points return to the balance", func(t *testing.T) {
+		uc, m := setupHoldUseCase(t)
+		hold := activeHold(entity.NewPoints(100, 0), nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Held: hold.Amount}, nil)
+		m.holds.EXPECT().GetHoldForUpdate(ctx, uint(7), int64(5)).Return(hold, nil)
+		m.holds.EXPECT().SettleHold(ctx, *hold, entity.HoldReleased, entity.Points(0)).Return(nil)
+		m.lots.EXPECT().CreatePointLot(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, l entity.PointLot) error {
+			assert.Equal(t, hold.Amount, l.Remaining)
+			assert.Nil(t, l.ExpiresAt, "points from non-expiring lots stay non-expiring")
+			return nil
+		})
+
+		got, err := uc.ReleaseHold(ctx, 7, 5)
+		require.NoError(t, err)
+		assert.Equal(t, entity.HoldReleased, got.Status)
+	})
+
+	t.Run("settled hold cannot be released again", func(t *testing.T) {
+		uc, m := setupHoldUseCase(t)
+		hold := activeHold(entity.NewPoints(100, 0), nil)
+		hold.Status = entity.HoldCaptured
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{}, nil)
+		m.holds.EXPECT().GetHoldForUpdate(ctx, uint(7), int64(5)).Return(hold, nil)
+
+		_, err := uc.ReleaseHold(ctx, 7, 5)
+		assert.ErrorIs(t, err, entity.ErrHoldNotActive)
+	})
+}
+
+func TestExpireHolds(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stale holds are released as expired", func(t *testing.T) {
+		uc, m := setupHoldUseCase(t)
+		stale := activeHold(entity.NewPoints(100, 0), nil)
+		stale.ExpiresAt = time.Now().Add(-time.Minute)
+		captured := &entity.Hold{ID: 6, UserID: 8, Status: entity.HoldCaptured}
+		m.holds.EXPECT().GetExpiredHolds(ctx, gomock.Any(), holdExpiryBatch).
+			Return([]entity.Hold{{ID: 5, UserID: 7}, {ID: 6, UserID: 8}}, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{Held: stale.Amount}, nil)
+		m.holds.EXPECT().GetHoldForUpdate(ctx, uint(7), int64(5)).Return(stale, nil)
+		m.holds.EXPECT().SettleHold(ctx, *stale, entity.HoldExpired, entity.Points(0)).Return(nil)
+		m.lots.EXPECT().CreatePointLot(ctx, gomock.Any()).Return(nil)
+		// резерв 6 подтвердили, пока он ждал своей очереди
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(8)).Return(&entity.Balance{}, nil)
+		m.holds.EXPECT().GetHoldForUpdate(ctx, uint(8), int64(6)).Return(captured, nil)
+
+		n, err := uc.ExpireHolds(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/entity"
+	"time"
+)
+
+const (
+	// idempotencyTTL сколько хранится сохраненный ответ
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyLease сколько ключ остается занятым без сохраненного ответа. Пока запрос
+	// выполняется, middleware продлевает аренду через RenewIdempotentRequest; если процесс
+	// упал до сохранения ответа, повтор получает 409 только до истечения аренды.
+	idempotencyLease = 2 * time.Minute
+)
+
+// BeginIdempotentRequest занимает ключ под новый запрос. Если запрос с этим ключом
+// уже выполнен, возвращает сохраненный ответ для повтора.
+// Если хранилище ключей не подключено, каждый запрос считается новым.
+func (uc *UserUseCase) BeginIdempotentRequest(ctx context.Context, k entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	if uc.idempotency == nil {
+		return nil, nil
+	}
+	// вторая попытка нужна, если запись истекла между захватом и чтением
+	for attempt := 0; attempt < 2; attempt++ {
+		claimed, err := uc.idempotency.ClaimIdempotencyKey(ctx, k, idempotencyLease)
+		if err != nil {
+			return nil, fmt.Errorf("GopherMartUseCase - BeginIdempotentRequest: %w", err)
+		}
+		if claimed {
+			return nil, nil
+		}
+		stored, err := uc.idempotency.GetIdempotencyKey(ctx, k.UserID, k.Key)
+		if err != nil {
+			return nil, fmt.Errorf("GopherMartUseCase - BeginIdempotentRequest: %w", err)
+		}
+		if stored == nil {
+			continue
+		}
+		if stored.Fingerprint != k.Fingerprint {
+			return nil, entity.ErrIdempotencyKeyReused
+		}
+		if stored.State != entity.IdempotencyCompleted {
+			return nil, entity.ErrIdempotencyConflict
+		}
+		return stored, nil
+	}
+	return nil, entity.ErrIdempotencyConflict
+}
+
+// CompleteIdempotentRequest сохраняет ответ на запрос с ключом
+func (uc *UserUseCase) CompleteIdempotentRequest(ctx context.Context, k entity.IdempotencyKey) error {
+	if uc.idempotency == nil {
+		return nil
+	}
+	if err := uc.idempotency.CompleteIdempotencyKey(ctx, k, idempotencyTTL); err != nil {
+		return fmt.Errorf("GopherMartUseCase - CompleteIdempotentRequest: %w", err)
+	}
+	return nil
+}
+
+// RenewIdempotentRequest продлевает аренду ключа, пока запрос еще выполняется
+func (uc *UserUseCase) RenewIdempotentRequest(ctx context.Context, k entity.IdempotencyKey) error {
+	if uc.idempotency == nil {
+		return nil
+	}
+	if err := uc.idempotency.RenewIdempotencyKey(ctx, k, idempotencyLease); err != nil {
+		return fmt.Errorf("GopherMartUseCase - RenewIdempotentRequest: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotentRequest освобождает ключ, если ответ сохранять нельзя
+func (uc *UserUseCase) ReleaseIdempotentRequest(ctx context.Context, userID uint, key string) error {
+	if uc.idempotency == nil {
+		return nil
+	}
+	if err := uc.idempotency.DeleteIdempotencyKey(ctx, userID, key); err != nil {
+		return fmt.Errorf("GopherMartUseCase - ReleaseIdempotentRequest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupIdempotencyUseCase(t *testing.T) (*UserUseCase, *mocks.MockIdempotencyRepository) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	keys := mocks.NewMockIdempotencyRepository(ctrl)
+	uc := NewGopherMart(
+		mocks.NewMockRepository(ctrl),
+		mocks.NewMockBalanceUseCase(ctrl),
+		mocks.NewMockOrderUseCase(ctrl),
+		mocks.NewMockAuthUseCase(ctrl),
+		log,
+		WithIdempotency(keys))
+	return uc, keys
+}
+
+func TestBeginIdempotentRequest(t *testing.T) {
+	ctx := context.Background()
+	req := entity.IdempotencyKey{UserID: 7, Key: "k-1", Fingerprint: "abc"}
+
+	t.Run("new key is claimed", func(t *testing.T) {
+		uc, keys := setupIdempotencyUseCase(t)
+		keys.EXPECT().ClaimIdempotencyKey(ctx, req, idempotencyLease).Return(true, nil)
+
+		stored, err := uc.BeginIdempotentRequest(ctx, req)
+		require.NoError(t, err)
+		assert.Nil(t, stored)
+	})
+
+	t.Run("completed key is replayed", func(t *testing.T) {
+		uc, keys := setupIdempotencyUseCase(t)
+		completed := &entity.IdempotencyKey{UserID: 7, Key: "k-1", Fingerprint: "abc",
+			State: entity.IdempotencyCompleted, StatusCode: 200, Body: []byte(`{}`)}
+		keys.EXPECT().ClaimIdempotencyKey(ctx, req, idempotencyLease).Return(false, nil)
+		keys.EXPECT().GetIdempotencyKey(ctx, uint(7), "k-1").Return(completed, nil)
+
+		stored, err := uc.BeginIdempotentRequest(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, completed, stored)
+	})
+
+	t.Run("different payload is rejected", func(t *testing.T) {
+		uc, keys := setupIdempotencyUseCase(t)
+		keys.EXPECT().ClaimIdempotencyKey(ctx, req, idempotencyLease).Return(false, nil)
+		keys.EXPECT().GetIdempotencyKey(ctx, uint(7), "k-1").Return(&entity.IdempotencyKey{
+			Fingerprint: "other", State: entity.IdempotencyCompleted}, nil)
+
+		_, err := uc.BeginIdempotentRequest(ctx, req)
+		assert.ErrorIs(t, err, entity.ErrIdempotencyKeyReused)
+	})
+
+	t.Run("key in progress", func(t *testing.T) {
+		uc, keys := setupIdempotencyUseCase(t)
+		keys.EXPECT().ClaimIdempotencyKey(ctx, req, idempotencyLease).Return(false, nil)
+		keys.EXPECT().GetIdempotencyKey(ctx, uint(7), "k-1").Return(&entity.IdempotencyKey{
+			Fingerprint: "abc", State: entity.IdempotencyInProgress}, nil)
+
+		_, err := uc.BeginIdempotentRequest(ctx, req)
+		assert.ErrorIs(t, err, entity.ErrIdempotencyConflict)
+	})
+
+	t.Run("key expired between claim and read", func(t *testing.T) {
+		uc, keys := setupIdempotencyUseCase(t)
+		gomock.InOrder(
+			keys.EXPECT().ClaimIdempotencyKey(ctx, req, idempotencyLease).Return(false, nil),
+			keys.EXPECT().GetIdempotencyKey(ctx, uint(7), "k-1").Return(nil, nil),
+			keys.EXPECT().ClaimIdempotencyKey(ctx, req, idempotencyLease).Return(true, nil),
+		)
+
+		stored, err := uc.BeginIdempotentRequest(ctx, req)
+		require.NoError(t, err)
+		assert.Nil(t, stored)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		uc, keys := setupIdempotencyUseCase(t)
+		keys.EXPECT().ClaimIdempotencyKey(ctx, req, idempotencyLease).Return(false, errors.New("database error"))
+
+		_, err := uc.BeginIdempotentRequest(ctx, req)
+		assert.Error(t, err)
+	})
+
+	t.Run("store is not configured", func(t *testing.T) {
+		uc, _ := setupLedgerUseCase(t)
+
+		stored, err := uc.BeginIdempotentRequest(ctx, req)
+		require.NoError(t, err)
+		assert.Nil(t, stored)
+	})
+}
+
+func TestRenewIdempotentRequest(t *testing.T) {
+	ctx := context.Background()
+	req := entity.IdempotencyKey{UserID: 7, Key: "k-1", Fingerprint: "abc"}
+
+	t.Run("lease is extended", func(t *testing.T) {
+		uc, keys := setupIdempotencyUseCase(t)
+		keys.EXPECT().RenewIdempotencyKey(ctx, req, idempotencyLease).Return(nil)
+
+		require.NoError(t, uc.RenewIdempotentRequest(ctx, req))
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		uc, keys := setupIdempotencyUseCase(t)
+		expectedErr := errors.New("database error")
+		keys.EXPECT().RenewIdempotencyKey(ctx, req, idempotencyLease).Return(expectedErr)
+
+		assert.ErrorIs(t, uc.RenewIdempotentRequest(ctx, req), expectedErr)
+	})
+
+	t.Run("lease is shorter than stored responses", func(t *testing.T) {
+		assert.Less(t, idempotencyLease, idempotencyTTL)
+	})
+}
@@ -2,7 +2,9 @@ package usecase
 
 import (
 	"context"
+	"go-loyalty-system/internal/audit"
 	"go-loyalty-system/internal/entity"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -13,21 +15,66 @@ type (
 		GetUsers(ctx context.Context) ([]entity.User, error)
 		GetUserByLogin(ctx context.Context, u entity.User) (*entity.User, error)
 		GetUserByEmail(ctx context.Context, u entity.User) (*entity.User, error)
+		GetUserByID(ctx context.Context, userID uint) (*entity.User, error)
 		RegisterUser(ctx context.Context, u entity.User) error
+		AuthenticateUser(ctx context.Context, login, password string) (*entity.User, error)
+		ResetPassword(ctx context.Context, r entity.PasswordReset) error
 		CreateToken(ctx context.Context, t *entity.Token) error
-		GetUserOrders(ctx context.Context, userID uint) ([]entity.OrderResponse, error)
-		GetUserWithdrawals(ctx context.Context, userID uint) ([]entity.Withdrawal, error)
+		ValidateAccessToken(ctx context.Context, tokenID uuid.UUID) error
+		RotateRefreshToken(ctx context.Context, tokenID uuid.UUID) (*entity.Token, error)
+		RevokeTokenFamily(ctx context.Context, familyID uuid.UUID) error
+		RevokeUserTokens(ctx context.Context, userID uint) error
+		GetUserOrders(ctx context.Context, userID uint, f entity.ListFilter) (*entity.OrderPage, error)
+		GetUserWithdrawals(ctx context.Context, userID uint, f entity.ListFilter) (*entity.WithdrawalPage, error)
+		SubscribeOrderEvents(userID uint) (<-chan struct{}, func(), error)
+		GetOrderEvents(ctx context.Context, userID uint, afterID int64) ([]entity.OrderEvent, error)
+		GetLastOrderEventID(ctx context.Context, userID uint) (int64, error)
 		GetUnprocessedOrders(ctx context.Context) ([]string, error)
 		SetOrders(ctx context.Context, userID uint, o entity.Order) error
-		SaveAccrual(ctx context.Context, orderNumber, status string, accrual float32) error
+		GetOrderGoods(ctx context.Context, orderNumber string) ([]entity.Product, error)
+		SaveAccrual(ctx context.Context, orderNumber, status string, accrual entity.Points) error
 		WithdrawBalance(ctx context.Context, withdrawal entity.Withdrawal) error
+		AdminReverseWithdrawal(ctx context.Context, actorID uint, r entity.WithdrawalReversal) (*entity.Withdrawal, error)
+		MerchantReverseWithdrawal(ctx context.Context, merchantID uint, r entity.WithdrawalReversal) (*entity.Withdrawal, error)
+		HoldBalance(ctx context.Context, userID uint, r entity.HoldRequest) (*entity.Hold, error)
+		CaptureHold(ctx context.Context, userID uint, holdID int64, r entity.HoldCapture) (*entity.Hold, error)
+		ReleaseHold(ctx context.Context, userID uint, holdID int64) (*entity.Hold, error)
+		TransferPoints(ctx context.Context, senderID uint, r entity.TransferRequest) (*entity.Transfer, error)
+		GetUserTransfers(ctx context.Context, userID uint, f entity.ListFilter) (*entity.TransferPage, error)
+		VerifyUserBalance(ctx context.Context, userID uint, repair bool) (*entity.BalanceCheck, error)
+		AdjustUserBalance(ctx context.Context, userID uint, amount entity.Points, reason string) error
+		GetLedgerEntries(ctx context.Context, userID uint) ([]entity.LedgerEntry, error)
+		GetBalanceHistory(ctx context.Context, userID uint, f entity.ListFilter) (*entity.BalanceHistoryPage, error)
+		ExportStatement(ctx context.Context, userID uint, p entity.StatementPeriod, w entity.StatementWriter) error
+		GetLoyaltyStats(ctx context.Context) (*entity.LoyaltyStats, error)
+		SearchUsers(ctx context.Context, actorID uint, query string) ([]entity.User, error)
+		ViewUserOrders(ctx context.Context, actorID, userID uint, f entity.ListFilter) (*entity.OrderPage, error)
+		ViewUserWithdrawals(ctx context.Context, actorID, userID uint, f entity.ListFilter) (*entity.WithdrawalPage, error)
+		ViewBalanceHistory(ctx context.Context, actorID, userID uint) (*entity.BalanceHistory, error)
+		AdminAdjustBalance(ctx context.Context, actorID, userID uint, adj entity.BalanceAdjustment) error
+		LockUser(ctx context.Context, actorID, userID uint, reason string) error
+		UnlockUser(ctx context.Context, actorID, userID uint, reason string) error
+		ForceLogout(ctx context.Context, actorID, userID uint) error
+		GetAdminAudit(ctx context.Context, actorID, userID uint) ([]entity.AdminAuditEntry, error)
+		GetAuditEvents(ctx context.Context, actorID uint, f audit.Filter) (*audit.Page, error)
+		BeginIdempotentRequest(ctx context.Context, k entity.IdempotencyKey) (*entity.IdempotencyKey, error)
+		CompleteIdempotentRequest(ctx context.Context, k entity.IdempotencyKey) error
+		RenewIdempotentRequest(ctx context.Context, k entity.IdempotencyKey) error
+		ReleaseIdempotentRequest(ctx context.Context, userID uint, key string) error
+		EnqueueAccrual(ctx context.Context, orderNumber string) error
+		ClaimAccrualJobs(ctx context.Context, workerID string, limit int, lease time.Duration) ([]entity.AccrualJob, error)
+		CompleteAccrualJob(ctx context.Context, jobID int64) error
+		RetryAccrualJob(ctx context.Context, jobID int64, delay time.Duration, lastErr string) error
+		PostponeAccrualJob(ctx context.Context, jobID int64, delay time.Duration) error
+		FailAccrualJob(ctx context.Context, jobID int64, lastErr string) error
+		SetAccrualJobRegistered(ctx context.Context, jobID int64, registered bool) error
 	}
 )
 
 //go:generate mockgen -source=interfaces.go -destination=./repo/mocks/mock_test_entity.go -package=mocks
 type TestEntity interface {
-	AddOrder(orderNumber string)
-	GenerateToken(user *entity.User) (string, error)
+	AddOrder(ctx context.Context, orderNumber string)
+	IssueTokens(ctx context.Context, user *entity.User) (*entity.TokenPair, error)
 	CreateToken(ctx context.Context, t *entity.Token) error
-	PersistToken(user *entity.User, tokenID uuid.UUID) error
+	PersistToken(ctx context.Context, t *entity.Token) error
 }
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/entity"
+
+	"go.uber.org/zap"
+)
+
+// VerifyUserBalance сверяет сохраненный баланс пользователя с журналом проводок.
+// Журнал — источник истины: при расхождении и repair=true проекция пересобирается.
+func (uc *UserUseCase) VerifyUserBalance(ctx context.Context, userID uint, repair bool) (*entity.BalanceCheck, error) {
+	ledger, err := uc.balance.GetLedgerBalance(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyUserBalance - ledger: %w", err)
+	}
+	projection, err := uc.balance.GetBalance(ctx, fmt.Sprint(userID))
+	if err != nil {
+		return nil, fmt.Errorf("VerifyUserBalance - projection: %w", err)
+	}
+
+	check := &entity.BalanceCheck{
+		UserID:     userID,
+		Ledger:     *ledger,
+		Projection: *projection,
+		Consistent: *ledger == *projection,
+	}
+	if check.Consistent {
+		return check, nil
+	}
+
+	uc.Logger.WarnCtx(ctx, "balance projection differs from ledger",
+		zap.Uint("user_id", userID),
+		zap.Stringer("ledger_current", ledger.Current),
+		zap.Stringer("projection_current", projection.Current),
+		zap.Stringer("ledger_withdrawn", ledger.Withdrawn),
+		zap.Stringer("projection_withdrawn", projection.Withdrawn))
+	if !repair {
+		return check, nil
+	}
+
+	if err := uc.balance.RebuildBalance(ctx, userID); err != nil {
+		return nil, fmt.Errorf("VerifyUserBalance - rebuild: %w", err)
+	}
+	check.Repaired = true
+	return check, nil
+}
+
+// AdjustUserBalance ручная корректировка баланса проводкой ADJUSTMENT.
+// Начисление заводит партию, списание расходует партии, поэтому вызывать
+// ее нужно в транзакции вместе с блокировкой баланса.
+func (uc *UserUseCase) AdjustUserBalance(ctx context.Context, userID uint, amount entity.Points, reason string) error {
+	if amount.IsZero() {
+		return fmt.Errorf("AdjustUserBalance: %w", entity.ErrInvalidAdjustment)
+	}
+	if err := uc.balance.AdjustBalance(ctx, userID, amount, reason); err != nil {
+		return fmt.Errorf("AdjustUserBalance: %w", err)
+	}
+	if amount.IsPositive() {
+		return uc.creditLot(ctx, userID, "", amount)
+	}
+	return uc.debitLots(ctx, userID, amount.Neg())
+}
+
+func (uc *UserUseCase) GetLedgerEntries(ctx context.Context, userID uint) ([]entity.LedgerEntry, error) {
+	entries, err := uc.balance.GetLedgerEntries(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("GetLedgerEntries: %w", err)
+	}
+	return entries, nil
+}
+
+// GetBalanceHistory страница движений баланса пользователя: начисления, списания,
+// корректировки, возвраты и прочие проводки по его счету с балансом после каждой
+func (uc *UserUseCase) GetBalanceHistory(ctx context.Context,
+	userID uint,
+	f entity.ListFilter) (*entity.BalanceHistoryPage, error) {
+	f, err := f.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Statuses) > 0 {
+		return nil, fmt.Errorf("%w: balance history has no status", entity.ErrInvalidListFilter)
+	}
+	entries, err := uc.balance.GetBalanceHistory(ctx, userID, f)
+	if err != nil {
+		uc.Logger.ErrorCtx(ctx, "GetBalanceHistory", zap.Error(err))
+		return nil, fmt.Errorf("GetBalanceHistory: %w", err)
+	}
+	page := &entity.BalanceHistoryPage{}
+	page.Entries, page.NextCursor = trimPage(entries, f.Limit, func(e entity.BalanceHistoryEntry) entity.PageCursor {
+		return entity.PageCursor{At: e.CreatedAt, ID: e.ID}
+	})
+	return page, nil
+}
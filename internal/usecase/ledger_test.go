@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupLedgerUseCase(t *testing.T) (*UserUseCase, *mocks.MockBalanceUseCase) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	balanceRepo := mocks.NewMockBalanceUseCase(ctrl)
+	uc := NewGopherMart(
+		mocks.NewMockRepository(ctrl),
+		balanceRepo,
+		mocks.NewMockOrderUseCase(ctrl),
+		mocks.NewMockAuthUseCase(ctrl),
+		log)
+	return uc, balanceRepo
+}
+
+func TestVerifyUserBalance(t *testing.T) {
+	ctx := context.Background()
+	userID := uint(7)
+	ledger := &entity.Balance{Current: entity.NewPoints(300, 50), Withdrawn: entity.NewPoints(200, 0)}
+
+	t.Run("projection matches ledger", func(t *testing.T) {
+		uc, balanceRepo := setupLedgerUseCase(t)
+		balanceRepo.EXPECT().GetLedgerBalance(ctx, userID).Return(ledger, nil)
+		balanceRepo.EXPECT().GetBalance(ctx, "7").Return(&entity.Balance{
+			Current:   entity.NewPoints(300, 50),
+			Withdrawn: entity.NewPoints(200, 0),
+		}, nil)
+
+		check, err := uc.VerifyUserBalance(ctx, userID, true)
+		require.NoError(t, err)
+		assert.True(t, check.Consistent)
+		assert.False(t, check.Repaired)
+	})
+
+	t.Run("drift is reported without repair", func(t *testing.T) {
+		uc, balanceRepo := setupLedgerUseCase(t)
+		balanceRepo.EXPECT().GetLedgerBalance(ctx, userID).Return(ledger, nil)
+		balanceRepo.EXPECT().GetBalance(ctx, "7").Return(&entity.Balance{
+			Current:   entity.NewPoints(600, 0),
+			Withdrawn: entity.NewPoints(200, 0),
+		}, nil)
+
+		check, err := uc.VerifyUserBalance(ctx, userID, false)
+		require.NoError(t, err)
+		assert.False(t, check.Consistent)
+		assert.False(t, check.Repaired)
+		assert.Equal(t, *ledger, check.Ledger)
+	})
+
+	t.Run("drift is repaired from ledger", func(t *testing.T) {
+		uc, balanceRepo := setupLedgerUseCase(t)
+		balanceRepo.EXPECT().GetLedgerBalance(ctx, userID).Return(ledger, nil)
+		balanceRepo.EXPECT().GetBalance(ctx, "7").Return(&entity.Balance{}, nil)
+		balanceRepo.EXPECT().RebuildBalance(ctx, userID).Return(nil)
+
+		check, err := uc.VerifyUserBalance(ctx, userID, true)
+		require.NoError(t, err)
+		assert.False(t, check.Consistent)
+		assert.True(t, check.Repaired)
+	})
+
+	t.Run("ledger error", func(t *testing.T) {
+		uc, balanceRepo := setupLedgerUseCase(t)
+		balanceRepo.EXPECT().GetLedgerBalance(ctx, userID).Return(nil, errors.New("database error"))
+
+		_, err := uc.VerifyUserBalance(ctx, userID, true)
+		assert.Error(t, err)
+	})
+}
+
+func TestAdjustUserBalance(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("adjustment is posted", func(t *testing.T) {
+		uc, balanceRepo := setupLedgerUseCase(t)
+		balanceRepo.EXPECT().AdjustBalance(ctx, uint(7), entity.NewPoints(-10, 0), "goodwill reversal").Return(nil)
+
+		assert.NoError(t, uc.AdjustUserBalance(ctx, 7, entity.NewPoints(-10, 0), "goodwill reversal"))
+	})
+
+	t.Run("zero adjustment is rejected", func(t *testing.T) {
+		uc, _ := setupLedgerUseCase(t)
+
+		assert.ErrorIs(t, uc.AdjustUserBalance(ctx, 7, 0, "noop"), entity.ErrInvalidAdjustment)
+	})
+}
+
+func TestGetBalanceHistory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("entries keep the running balance and the extra row becomes the cursor", func(t *testing.T) {
+		uc, balanceRepo := setupLedgerUseCase(t)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		rows := []entity.BalanceHistoryEntry{
+			{ID: 9, Type: entity.LedgerWithdrawal, Amount: entity.NewPoints(-40, 0), OrderNumber: "2377225624",
+				Balance: entity.NewPoints(60, 0), CreatedAt: now},
+			{ID: 4, Type: entity.LedgerAccrual, Amount: entity.NewPoints(100, 0), OrderNumber: "12345678903",
+				Balance: entity.NewPoints(100, 0), CreatedAt: now.Add(-time.Hour)},
+		}
+		balanceRepo.EXPECT().GetBalanceHistory(ctx, uint(7), entity.ListFilter{Limit: 1, Sort: entity.SortDesc,
+			Statuses: []entity.OrderStatus{}}).Return(rows, nil)
+
+		page, err := uc.GetBalanceHistory(ctx, 7, entity.ListFilter{Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, rows[:1], page.Entries)
+		assert.Equal(t, entity.PageCursor{At: now, ID: 9}.Encode(), page.NextCursor)
+	})
+
+	t.Run("history cannot be filtered by status", func(t *testing.T) {
+		uc, _ := setupLedgerUseCase(t)
+
+		_, err := uc.GetBalanceHistory(ctx, 7, entity.ListFilter{Statuses: []entity.OrderStatus{entity.OrderStatusNew}})
+		assert.ErrorIs(t, err, entity.ErrInvalidListFilter)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		uc, balanceRepo := setupLedgerUseCase(t)
+		balanceRepo.EXPECT().GetBalanceHistory(ctx, uint(7), gomock.Any()).Return(nil, errors.New("db down"))
+
+		_, err := uc.GetBalanceHistory(ctx, 7, entity.ListFilter{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserWithdrawalsPage(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (*UserUseCase, *mocks.MockBalanceUseCase) {
+		ctrl := gomock.NewController(t)
+		log, _ := logging.NewZapLogger(1)
+		balance := mocks.NewMockBalanceUseCase(ctrl)
+		return NewGopherMart(mocks.NewMockRepository(ctrl), balance, mocks.NewMockOrderUseCase(ctrl),
+			mocks.NewMockAuthUseCase(ctrl), log), balance
+	}
+
+	t.Run("extra row becomes the next cursor", func(t *testing.T) {
+		uc, balance := setup(t)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		rows := []entity.Withdrawal{
+			{ID: 1, CreatedAt: now},
+			{ID: 2, CreatedAt: now.Add(time.Second)},
+			{ID: 3, CreatedAt: now.Add(2 * time.Second)},
+		}
+		balance.EXPECT().GetUserWithdrawals(ctx, uint(7), entity.ListFilter{Limit: 2, Sort: entity.SortAsc,
+			Statuses: []entity.OrderStatus{}}).Return(rows, nil)
+
+		page, err := uc.GetUserWithdrawals(ctx, 7, entity.ListFilter{Limit: 2, Sort: entity.SortAsc})
+		require.NoError(t, err)
+		assert.Equal(t, rows[:2], page.Withdrawals)
+		assert.Equal(t, entity.PageCursor{At: rows[1].CreatedAt, ID: 2}.Encode(), page.NextCursor)
+	})
+
+	t.Run("withdrawals cannot be filtered by status", func(t *testing.T) {
+		uc, _ := setup(t)
+
+		_, err := uc.GetUserWithdrawals(ctx, 7, entity.ListFilter{Statuses: []entity.OrderStatus{entity.OrderStatusNew}})
+		assert.ErrorIs(t, err, entity.ErrInvalidListFilter)
+	})
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"go-loyalty-system/internal/audit"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Option подключает к UserUseCase необязательные хранилища
+type Option func(*UserUseCase)
+
+func WithIdempotency(r repo.IdempotencyRepository) Option {
+	return func(uc *UserUseCase) {
+		uc.idempotency = r
+	}
+}
+
+func WithTransactor(t repo.Transactor) Option {
+	return func(uc *UserUseCase) {
+		uc.tx = t
+	}
+}
+
+// WithPasswordCost задает стоимость bcrypt. Значение вне допустимого диапазона игнорируется.
+func WithPasswordCost(cost int) Option {
+	return func(uc *UserUseCase) {
+		if cost >= bcrypt.MinCost && cost <= bcrypt.MaxCost {
+			uc.passwordCost = cost
+		}
+	}
+}
+
+func WithTokens(r repo.TokenRepository) Option {
+	return func(uc *UserUseCase) {
+		uc.tokens = r
+	}
+}
+
+// WithAdminAudit подключает журнал действий сотрудников
+func WithAdminAudit(r repo.AdminAuditRepository) Option {
+	return func(uc *UserUseCase) {
+		uc.adminAudit = r
+	}
+}
+
+// WithOrderEvents подключает поток событий заказов
+func WithOrderEvents(r repo.OrderEventRepository) Option {
+	return func(uc *UserUseCase) {
+		uc.orderEvents = r
+		uc.eventHub = newOrderEventHub()
+	}
+}
+
+// WithStats подключает сводные показатели для метрик
+func WithStats(r repo.StatsRepository) Option {
+	return func(uc *UserUseCase) {
+		uc.stats = r
+	}
+}
+
+// WithAudit подключает журнал событий безопасности и движения баллов
+func WithAudit(r *audit.Recorder) Option {
+	return func(uc *UserUseCase) {
+		uc.auditLog = r
+	}
+}
+
+// WithPointExpiry подключает партии баллов и политику их сгорания
+func WithPointExpiry(r repo.PointLotRepository, p entity.ExpiryPolicy) Option {
+	return func(uc *UserUseCase) {
+		uc.lots = r
+		uc.expiry = p
+	}
+}
+
+// WithHolds подключает резервы баллов; ttl — через сколько неподтвержденный резерв отменяется
+func WithHolds(r repo.HoldRepository, ttl time.Duration) Option {
+	return func(uc *UserUseCase) {
+		uc.holds = r
+		uc.holdTTL = ttl
+	}
+}
+
+// WithTransferLimits задает суточные лимиты переводов между пользователями
+func WithTransferLimits(l entity.TransferLimits) Option {
+	return func(uc *UserUseCase) {
+		uc.transferLimits = l
+	}
+}
+
+// WithTiers подключает уровни участия и множители начислений на них
+func WithTiers(r repo.TierRepository, p entity.TierPolicy) Option {
+	return func(uc *UserUseCase) {
+		uc.tiers = r
+		uc.tierPolicy = p
+	}
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/entity"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// orderEventsBatch сколько событий читается из базы за один запрос
+	orderEventsBatch = 100
+	// orderEventsReconnectDelay пауза перед повторным LISTEN после обрыва соединения
+	orderEventsReconnectDelay = 2 * time.Second
+)
+
+// orderEventHub будит подписчиков этого экземпляра по уведомлениям LISTEN/NOTIFY.
+// Сигнал не несет данных: подписчик сам дочитывает события после последнего
+// отданного id, поэтому слитые или потерянные сигналы ничего не теряют.
+type orderEventHub struct {
+	mu   sync.Mutex
+	subs map[uint]map[chan struct{}]struct{}
+}
+
+func newOrderEventHub() *orderEventHub {
+	return &orderEventHub{subs: make(map[uint]map[chan struct{}]struct{})}
+}
+
+func (h *orderEventHub) subscribe(userID uint) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[chan struct{}]struct{})
+	}
+	h.subs[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[userID], ch)
+		if len(h.subs[userID]) == 0 {
+			delete(h.subs, userID)
+		}
+	}
+}
+
+func (h *orderEventHub) publish(userID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[userID] {
+		wake(ch)
+	}
+}
+
+// publishAll будит всех подписчиков, например после переподключения,
+// когда часть уведомлений могла пройти мимо
+func (h *orderEventHub) publishAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for ch := range subs {
+			wake(ch)
+		}
+	}
+}
+
+func wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// ListenOrderEvents раздает уведомления о событиях заказов подписчикам,
+// пока не отменен ctx. Обрыв соединения с базой переживается переподключением.
+func (uc *UserUseCase) ListenOrderEvents(ctx context.Context) {
+	if uc.orderEvents == nil {
+		return
+	}
+	for {
+		err := uc.orderEvents.ListenOrderEvents(ctx, uc.eventHub.publish)
+		if ctx.Err() != nil {
+			return
+		}
+		uc.Logger.ErrorCtx(ctx, "ListenOrderEvents - listener stopped, reconnecting", zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(orderEventsReconnectDelay):
+		}
+		uc.eventHub.publishAll()
+	}
+}
+
+// SubscribeOrderEvents подписывает на сигналы о новых событиях пользователя.
+// Подписываться нужно до чтения событий, иначе событие между чтением
+// и подпиской останется без сигнала.
+func (uc *UserUseCase) SubscribeOrderEvents(userID uint) (<-chan struct{}, func(), error) {
+	if uc.orderEvents == nil {
+		return nil, nil, entity.ErrEventsUnavailable
+	}
+	ch, cancel := uc.eventHub.subscribe(userID)
+	return ch, cancel, nil
+}
+
+// GetOrderEvents события пользователя после afterID
+func (uc *UserUseCase) GetOrderEvents(ctx context.Context, userID uint, afterID int64) ([]entity.OrderEvent, error) {
+	if uc.orderEvents == nil {
+		return nil, entity.ErrEventsUnavailable
+	}
+	events, err := uc.orderEvents.GetOrderEvents(ctx, userID, afterID, orderEventsBatch)
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - GetOrderEvents: %w", err)
+	}
+	return events, nil
+}
+
+// GetLastOrderEventID id последнего события пользователя: с него начинается
+// поток для клиента, который подключается без Last-Event-ID
+func (uc *UserUseCase) GetLastOrderEventID(ctx context.Context, userID uint) (int64, error) {
+	if uc.orderEvents == nil {
+		return 0, entity.ErrEventsUnavailable
+	}
+	id, err := uc.orderEvents.GetLastOrderEventID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("GopherMartUseCase - GetLastOrderEventID: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderEvents(t *testing.T) {
+	setup := func(t *testing.T) (*UserUseCase, *mocks.MockOrderEventRepository) {
+		ctrl := gomock.NewController(t)
+		log, _ := logging.NewZapLogger(1)
+		events := mocks.NewMockOrderEventRepository(ctrl)
+		uc := NewGopherMart(mocks.NewMockRepository(ctrl), mocks.NewMockBalanceUseCase(ctrl),
+			mocks.NewMockOrderUseCase(ctrl), mocks.NewMockAuthUseCase(ctrl), log, WithOrderEvents(events))
+		return uc, events
+	}
+
+	t.Run("notification wakes only the owner", func(t *testing.T) {
+		uc, events := setup(t)
+		owner, unsubscribeOwner, err := uc.SubscribeOrderEvents(7)
+		require.NoError(t, err)
+		defer unsubscribeOwner()
+		other, unsubscribeOther, err := uc.SubscribeOrderEvents(8)
+		require.NoError(t, err)
+		defer unsubscribeOther()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		events.EXPECT().ListenOrderEvents(ctx, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, notify func(uint)) error {
+				notify(7)
+				notify(7)
+				cancel()
+				return ctx.Err()
+			})
+		uc.ListenOrderEvents(ctx)
+
+		assert.Len(t, owner, 1, "repeated notifications collapse into one wakeup")
+		assert.Empty(t, other)
+	})
+
+	t.Run("unsubscribed channel is not woken", func(t *testing.T) {
+		uc, _ := setup(t)
+		ch, unsubscribe, err := uc.SubscribeOrderEvents(7)
+		require.NoError(t, err)
+		unsubscribe()
+
+		uc.eventHub.publish(7)
+		assert.Empty(t, ch)
+		assert.Empty(t, uc.eventHub.subs)
+	})
+
+	t.Run("events are read after the given id", func(t *testing.T) {
+		uc, events := setup(t)
+		ctx := context.Background()
+		want := []entity.OrderEvent{{ID: 6, Number: "12345678903", Status: "PROCESSED"}}
+		events.EXPECT().GetOrderEvents(ctx, uint(7), int64(5), orderEventsBatch).Return(want, nil)
+
+		got, err := uc.GetOrderEvents(ctx, 7, 5)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("stream is unavailable without a store", func(t *testing.T) {
+		log, _ := logging.NewZapLogger(1)
+		uc := NewGopherMart(nil, nil, nil, nil, log)
+
+		_, _, err := uc.SubscribeOrderEvents(7)
+		assert.ErrorIs(t, err, entity.ErrEventsUnavailable)
+	})
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"go-loyalty-system/internal/audit"
+	"go-loyalty-system/internal/entity"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// RegisterUser хеширует пароль и сохраняет пользователя
+func (uc *UserUseCase) RegisterUser(ctx context.Context, u entity.User) error {
+	hash, err := uc.hashPassword(u.Password)
+	if err != nil {
+		return fmt.Errorf("GopherMartUseCase - RegisterUser: %w", err)
+	}
+	u.Password = hash
+	if err := uc.user.RegisterUser(ctx, u); err != nil {
+		return fmt.Errorf("GopherMartUseCase - RegisterUser: %w", err)
+	}
+	uc.auditRegistration(ctx, u.Login)
+	return nil
+}
+
+// auditRegistration записывает регистрацию; ID нового пользователя читается заново,
+// потому что хранилище его не возвращает
+func (uc *UserUseCase) auditRegistration(ctx context.Context, login string) {
+	if uc.auditLog == nil {
+		return
+	}
+	user, err := uc.user.GetUserByLogin(ctx, entity.User{Login: login})
+	if err != nil {
+		uc.Logger.ErrorCtx(ctx, "audit event lost", zap.String("type", string(audit.UserRegistered)), zap.Error(err))
+		return
+	}
+	uc.recordSecurityAudit(ctx, audit.Event{
+		Type:    audit.UserRegistered,
+		ActorID: &user.ID,
+		UserID:  &user.ID,
+		Details: map[string]string{"login": login},
+	})
+}
+
+// AuthenticateUser проверяет логин и пароль. Если хеш посчитан с другой стоимостью,
+// пароль прозрачно перехешируется. Пользователь со старым паролем открытым текстом
+// получает ErrPasswordResetNeeded, только если пароль верный.
+func (uc *UserUseCase) AuthenticateUser(ctx context.Context, login, password string) (*entity.User, error) {
+	user, err := uc.user.GetUserByLogin(ctx, entity.User{Login: login})
+	if errors.Is(err, entity.ErrUserDoesNotExist) {
+		// сравнение с фиктивным хешем выравнивает время ответа для несуществующих логинов
+		_ = bcrypt.CompareHashAndPassword(uc.dummyHash(), []byte(password))
+		uc.auditLoginFailed(ctx, login, nil, loginUnknownUser)
+		return nil, entity.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - AuthenticateUser: %w", err)
+	}
+
+	if user.PasswordResetRequired {
+		if !legacyPasswordMatches(user.Password, password) {
+			uc.auditLoginFailed(ctx, login, &user.ID, loginInvalidPassword)
+			return nil, entity.ErrInvalidCredentials
+		}
+		uc.auditLoginFailed(ctx, login, &user.ID, loginResetRequired)
+		return nil, entity.ErrPasswordResetNeeded
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		uc.auditLoginFailed(ctx, login, &user.ID, loginInvalidPassword)
+		return nil, entity.ErrInvalidCredentials
+	}
+	// блокировка сообщается только после проверки пароля, чтобы не раскрывать учетные записи
+	if user.LockedAt != nil {
+		uc.auditLoginFailed(ctx, login, &user.ID, loginLocked)
+		return nil, entity.ErrUserLocked
+	}
+
+	if cost, err := bcrypt.Cost([]byte(user.Password)); err == nil && cost != uc.passwordCost {
+		uc.rehashPassword(ctx, user, password)
+	}
+	uc.recordSecurityAudit(ctx, audit.Event{
+		Type:    audit.LoginSucceeded,
+		ActorID: &user.ID,
+		UserID:  &user.ID,
+		Details: map[string]string{"login": login},
+	})
+	return user, nil
+}
+
+// ResetPassword меняет пароль после проверки текущего. Для пользователей со старым
+// паролем открытым текстом это единственный способ снова войти.
+func (uc *UserUseCase) ResetPassword(ctx context.Context, r entity.PasswordReset) error {
+	user, err := uc.user.GetUserByLogin(ctx, entity.User{Login: r.Login})
+	if errors.Is(err, entity.ErrUserDoesNotExist) {
+		_ = bcrypt.CompareHashAndPassword(uc.dummyHash(), []byte(r.Password))
+		return entity.ErrInvalidCredentials
+	}
+	if err != nil {
+		return fmt.Errorf("GopherMartUseCase - ResetPassword: %w", err)
+	}
+
+	if user.PasswordResetRequired {
+		if !legacyPasswordMatches(user.Password, r.Password) {
+			return entity.ErrInvalidCredentials
+		}
+	} else if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(r.Password)) != nil {
+		return entity.ErrInvalidCredentials
+	}
+
+	hash, err := uc.hashPassword(r.NewPassword)
+	if err != nil {
+		return fmt.Errorf("GopherMartUseCase - ResetPassword: %w", err)
+	}
+	if err := uc.user.UpdatePassword(ctx, user.ID, hash); err != nil {
+		return fmt.Errorf("GopherMartUseCase - ResetPassword: %w", err)
+	}
+	return nil
+}
+
+func (uc *UserUseCase) hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), uc.passwordCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", entity.ErrInvalidPassword
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// rehashPassword ошибка перехеширования не мешает входу, попробуем в следующий раз
+func (uc *UserUseCase) rehashPassword(ctx context.Context, user *entity.User, password string) {
+	hash, err := uc.hashPassword(password)
+	if err == nil {
+		err = uc.user.UpdatePassword(ctx, user.ID, hash)
+	}
+	if err != nil {
+		uc.Logger.WarnCtx(ctx, "AuthenticateUser - rehash password", zap.Uint("userID", user.ID), zap.Error(err))
+		return
+	}
+	user.Password = hash
+}
+
+func (uc *UserUseCase) dummyHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("gophermart"), uc.passwordCost)
+	})
+	return dummyHash
+}
+
+func legacyPasswordMatches(stored, password string) bool {
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+}
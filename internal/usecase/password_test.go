@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "piqQJ5SihA264dO324j132"
+
+func setupPasswordUseCase(t *testing.T, cost int) (*UserUseCase, *mocks.MockAuthUseCase) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	userRepo := mocks.NewMockAuthUseCase(ctrl)
+	uc := NewGopherMart(
+		mocks.NewMockRepository(ctrl),
+		mocks.NewMockBalanceUseCase(ctrl),
+		mocks.NewMockOrderUseCase(ctrl),
+		userRepo,
+		log,
+		WithPasswordCost(cost))
+	return uc, userRepo
+}
+
+func hashFor(t *testing.T, password string, cost int) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	require.NoError(t, err)
+	return string(hash)
+}
+
+func TestRegisterUserHashesPassword(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("password is stored as bcrypt hash with configured cost", func(t *testing.T) {
+		uc, userRepo := setupPasswordUseCase(t, bcrypt.MinCost)
+		userRepo.EXPECT().RegisterUser(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, u entity.User) error {
+			assert.NotEqual(t, testPassword, u.Password)
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(testPassword)))
+			cost, err := bcrypt.Cost([]byte(u.Password))
+			assert.NoError(t, err)
+			assert.Equal(t, bcrypt.MinCost, cost)
+			return nil
+		})
+
+		assert.NoError(t, uc.RegisterUser(ctx, entity.User{Login: "user", Password: testPassword}))
+	})
+
+	t.Run("duplicate login", func(t *testing.T) {
+		uc, userRepo := setupPasswordUseCase(t, bcrypt.MinCost)
+		userRepo.EXPECT().RegisterUser(ctx, gomock.Any()).Return(entity.ErrUserExists)
+
+		assert.ErrorIs(t, uc.RegisterUser(ctx, entity.User{Login: "user", Password: testPassword}), entity.ErrUserExists)
+	})
+
+	t.Run("password longer than bcrypt limit", func(t *testing.T) {
+		uc, _ := setupPasswordUseCase(t, bcrypt.MinCost)
+
+		err := uc.RegisterUser(ctx, entity.User{Login: "user", Password: strings.Repeat("p", 73)})
+		assert.ErrorIs(t, err, entity.ErrInvalidPassword)
+	})
+}
+
+func TestAuthenticateUser(t *testing.T) {
+	ctx := context.Background()
+	login := entity.User{Login: "user"}
+
+	t.Run("valid password", func(t *testing.T) {
+		uc, userRepo := setupPasswordUseCase(t, bcrypt.MinCost)
+		userRepo.EXPECT().GetUserByLogin(ctx, login).
+			Return(&entity.User{ID: 1, Login: "user", Password: hashFor(t, testPassword, bcrypt.MinCost)}, nil)
+
+		user, err := uc.AuthenticateUser(ctx, "user", testPassword)
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), user.ID)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		uc, userRepo := setupPasswordUseCase(t, bcrypt.MinCost)
+		userRepo.EXPECT().GetUserByLogin(ctx, login).
+			Return(&entity.User{ID: 1, Login: "user", Password: hashFor(t, testPassword, bcrypt.MinCost)}, nil)
+
+		_, err := uc.AuthenticateUser(ctx, "user", "wrong-password")
+		assert.ErrorIs(t, err, entity.ErrInvalidCredentials)
+	})
+
+	t.Run("unknown login", func(t *testing.T) {
+		uc, userRepo := setupPasswordUseCase(t, bcrypt.MinCost)
+		userRepo.EXPECT().GetUserByLogin(ctx, login).Return(nil, entity.ErrUserDoesNotExist)
+
+		_, err := uc.AuthenticateUser(ctx, "user", testPassword)
+		assert.ErrorIs(t, err, entity.ErrInvalidCredentials)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		uc, userRepo := setupPasswordUseCase(t, bcrypt.MinCost)
+		userRepo.EXPECT().GetUserByLogin(ctx, login).Return(nil, errors.New("database error"))
+
+		_, err := uc.AuthenticateUser(ctx, "user", testPassword)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, entity.ErrInvalidCredentials)
+	})
+
+	t.Run("hash with old cost is upgraded", func(t *testing.T) {
+		uc, userRepo := setupPasswordUseCase(t, bcrypt.MinCost+1)
+		userRepo.EXPECT().GetUserByLogin(ctx, login).
+			Return(&entity.User{ID: 1, Login: "user", Password: hashFor(t, testPassword, bcrypt.MinCost)}, nil)
+		userRepo.EXPECT().UpdatePassword(ctx, uint(1), gomock.Any()).DoAndReturn(func(_ context.Context, _ uint, hash string) error {
+			cost, err := bcrypt.Cost([]byte(hash))
+			assert.NoError(t, err)
+			assert.Equal(t, bcrypt.MinCost+1, cost)
+			return nil
+		})
+
+		_, err := uc.AuthenticateUser(ctx, "user", testPassword)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed rehash does not block login", func(t *testing.T) {
+		uc, userRepo := setupPasswordUseCase(t, bcrypt.MinCost+1)
+		userRepo.EXPECT().GetUserByLogin(ctx, login).
+			Return(&entity.User{ID: 1, Login: "user", Password: hashFor(t, testPassword, bcrypt.MinCost)}, nil)
+		userRepo.EXPECT().UpdatePassword(ctx, uint(1), gomock.Any()).Return(errors.New("database error"))
+
+		_, err := uc.AuthenticateUser(ctx, "user", testPassword)
+		assert.NoError(t, err)
+	})
+
+	t.Run("legacy plaintext password", func(t *testing.T) {
+		uc, userRepo := setupPasswordUseCase(t, bcrypt.MinCost)
+		legacy := &entity.User{ID: 1, Login: "user", Password: testPassword, PasswordResetRequired: true}
+		userRepo.EXPECT().GetUserByLogin(ctx, login).Return(legacy, nil).Times(2)
+
+		_, err := uc.AuthenticateUser(ctx, "user", testPassword)
+		assert.ErrorIs(t, err, entity.ErrPasswordResetNeeded)
+
+		_, err = uc.AuthenticateUser(ctx, "user", "wrong-password")
+		assert.ErrorIs(t, err, entity.ErrInvalidCredentials)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	login := entity.User{Login: "user"}
+	reset := entity.PasswordReset{Login: "user", Password: testPassword, NewPassword: "n3w-Passw0rd"}
+
+	t.Run("legacy account sets a hashed password", func(t *testing.T) {
+		uc, userRepo := setupPasswordUseCase(t, bcrypt.MinCost)
+		userRepo.EXPECT().GetUserByLogin(ctx, login).
+			Return(&entity.User{ID: 1, Login: "user", Password: testPassword, PasswordResetRequired: true}, nil)
+		userRepo.EXPECT().UpdatePassword(ctx, uint(1), gomock.Any()).DoAndReturn(func(_ context.Context, _ uint, hash string) error {
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("n3w-Passw0rd")))
+			return nil
+		})
+
+		assert.NoError(t, uc.ResetPassword(ctx, reset))
+	})
+
+	t.Run("hashed account changes password", func(t *testing.T) {
+		uc, userRepo := setupPasswordUseCase(t, bcrypt.MinCost)
+		userRepo.EXPECT().GetUserByLogin(ctx, login).
+			Return(&entity.User{ID: 1, Login: "user", Password: hashFor(t, testPassword, bcrypt.MinCost)}, nil)
+		userRepo.EXPECT().UpdatePassword(ctx, uint(1), gomock.Any()).Return(nil)
+
+		assert.NoError(t, uc.ResetPassword(ctx, reset))
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		uc, userRepo := setupPasswordUseCase(t, bcrypt.MinCost)
+		userRepo.EXPECT().GetUserByLogin(ctx, login).
+			Return(&entity.User{ID: 1, Login: "user", Password: "other", PasswordResetRequired: true}, nil)
+
+		assert.ErrorIs(t, uc.ResetPassword(ctx, reset), entity.ErrInvalidCredentials)
+	})
+}
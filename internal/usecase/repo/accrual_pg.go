@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"go-loyalty-system/internal/entity"
 	"go-loyalty-system/pkg/logging"
 	"go-loyalty-system/pkg/postgres"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -14,9 +16,16 @@ import (
 
 //go:generate mockgen -source=accrual_pg.go -destination=./mocks/mock_accrual.go -package=mocks
 type Repository interface {
-	SaveAccrual(ctx context.Context, orderNumber string, status string, accrual float32) error
+	SaveAccrual(ctx context.Context, orderNumber string, status string, accrual entity.Points) error
 	GetUnprocessedOrders(ctx context.Context) ([]string, error)
 	ExistOrderAccrual(ctx context.Context, orderNumber string) (bool, error)
+	EnqueueAccrualJob(ctx context.Context, orderNumber, traceParent string) error
+	ClaimAccrualJobs(ctx context.Context, workerID string, limit int, lease time.Duration) ([]entity.AccrualJob, error)
+	CompleteAccrualJob(ctx context.Context, jobID int64) error
+	RetryAccrualJob(ctx context.Context, jobID int64, delay time.Duration, lastErr string) error
+	PostponeAccrualJob(ctx context.Context, jobID int64, delay time.Duration) error
+	FailAccrualJob(ctx context.Context, jobID int64, lastErr string) error
+	SetAccrualJobRegistered(ctx context.Context, jobID int64, registered bool) error
 }
 
 func NewOrderAccrualRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
@@ -36,7 +45,7 @@ func (g *GopherMartRepo) ExistOrderAccrual(ctx context.Context, orderNumber stri
 		FROM accrual a
 		JOIN orders o ON o.id = a.order_id 
 		WHERE o.number = $1 )`
-	err := g.pg.Pool.QueryRow(ctx, queryExistOrderAccrual, orderNumber).Scan(&exists)
+	err := g.conn(ctx).QueryRow(ctx, queryExistOrderAccrual, orderNumber).Scan(&exists)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return false, nil
@@ -47,8 +56,8 @@ func (g *GopherMartRepo) ExistOrderAccrual(ctx context.Context, orderNumber stri
 }
 
 // SaveAccrual сохраняет информацию о начислении баллов
-func (g *GopherMartRepo) SaveAccrual(ctx context.Context, orderNumber, status string, accrual float32) error {
-	tx, err := g.pg.Pool.Begin(ctx)
+func (g *GopherMartRepo) SaveAccrual(ctx context.Context, orderNumber, status string, accrual entity.Points) error {
+	tx, err := g.conn(ctx).Begin(ctx)
 	if err != nil {
 		return g.logAndReturnError(ctx, "SaveAccrual - begin transaction", err)
 	}
@@ -56,22 +65,22 @@ func (g *GopherMartRepo) SaveAccrual(ctx context.Context, orderNumber, status st
 		_ = tx.Rollback(ctx)
 	}()
 
-	// Вставляем начисление
 	var orderID int
+	var userID uint
+	const queryOrder = `
+	SELECT id, user_id
+	FROM orders
+	WHERE number = $1`
+	err = tx.QueryRow(ctx, queryOrder, orderNumber).Scan(&orderID, &userID)
+	if err != nil {
+		return g.logAndReturnError(ctx, "SaveAccrual - get order", err)
+	}
+
+	// Вставляем начисление
 	const queryInsertAccrual = `
-	WITH order_data AS (
-		SELECT id, user_id 
-		FROM orders 
-		WHERE number = $1
-	)
 	INSERT INTO accrual (order_id, status_id, accrual)
-	SELECT 
-		od.id,
-		(SELECT id FROM accrual_statuses WHERE status = $2),
-		$3
-	FROM order_data od
-	RETURNING order_id`
-	err = tx.QueryRow(ctx, queryInsertAccrual, orderNumber, status, accrual).Scan(&orderID)
+	VALUES ($1, (SELECT id FROM accrual_statuses WHERE status = $2), $3)`
+	_, err = tx.Exec(ctx, queryInsertAccrual, orderID, status, accrual)
 	if err != nil {
 		return g.logAndReturnError(ctx, "SaveAccrual - insert accrual", err)
 	}
@@ -86,15 +95,29 @@ func (g *GopherMartRepo) SaveAccrual(ctx context.Context, orderNumber, status st
 		return g.logAndReturnError(ctx, "SaveAccrual - update order status", err)
 	}
 
-	const queryInsertBalance = `
-	INSERT INTO balance (user_id, current_balance, withdrawn)
-	SELECT user_id, $2, 0
-	FROM orders
-	WHERE number = $1`
-	// Обновляем баланс пользователя
-	_, err = tx.Exec(ctx, queryInsertBalance, orderNumber, accrual)
-	if err != nil {
-		return g.logAndReturnError(ctx, "SaveAccrual - insert balance", err)
+	// Баллы начисляются проводкой в журнал, проекция баланса сдвигается в той же транзакции
+	if status == entity.AccrualStatusProcessed && accrual.IsPositive() {
+		_, err = g.postLedgerTx(ctx, tx, ledgerPosting{
+			UserID:  userID,
+			Kind:    entity.LedgerAccrual,
+			Counter: entity.LedgerAccountIssued,
+			Amount:  accrual,
+			OrderID: &orderID,
+		})
+		if err != nil {
+			return err
+		}
+		if err = g.applyBalanceTx(ctx, tx, userID, accrual, 0); err != nil {
+			return err
+		}
+	}
+
+	var eventAccrual *entity.Points
+	if status == entity.AccrualStatusProcessed {
+		eventAccrual = &accrual
+	}
+	if err = g.createOrderEventTx(ctx, tx, userID, orderID, status, eventAccrual); err != nil {
+		return err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
@@ -104,7 +127,7 @@ func (g *GopherMartRepo) SaveAccrual(ctx context.Context, orderNumber, status st
 	g.Logger.InfoCtx(ctx, "accrual saved successfully",
 		zap.String("orderNumber", orderNumber),
 		zap.String("status", status),
-		zap.Float32("accrual", accrual))
+		zap.Stringer("accrual", accrual))
 
 	return nil
 }
@@ -116,10 +139,11 @@ func (g *GopherMartRepo) GetUnprocessedOrders(ctx context.Context) ([]string, er
         FROM orders o
         LEFT JOIN accrual a ON a.order_id = o.id
         LEFT JOIN accrual_statuses s ON a.status_id = s.id
-        WHERE a.id IS NULL
-           OR s.status NOT IN ('PROCESSED', 'INVALID')
+        WHERE (a.id IS NULL
+           OR s.status NOT IN ('PROCESSED', 'INVALID'))
+          AND NOT EXISTS (SELECT 1 FROM withdrawals w WHERE w.order_id = o.id)
         ORDER BY o.uploaded_at ASC`
-	rows, err := g.pg.Pool.Query(ctx, queryUnprocessedOrders)
+	rows, err := g.conn(ctx).Query(ctx, queryUnprocessedOrders)
 	if err != nil {
 		return nil, g.logAndReturnError(ctx, "GetUnprocessedOrders - query unprocessed orders", err)
 	}
@@ -140,3 +164,152 @@ func (g *GopherMartRepo) GetUnprocessedOrders(ctx context.Context) ([]string, er
 
 	return orders, nil
 }
+
+// EnqueueAccrualJob ставит заказ в очередь на получение начисления.
+// Повторная постановка того же заказа ничего не меняет.
+func (g *GopherMartRepo) EnqueueAccrualJob(ctx context.Context, orderNumber, traceParent string) error {
+	const queryEnqueueAccrualJob = `
+	INSERT INTO accrual_jobs (order_id, traceparent)
+	SELECT id, NULLIF($2, '') FROM orders WHERE number = $1
+	ON CONFLICT (order_id) DO NOTHING`
+	_, err := g.conn(ctx).Exec(ctx, queryEnqueueAccrualJob, orderNumber, traceParent)
+	if err != nil {
+		return g.logAndReturnError(ctx, "EnqueueAccrualJob - Exec", err)
+	}
+	return nil
+}
+
+// ClaimAccrualJobs забирает готовые к запуску задания и арендует их на lease.
+// SKIP LOCKED позволяет нескольким репликам разбирать очередь одновременно,
+// а задания упавшего воркера возвращаются в работу по истечении аренды.
+func (g *GopherMartRepo) ClaimAccrualJobs(ctx context.Context,
+	workerID string,
+	limit int,
+	lease time.Duration) ([]entity.AccrualJob, error) {
+	const queryClaimAccrualJobs = `
+	WITH claimed AS (
+		UPDATE accrual_jobs
+		SET state = 'RUNNING',
+			locked_by = $1,
+			locked_until = CURRENT_TIMESTAMP + make_interval(secs => $3),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id
+			FROM accrual_jobs
+			WHERE (state = 'PENDING' AND next_run_at <= CURRENT_TIMESTAMP)
+			   OR (state = 'RUNNING' AND locked_until < CURRENT_TIMESTAMP)
+			ORDER BY next_run_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, order_id, state, attempts, next_run_at, last_error, created_at, traceparent, registered_at
+	)
+	SELECT c.id, CAST(o.number AS TEXT), c.state, c.attempts, c.next_run_at, COALESCE(c.last_error, ''), c.created_at,
+		COALESCE(c.traceparent, ''), c.registered_at
+	FROM claimed c
+	JOIN orders o ON o.id = c.order_id`
+	rows, err := g.conn(ctx).Query(ctx, queryClaimAccrualJobs, workerID, limit, lease.Seconds())
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "ClaimAccrualJobs - Query", err)
+	}
+	defer rows.Close()
+
+	var jobs []entity.AccrualJob
+	for rows.Next() {
+		var job entity.AccrualJob
+		if err := rows.Scan(
+			&job.ID,
+			&job.OrderNumber,
+			&job.State,
+			&job.Attempts,
+			&job.NextRunAt,
+			&job.LastError,
+			&job.CreatedAt,
+			&job.TraceParent,
+			&job.RegisteredAt,
+		); err != nil {
+			return nil, g.logAndReturnError(ctx, "ClaimAccrualJobs - Scan", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "ClaimAccrualJobs - rows.Err", err)
+	}
+	return jobs, nil
+}
+
+// CompleteAccrualJob помечает задание выполненным
+func (g *GopherMartRepo) CompleteAccrualJob(ctx context.Context, jobID int64) error {
+	const queryCompleteAccrualJob = `
+	UPDATE accrual_jobs
+	SET state = 'DONE',
+		locked_by = NULL,
+		locked_until = NULL,
+		last_error = NULL,
+		updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1`
+	return g.execAccrualJob(ctx, "CompleteAccrualJob", queryCompleteAccrualJob, jobID)
+}
+
+// RetryAccrualJob возвращает задание в очередь после ошибки, увеличивая счетчик попыток
+func (g *GopherMartRepo) RetryAccrualJob(ctx context.Context, jobID int64, delay time.Duration, lastErr string) error {
+	const queryRetryAccrualJob = `
+	UPDATE accrual_jobs
+	SET state = 'PENDING',
+		attempts = attempts + 1,
+		next_run_at = CURRENT_TIMESTAMP + make_interval(secs => $2),
+		last_error = $3,
+		locked_by = NULL,
+		locked_until = NULL,
+		updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1`
+	return g.execAccrualJob(ctx, "RetryAccrualJob", queryRetryAccrualJob, jobID, delay.Seconds(), lastErr)
+}
+
+// PostponeAccrualJob откладывает задание без учета попытки,
+// например пока система начислений еще считает заказ
+func (g *GopherMartRepo) PostponeAccrualJob(ctx context.Context, jobID int64, delay time.Duration) error {
+	const queryPostponeAccrualJob = `
+	UPDATE accrual_jobs
+	SET state = 'PENDING',
+		next_run_at = CURRENT_TIMESTAMP + make_interval(secs => $2),
+		locked_by = NULL,
+		locked_until = NULL,
+		updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1`
+	return g.execAccrualJob(ctx, "PostponeAccrualJob", queryPostponeAccrualJob, jobID, delay.Seconds())
+}
+
+// FailAccrualJob окончательно снимает задание с обработки
+func (g *GopherMartRepo) FailAccrualJob(ctx context.Context, jobID int64, lastErr string) error {
+	const queryFailAccrualJob = `
+	UPDATE accrual_jobs
+	SET state = 'FAILED',
+		attempts = attempts + 1,
+		last_error = $2,
+		locked_by = NULL,
+		locked_until = NULL,
+		updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1`
+	return g.execAccrualJob(ctx, "FailAccrualJob", queryFailAccrualJob, jobID, lastErr)
+}
+
+// SetAccrualJobRegistered отмечает, что заказ задания принят системой начислений,
+// или сбрасывает отметку, если система заказ не знает
+func (g *GopherMartRepo) SetAccrualJobRegistered(ctx context.Context, jobID int64, registered bool) error {
+	const querySetAccrualJobRegistered = `
+	UPDATE accrual_jobs
+	SET registered_at = CASE WHEN $2 THEN CURRENT_TIMESTAMP END,
+		updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1`
+	return g.execAccrualJob(ctx, "SetAccrualJobRegistered", querySetAccrualJobRegistered, jobID, registered)
+}
+
+func (g *GopherMartRepo) execAccrualJob(ctx context.Context, method, query string, args ...interface{}) error {
+	_, err := g.conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		return g.logAndReturnError(ctx, method+" - Exec", err)
+	}
+	return nil
+}
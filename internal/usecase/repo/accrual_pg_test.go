@@ -3,6 +3,7 @@ package repo
 import (
 	"context"
 	"errors"
+	"go-loyalty-system/internal/entity"
 	"go-loyalty-system/internal/usecase/repo/mocks"
 	"testing"
 
@@ -18,7 +19,7 @@ func TestSaveAccrual(t *testing.T) {
 	ctx := context.Background()
 	orderNumber := "12345678"
 	status := "PROCESSED"
-	accrual := float32(500.50)
+	accrual := entity.NewPoints(500, 50)
 
 	t.Run("successful save accrual", func(t *testing.T) {
 		mockRepo.EXPECT().
@@ -141,7 +142,7 @@ func TestOrderAccrualFlow(t *testing.T) {
 			Return([]string{orderNumber}, nil)
 
 		mockRepo.EXPECT().
-			SaveAccrual(ctx, orderNumber, "PROCESSED", float32(100.50)).
+			SaveAccrual(ctx, orderNumber, "PROCESSED", entity.NewPoints(100, 50)).
 			Return(nil)
 
 		exists, err := mockRepo.ExistOrderAccrual(ctx, orderNumber)
@@ -150,7 +151,7 @@ func TestOrderAccrualFlow(t *testing.T) {
 		orders, err := mockRepo.GetUnprocessedOrders(ctx)
 		assert.NoError(t, err)
 		assert.Contains(t, orders, orderNumber)
-		err = mockRepo.SaveAccrual(ctx, orderNumber, "PROCESSED", float32(100.50))
+		err = mockRepo.SaveAccrual(ctx, orderNumber, "PROCESSED", entity.NewPoints(100, 50))
 		assert.NoError(t, err)
 	})
 }
@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"context"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:generate mockgen -source=admin_pg.go -destination=./mocks/mock_admin.go -package=mocks
+type AdminAuditRepository interface {
+	CreateAdminAudit(ctx context.Context, e entity.AdminAuditEntry) error
+	GetAdminAudit(ctx context.Context, targetUserID uint, limit int) ([]entity.AdminAuditEntry, error)
+}
+
+func NewAdminAuditRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
+	return &GopherMartRepo{
+		pg:     pg,
+		Logger: l,
+		pool:   pool,
+	}
+}
+
+// CreateAdminAudit дописывает действие сотрудника в журнал
+func (g *GopherMartRepo) CreateAdminAudit(ctx context.Context, e entity.AdminAuditEntry) error {
+	const queryCreateAdminAudit = `
+	INSERT INTO admin_audit (actor_id, action, target_user_id, details)
+	VALUES ($1, $2, $3, $4)`
+	if _, err := g.conn(ctx).Exec(ctx, queryCreateAdminAudit, e.ActorID, e.Action, e.TargetUserID, e.Details); err != nil {
+		return g.logAndReturnError(ctx, "CreateAdminAudit - Exec", err)
+	}
+	return nil
+}
+
+// GetAdminAudit возвращает последние действия сотрудников; targetUserID = 0 — по всем пользователям
+func (g *GopherMartRepo) GetAdminAudit(ctx context.Context, targetUserID uint, limit int) ([]entity.AdminAuditEntry, error) {
+	const queryGetAdminAudit = `
+	SELECT id, actor_id, action, target_user_id, details, created_at
+	FROM admin_audit
+	WHERE $1 = 0 OR target_user_id = $1
+	ORDER BY id DESC
+	LIMIT $2`
+	rows, err := g.conn(ctx).Query(ctx, queryGetAdminAudit, targetUserID, limit)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetAdminAudit - Query", err)
+	}
+	defer rows.Close()
+
+	entries := make([]entity.AdminAuditEntry, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.AdminAuditEntry{}
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.TargetUserID, &e.Details, &e.CreatedAt); err != nil {
+			return nil, g.logAndReturnError(ctx, "GetAdminAudit - Scan", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "GetAdminAudit - rows", err)
+	}
+	return entries, nil
+}
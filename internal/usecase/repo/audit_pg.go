@@ -0,0 +1,96 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"go-loyalty-system/internal/audit"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/postgres"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:generate mockgen -source=audit_pg.go -destination=./mocks/mock_audit.go -package=mocks
+type AuditRepository interface {
+	CreateAuditEvent(ctx context.Context, e *audit.Event) error
+	GetAuditEvents(ctx context.Context, f audit.Filter) ([]audit.Event, error)
+}
+
+func NewAuditRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
+	return &GopherMartRepo{
+		pg:     pg,
+		Logger: l,
+		pool:   pool,
+	}
+}
+
+// CreateAuditEvent добавляет событие в журнал и заполняет его ID и время записи.
+// Внутри транзакции событие фиксируется или откатывается вместе с ней.
+func (g *GopherMartRepo) CreateAuditEvent(ctx context.Context, e *audit.Event) error {
+	var details []byte
+	if len(e.Details) > 0 {
+		var err error
+		if details, err = json.Marshal(e.Details); err != nil {
+			return g.logAndReturnError(ctx, "CreateAuditEvent - Marshal", err)
+		}
+	}
+	const queryCreateAuditEvent = `
+	INSERT INTO audit_events (type, actor_id, user_id, ip, request_id, order_number,
+		amount, balance_before, balance_after, details)
+	VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9, $10)
+	RETURNING id, created_at`
+	err := g.conn(ctx).QueryRow(ctx, queryCreateAuditEvent,
+		e.Type, e.ActorID, e.UserID, e.IP, e.RequestID, e.OrderNumber,
+		e.Amount, e.Before, e.After, details).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return g.logAndReturnError(ctx, "CreateAuditEvent - QueryRow", err)
+	}
+	return nil
+}
+
+// GetAuditEvents страница журнала по фильтру
+func (g *GopherMartRepo) GetAuditEvents(ctx context.Context, f audit.Filter) ([]audit.Event, error) {
+	q := g.pg.Builder.
+		Select("id", "type", "actor_id", "user_id", "COALESCE(ip, '')", "COALESCE(request_id, '')",
+			"COALESCE(order_number, '')", "amount", "balance_before", "balance_after", "details", "created_at").
+		From("audit_events")
+	if len(f.Types) > 0 {
+		q = q.Where(squirrel.Eq{"type": f.Types})
+	}
+	if f.UserID != 0 {
+		q = q.Where(squirrel.Or{squirrel.Eq{"user_id": f.UserID}, squirrel.Eq{"actor_id": f.UserID}})
+	}
+	if f.RequestID != "" {
+		q = q.Where(squirrel.Eq{"request_id": f.RequestID})
+	}
+	sql, args, err := pageQuery(q, "created_at", "id", f.ListFilter).ToSql()
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetAuditEvents - ToSql", err)
+	}
+	rows, err := g.conn(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetAuditEvents - Query", err)
+	}
+	defer rows.Close()
+
+	var events []audit.Event
+	for rows.Next() {
+		var e audit.Event
+		var details []byte
+		if err := rows.Scan(&e.ID, &e.Type, &e.ActorID, &e.UserID, &e.IP, &e.RequestID, &e.OrderNumber,
+			&e.Amount, &e.Before, &e.After, &details, &e.CreatedAt); err != nil {
+			return nil, g.logAndReturnError(ctx, "GetAuditEvents - Scan", err)
+		}
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &e.Details); err != nil {
+				return nil, g.logAndReturnError(ctx, "GetAuditEvents - Unmarshal details", err)
+			}
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "GetAuditEvents - rows", err)
+	}
+	return events, nil
+}
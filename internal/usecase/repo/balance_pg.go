@@ -7,7 +7,9 @@ import (
 	"go-loyalty-system/internal/entity"
 	"go-loyalty-system/pkg/logging"
 	"go-loyalty-system/pkg/postgres"
+	"time"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
@@ -17,11 +19,22 @@ import (
 type BalanceUseCase interface {
 	GetBalance(ctx context.Context, userID string) (*entity.Balance, error)
 	GetUserByLogin(ctx context.Context, u entity.User) (*entity.User, error)
-	GetBalanceTx(ctx context.Context, tx pgx.Tx, userID uint) (*entity.Balance, error)
+	GetBalanceForUpdate(ctx context.Context, userID uint) (*entity.Balance, error)
 	CreateWithdrawalTx(ctx context.Context, withdrawal entity.Withdrawal, order *entity.OrderResponse) error
-	GetUserWithdrawals(ctx context.Context, userID uint) ([]entity.Withdrawal, error)
-	BeginTx(ctx context.Context) (pgx.Tx, error)
-	UpdateBalanceTx(ctx context.Context, tx pgx.Tx, userID uint, amount float32) error
+	GetUserWithdrawals(ctx context.Context, userID uint, f entity.ListFilter) ([]entity.Withdrawal, error)
+	UpdateBalanceTx(ctx context.Context, userID uint, amount entity.Points) error
+	GetLedgerBalance(ctx context.Context, userID uint) (*entity.Balance, error)
+	GetLedgerEntries(ctx context.Context, userID uint) ([]entity.LedgerEntry, error)
+	GetBalanceHistory(ctx context.Context, userID uint, f entity.ListFilter) ([]entity.BalanceHistoryEntry, error)
+	GetBalanceAt(ctx context.Context, userID uint, at time.Time) (entity.Points, error)
+	StreamStatement(ctx context.Context, userID uint, p entity.StatementPeriod, fn func(entity.StatementLine) error) error
+	RebuildBalance(ctx context.Context, userID uint) error
+	AdjustBalance(ctx context.Context, userID uint, amount entity.Points, reason string) error
+	GetWithdrawalForUpdate(ctx context.Context, orderNumber string) (*entity.Withdrawal, error)
+	ReverseWithdrawal(ctx context.Context, w entity.Withdrawal, amount entity.Points, reason string) error
+	CreateTransferTx(ctx context.Context, t entity.Transfer) (*entity.Transfer, error)
+	GetSentTransfers(ctx context.Context, senderID uint, since time.Time) (entity.Points, int, error)
+	GetUserTransfers(ctx context.Context, userID uint, f entity.ListFilter) ([]entity.Transfer, error)
 }
 
 func NewBalanceRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
@@ -32,57 +45,58 @@ func NewBalanceRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgx
 	}
 }
 
-// GetBalance возвращает баланс пользователя
+// GetBalance возвращает баланс пользователя из проекции журнала.
+// У пользователя без проводок баланс нулевой.
 func (g *GopherMartRepo) GetBalance(ctx context.Context, userID string) (*entity.Balance, error) {
 	const queryGetBalance = `
 	SELECT 
 		current_balance as current,
+		held as held,
 		withdrawn as withdrawn
 	FROM balance
 	WHERE user_id = $1`
 	var balance entity.Balance
-	err := g.pg.Pool.QueryRow(ctx, queryGetBalance, userID).Scan(&balance.Current, &balance.Withdrawn)
+	err := g.conn(ctx).QueryRow(ctx, queryGetBalance, userID).Scan(&balance.Current, &balance.Held, &balance.Withdrawn)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, entity.ErrUserDoesNotExist
+			return &entity.Balance{}, nil
 		}
 		return nil, g.logAndReturnError(ctx, "GopherMartRepo -GetBalance - QueryRow", err)
 	}
 	return &balance, nil
 }
 
-// GetBalanceTx получает баланс пользователя в транзакции
-func (g *GopherMartRepo) GetBalanceTx(ctx context.Context, tx pgx.Tx, userID uint) (*entity.Balance, error) {
+// GetBalanceForUpdate блокирует строку баланса пользователя до конца транзакции.
+// Пустая строка создается заранее, чтобы блокировать было что и у нового пользователя.
+func (g *GopherMartRepo) GetBalanceForUpdate(ctx context.Context, userID uint) (*entity.Balance, error) {
 	if userID == 0 {
 		return nil, entity.ErrUserDoesNotExist
 	}
-	_, err := g.GetUserByID(ctx, userID)
-	if err != nil {
-		_ = g.logAndReturnError(ctx, "GetBalanceTx - GetUser", err)
-		return nil, entity.ErrUserDoesNotExist
+	const queryEnsureBalance = `
+	INSERT INTO balance (user_id, current_balance, withdrawn, updated)
+	VALUES ($1, 0, 0, 'GetBalanceForUpdate')
+	ON CONFLICT (user_id) DO NOTHING`
+	if _, err := g.conn(ctx).Exec(ctx, queryEnsureBalance, userID); err != nil {
+		return nil, g.logAndReturnError(ctx, "GetBalanceForUpdate - ensure balance", err)
 	}
 
+	const queryLockBalance = `
+	SELECT current_balance, held, withdrawn
+	FROM balance
+	WHERE user_id = $1
+	FOR UPDATE`
 	var balance entity.Balance
-	const queryGetBalanceTx = `
-        SELECT current_balance, withdrawn
-        FROM balance
-        WHERE user_id = $1
-        `
-	err = tx.QueryRow(ctx, queryGetBalanceTx, userID).Scan(&balance.Current, &balance.Withdrawn)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			_ = g.logAndReturnError(ctx, "GopherMartRepo -GetBalance - QueryRow", err)
-			return nil, entity.ErrInsufficientFunds
-		}
-		return nil, fmt.Errorf("failed to scan balance: %w", err)
+	err := g.conn(ctx).QueryRow(ctx, queryLockBalance, userID).Scan(&balance.Current, &balance.Held, &balance.Withdrawn)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetBalanceForUpdate - QueryRow", err)
 	}
-
 	return &balance, nil
 }
 
-// CreateWithdrawalTx создает запись о списании в транзакции
+// CreateWithdrawalTx создает запись о списании в транзакции.
+// Баланс пользователя блокируется, поэтому параллельные списания проверяют остаток по очереди.
 func (g *GopherMartRepo) CreateWithdrawalTx(ctx context.Context, withdrawal entity.Withdrawal, order *entity.OrderResponse) error {
-	tx, err := g.pg.Pool.Begin(ctx)
+	tx, err := g.conn(ctx).Begin(ctx)
 	if err != nil {
 		g.Logger.ErrorCtx(ctx, "WithdrawBalance - begin transaction", zap.Error(err))
 		return err
@@ -93,12 +107,16 @@ func (g *GopherMartRepo) CreateWithdrawalTx(ctx context.Context, withdrawal enti
 			g.Logger.ErrorCtx(ctx, "WithdrawBalance - rollback transaction", zap.Error(err))
 		}
 	}()
-	var currentBalance float32
+	txCtx := context.WithValue(ctx, txKey{}, tx)
+	if _, err = g.GetBalanceForUpdate(txCtx, withdrawal.UserID); err != nil {
+		return err
+	}
+	// остаток считаем по журналу, а не по проекции
+	var currentBalance entity.Points
 	const queryCheckBalance = `
-	SELECT 
-		 current_balance -	withdrawn
-	FROM balance 
-	WHERE user_id = $1`
+	SELECT COALESCE(SUM(amount), 0)
+	FROM ledger_entries
+	WHERE user_id = $1 AND account = 'USER'`
 	err = tx.QueryRow(ctx, queryCheckBalance, withdrawal.UserID).Scan(&currentBalance)
 	if err != nil {
 		g.Logger.ErrorCtx(ctx, "WithdrawBalance - check balance"+err.Error(), zap.Error(err))
@@ -111,18 +129,33 @@ func (g *GopherMartRepo) CreateWithdrawalTx(ctx context.Context, withdrawal enti
 	const queryInsertwithdrawals = `
        INSERT INTO withdrawals (user_id, order_id, amount, created_at)
         VALUES ($1, $2, $3, $4)
+        RETURNING id
     `
-	_, err = tx.Exec(ctx, queryInsertwithdrawals,
+	var withdrawalID int64
+	err = tx.QueryRow(ctx, queryInsertwithdrawals,
 		withdrawal.UserID,
 		order.ID,
 		withdrawal.Amount,
 		withdrawal.CreatedAt,
-	)
+	).Scan(&withdrawalID)
 	if err != nil {
 		g.Logger.ErrorCtx(ctx, "WithdrawBalance - insert withdrawal", zap.Error(err))
 		return err
 	}
 
+	orderID := int(order.ID)
+	_, err = g.postLedgerTx(ctx, tx, ledgerPosting{
+		UserID:       withdrawal.UserID,
+		Kind:         entity.LedgerWithdrawal,
+		Counter:      entity.LedgerAccountRedeemed,
+		Amount:       withdrawal.Amount.Neg(),
+		OrderID:      &orderID,
+		WithdrawalID: &withdrawalID,
+	})
+	if err != nil {
+		return err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		g.Logger.ErrorCtx(ctx, "WithdrawBalance - commit transaction", zap.Error(err))
 		return err
@@ -131,15 +164,20 @@ func (g *GopherMartRepo) CreateWithdrawalTx(ctx context.Context, withdrawal enti
 	return nil
 }
 
-// GetWithdrawals возвращает историю списаний
-func (g *GopherMartRepo) GetUserWithdrawals(ctx context.Context, userID uint) ([]entity.Withdrawal, error) {
-	const queryGetWithdrawals = `
-	SELECT w.id, w.user_id, o.number, w.amount, w.created_at
-	FROM withdrawals as w
-	LEFT JOIN orders as o ON o.id = w.order_id
-	WHERE w.user_id = $1
-	ORDER BY w.created_at DESC`
-	rows, err := g.pool.Query(ctx, queryGetWithdrawals, userID)
+// GetUserWithdrawals страница истории списаний за период
+func (g *GopherMartRepo) GetUserWithdrawals(ctx context.Context,
+	userID uint,
+	f entity.ListFilter) ([]entity.Withdrawal, error) {
+	q := g.pg.Builder.
+		Select("w.id", "w.user_id", "o.number", "w.amount", "w.reversed_amount", "w.created_at").
+		From("withdrawals AS w").
+		LeftJoin("orders AS o ON o.id = w.order_id").
+		Where(squirrel.Eq{"w.user_id": userID})
+	sql, args, err := pageQuery(q, "w.created_at", "w.id", f).ToSql()
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetUserWithdrawals - ToSql", err)
+	}
+	rows, err := g.conn(ctx).Query(ctx, sql, args...)
 	if err != nil {
 		g.Logger.ErrorCtx(ctx, "GopherMartRepo - GetWithdrawals - Query", zap.Error(err))
 		return nil, fmt.Errorf("failed to query withdrawals: %w", err)
@@ -149,9 +187,11 @@ func (g *GopherMartRepo) GetUserWithdrawals(ctx context.Context, userID uint) ([
 	var withdrawals []entity.Withdrawal
 	for rows.Next() {
 		var w entity.Withdrawal
-		if err := rows.Scan(&w.ID, &w.UserID, &w.OrderNumber, &w.Amount, &w.CreatedAt); err != nil {
+		var reversed entity.Points
+		if err := rows.Scan(&w.ID, &w.UserID, &w.OrderNumber, &w.Amount, &reversed, &w.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan withdrawal: %w", err)
 		}
+		w.SetReversed(reversed)
 		withdrawals = append(withdrawals, w)
 	}
 
@@ -162,59 +202,210 @@ func (g *GopherMartRepo) GetUserWithdrawals(ctx context.Context, userID uint) ([
 	return withdrawals, nil
 }
 
-// CreateBalance создает новый баланс пользователя
-func (g *GopherMartRepo) CreateBalance(ctx context.Context, userID uint) error {
-	const queryCreateBalance = `
-	INSERT INTO balance (user_id, current_balance, withdrawn)
-	VALUES ($1, 0, 0)
-	`
-	_, err := g.pool.Exec(ctx, queryCreateBalance, userID)
+// UpdateBalanceTx переносит списание в проекцию баланса в рамках транзакции из контекста.
+// Сама проводка уже записана в журнал в CreateWithdrawalTx.
+func (g *GopherMartRepo) UpdateBalanceTx(ctx context.Context, userID uint, amount entity.Points) error {
+	tx, err := g.conn(ctx).Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("create balance: %w", err)
+		return g.logAndReturnError(ctx, "UpdateBalanceTx - begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+	if err := g.applyBalanceTx(ctx, tx, userID, amount.Neg(), amount); err != nil {
+		return fmt.Errorf("failed to update balance: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return g.logAndReturnError(ctx, "UpdateBalanceTx - commit transaction", err)
 	}
 	return nil
 }
 
-// UpdateBalance обновляет баланс пользователя
-func (g *GopherMartRepo) UpdateBalance(ctx context.Context, userID uint, amount float64) error {
-	var id uint
-	const queryUpdateBalance = `
-	UPDATE balance
-	SET 
-		current_balance = current_balance + $1,
-		updated = ' UpdateBalanceTx'
-	WHERE user_id = $2
-	RETURNING id`
-	err := g.pool.QueryRow(ctx, queryUpdateBalance, amount, userID).Scan(&id)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return fmt.Errorf("balance not found for user %d: %w", userID, err)
-		}
-		return fmt.Errorf("failed to update balance: %w", err)
+// GetWithdrawalForUpdate находит списание по номеру заказа и блокирует его
+// до конца транзакции, чтобы параллельные возвраты проверяли остаток по очереди
+func (g *GopherMartRepo) GetWithdrawalForUpdate(ctx context.Context, orderNumber string) (*entity.Withdrawal, error) {
+	const queryLockWithdrawal = `
+	SELECT w.id, w.user_id, CAST(o.number AS TEXT), w.amount, w.reversed_amount, w.created_at
+	FROM withdrawals AS w
+	JOIN orders AS o ON o.id = w.order_id
+	WHERE o.number = $1
+	FOR UPDATE OF w`
+	var w entity.Withdrawal
+	var reversed entity.Points
+	err := g.conn(ctx).QueryRow(ctx, queryLockWithdrawal, orderNumber).
+		Scan(&w.ID, &w.UserID, &w.OrderNumber, &w.Amount, &reversed, &w.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrWithdrawalNotFound
 	}
-	return nil
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetWithdrawalForUpdate - QueryRow", err)
+	}
+	w.SetReversed(reversed)
+	return &w, nil
 }
 
-func (g *GopherMartRepo) BeginTx(ctx context.Context) (pgx.Tx, error) {
-	return g.pool.Begin(ctx)
+// ReverseWithdrawal возвращает amount баллов по списанию компенсирующей проводкой
+// REVERSAL, которая ссылается на проводку списания, и сдвигает проекцию баланса.
+// Проверка остатка — дело вызывающего; ограничение в базе страхует от гонок.
+func (g *GopherMartRepo) ReverseWithdrawal(ctx context.Context,
+	w entity.Withdrawal,
+	amount entity.Points,
+	reason string) error {
+	tx, err := g.conn(ctx).Begin(ctx)
+	if err != nil {
+		return g.logAndReturnError(ctx, "ReverseWithdrawal - begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	const queryReverseWithdrawal = `
+	UPDATE withdrawals
+	SET reversed_amount = reversed_amount + $2, updated = 'ReverseWithdrawal'
+	WHERE id = $1
+	RETURNING order_id`
+	var orderID int
+	if err = tx.QueryRow(ctx, queryReverseWithdrawal, w.ID, amount).Scan(&orderID); err != nil {
+		return g.logAndReturnError(ctx, "ReverseWithdrawal - update withdrawal", err)
+	}
+
+	// у списаний до журнала проводок может не быть, тогда возврат ни на что не ссылается
+	const queryWithdrawalTransaction = `
+	SELECT transaction_id::text
+	FROM ledger_entries
+	WHERE withdrawal_id = $1 AND kind = 'WITHDRAWAL'
+	LIMIT 1`
+	var reverses *string
+	var txID string
+	err = tx.QueryRow(ctx, queryWithdrawalTransaction, w.ID).Scan(&txID)
+	switch {
+	case err == nil:
+		reverses = &txID
+	case !errors.Is(err, pgx.ErrNoRows):
+		return g.logAndReturnError(ctx, "ReverseWithdrawal - find withdrawal transaction", err)
+	}
+
+	withdrawalID := int64(w.ID)
+	_, err = g.postLedgerTx(ctx, tx, ledgerPosting{
+		UserID:       w.UserID,
+		Kind:         entity.LedgerReversal,
+		Counter:      entity.LedgerAccountRedeemed,
+		Amount:       amount,
+		OrderID:      &orderID,
+		WithdrawalID: &withdrawalID,
+		Reverses:     reverses,
+		Description:  reason,
+	})
+	if err != nil {
+		return err
+	}
+	if err = g.applyBalanceTx(ctx, tx, w.UserID, amount, amount.Neg()); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return g.logAndReturnError(ctx, "ReverseWithdrawal - commit transaction", err)
+	}
+	return nil
 }
 
-// UpdateBalanceTx обновляет баланс пользователя в рамках транзакции
-func (g *GopherMartRepo) UpdateBalanceTx(ctx context.Context, tx pgx.Tx, userID uint, amount float32) error {
-	const queryUpdateBalanceTx = `
-	UPDATE balance
-	SET 
-		current_balance = current_balance - $1,
-		withdrawn = withdrawn + $1,
-		updated = ' UpdateBalanceTx'
-	WHERE user_id = $2`
-	_, err := g.pg.Pool.Exec(ctx, queryUpdateBalanceTx, amount, userID)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return fmt.Errorf("balance not found for user %d: %w", userID, err)
+// CreateTransferTx переводит баллы одной транзакцией: у отправителя проводка TRANSFER
+// списывает сумму, у получателя такая же проводка ее зачисляет. Балансы обеих
+// сторон должны быть заблокированы вызывающим, он же проверяет остаток.
+func (g *GopherMartRepo) CreateTransferTx(ctx context.Context, t entity.Transfer) (*entity.Transfer, error) {
+	tx, err := g.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "CreateTransferTx - begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	const queryCreateTransfer = `
+	INSERT INTO transfers (sender_id, recipient_id, amount)
+	VALUES ($1, $2, $3)
+	RETURNING id, created_at`
+	err = tx.QueryRow(ctx, queryCreateTransfer, t.SenderID, t.RecipientID, t.Amount).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "CreateTransferTx - insert transfer", err)
+	}
+
+	sides := []struct {
+		userID uint
+		amount entity.Points
+		note   string
+	}{
+		{t.SenderID, t.Amount.Neg(), fmt.Sprintf("transfer %d to user %d", t.ID, t.RecipientID)},
+		{t.RecipientID, t.Amount, fmt.Sprintf("transfer %d from user %d", t.ID, t.SenderID)},
+	}
+	for _, side := range sides {
+		_, err = g.postLedgerTx(ctx, tx, ledgerPosting{
+			UserID:      side.userID,
+			Kind:        entity.LedgerTransfer,
+			Counter:     entity.LedgerAccountTransfer,
+			Amount:      side.amount,
+			Description: side.note,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err = g.applyBalanceTx(ctx, tx, side.userID, side.amount, 0); err != nil {
+			return nil, err
 		}
-		return fmt.Errorf("failed to update balance: %w", err)
 	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, g.logAndReturnError(ctx, "CreateTransferTx - commit transaction", err)
+	}
+	return &t, nil
+}
 
-	return nil
+// GetSentTransfers сумма и число переводов, отправленных пользователем начиная с since
+func (g *GopherMartRepo) GetSentTransfers(ctx context.Context, senderID uint, since time.Time) (entity.Points, int, error) {
+	const querySentTransfers = `
+	SELECT COALESCE(SUM(amount), 0), COUNT(*)
+	FROM transfers
+	WHERE sender_id = $1 AND created_at >= $2`
+	var sent entity.Points
+	var count int
+	if err := g.conn(ctx).QueryRow(ctx, querySentTransfers, senderID, since).Scan(&sent, &count); err != nil {
+		return 0, 0, g.logAndReturnError(ctx, "GetSentTransfers - QueryRow", err)
+	}
+	return sent, count, nil
+}
+
+// GetUserTransfers страница отправленных и полученных переводов пользователя
+// с логином второй стороны
+func (g *GopherMartRepo) GetUserTransfers(ctx context.Context,
+	userID uint,
+	f entity.ListFilter) ([]entity.Transfer, error) {
+	q := g.pg.Builder.
+		Select("t.id", "t.sender_id", "t.recipient_id", "u.login", "t.amount", "t.created_at").
+		From("transfers AS t").
+		Join("users AS u ON u.id = CASE WHEN t.sender_id = ? THEN t.recipient_id ELSE t.sender_id END", userID).
+		Where(squirrel.Or{squirrel.Eq{"t.sender_id": userID}, squirrel.Eq{"t.recipient_id": userID}})
+	sql, args, err := pageQuery(q, "t.created_at", "t.id", f).ToSql()
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetUserTransfers - ToSql", err)
+	}
+	rows, err := g.conn(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetUserTransfers - Query", err)
+	}
+	defer rows.Close()
+
+	transfers := make([]entity.Transfer, 0, _defaultEntityCap)
+	for rows.Next() {
+		var t entity.Transfer
+		if err := rows.Scan(&t.ID, &t.SenderID, &t.RecipientID, &t.Login, &t.Amount, &t.CreatedAt); err != nil {
+			return nil, g.logAndReturnError(ctx, "GetUserTransfers - Scan", err)
+		}
+		t.Direction = entity.TransferIn
+		if t.SenderID == userID {
+			t.Direction = entity.TransferOut
+		}
+		transfers = append(transfers, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "GetUserTransfers - rows.Err", err)
+	}
+	return transfers, nil
 }
@@ -9,14 +9,9 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
-	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 )
 
-type mockTx struct {
-	pgx.Tx
-}
-
 func TestBalanceRepository_GetBalance(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -26,8 +21,8 @@ func TestBalanceRepository_GetBalance(t *testing.T) {
 	userID := "1"
 
 	expectedBalance := &entity.Balance{
-		Current:   100.50,
-		Withdrawn: 50.25,
+		Current:   entity.NewPoints(100, 50),
+		Withdrawn: entity.NewPoints(50, 25),
 	}
 
 	t.Run("успешное получение баланса", func(t *testing.T) {
@@ -39,8 +34,8 @@ func TestBalanceRepository_GetBalance(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedBalance, balance)
-		assert.Equal(t, float32(100.50), balance.Current)
-		assert.Equal(t, float32(50.25), balance.Withdrawn)
+		assert.Equal(t, entity.NewPoints(100, 50), balance.Current)
+		assert.Equal(t, entity.NewPoints(50, 25), balance.Withdrawn)
 	})
 
 	t.Run("ошибка при получении баланса", func(t *testing.T) {
@@ -57,40 +52,39 @@ func TestBalanceRepository_GetBalance(t *testing.T) {
 	})
 }
 
-func TestBalanceRepository_GetBalanceTx(t *testing.T) {
+func TestBalanceRepository_GetBalanceForUpdate(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockBalanceUseCase := mocks.NewMockBalanceUseCase(ctrl)
 	ctx := context.Background()
 	userID := uint(1)
-	tx := &mockTx{}
 
 	expectedBalance := &entity.Balance{
-		Current:   200.75,
-		Withdrawn: 100.25,
+		Current:   entity.NewPoints(200, 75),
+		Withdrawn: entity.NewPoints(100, 25),
 	}
 
 	t.Run("успешное получение баланса в транзакции", func(t *testing.T) {
 		mockBalanceUseCase.EXPECT().
-			GetBalanceTx(gomock.Any(), tx, userID).
+			GetBalanceForUpdate(gomock.Any(), userID).
 			Return(expectedBalance, nil)
 
-		balance, err := mockBalanceUseCase.GetBalanceTx(ctx, tx, userID)
+		balance, err := mockBalanceUseCase.GetBalanceForUpdate(ctx, userID)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedBalance, balance)
-		assert.Equal(t, float32(200.75), balance.Current)
-		assert.Equal(t, float32(100.25), balance.Withdrawn)
+		assert.Equal(t, entity.NewPoints(200, 75), balance.Current)
+		assert.Equal(t, entity.NewPoints(100, 25), balance.Withdrawn)
 	})
 
 	t.Run("ошибка при получении баланса в транзакции", func(t *testing.T) {
 		expectedErr := errors.New("tx error")
 		mockBalanceUseCase.EXPECT().
-			GetBalanceTx(gomock.Any(), tx, userID).
+			GetBalanceForUpdate(gomock.Any(), userID).
 			Return(nil, expectedErr)
 
-		balance, err := mockBalanceUseCase.GetBalanceTx(ctx, tx, userID)
+		balance, err := mockBalanceUseCase.GetBalanceForUpdate(ctx, userID)
 
 		assert.Error(t, err)
 		assert.Nil(t, balance)
@@ -105,12 +99,12 @@ func TestBalanceRepository_CreateWithdrawalTx(t *testing.T) {
 	mockBalanceUseCase := mocks.NewMockBalanceUseCase(ctrl)
 	ctx := context.Background()
 	now := time.Now()
-	accrual1 := float64(100.50)
+	accrual1 := entity.NewPoints(100, 50)
 
 	withdrawal := entity.Withdrawal{
 		UserID:      1,
 		OrderNumber: "12345678",
-		Amount:      100.50,
+		Amount:      entity.NewPoints(100, 50),
 		CreatedAt:   now,
 	}
 
@@ -157,38 +151,38 @@ func TestBalanceRepository_GetUserWithdrawals(t *testing.T) {
 		{
 			UserID:      userID,
 			OrderNumber: "12345678",
-			Amount:      100.50,
+			Amount:      entity.NewPoints(100, 50),
 			CreatedAt:   now,
 		},
 		{
 			UserID:      userID,
 			OrderNumber: "87654321",
-			Amount:      50.25,
+			Amount:      entity.NewPoints(50, 25),
 			CreatedAt:   now.Add(-24 * time.Hour),
 		},
 	}
 
 	t.Run("успешное получение списка снятий", func(t *testing.T) {
 		mockBalanceUseCase.EXPECT().
-			GetUserWithdrawals(gomock.Any(), userID).
+			GetUserWithdrawals(gomock.Any(), userID, gomock.Any()).
 			Return(expectedWithdrawals, nil)
 
-		withdrawals, err := mockBalanceUseCase.GetUserWithdrawals(ctx, userID)
+		withdrawals, err := mockBalanceUseCase.GetUserWithdrawals(ctx, userID, entity.ListFilter{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedWithdrawals, withdrawals)
 		assert.Len(t, withdrawals, 2)
 		assert.Equal(t, "12345678", withdrawals[0].OrderNumber)
-		assert.Equal(t, float32(100.50), withdrawals[0].Amount)
+		assert.Equal(t, entity.NewPoints(100, 50), withdrawals[0].Amount)
 	})
 
 	t.Run("ошибка при запросе снятий", func(t *testing.T) {
 		expectedErr := errors.New("query error")
 		mockBalanceUseCase.EXPECT().
-			GetUserWithdrawals(gomock.Any(), userID).
+			GetUserWithdrawals(gomock.Any(), userID, gomock.Any()).
 			Return(nil, expectedErr)
 
-		withdrawals, err := mockBalanceUseCase.GetUserWithdrawals(ctx, userID)
+		withdrawals, err := mockBalanceUseCase.GetUserWithdrawals(ctx, userID, entity.ListFilter{})
 
 		assert.Error(t, err)
 		assert.Nil(t, withdrawals)
@@ -197,10 +191,10 @@ func TestBalanceRepository_GetUserWithdrawals(t *testing.T) {
 
 	t.Run("пустой список снятий", func(t *testing.T) {
 		mockBalanceUseCase.EXPECT().
-			GetUserWithdrawals(gomock.Any(), userID).
+			GetUserWithdrawals(gomock.Any(), userID, gomock.Any()).
 			Return([]entity.Withdrawal{}, nil)
 
-		withdrawals, err := mockBalanceUseCase.GetUserWithdrawals(ctx, userID)
+		withdrawals, err := mockBalanceUseCase.GetUserWithdrawals(ctx, userID, entity.ListFilter{})
 
 		assert.NoError(t, err)
 		assert.Empty(t, withdrawals)
@@ -214,15 +208,14 @@ func TestBalanceRepository_UpdateBalanceTx(t *testing.T) {
 	mockBalanceUseCase := mocks.NewMockBalanceUseCase(ctrl)
 	ctx := context.Background()
 	userID := uint(1)
-	amount := float32(50.75)
-	tx := &mockTx{}
+	amount := entity.NewPoints(50, 75)
 
 	t.Run("успешное обновление баланса", func(t *testing.T) {
 		mockBalanceUseCase.EXPECT().
-			UpdateBalanceTx(gomock.Any(), tx, userID, amount).
+			UpdateBalanceTx(gomock.Any(), userID, amount).
 			Return(nil)
 
-		err := mockBalanceUseCase.UpdateBalanceTx(ctx, tx, userID, amount)
+		err := mockBalanceUseCase.UpdateBalanceTx(ctx, userID, amount)
 
 		assert.NoError(t, err)
 	})
@@ -230,46 +223,53 @@ func TestBalanceRepository_UpdateBalanceTx(t *testing.T) {
 	t.Run("ошибка при обновлении баланса", func(t *testing.T) {
 		expectedErr := errors.New("update error")
 		mockBalanceUseCase.EXPECT().
-			UpdateBalanceTx(gomock.Any(), tx, userID, amount).
+			UpdateBalanceTx(gomock.Any(), userID, amount).
 			Return(expectedErr)
 
-		err := mockBalanceUseCase.UpdateBalanceTx(ctx, tx, userID, amount)
+		err := mockBalanceUseCase.UpdateBalanceTx(ctx, userID, amount)
 
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
 	})
 }
 
-func TestBalanceRepository_BeginTx(t *testing.T) {
+func TestTransactor_WithinTransaction(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockBalanceUseCase := mocks.NewMockBalanceUseCase(ctrl)
+	mockTransactor := mocks.NewMockTransactor(ctrl)
 	ctx := context.Background()
-	tx := &mockTx{}
 
-	t.Run("успешное начало транзакции", func(t *testing.T) {
-		mockBalanceUseCase.EXPECT().
-			BeginTx(gomock.Any()).
-			Return(tx, nil)
+	t.Run("функция выполняется в транзакции", func(t *testing.T) {
+		mockTransactor.EXPECT().
+			WithinTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
 
-		resultTx, err := mockBalanceUseCase.BeginTx(ctx)
+		called := false
+		err := mockTransactor.WithinTransaction(ctx, func(context.Context) error {
+			called = true
+			return nil
+		})
 
 		assert.NoError(t, err)
-		assert.Equal(t, tx, resultTx)
+		assert.True(t, called)
 	})
 
-	t.Run("ошибка при начале транзакции", func(t *testing.T) {
-		expectedErr := errors.New("begin transaction error")
-		mockBalanceUseCase.EXPECT().
-			BeginTx(gomock.Any()).
-			Return(nil, expectedErr)
+	t.Run("ошибка функции откатывает транзакцию", func(t *testing.T) {
+		expectedErr := errors.New("insufficient funds")
+		mockTransactor.EXPECT().
+			WithinTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
 
-		resultTx, err := mockBalanceUseCase.BeginTx(ctx)
+		err := mockTransactor.WithinTransaction(ctx, func(context.Context) error {
+			return expectedErr
+		})
 
-		assert.Error(t, err)
-		assert.Nil(t, resultTx)
-		assert.Equal(t, expectedErr, err)
+		assert.ErrorIs(t, err, expectedErr)
 	})
 }
 
@@ -287,14 +287,14 @@ func TestBalanceRepository_BeginTx(t *testing.T) {
 // 	withdrawal := entity.Withdrawal{
 // 		UserID:      userID,
 // 		OrderNumber: "12345678",
-// 		Amount:      100.50,
+// 		Amount:      entity.NewPoints(100, 50),
 // 		CreatedAt:   now,
 // 	}
 
 // 	order := &entity.OrderResponse{
 // 		Number:     "12345678",
 // 		Status:     "PROCESSED",
-// 		Accrual:    100.50,
+// 		Accrual:    entity.NewPoints(100, 50),
 // 		UploadedAt: now,
 // 	}
 
@@ -306,12 +306,12 @@ func TestBalanceRepository_BeginTx(t *testing.T) {
 
 // 		// Ожидаем получение баланса
 // 		mockBalanceUseCase.EXPECT().
-// 			GetBalanceTx(gomock.Any(), tx, userID).
-// 			Return(&entity.Balance{Current: 200.50, Withdrawn: 0}, nil)
+// 			GetBalanceForUpdate(gomock.Any(), userID).
+// 			Return(&entity.Balance{Current: entity.NewPoints(200, 50), Withdrawn: 0}, nil)
 
 // 		// Ожидаем обновление баланса
 // 		mockBalanceUseCase.EXPECT().
-// 			UpdateBalanceTx(gomock.Any(), tx, userID, float32(-100.50)).
+// 			UpdateBalanceTx(gomock.Any(), tx, userID, entity.NewPoints(-100, 50)).
 // 			Return(nil)
 
 // 		// Ожидаем создание записи о снятии
@@ -346,8 +346,8 @@ func TestBalanceRepository_BeginTx(t *testing.T) {
 
 // 		// Ожидаем получение баланса с недостаточными средствами
 // 		mockBalanceUseCase.EXPECT().
-// 			GetBalanceTx(gomock.Any(), tx, userID).
-// 			Return(&entity.Balance{Current: 50.25, Withdrawn: 0}, nil)
+// 			GetBalanceForUpdate(gomock.Any(), userID).
+// 			Return(&entity.Balance{Current: entity.NewPoints(50, 25), Withdrawn: 0}, nil)
 
 // 		// Начинаем транзакцию
 // 		resultTx, err := mockBalanceUseCase.BeginTx(ctx)
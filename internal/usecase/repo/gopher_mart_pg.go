@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 
+	"go-loyalty-system/internal/entity"
 	"go-loyalty-system/pkg/logging"
 	"go-loyalty-system/pkg/postgres"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
@@ -34,3 +36,23 @@ func (g *GopherMartRepo) logAndReturnError(ctx context.Context, method string, e
 	g.Logger.ErrorCtx(ctx, msg, zap.Error(err))
 	return err
 }
+
+// pageQuery добавляет к выборке фильтр по времени и keyset-пагинацию по паре
+// (timeCol, idCol). Выбирается на одну строку больше лимита, чтобы понять,
+// есть ли следующая страница.
+func pageQuery(q squirrel.SelectBuilder, timeCol, idCol string, f entity.ListFilter) squirrel.SelectBuilder {
+	if f.From != nil {
+		q = q.Where(squirrel.GtOrEq{timeCol: *f.From})
+	}
+	if f.To != nil {
+		q = q.Where(squirrel.Lt{timeCol: *f.To})
+	}
+	dir, cmp := "DESC", "<"
+	if f.Sort == entity.SortAsc {
+		dir, cmp = "ASC", ">"
+	}
+	if f.Cursor != nil {
+		q = q.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", timeCol, idCol, cmp), f.Cursor.At, f.Cursor.ID)
+	}
+	return q.OrderBy(timeCol+" "+dir, idCol+" "+dir).Limit(uint64(f.Limit) + 1)
+}
@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"context"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:generate mockgen -source=health_pg.go -destination=./mocks/mock_health.go -package=mocks
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	GetMigrationVersion(ctx context.Context) (version uint, dirty bool, err error)
+}
+
+func NewHealthRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
+	return &GopherMartRepo{
+		pg:     pg,
+		Logger: l,
+		pool:   pool,
+	}
+}
+
+// Ping проверяет, что пул может получить соединение и база отвечает
+func (g *GopherMartRepo) Ping(ctx context.Context) error {
+	return g.pool.Ping(ctx)
+}
+
+// GetMigrationVersion текущая версия схемы из таблицы golang-migrate.
+// dirty означает, что миграция упала на середине и схему нужно чинить руками.
+func (g *GopherMartRepo) GetMigrationVersion(ctx context.Context) (uint, bool, error) {
+	const queryMigrationVersion = `
+	SELECT version, dirty
+	FROM schema_migrations
+	LIMIT 1`
+	var version int64
+	var dirty bool
+	if err := g.conn(ctx).QueryRow(ctx, queryMigrationVersion).Scan(&version, &dirty); err != nil {
+		return 0, false, g.logAndReturnError(ctx, "GetMigrationVersion - QueryRow", err)
+	}
+	return uint(version), dirty, nil
+}
@@ -0,0 +1,169 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/postgres"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:generate mockgen -source=hold_pg.go -destination=./mocks/mock_hold.go -package=mocks
+type HoldRepository interface {
+	CreateHold(ctx context.Context, h entity.Hold) (*entity.Hold, error)
+	GetHoldForUpdate(ctx context.Context, userID uint, holdID int64) (*entity.Hold, error)
+	SettleHold(ctx context.Context, h entity.Hold, status entity.HoldStatus, captured entity.Points) error
+	GetExpiredHolds(ctx context.Context, now time.Time, limit int) ([]entity.Hold, error)
+}
+
+func NewHoldRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
+	return &GopherMartRepo{
+		pg:     pg,
+		Logger: l,
+		pool:   pool,
+	}
+}
+
+// CreateHold резервирует баллы: проводка HOLD переносит их со счета USER на HELD.
+// Остаток проверяет вызывающий под блокировкой баланса.
+func (g *GopherMartRepo) CreateHold(ctx context.Context, h entity.Hold) (*entity.Hold, error) {
+	tx, err := g.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "CreateHold - begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	const queryCreateHold = `
+	INSERT INTO balance_holds (user_id, order_number, amount, points_expire_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (order_number) WHERE status = 'ACTIVE' DO NOTHING
+	RETURNING id, status, created_at`
+	err = tx.QueryRow(ctx, queryCreateHold, h.UserID, h.OrderNumber, h.Amount, h.PointsExpireAt, h.ExpiresAt).
+		Scan(&h.ID, &h.Status, &h.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrHoldExists
+	}
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "CreateHold - insert hold", err)
+	}
+
+	_, err = g.postLedgerTx(ctx, tx, ledgerPosting{
+		UserID:      h.UserID,
+		Kind:        entity.LedgerHold,
+		Counter:     entity.LedgerAccountHeld,
+		Amount:      h.Amount.Neg(),
+		Description: fmt.Sprintf("hold %d for order %s", h.ID, h.OrderNumber),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = g.applyHoldTx(ctx, tx, h.UserID, h.Amount); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, g.logAndReturnError(ctx, "CreateHold - commit transaction", err)
+	}
+	return &h, nil
+}
+
+// GetHoldForUpdate находит резерв пользователя и блокирует его до конца транзакции.
+// Чужой резерв не находится.
+func (g *GopherMartRepo) GetHoldForUpdate(ctx context.Context, userID uint, holdID int64) (*entity.Hold, error) {
+	const queryLockHold = `
+	SELECT id, user_id, order_number, amount, COALESCE(captured_amount, 0), status,
+		points_expire_at, expires_at, created_at
+	FROM balance_holds
+	WHERE id = $1 AND user_id = $2
+	FOR UPDATE`
+	var h entity.Hold
+	err := g.conn(ctx).QueryRow(ctx, queryLockHold, holdID, userID).Scan(&h.ID, &h.UserID, &h.OrderNumber,
+		&h.Amount, &h.Captured, &h.Status, &h.PointsExpireAt, &h.ExpiresAt, &h.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetHoldForUpdate - QueryRow", err)
+	}
+	return &h, nil
+}
+
+// SettleHold закрывает действующий резерв с итоговым статусом и проводкой RELEASE
+// возвращает весь резерв на счет USER. Списание захваченной части проводится
+// отдельно, как обычное списание.
+func (g *GopherMartRepo) SettleHold(ctx context.Context,
+	h entity.Hold,
+	status entity.HoldStatus,
+	captured entity.Points) error {
+	tx, err := g.conn(ctx).Begin(ctx)
+	if err != nil {
+		return g.logAndReturnError(ctx, "SettleHold - begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	const querySettleHold = `
+	UPDATE balance_holds
+	SET status = $2, captured_amount = NULLIF($3::numeric, 0), settled_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND status = 'ACTIVE'`
+	tag, err := tx.Exec(ctx, querySettleHold, h.ID, status, captured)
+	if err != nil {
+		return g.logAndReturnError(ctx, "SettleHold - update hold", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrHoldNotActive
+	}
+
+	_, err = g.postLedgerTx(ctx, tx, ledgerPosting{
+		UserID:      h.UserID,
+		Kind:        entity.LedgerRelease,
+		Counter:     entity.LedgerAccountHeld,
+		Amount:      h.Amount,
+		Description: fmt.Sprintf("hold %d %s", h.ID, status),
+	})
+	if err != nil {
+		return err
+	}
+	if err = g.applyHoldTx(ctx, tx, h.UserID, h.Amount.Neg()); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return g.logAndReturnError(ctx, "SettleHold - commit transaction", err)
+	}
+	return nil
+}
+
+// GetExpiredHolds действующие резервы, срок которых истек к now
+func (g *GopherMartRepo) GetExpiredHolds(ctx context.Context, now time.Time, limit int) ([]entity.Hold, error) {
+	const queryExpiredHolds = `
+	SELECT id, user_id
+	FROM balance_holds
+	WHERE status = 'ACTIVE' AND expires_at <= $1
+	ORDER BY expires_at, id
+	LIMIT $2`
+	rows, err := g.conn(ctx).Query(ctx, queryExpiredHolds, now, limit)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetExpiredHolds - Query", err)
+	}
+	defer rows.Close()
+
+	holds := make([]entity.Hold, 0, limit)
+	for rows.Next() {
+		var h entity.Hold
+		if err := rows.Scan(&h.ID, &h.UserID); err != nil {
+			return nil, g.logAndReturnError(ctx, "GetExpiredHolds - Scan", err)
+		}
+		holds = append(holds, h)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "GetExpiredHolds - rows.Err", err)
+	}
+	return holds, nil
+}
@@ -0,0 +1,116 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/postgres"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:generate mockgen -source=idempotency_pg.go -destination=./mocks/mock_idempotency.go -package=mocks
+type IdempotencyRepository interface {
+	ClaimIdempotencyKey(ctx context.Context, k entity.IdempotencyKey, lease time.Duration) (bool, error)
+	GetIdempotencyKey(ctx context.Context, userID uint, key string) (*entity.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, k entity.IdempotencyKey, ttl time.Duration) error
+	RenewIdempotencyKey(ctx context.Context, k entity.IdempotencyKey, lease time.Duration) error
+	DeleteIdempotencyKey(ctx context.Context, userID uint, key string) error
+}
+
+func NewIdempotencyRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
+	return &GopherMartRepo{
+		pg:     pg,
+		Logger: l,
+		pool:   pool,
+	}
+}
+
+// ClaimIdempotencyKey занимает ключ на время lease. Просроченную запись
+// (в том числе брошенную упавшим запросом) можно занять заново.
+// false означает, что ключ уже занят действующей записью.
+func (g *GopherMartRepo) ClaimIdempotencyKey(ctx context.Context, k entity.IdempotencyKey, lease time.Duration) (bool, error) {
+	const queryClaimKey = `
+	INSERT INTO idempotency_keys (user_id, idempotency_key, fingerprint, state, expires_at)
+	VALUES ($1, $2, $3, 'IN_PROGRESS', NOW() + make_interval(secs => $4))
+	ON CONFLICT (user_id, idempotency_key) DO UPDATE SET
+		fingerprint = EXCLUDED.fingerprint,
+		state = EXCLUDED.state,
+		status_code = NULL,
+		content_type = NULL,
+		response_body = NULL,
+		created_at = CURRENT_TIMESTAMP,
+		expires_at = EXCLUDED.expires_at
+	WHERE idempotency_keys.expires_at < NOW()
+	RETURNING user_id`
+	var userID uint
+	err := g.conn(ctx).QueryRow(ctx, queryClaimKey, k.UserID, k.Key, k.Fingerprint, lease.Seconds()).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, g.logAndReturnError(ctx, "ClaimIdempotencyKey - QueryRow", err)
+	}
+	return true, nil
+}
+
+// GetIdempotencyKey возвращает действующую запись ключа или nil
+func (g *GopherMartRepo) GetIdempotencyKey(ctx context.Context, userID uint, key string) (*entity.IdempotencyKey, error) {
+	const queryGetKey = `
+	SELECT user_id, idempotency_key, fingerprint, state,
+		COALESCE(status_code, 0), COALESCE(content_type, ''), response_body, created_at, expires_at
+	FROM idempotency_keys
+	WHERE user_id = $1 AND idempotency_key = $2 AND expires_at >= NOW()`
+	var k entity.IdempotencyKey
+	err := g.conn(ctx).QueryRow(ctx, queryGetKey, userID, key).Scan(&k.UserID, &k.Key, &k.Fingerprint, &k.State,
+		&k.StatusCode, &k.ContentType, &k.Body, &k.CreatedAt, &k.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetIdempotencyKey - QueryRow", err)
+	}
+	return &k, nil
+}
+
+// CompleteIdempotencyKey сохраняет ответ и продлевает запись на ttl
+func (g *GopherMartRepo) CompleteIdempotencyKey(ctx context.Context, k entity.IdempotencyKey, ttl time.Duration) error {
+	const queryCompleteKey = `
+	UPDATE idempotency_keys SET
+		state = 'COMPLETED',
+		status_code = $3,
+		content_type = NULLIF($4, ''),
+		response_body = $5,
+		expires_at = NOW() + make_interval(secs => $6)
+	WHERE user_id = $1 AND idempotency_key = $2 AND fingerprint = $7`
+	_, err := g.conn(ctx).Exec(ctx, queryCompleteKey, k.UserID, k.Key, k.StatusCode, k.ContentType, k.Body,
+		ttl.Seconds(), k.Fingerprint)
+	if err != nil {
+		return g.logAndReturnError(ctx, "CompleteIdempotencyKey - Exec", err)
+	}
+	return nil
+}
+
+// RenewIdempotencyKey продлевает аренду ключа, который еще ждет ответа.
+// Сохраненный ответ хранится свой срок и не продлевается.
+func (g *GopherMartRepo) RenewIdempotencyKey(ctx context.Context, k entity.IdempotencyKey, lease time.Duration) error {
+	const queryRenewKey = `
+	UPDATE idempotency_keys SET expires_at = NOW() + make_interval(secs => $4)
+	WHERE user_id = $1 AND idempotency_key = $2 AND fingerprint = $3 AND state = 'IN_PROGRESS'`
+	if _, err := g.conn(ctx).Exec(ctx, queryRenewKey, k.UserID, k.Key, k.Fingerprint, lease.Seconds()); err != nil {
+		return g.logAndReturnError(ctx, "RenewIdempotencyKey - Exec", err)
+	}
+	return nil
+}
+
+// DeleteIdempotencyKey освобождает ключ, чтобы запрос можно было повторить
+func (g *GopherMartRepo) DeleteIdempotencyKey(ctx context.Context, userID uint, key string) error {
+	const queryDeleteKey = `DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2`
+	if _, err := g.conn(ctx).Exec(ctx, queryDeleteKey, userID, key); err != nil {
+		return g.logAndReturnError(ctx, "DeleteIdempotencyKey - Exec", err)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package repo
+
+import (
+	"context"
+	"go-loyalty-system/internal/entity"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ledgerPosting проводка по журналу: Amount меняет счет пользователя,
+// встречная запись на Counter получает -Amount
+type ledgerPosting struct {
+	UserID       uint
+	Kind         entity.LedgerKind
+	Counter      entity.LedgerAccount
+	Amount       entity.Points
+	OrderID      *int
+	WithdrawalID *int64
+	Reverses     *string
+	Description  string
+}
+
+// postLedgerTx записывает обе стороны проводки в рамках транзакции
+func (g *GopherMartRepo) postLedgerTx(ctx context.Context, tx pgx.Tx, p ledgerPosting) (string, error) {
+	const queryPostLedger = `
+	INSERT INTO ledger_entries
+		(transaction_id, account, user_id, kind, amount, order_id, withdrawal_id, reverses_transaction_id, description)
+	VALUES
+		($1, 'USER', $2, $3, $4, $5, $6, $7::uuid, NULLIF($8, '')),
+		($1, $9, $2, $3, $10, $5, $6, $7::uuid, NULLIF($8, ''))`
+	txID := uuid.New()
+	_, err := tx.Exec(ctx, queryPostLedger,
+		txID, p.UserID, p.Kind, p.Amount, p.OrderID, p.WithdrawalID, p.Reverses, p.Description,
+		p.Counter, p.Amount.Neg())
+	if err != nil {
+		return "", g.logAndReturnError(ctx, "postLedgerTx - insert entries", err)
+	}
+	return txID.String(), nil
+}
+
+// applyBalanceTx сдвигает проекцию баланса на величину проводки
+func (g *GopherMartRepo) applyBalanceTx(ctx context.Context, tx pgx.Tx, userID uint, current, withdrawn entity.Points) error {
+	const queryApplyBalance = `
+	INSERT INTO balance (user_id, current_balance, withdrawn, updated)
+	VALUES ($1, $2, $3, 'applyBalanceTx')
+	ON CONFLICT (user_id) DO UPDATE SET
+		current_balance = balance.current_balance + EXCLUDED.current_balance,
+		withdrawn = balance.withdrawn + EXCLUDED.withdrawn,
+		updated = EXCLUDED.updated`
+	if _, err := tx.Exec(ctx, queryApplyBalance, userID, current, withdrawn); err != nil {
+		return g.logAndReturnError(ctx, "applyBalanceTx - upsert balance", err)
+	}
+	return nil
+}
+
+// applyHoldTx переносит amount из доступного баланса в резерв; отрицательная сумма возвращает из резерва
+func (g *GopherMartRepo) applyHoldTx(ctx context.Context, tx pgx.Tx, userID uint, amount entity.Points) error {
+	const queryApplyHold = `
+	UPDATE balance
+	SET current_balance = current_balance - $2, held = held + $2, updated = 'applyHoldTx'
+	WHERE user_id = $1`
+	if _, err := tx.Exec(ctx, queryApplyHold, userID, amount); err != nil {
+		return g.logAndReturnError(ctx, "applyHoldTx - update balance", err)
+	}
+	return nil
+}
+
+// GetLedgerBalance считает баланс пользователя по журналу, минуя проекцию
+func (g *GopherMartRepo) GetLedgerBalance(ctx context.Context, userID uint) (*entity.Balance, error) {
+	const queryLedgerBalance = `
+	SELECT
+		COALESCE(SUM(amount) FILTER (WHERE account = 'USER'), 0),
+		COALESCE(SUM(amount) FILTER (WHERE account = 'HELD'), 0),
+		COALESCE(SUM(amount) FILTER (WHERE account = 'REDEEMED'), 0)
+	FROM ledger_entries
+	WHERE user_id = $1`
+	var balance entity.Balance
+	err := g.conn(ctx).QueryRow(ctx, queryLedgerBalance, userID).Scan(&balance.Current, &balance.Held, &balance.Withdrawn)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetLedgerBalance - QueryRow", err)
+	}
+	return &balance, nil
+}
+
+// GetLedgerEntries возвращает все проводки пользователя по порядку
+func (g *GopherMartRepo) GetLedgerEntries(ctx context.Context, userID uint) ([]entity.LedgerEntry, error) {
+	const queryLedgerEntries = `
+	SELECT
+		l.id,
+		l.transaction_id::text,
+		l.account,
+		l.user_id,
+		l.kind,
+		l.amount,
+		COALESCE(CAST(o.number AS TEXT), ''),
+		l.withdrawal_id,
+		COALESCE(l.description, ''),
+		l.created_at
+	FROM ledger_entries l
+	LEFT JOIN orders o ON o.id = l.order_id
+	WHERE l.user_id = $1
+	ORDER BY l.id`
+	rows, err := g.conn(ctx).Query(ctx, queryLedgerEntries, userID)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetLedgerEntries - Query", err)
+	}
+	defer rows.Close()
+
+	entries := make([]entity.LedgerEntry, 0, _defaultEntityCap)
+	for rows.Next() {
+		var e entity.LedgerEntry
+		err := rows.Scan(&e.ID, &e.TransactionID, &e.Account, &e.UserID, &e.Kind, &e.Amount,
+			&e.OrderNumber, &e.WithdrawalID, &e.Description, &e.CreatedAt)
+		if err != nil {
+			return nil, g.logAndReturnError(ctx, "GetLedgerEntries - Scan", err)
+		}
+		entries = append(entries, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "GetLedgerEntries - rows.Err", err)
+	}
+	return entries, nil
+}
+
+// GetBalanceHistory страница проводок по счету пользователя с балансом после каждой.
+// Баланс считается по всему журналу до фильтров, поэтому он верен на любой странице.
+func (g *GopherMartRepo) GetBalanceHistory(ctx context.Context,
+	userID uint,
+	f entity.ListFilter) ([]entity.BalanceHistoryEntry, error) {
+	entries := g.pg.Builder.
+		Select("l.id", "l.kind", "l.amount", "COALESCE(CAST(o.number AS TEXT), '') AS order_number", "l.created_at",
+			"SUM(l.amount) OVER (ORDER BY l.created_at, l.id) AS balance").
+		From("ledger_entries AS l").
+		LeftJoin("orders AS o ON o.id = l.order_id").
+		Where(squirrel.Eq{"l.user_id": userID, "l.account": entity.LedgerAccountUser})
+	q := g.pg.Builder.
+		Select("h.id", "h.kind", "h.amount", "h.order_number", "h.balance", "h.created_at").
+		FromSelect(entries, "h")
+	sql, args, err := pageQuery(q, "h.created_at", "h.id", f).ToSql()
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetBalanceHistory - ToSql", err)
+	}
+	rows, err := g.conn(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetBalanceHistory - Query", err)
+	}
+	defer rows.Close()
+
+	history := make([]entity.BalanceHistoryEntry, 0, _defaultEntityCap)
+	for rows.Next() {
+		var e entity.BalanceHistoryEntry
+		if err := rows.Scan(&e.ID, &e.Type, &e.Amount, &e.OrderNumber, &e.Balance, &e.CreatedAt); err != nil {
+			return nil, g.logAndReturnError(ctx, "GetBalanceHistory - Scan", err)
+		}
+		history = append(history, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "GetBalanceHistory - rows.Err", err)
+	}
+	return history, nil
+}
+
+// GetBalanceAt доступный баланс пользователя по журналу на момент at, не включая его
+func (g *GopherMartRepo) GetBalanceAt(ctx context.Context, userID uint, at time.Time) (entity.Points, error) {
+	const queryBalanceAt = `
+	SELECT COALESCE(SUM(amount), 0)
+	FROM ledger_entries
+	WHERE user_id = $1 AND account = 'USER' AND created_at < $2`
+	var balance entity.Points
+	if err := g.conn(ctx).QueryRow(ctx, queryBalanceAt, userID, at).Scan(&balance); err != nil {
+		return 0, g.logAndReturnError(ctx, "GetBalanceAt - QueryRow", err)
+	}
+	return balance, nil
+}
+
+// StreamStatement передает в fn заказы и проводки по счету пользователя за период
+// по порядку времени, не собирая их в памяти. Ошибка fn прерывает выборку.
+func (g *GopherMartRepo) StreamStatement(ctx context.Context,
+	userID uint,
+	p entity.StatementPeriod,
+	fn func(entity.StatementLine) error) error {
+	const queryStatement = `
+	SELECT 0 AS src, o.id, 'ORDER', CAST(o.number AS TEXT), COALESCE(s.status, ''), NULL::numeric, o.uploaded_at
+	FROM orders o
+	LEFT JOIN statuses s ON s.id = o.status_id
+	WHERE o.user_id = $1
+		AND ($2::timestamp IS NULL OR o.uploaded_at >= $2)
+		AND o.uploaded_at < $3
+	UNION ALL
+	SELECT 1, l.id, l.kind, COALESCE(CAST(o.number AS TEXT), ''), '', l.amount, l.created_at
+	FROM ledger_entries l
+	LEFT JOIN orders o ON o.id = l.order_id
+	WHERE l.user_id = $1 AND l.account = 'USER'
+		AND ($2::timestamp IS NULL OR l.created_at >= $2)
+		AND l.created_at < $3
+	ORDER BY 7, 1, 2`
+	rows, err := g.conn(ctx).Query(ctx, queryStatement, userID, p.From, p.To)
+	if err != nil {
+		return g.logAndReturnError(ctx, "StreamStatement - Query", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var src int
+		var line entity.StatementLine
+		if err := rows.Scan(&src, &line.ID, &line.Type, &line.OrderNumber, &line.Status, &line.Amount, &line.At); err != nil {
+			return g.logAndReturnError(ctx, "StreamStatement - Scan", err)
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return g.logAndReturnError(ctx, "StreamStatement - rows.Err", err)
+	}
+	return nil
+}
+
+// RebuildBalance пересобирает проекцию баланса пользователя из журнала
+func (g *GopherMartRepo) RebuildBalance(ctx context.Context, userID uint) error {
+	const queryRebuildBalance = `
+	INSERT INTO balance (user_id, current_balance, held, withdrawn, updated)
+	SELECT
+		$1,
+		COALESCE(SUM(amount) FILTER (WHERE account = 'USER'), 0),
+		COALESCE(SUM(amount) FILTER (WHERE account = 'HELD'), 0),
+		COALESCE(SUM(amount) FILTER (WHERE account = 'REDEEMED'), 0),
+		'RebuildBalance'
+	FROM ledger_entries
+	WHERE user_id = $1
+	ON CONFLICT (user_id) DO UPDATE SET
+		current_balance = EXCLUDED.current_balance,
+		held = EXCLUDED.held,
+		withdrawn = EXCLUDED.withdrawn,
+		updated = EXCLUDED.updated`
+	if _, err := g.conn(ctx).Exec(ctx, queryRebuildBalance, userID); err != nil {
+		return g.logAndReturnError(ctx, "RebuildBalance - Exec", err)
+	}
+	return nil
+}
+
+// AdjustBalance ручная корректировка: положительная сумма начисляет, отрицательная списывает
+func (g *GopherMartRepo) AdjustBalance(ctx context.Context, userID uint, amount entity.Points, reason string) error {
+	tx, err := g.conn(ctx).Begin(ctx)
+	if err != nil {
+		return g.logAndReturnError(ctx, "AdjustBalance - begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	_, err = g.postLedgerTx(ctx, tx, ledgerPosting{
+		UserID:      userID,
+		Kind:        entity.LedgerAdjustment,
+		Counter:     entity.LedgerAccountAdjustment,
+		Amount:      amount,
+		Description: reason,
+	})
+	if err != nil {
+		return err
+	}
+	if err = g.applyBalanceTx(ctx, tx, userID, amount, 0); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return g.logAndReturnError(ctx, "AdjustBalance - commit transaction", err)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package repo
+
+import (
+	"context"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/postgres"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:generate mockgen -source=lot_pg.go -destination=./mocks/mock_lot.go -package=mocks
+type PointLotRepository interface {
+	CreatePointLot(ctx context.Context, lot entity.PointLot) error
+	ConsumePointLots(ctx context.Context, userID uint, amount entity.Points, cutoff time.Time) ([]entity.PointLot, error)
+	GetExpiringPointLots(ctx context.Context, userID uint, cutoff, until time.Time) ([]entity.PointLot, error)
+	GetUsersWithExpiredLots(ctx context.Context, cutoff time.Time, limit int) ([]uint, error)
+	ExpireUserLots(ctx context.Context, userID uint, cutoff time.Time) (entity.Points, error)
+}
+
+func NewPointLotRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
+	return &GopherMartRepo{
+		pg:     pg,
+		Logger: l,
+		pool:   pool,
+	}
+}
+
+// CreatePointLot заводит партию на начисленные баллы
+func (g *GopherMartRepo) CreatePointLot(ctx context.Context, lot entity.PointLot) error {
+	const queryCreateLot = `
+	INSERT INTO point_lots (user_id, order_id, amount, remaining, expires_at)
+	VALUES ($1, (SELECT id FROM orders WHERE number = NULLIF($2, '')), $3, $3, $4)`
+	if _, err := g.conn(ctx).Exec(ctx, queryCreateLot, lot.UserID, lot.OrderNumber, lot.Amount, lot.ExpiresAt); err != nil {
+		return g.logAndReturnError(ctx, "CreatePointLot - Exec", err)
+	}
+	return nil
+}
+
+// ConsumePointLots расходует amount из несгоревших партий пользователя, начиная
+// с тех, что сгорают раньше; бессрочные партии расходуются последними.
+// Партии со сроком не позже cutoff уже сгорели и не трогаются.
+// Возвращает затронутые партии в порядке расхода; Amount — сколько взято из партии.
+func (g *GopherMartRepo) ConsumePointLots(ctx context.Context,
+	userID uint,
+	amount entity.Points,
+	cutoff time.Time) ([]entity.PointLot, error) {
+	// оконные функции несовместимы с FOR UPDATE, поэтому партии блокируются отдельно
+	const queryLockLots = `
+	SELECT id
+	FROM point_lots
+	WHERE user_id = $1 AND remaining > 0
+	FOR UPDATE`
+	if _, err := g.conn(ctx).Exec(ctx, queryLockLots, userID); err != nil {
+		return nil, g.logAndReturnError(ctx, "ConsumePointLots - lock lots", err)
+	}
+
+	const queryConsumeLots = `
+	WITH ordered AS (
+		SELECT id, remaining,
+			SUM(remaining) OVER (ORDER BY expires_at NULLS LAST, id) - remaining AS consumed_before
+		FROM point_lots
+		WHERE user_id = $1 AND remaining > 0 AND (expires_at IS NULL OR expires_at > $3)
+	), consumed AS (
+		UPDATE point_lots AS l
+		SET remaining = l.remaining - LEAST(o.remaining, $2 - o.consumed_before)
+		FROM ordered AS o
+		WHERE l.id = o.id AND o.consumed_before < $2
+		RETURNING l.id, LEAST(o.remaining, $2 - o.consumed_before) AS consumed, l.expires_at
+	)
+	SELECT id, consumed, expires_at FROM consumed
+	ORDER BY expires_at NULLS LAST, id`
+	rows, err := g.conn(ctx).Query(ctx, queryConsumeLots, userID, amount, cutoff)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "ConsumePointLots - Query", err)
+	}
+	defer rows.Close()
+
+	lots := make([]entity.PointLot, 0, _defaultEntityCap)
+	for rows.Next() {
+		l := entity.PointLot{UserID: userID}
+		if err := rows.Scan(&l.ID, &l.Amount, &l.ExpiresAt); err != nil {
+			return nil, g.logAndReturnError(ctx, "ConsumePointLots - Scan", err)
+		}
+		lots = append(lots, l)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "ConsumePointLots - rows.Err", err)
+	}
+	return lots, nil
+}
+
+// GetExpiringPointLots несгоревшие партии пользователя со сроком до until
+func (g *GopherMartRepo) GetExpiringPointLots(ctx context.Context,
+	userID uint,
+	cutoff, until time.Time) ([]entity.PointLot, error) {
+	const queryExpiringLots = `
+	SELECT l.id, l.user_id, COALESCE(CAST(o.number AS TEXT), ''), l.amount, l.remaining, l.expires_at, l.created_at
+	FROM point_lots AS l
+	LEFT JOIN orders AS o ON o.id = l.order_id
+	WHERE l.user_id = $1 AND l.remaining > 0 AND l.expires_at > $2 AND l.expires_at <= $3
+	ORDER BY l.expires_at, l.id`
+	rows, err := g.conn(ctx).Query(ctx, queryExpiringLots, userID, cutoff, until)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetExpiringPointLots - Query", err)
+	}
+	defer rows.Close()
+
+	lots := make([]entity.PointLot, 0, _defaultEntityCap)
+	for rows.Next() {
+		var l entity.PointLot
+		if err := rows.Scan(&l.ID, &l.UserID, &l.OrderNumber, &l.Amount, &l.Remaining, &l.ExpiresAt, &l.CreatedAt); err != nil {
+			return nil, g.logAndReturnError(ctx, "GetExpiringPointLots - Scan", err)
+		}
+		lots = append(lots, l)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "GetExpiringPointLots - rows.Err", err)
+	}
+	return lots, nil
+}
+
+// GetUsersWithExpiredLots пользователи, у которых есть сгоревшие, но еще не списанные партии
+func (g *GopherMartRepo) GetUsersWithExpiredLots(ctx context.Context, cutoff time.Time, limit int) ([]uint, error) {
+	const queryExpiredUsers = `
+	SELECT DISTINCT user_id
+	FROM point_lots
+	WHERE remaining > 0 AND expires_at <= $1
+	ORDER BY user_id
+	LIMIT $2`
+	rows, err := g.conn(ctx).Query(ctx, queryExpiredUsers, cutoff, limit)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetUsersWithExpiredLots - Query", err)
+	}
+	defer rows.Close()
+
+	users := make([]uint, 0, limit)
+	for rows.Next() {
+		var userID uint
+		if err := rows.Scan(&userID); err != nil {
+			return nil, g.logAndReturnError(ctx, "GetUsersWithExpiredLots - Scan", err)
+		}
+		users = append(users, userID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "GetUsersWithExpiredLots - rows.Err", err)
+	}
+	return users, nil
+}
+
+// ExpireUserLots обнуляет сгоревшие партии пользователя и списывает их остаток
+// проводкой EXPIRY. Возвращает сгоревшую сумму; ноль — списывать было нечего.
+func (g *GopherMartRepo) ExpireUserLots(ctx context.Context, userID uint, cutoff time.Time) (entity.Points, error) {
+	tx, err := g.conn(ctx).Begin(ctx)
+	if err != nil {
+		return 0, g.logAndReturnError(ctx, "ExpireUserLots - begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	const queryExpireLots = `
+	WITH expired AS (
+		SELECT id, remaining
+		FROM point_lots
+		WHERE user_id = $1 AND remaining > 0 AND expires_at <= $2
+		FOR UPDATE
+	), cleared AS (
+		UPDATE point_lots AS l
+		SET remaining = 0
+		FROM expired AS e
+		WHERE l.id = e.id
+	)
+	SELECT COALESCE(SUM(remaining), 0) FROM expired`
+	var expired entity.Points
+	if err = tx.QueryRow(ctx, queryExpireLots, userID, cutoff).Scan(&expired); err != nil {
+		return 0, g.logAndReturnError(ctx, "ExpireUserLots - expire lots", err)
+	}
+	if !expired.IsPositive() {
+		return 0, nil
+	}
+
+	_, err = g.postLedgerTx(ctx, tx, ledgerPosting{
+		UserID:      userID,
+		Kind:        entity.LedgerExpiry,
+		Counter:     entity.LedgerAccountExpired,
+		Amount:      expired.Neg(),
+		Description: "points expired",
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err = g.applyBalanceTx(ctx, tx, userID, expired.Neg(), 0); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, g.logAndReturnError(ctx, "ExpireUserLots - commit transaction", err)
+	}
+	return expired, nil
+}
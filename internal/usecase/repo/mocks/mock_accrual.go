@@ -6,7 +6,9 @@ package mocks
 
 import (
 	context "context"
+	entity "go-loyalty-system/internal/entity"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,6 +36,49 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClaimAccrualJobs mocks base method.
+func (m *MockRepository) ClaimAccrualJobs(ctx context.Context, workerID string, limit int, lease time.Duration) ([]entity.AccrualJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimAccrualJobs", ctx, workerID, limit, lease)
+	ret0, _ := ret[0].([]entity.AccrualJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimAccrualJobs indicates an expected call of ClaimAccrualJobs.
+func (mr *MockRepositoryMockRecorder) ClaimAccrualJobs(ctx, workerID, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimAccrualJobs", reflect.TypeOf((*MockRepository)(nil).ClaimAccrualJobs), ctx, workerID, limit, lease)
+}
+
+// CompleteAccrualJob mocks base method.
+func (m *MockRepository) CompleteAccrualJob(ctx context.Context, jobID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteAccrualJob", ctx, jobID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteAccrualJob indicates an expected call of CompleteAccrualJob.
+func (mr *MockRepositoryMockRecorder) CompleteAccrualJob(ctx, jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteAccrualJob", reflect.TypeOf((*MockRepository)(nil).CompleteAccrualJob), ctx, jobID)
+}
+
+// EnqueueAccrualJob mocks base method.
+func (m *MockRepository) EnqueueAccrualJob(ctx context.Context, orderNumber, traceParent string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueAccrualJob", ctx, orderNumber, traceParent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueAccrualJob indicates an expected call of EnqueueAccrualJob.
+func (mr *MockRepositoryMockRecorder) EnqueueAccrualJob(ctx, orderNumber, traceParent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueAccrualJob", reflect.TypeOf((*MockRepository)(nil).EnqueueAccrualJob), ctx, orderNumber, traceParent)
+}
+
 // ExistOrderAccrual mocks base method.
 func (m *MockRepository) ExistOrderAccrual(ctx context.Context, orderNumber string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -49,6 +94,20 @@ func (mr *MockRepositoryMockRecorder) ExistOrderAccrual(ctx, orderNumber interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistOrderAccrual", reflect.TypeOf((*MockRepository)(nil).ExistOrderAccrual), ctx, orderNumber)
 }
 
+// FailAccrualJob mocks base method.
+func (m *MockRepository) FailAccrualJob(ctx context.Context, jobID int64, lastErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailAccrualJob", ctx, jobID, lastErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailAccrualJob indicates an expected call of FailAccrualJob.
+func (mr *MockRepositoryMockRecorder) FailAccrualJob(ctx, jobID, lastErr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailAccrualJob", reflect.TypeOf((*MockRepository)(nil).FailAccrualJob), ctx, jobID, lastErr)
+}
+
 // GetUnprocessedOrders mocks base method.
 func (m *MockRepository) GetUnprocessedOrders(ctx context.Context) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -64,8 +123,36 @@ func (mr *MockRepositoryMockRecorder) GetUnprocessedOrders(ctx interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnprocessedOrders", reflect.TypeOf((*MockRepository)(nil).GetUnprocessedOrders), ctx)
 }
 
+// PostponeAccrualJob mocks base method.
+func (m *MockRepository) PostponeAccrualJob(ctx context.Context, jobID int64, delay time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostponeAccrualJob", ctx, jobID, delay)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostponeAccrualJob indicates an expected call of PostponeAccrualJob.
+func (mr *MockRepositoryMockRecorder) PostponeAccrualJob(ctx, jobID, delay interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostponeAccrualJob", reflect.TypeOf((*MockRepository)(nil).PostponeAccrualJob), ctx, jobID, delay)
+}
+
+// RetryAccrualJob mocks base method.
+func (m *MockRepository) RetryAccrualJob(ctx context.Context, jobID int64, delay time.Duration, lastErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryAccrualJob", ctx, jobID, delay, lastErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryAccrualJob indicates an expected call of RetryAccrualJob.
+func (mr *MockRepositoryMockRecorder) RetryAccrualJob(ctx, jobID, delay, lastErr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryAccrualJob", reflect.TypeOf((*MockRepository)(nil).RetryAccrualJob), ctx, jobID, delay, lastErr)
+}
+
 // SaveAccrual mocks base method.
-func (m *MockRepository) SaveAccrual(ctx context.Context, orderNumber, status string, accrual float32) error {
+func (m *MockRepository) SaveAccrual(ctx context.Context, orderNumber, status string, accrual entity.Points) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SaveAccrual", ctx, orderNumber, status, accrual)
 	ret0, _ := ret[0].(error)
@@ -77,3 +164,17 @@ func (mr *MockRepositoryMockRecorder) SaveAccrual(ctx, orderNumber, status, accr
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAccrual", reflect.TypeOf((*MockRepository)(nil).SaveAccrual), ctx, orderNumber, status, accrual)
 }
+
+// SetAccrualJobRegistered mocks base method.
+func (m *MockRepository) SetAccrualJobRegistered(ctx context.Context, jobID int64, registered bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccrualJobRegistered", ctx, jobID, registered)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAccrualJobRegistered indicates an expected call of SetAccrualJobRegistered.
+func (mr *MockRepositoryMockRecorder) SetAccrualJobRegistered(ctx, jobID, registered interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccrualJobRegistered", reflect.TypeOf((*MockRepository)(nil).SetAccrualJobRegistered), ctx, jobID, registered)
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: admin_pg.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	entity "go-loyalty-system/internal/entity"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAdminAuditRepository is a mock of AdminAuditRepository interface.
+type MockAdminAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminAuditRepositoryMockRecorder
+}
+
+// MockAdminAuditRepositoryMockRecorder is the mock recorder for MockAdminAuditRepository.
+type MockAdminAuditRepositoryMockRecorder struct {
+	mock *MockAdminAuditRepository
+}
+
+// NewMockAdminAuditRepository creates a new mock instance.
+func NewMockAdminAuditRepository(ctrl *gomock.Controller) *MockAdminAuditRepository {
+	mock := &MockAdminAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAdminAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminAuditRepository) EXPECT() *MockAdminAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAdminAudit mocks base method.
+func (m *MockAdminAuditRepository) CreateAdminAudit(ctx context.Context, e entity.AdminAuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdminAudit", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAdminAudit indicates an expected call of CreateAdminAudit.
+func (mr *MockAdminAuditRepositoryMockRecorder) CreateAdminAudit(ctx, e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdminAudit", reflect.TypeOf((*MockAdminAuditRepository)(nil).CreateAdminAudit), ctx, e)
+}
+
+// GetAdminAudit mocks base method.
+func (m *MockAdminAuditRepository) GetAdminAudit(ctx context.Context, targetUserID uint, limit int) ([]entity.AdminAuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdminAudit", ctx, targetUserID, limit)
+	ret0, _ := ret[0].([]entity.AdminAuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdminAudit indicates an expected call of GetAdminAudit.
+func (mr *MockAdminAuditRepositoryMockRecorder) GetAdminAudit(ctx, targetUserID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdminAudit", reflect.TypeOf((*MockAdminAuditRepository)(nil).GetAdminAudit), ctx, targetUserID, limit)
+}
@@ -8,9 +8,9 @@ import (
 	context "context"
 	entity "go-loyalty-system/internal/entity"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
-	pgx "github.com/jackc/pgx/v5"
 )
 
 // MockBalanceUseCase is a mock of BalanceUseCase interface.
@@ -36,19 +36,33 @@ func (m *MockBalanceUseCase) EXPECT() *MockBalanceUseCaseMockRecorder {
 	return m.recorder
 }
 
-// BeginTx mocks base method.
-func (m *MockBalanceUseCase) BeginTx(ctx context.Context) (pgx.Tx, error) {
+// AdjustBalance mocks base method.
+func (m *MockBalanceUseCase) AdjustBalance(ctx context.Context, userID uint, amount entity.Points, reason string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BeginTx", ctx)
-	ret0, _ := ret[0].(pgx.Tx)
+	ret := m.ctrl.Call(m, "AdjustBalance", ctx, userID, amount, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockBalanceUseCaseMockRecorder) AdjustBalance(ctx, userID, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockBalanceUseCase)(nil).AdjustBalance), ctx, userID, amount, reason)
+}
+
+// CreateTransferTx mocks base method.
+func (m *MockBalanceUseCase) CreateTransferTx(ctx context.Context, t entity.Transfer) (*entity.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferTx", ctx, t)
+	ret0, _ := ret[0].(*entity.Transfer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// BeginTx indicates an expected call of BeginTx.
-func (mr *MockBalanceUseCaseMockRecorder) BeginTx(ctx interface{}) *gomock.Call {
+// CreateTransferTx indicates an expected call of CreateTransferTx.
+func (mr *MockBalanceUseCaseMockRecorder) CreateTransferTx(ctx, t interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTx", reflect.TypeOf((*MockBalanceUseCase)(nil).BeginTx), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferTx", reflect.TypeOf((*MockBalanceUseCase)(nil).CreateTransferTx), ctx, t)
 }
 
 // CreateWithdrawalTx mocks base method.
@@ -80,19 +94,95 @@ func (mr *MockBalanceUseCaseMockRecorder) GetBalance(ctx, userID interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockBalanceUseCase)(nil).GetBalance), ctx, userID)
 }
 
-// GetBalanceTx mocks base method.
-func (m *MockBalanceUseCase) GetBalanceTx(ctx context.Context, tx pgx.Tx, userID uint) (*entity.Balance, error) {
+// GetBalanceAt mocks base method.
+func (m *MockBalanceUseCase) GetBalanceAt(ctx context.Context, userID uint, at time.Time) (entity.Points, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", ctx, userID, at)
+	ret0, _ := ret[0].(entity.Points)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockBalanceUseCaseMockRecorder) GetBalanceAt(ctx, userID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockBalanceUseCase)(nil).GetBalanceAt), ctx, userID, at)
+}
+
+// GetBalanceForUpdate mocks base method.
+func (m *MockBalanceUseCase) GetBalanceForUpdate(ctx context.Context, userID uint) (*entity.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceForUpdate", ctx, userID)
+	ret0, _ := ret[0].(*entity.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceForUpdate indicates an expected call of GetBalanceForUpdate.
+func (mr *MockBalanceUseCaseMockRecorder) GetBalanceForUpdate(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceForUpdate", reflect.TypeOf((*MockBalanceUseCase)(nil).GetBalanceForUpdate), ctx, userID)
+}
+
+// GetBalanceHistory mocks base method.
+func (m *MockBalanceUseCase) GetBalanceHistory(ctx context.Context, userID uint, f entity.ListFilter) ([]entity.BalanceHistoryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceHistory", ctx, userID, f)
+	ret0, _ := ret[0].([]entity.BalanceHistoryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceHistory indicates an expected call of GetBalanceHistory.
+func (mr *MockBalanceUseCaseMockRecorder) GetBalanceHistory(ctx, userID, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceHistory", reflect.TypeOf((*MockBalanceUseCase)(nil).GetBalanceHistory), ctx, userID, f)
+}
+
+// GetLedgerBalance mocks base method.
+func (m *MockBalanceUseCase) GetLedgerBalance(ctx context.Context, userID uint) (*entity.Balance, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBalanceTx", ctx, tx, userID)
+	ret := m.ctrl.Call(m, "GetLedgerBalance", ctx, userID)
 	ret0, _ := ret[0].(*entity.Balance)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBalanceTx indicates an expected call of GetBalanceTx.
-func (mr *MockBalanceUseCaseMockRecorder) GetBalanceTx(ctx, tx, userID interface{}) *gomock.Call {
+// GetLedgerBalance indicates an expected call of GetLedgerBalance.
+func (mr *MockBalanceUseCaseMockRecorder) GetLedgerBalance(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceTx", reflect.TypeOf((*MockBalanceUseCase)(nil).GetBalanceTx), ctx, tx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedgerBalance", reflect.TypeOf((*MockBalanceUseCase)(nil).GetLedgerBalance), ctx, userID)
+}
+
+// GetLedgerEntries mocks base method.
+func (m *MockBalanceUseCase) GetLedgerEntries(ctx context.Context, userID uint) ([]entity.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedgerEntries", ctx, userID)
+	ret0, _ := ret[0].([]entity.LedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedgerEntries indicates an expected call of GetLedgerEntries.
+func (mr *MockBalanceUseCaseMockRecorder) GetLedgerEntries(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedgerEntries", reflect.TypeOf((*MockBalanceUseCase)(nil).GetLedgerEntries), ctx, userID)
+}
+
+// GetSentTransfers mocks base method.
+func (m *MockBalanceUseCase) GetSentTransfers(ctx context.Context, senderID uint, since time.Time) (entity.Points, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSentTransfers", ctx, senderID, since)
+	ret0, _ := ret[0].(entity.Points)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSentTransfers indicates an expected call of GetSentTransfers.
+func (mr *MockBalanceUseCaseMockRecorder) GetSentTransfers(ctx, senderID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSentTransfers", reflect.TypeOf((*MockBalanceUseCase)(nil).GetSentTransfers), ctx, senderID, since)
 }
 
 // GetUserByLogin mocks base method.
@@ -110,31 +200,103 @@ func (mr *MockBalanceUseCaseMockRecorder) GetUserByLogin(ctx, u interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockBalanceUseCase)(nil).GetUserByLogin), ctx, u)
 }
 
+// GetUserTransfers mocks base method.
+func (m *MockBalanceUseCase) GetUserTransfers(ctx context.Context, userID uint, f entity.ListFilter) ([]entity.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTransfers", ctx, userID, f)
+	ret0, _ := ret[0].([]entity.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTransfers indicates an expected call of GetUserTransfers.
+func (mr *MockBalanceUseCaseMockRecorder) GetUserTransfers(ctx, userID, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTransfers", reflect.TypeOf((*MockBalanceUseCase)(nil).GetUserTransfers), ctx, userID, f)
+}
+
 // GetUserWithdrawals mocks base method.
-func (m *MockBalanceUseCase) GetUserWithdrawals(ctx context.Context, userID uint) ([]entity.Withdrawal, error) {
+func (m *MockBalanceUseCase) GetUserWithdrawals(ctx context.Context, userID uint, f entity.ListFilter) ([]entity.Withdrawal, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserWithdrawals", ctx, userID)
+	ret := m.ctrl.Call(m, "GetUserWithdrawals", ctx, userID, f)
 	ret0, _ := ret[0].([]entity.Withdrawal)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserWithdrawals indicates an expected call of GetUserWithdrawals.
-func (mr *MockBalanceUseCaseMockRecorder) GetUserWithdrawals(ctx, userID interface{}) *gomock.Call {
+func (mr *MockBalanceUseCaseMockRecorder) GetUserWithdrawals(ctx, userID, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWithdrawals", reflect.TypeOf((*MockBalanceUseCase)(nil).GetUserWithdrawals), ctx, userID, f)
+}
+
+// GetWithdrawalForUpdate mocks base method.
+func (m *MockBalanceUseCase) GetWithdrawalForUpdate(ctx context.Context, orderNumber string) (*entity.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalForUpdate", ctx, orderNumber)
+	ret0, _ := ret[0].(*entity.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalForUpdate indicates an expected call of GetWithdrawalForUpdate.
+func (mr *MockBalanceUseCaseMockRecorder) GetWithdrawalForUpdate(ctx, orderNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalForUpdate", reflect.TypeOf((*MockBalanceUseCase)(nil).GetWithdrawalForUpdate), ctx, orderNumber)
+}
+
+// RebuildBalance mocks base method.
+func (m *MockBalanceUseCase) RebuildBalance(ctx context.Context, userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebuildBalance", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebuildBalance indicates an expected call of RebuildBalance.
+func (mr *MockBalanceUseCaseMockRecorder) RebuildBalance(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildBalance", reflect.TypeOf((*MockBalanceUseCase)(nil).RebuildBalance), ctx, userID)
+}
+
+// ReverseWithdrawal mocks base method.
+func (m *MockBalanceUseCase) ReverseWithdrawal(ctx context.Context, w entity.Withdrawal, amount entity.Points, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseWithdrawal", ctx, w, amount, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReverseWithdrawal indicates an expected call of ReverseWithdrawal.
+func (mr *MockBalanceUseCaseMockRecorder) ReverseWithdrawal(ctx, w, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseWithdrawal", reflect.TypeOf((*MockBalanceUseCase)(nil).ReverseWithdrawal), ctx, w, amount, reason)
+}
+
+// StreamStatement mocks base method.
+func (m *MockBalanceUseCase) StreamStatement(ctx context.Context, userID uint, p entity.StatementPeriod, fn func(entity.StatementLine) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamStatement", ctx, userID, p, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamStatement indicates an expected call of StreamStatement.
+func (mr *MockBalanceUseCaseMockRecorder) StreamStatement(ctx, userID, p, fn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWithdrawals", reflect.TypeOf((*MockBalanceUseCase)(nil).GetUserWithdrawals), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamStatement", reflect.TypeOf((*MockBalanceUseCase)(nil).StreamStatement), ctx, userID, p, fn)
 }
 
 // UpdateBalanceTx mocks base method.
-func (m *MockBalanceUseCase) UpdateBalanceTx(ctx context.Context, tx pgx.Tx, userID uint, amount float32) error {
+func (m *MockBalanceUseCase) UpdateBalanceTx(ctx context.Context, userID uint, amount entity.Points) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateBalanceTx", ctx, tx, userID, amount)
+	ret := m.ctrl.Call(m, "UpdateBalanceTx", ctx, userID, amount)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateBalanceTx indicates an expected call of UpdateBalanceTx.
-func (mr *MockBalanceUseCaseMockRecorder) UpdateBalanceTx(ctx, tx, userID, amount interface{}) *gomock.Call {
+func (mr *MockBalanceUseCaseMockRecorder) UpdateBalanceTx(ctx, userID, amount interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalanceTx", reflect.TypeOf((*MockBalanceUseCase)(nil).UpdateBalanceTx), ctx, tx, userID, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalanceTx", reflect.TypeOf((*MockBalanceUseCase)(nil).UpdateBalanceTx), ctx, userID, amount)
 }
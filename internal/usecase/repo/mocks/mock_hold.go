@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: hold_pg.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	entity "go-loyalty-system/internal/entity"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockHoldRepository is a mock of HoldRepository interface.
+type MockHoldRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHoldRepositoryMockRecorder
+}
+
+// MockHoldRepositoryMockRecorder is the mock recorder for MockHoldRepository.
+type MockHoldRepositoryMockRecorder struct {
+	mock *MockHoldRepository
+}
+
+// NewMockHoldRepository creates a new mock instance.
+func NewMockHoldRepository(ctrl *gomock.Controller) *MockHoldRepository {
+	mock := &MockHoldRepository{ctrl: ctrl}
+	mock.recorder = &MockHoldRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHoldRepository) EXPECT() *MockHoldRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateHold mocks base method.
+func (m *MockHoldRepository) CreateHold(ctx context.Context, h entity.Hold) (*entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, h)
+	ret0, _ := ret[0].(*entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockHoldRepositoryMockRecorder) CreateHold(ctx, h interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockHoldRepository)(nil).CreateHold), ctx, h)
+}
+
+// GetExpiredHolds mocks base method.
+func (m *MockHoldRepository) GetExpiredHolds(ctx context.Context, now time.Time, limit int) ([]entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredHolds", ctx, now, limit)
+	ret0, _ := ret[0].([]entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredHolds indicates an expected call of GetExpiredHolds.
+func (mr *MockHoldRepositoryMockRecorder) GetExpiredHolds(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredHolds", reflect.TypeOf((*MockHoldRepository)(nil).GetExpiredHolds), ctx, now, limit)
+}
+
+// GetHoldForUpdate mocks base method.
+func (m *MockHoldRepository) GetHoldForUpdate(ctx context.Context, userID uint, holdID int64) (*entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldForUpdate", ctx, userID, holdID)
+	ret0, _ := ret[0].(*entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldForUpdate indicates an expected call of GetHoldForUpdate.
+func (mr *MockHoldRepositoryMockRecorder) GetHoldForUpdate(ctx, userID, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldForUpdate", reflect.TypeOf((*MockHoldRepository)(nil).GetHoldForUpdate), ctx, userID, holdID)
+}
+
+// SettleHold mocks base method.
+func (m *MockHoldRepository) SettleHold(ctx context.Context, h entity.Hold, status entity.HoldStatus, captured entity.Points) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SettleHold", ctx, h, status, captured)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SettleHold indicates an expected call of SettleHold.
+func (mr *MockHoldRepositoryMockRecorder) SettleHold(ctx, h, status, captured interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SettleHold", reflect.TypeOf((*MockHoldRepository)(nil).SettleHold), ctx, h, status, captured)
+}
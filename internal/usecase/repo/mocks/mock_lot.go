@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: lot_pg.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	entity "go-loyalty-system/internal/entity"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPointLotRepository is a mock of PointLotRepository interface.
+type MockPointLotRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPointLotRepositoryMockRecorder
+}
+
+// MockPointLotRepositoryMockRecorder is the mock recorder for MockPointLotRepository.
+type MockPointLotRepositoryMockRecorder struct {
+	mock *MockPointLotRepository
+}
+
+// NewMockPointLotRepository creates a new mock instance.
+func NewMockPointLotRepository(ctrl *gomock.Controller) *MockPointLotRepository {
+	mock := &MockPointLotRepository{ctrl: ctrl}
+	mock.recorder = &MockPointLotRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPointLotRepository) EXPECT() *MockPointLotRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConsumePointLots mocks base method.
+func (m *MockPointLotRepository) ConsumePointLots(ctx context.Context, userID uint, amount entity.Points, cutoff time.Time) ([]entity.PointLot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumePointLots", ctx, userID, amount, cutoff)
+	ret0, _ := ret[0].([]entity.PointLot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumePointLots indicates an expected call of ConsumePointLots.
+func (mr *MockPointLotRepositoryMockRecorder) ConsumePointLots(ctx, userID, amount, cutoff interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumePointLots", reflect.TypeOf((*MockPointLotRepository)(nil).ConsumePointLots), ctx, userID, amount, cutoff)
+}
+
+// CreatePointLot mocks base method.
+func (m *MockPointLotRepository) CreatePointLot(ctx context.Context, lot entity.PointLot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePointLot", ctx, lot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePointLot indicates an expected call of CreatePointLot.
+func (mr *MockPointLotRepositoryMockRecorder) CreatePointLot(ctx, lot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePointLot", reflect.TypeOf((*MockPointLotRepository)(nil).CreatePointLot), ctx, lot)
+}
+
+// ExpireUserLots mocks base method.
+func (m *MockPointLotRepository) ExpireUserLots(ctx context.Context, userID uint, cutoff time.Time) (entity.Points, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireUserLots", ctx, userID, cutoff)
+	ret0, _ := ret[0].(entity.Points)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireUserLots indicates an expected call of ExpireUserLots.
+func (mr *MockPointLotRepositoryMockRecorder) ExpireUserLots(ctx, userID, cutoff interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireUserLots", reflect.TypeOf((*MockPointLotRepository)(nil).ExpireUserLots), ctx, userID, cutoff)
+}
+
+// GetExpiringPointLots mocks base method.
+func (m *MockPointLotRepository) GetExpiringPointLots(ctx context.Context, userID uint, cutoff, until time.Time) ([]entity.PointLot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiringPointLots", ctx, userID, cutoff, until)
+	ret0, _ := ret[0].([]entity.PointLot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiringPointLots indicates an expected call of GetExpiringPointLots.
+func (mr *MockPointLotRepositoryMockRecorder) GetExpiringPointLots(ctx, userID, cutoff, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiringPointLots", reflect.TypeOf((*MockPointLotRepository)(nil).GetExpiringPointLots), ctx, userID, cutoff, until)
+}
+
+// GetUsersWithExpiredLots mocks base method.
+func (m *MockPointLotRepository) GetUsersWithExpiredLots(ctx context.Context, cutoff time.Time, limit int) ([]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersWithExpiredLots", ctx, cutoff, limit)
+	ret0, _ := ret[0].([]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersWithExpiredLots indicates an expected call of GetUsersWithExpiredLots.
+func (mr *MockPointLotRepositoryMockRecorder) GetUsersWithExpiredLots(ctx, cutoff, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersWithExpiredLots", reflect.TypeOf((*MockPointLotRepository)(nil).GetUsersWithExpiredLots), ctx, cutoff, limit)
+}
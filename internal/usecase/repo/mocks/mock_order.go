@@ -66,19 +66,49 @@ func (mr *MockOrderUseCaseMockRecorder) GetOrderByNumber(ctx, orderNumber interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByNumber", reflect.TypeOf((*MockOrderUseCase)(nil).GetOrderByNumber), ctx, orderNumber)
 }
 
+// GetOrderGoods mocks base method.
+func (m *MockOrderUseCase) GetOrderGoods(ctx context.Context, orderNumber string) ([]entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderGoods", ctx, orderNumber)
+	ret0, _ := ret[0].([]entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderGoods indicates an expected call of GetOrderGoods.
+func (mr *MockOrderUseCaseMockRecorder) GetOrderGoods(ctx, orderNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderGoods", reflect.TypeOf((*MockOrderUseCase)(nil).GetOrderGoods), ctx, orderNumber)
+}
+
 // GetUserOrders mocks base method.
-func (m *MockOrderUseCase) GetUserOrders(ctx context.Context, userID uint) ([]entity.OrderResponse, error) {
+func (m *MockOrderUseCase) GetUserOrders(ctx context.Context, userID uint, f entity.ListFilter) ([]entity.OrderResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserOrders", ctx, userID)
+	ret := m.ctrl.Call(m, "GetUserOrders", ctx, userID, f)
 	ret0, _ := ret[0].([]entity.OrderResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserOrders indicates an expected call of GetUserOrders.
-func (mr *MockOrderUseCaseMockRecorder) GetUserOrders(ctx, userID interface{}) *gomock.Call {
+func (mr *MockOrderUseCaseMockRecorder) GetUserOrders(ctx, userID, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrders", reflect.TypeOf((*MockOrderUseCase)(nil).GetUserOrders), ctx, userID, f)
+}
+
+// IsMerchantOrder mocks base method.
+func (m *MockOrderUseCase) IsMerchantOrder(ctx context.Context, orderNumber string, buyerID, merchantID uint) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMerchantOrder", ctx, orderNumber, buyerID, merchantID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsMerchantOrder indicates an expected call of IsMerchantOrder.
+func (mr *MockOrderUseCaseMockRecorder) IsMerchantOrder(ctx, orderNumber, buyerID, merchantID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrders", reflect.TypeOf((*MockOrderUseCase)(nil).GetUserOrders), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMerchantOrder", reflect.TypeOf((*MockOrderUseCase)(nil).IsMerchantOrder), ctx, orderNumber, buyerID, merchantID)
 }
 
 // SetOrders mocks base method.
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: order_event_pg.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	entity "go-loyalty-system/internal/entity"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOrderEventRepository is a mock of OrderEventRepository interface.
+type MockOrderEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderEventRepositoryMockRecorder
+}
+
+// MockOrderEventRepositoryMockRecorder is the mock recorder for MockOrderEventRepository.
+type MockOrderEventRepositoryMockRecorder struct {
+	mock *MockOrderEventRepository
+}
+
+// NewMockOrderEventRepository creates a new mock instance.
+func NewMockOrderEventRepository(ctrl *gomock.Controller) *MockOrderEventRepository {
+	mock := &MockOrderEventRepository{ctrl: ctrl}
+	mock.recorder = &MockOrderEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderEventRepository) EXPECT() *MockOrderEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetLastOrderEventID mocks base method.
+func (m *MockOrderEventRepository) GetLastOrderEventID(ctx context.Context, userID uint) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastOrderEventID", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastOrderEventID indicates an expected call of GetLastOrderEventID.
+func (mr *MockOrderEventRepositoryMockRecorder) GetLastOrderEventID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastOrderEventID", reflect.TypeOf((*MockOrderEventRepository)(nil).GetLastOrderEventID), ctx, userID)
+}
+
+// GetOrderEvents mocks base method.
+func (m *MockOrderEventRepository) GetOrderEvents(ctx context.Context, userID uint, afterID int64, limit int) ([]entity.OrderEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderEvents", ctx, userID, afterID, limit)
+	ret0, _ := ret[0].([]entity.OrderEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderEvents indicates an expected call of GetOrderEvents.
+func (mr *MockOrderEventRepositoryMockRecorder) GetOrderEvents(ctx, userID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderEvents", reflect.TypeOf((*MockOrderEventRepository)(nil).GetOrderEvents), ctx, userID, afterID, limit)
+}
+
+// ListenOrderEvents mocks base method.
+func (m *MockOrderEventRepository) ListenOrderEvents(ctx context.Context, notify func(uint)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListenOrderEvents", ctx, notify)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListenOrderEvents indicates an expected call of ListenOrderEvents.
+func (mr *MockOrderEventRepositoryMockRecorder) ListenOrderEvents(ctx, notify interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenOrderEvents", reflect.TypeOf((*MockOrderEventRepository)(nil).ListenOrderEvents), ctx, notify)
+}
@@ -6,8 +6,10 @@ package mocks
 
 import (
 	context "context"
+	audit "go-loyalty-system/internal/audit"
 	entity "go-loyalty-system/internal/entity"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -36,6 +38,137 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// AdjustUserBalance mocks base method.
+func (m *MockUserService) AdjustUserBalance(ctx context.Context, userID uint, amount entity.Points, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustUserBalance", ctx, userID, amount, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustUserBalance indicates an expected call of AdjustUserBalance.
+func (mr *MockUserServiceMockRecorder) AdjustUserBalance(ctx, userID, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustUserBalance", reflect.TypeOf((*MockUserService)(nil).AdjustUserBalance), ctx, userID, amount, reason)
+}
+
+// AdminAdjustBalance mocks base method.
+func (m *MockUserService) AdminAdjustBalance(ctx context.Context, actorID, userID uint, adj entity.BalanceAdjustment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminAdjustBalance", ctx, actorID, userID, adj)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdminAdjustBalance indicates an expected call of AdminAdjustBalance.
+func (mr *MockUserServiceMockRecorder) AdminAdjustBalance(ctx, actorID, userID, adj interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminAdjustBalance", reflect.TypeOf((*MockUserService)(nil).AdminAdjustBalance), ctx, actorID, userID, adj)
+}
+
+// AdminReverseWithdrawal mocks base method.
+func (m *MockUserService) AdminReverseWithdrawal(ctx context.Context, actorID uint, r entity.WithdrawalReversal) (*entity.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminReverseWithdrawal", ctx, actorID, r)
+	ret0, _ := ret[0].(*entity.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminReverseWithdrawal indicates an expected call of AdminReverseWithdrawal.
+func (mr *MockUserServiceMockRecorder) AdminReverseWithdrawal(ctx, actorID, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminReverseWithdrawal", reflect.TypeOf((*MockUserService)(nil).AdminReverseWithdrawal), ctx, actorID, r)
+}
+
+// AuthenticateUser mocks base method.
+func (m *MockUserService) AuthenticateUser(ctx context.Context, login, password string) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateUser", ctx, login, password)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateUser indicates an expected call of AuthenticateUser.
+func (mr *MockUserServiceMockRecorder) AuthenticateUser(ctx, login, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateUser", reflect.TypeOf((*MockUserService)(nil).AuthenticateUser), ctx, login, password)
+}
+
+// BeginIdempotentRequest mocks base method.
+func (m *MockUserService) BeginIdempotentRequest(ctx context.Context, k entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginIdempotentRequest", ctx, k)
+	ret0, _ := ret[0].(*entity.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginIdempotentRequest indicates an expected call of BeginIdempotentRequest.
+func (mr *MockUserServiceMockRecorder) BeginIdempotentRequest(ctx, k interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginIdempotentRequest", reflect.TypeOf((*MockUserService)(nil).BeginIdempotentRequest), ctx, k)
+}
+
+// CaptureHold mocks base method.
+func (m *MockUserService) CaptureHold(ctx context.Context, userID uint, holdID int64, r entity.HoldCapture) (*entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, userID, holdID, r)
+	ret0, _ := ret[0].(*entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockUserServiceMockRecorder) CaptureHold(ctx, userID, holdID, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockUserService)(nil).CaptureHold), ctx, userID, holdID, r)
+}
+
+// ClaimAccrualJobs mocks base method.
+func (m *MockUserService) ClaimAccrualJobs(ctx context.Context, workerID string, limit int, lease time.Duration) ([]entity.AccrualJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimAccrualJobs", ctx, workerID, limit, lease)
+	ret0, _ := ret[0].([]entity.AccrualJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimAccrualJobs indicates an expected call of ClaimAccrualJobs.
+func (mr *MockUserServiceMockRecorder) ClaimAccrualJobs(ctx, workerID, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimAccrualJobs", reflect.TypeOf((*MockUserService)(nil).ClaimAccrualJobs), ctx, workerID, limit, lease)
+}
+
+// CompleteAccrualJob mocks base method.
+func (m *MockUserService) CompleteAccrualJob(ctx context.Context, jobID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteAccrualJob", ctx, jobID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteAccrualJob indicates an expected call of CompleteAccrualJob.
+func (mr *MockUserServiceMockRecorder) CompleteAccrualJob(ctx, jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteAccrualJob", reflect.TypeOf((*MockUserService)(nil).CompleteAccrualJob), ctx, jobID)
+}
+
+// CompleteIdempotentRequest mocks base method.
+func (m *MockUserService) CompleteIdempotentRequest(ctx context.Context, k entity.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotentRequest", ctx, k)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotentRequest indicates an expected call of CompleteIdempotentRequest.
+func (mr *MockUserServiceMockRecorder) CompleteIdempotentRequest(ctx, k interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotentRequest", reflect.TypeOf((*MockUserService)(nil).CompleteIdempotentRequest), ctx, k)
+}
+
 // CreateToken mocks base method.
 func (m *MockUserService) CreateToken(ctx context.Context, t *entity.Token) error {
 	m.ctrl.T.Helper()
@@ -50,6 +183,182 @@ func (mr *MockUserServiceMockRecorder) CreateToken(ctx, t interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockUserService)(nil).CreateToken), ctx, t)
 }
 
+// EnqueueAccrual mocks base method.
+func (m *MockUserService) EnqueueAccrual(ctx context.Context, orderNumber string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueAccrual", ctx, orderNumber)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueAccrual indicates an expected call of EnqueueAccrual.
+func (mr *MockUserServiceMockRecorder) EnqueueAccrual(ctx, orderNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueAccrual", reflect.TypeOf((*MockUserService)(nil).EnqueueAccrual), ctx, orderNumber)
+}
+
+// ExportStatement mocks base method.
+func (m *MockUserService) ExportStatement(ctx context.Context, userID uint, p entity.StatementPeriod, w entity.StatementWriter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportStatement", ctx, userID, p, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportStatement indicates an expected call of ExportStatement.
+func (mr *MockUserServiceMockRecorder) ExportStatement(ctx, userID, p, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportStatement", reflect.TypeOf((*MockUserService)(nil).ExportStatement), ctx, userID, p, w)
+}
+
+// FailAccrualJob mocks base method.
+func (m *MockUserService) FailAccrualJob(ctx context.Context, jobID int64, lastErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailAccrualJob", ctx, jobID, lastErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailAccrualJob indicates an expected call of FailAccrualJob.
+func (mr *MockUserServiceMockRecorder) FailAccrualJob(ctx, jobID, lastErr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailAccrualJob", reflect.TypeOf((*MockUserService)(nil).FailAccrualJob), ctx, jobID, lastErr)
+}
+
+// ForceLogout mocks base method.
+func (m *MockUserService) ForceLogout(ctx context.Context, actorID, userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForceLogout", ctx, actorID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForceLogout indicates an expected call of ForceLogout.
+func (mr *MockUserServiceMockRecorder) ForceLogout(ctx, actorID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceLogout", reflect.TypeOf((*MockUserService)(nil).ForceLogout), ctx, actorID, userID)
+}
+
+// GetAdminAudit mocks base method.
+func (m *MockUserService) GetAdminAudit(ctx context.Context, actorID, userID uint) ([]entity.AdminAuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdminAudit", ctx, actorID, userID)
+	ret0, _ := ret[0].([]entity.AdminAuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdminAudit indicates an expected call of GetAdminAudit.
+func (mr *MockUserServiceMockRecorder) GetAdminAudit(ctx, actorID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdminAudit", reflect.TypeOf((*MockUserService)(nil).GetAdminAudit), ctx, actorID, userID)
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockUserService) GetAuditEvents(ctx context.Context, actorID uint, f audit.Filter) (*audit.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", ctx, actorID, f)
+	ret0, _ := ret[0].(*audit.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockUserServiceMockRecorder) GetAuditEvents(ctx, actorID, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockUserService)(nil).GetAuditEvents), ctx, actorID, f)
+}
+
+// GetBalanceHistory mocks base method.
+func (m *MockUserService) GetBalanceHistory(ctx context.Context, userID uint, f entity.ListFilter) (*entity.BalanceHistoryPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceHistory", ctx, userID, f)
+	ret0, _ := ret[0].(*entity.BalanceHistoryPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceHistory indicates an expected call of GetBalanceHistory.
+func (mr *MockUserServiceMockRecorder) GetBalanceHistory(ctx, userID, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceHistory", reflect.TypeOf((*MockUserService)(nil).GetBalanceHistory), ctx, userID, f)
+}
+
+// GetLastOrderEventID mocks base method.
+func (m *MockUserService) GetLastOrderEventID(ctx context.Context, userID uint) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastOrderEventID", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastOrderEventID indicates an expected call of GetLastOrderEventID.
+func (mr *MockUserServiceMockRecorder) GetLastOrderEventID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastOrderEventID", reflect.TypeOf((*MockUserService)(nil).GetLastOrderEventID), ctx, userID)
+}
+
+// GetLedgerEntries mocks base method.
+func (m *MockUserService) GetLedgerEntries(ctx context.Context, userID uint) ([]entity.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedgerEntries", ctx, userID)
+	ret0, _ := ret[0].([]entity.LedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedgerEntries indicates an expected call of GetLedgerEntries.
+func (mr *MockUserServiceMockRecorder) GetLedgerEntries(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedgerEntries", reflect.TypeOf((*MockUserService)(nil).GetLedgerEntries), ctx, userID)
+}
+
+// GetLoyaltyStats mocks base method.
+func (m *MockUserService) GetLoyaltyStats(ctx context.Context) (*entity.LoyaltyStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoyaltyStats", ctx)
+	ret0, _ := ret[0].(*entity.LoyaltyStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoyaltyStats indicates an expected call of GetLoyaltyStats.
+func (mr *MockUserServiceMockRecorder) GetLoyaltyStats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoyaltyStats", reflect.TypeOf((*MockUserService)(nil).GetLoyaltyStats), ctx)
+}
+
+// GetOrderEvents mocks base method.
+func (m *MockUserService) GetOrderEvents(ctx context.Context, userID uint, afterID int64) ([]entity.OrderEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderEvents", ctx, userID, afterID)
+	ret0, _ := ret[0].([]entity.OrderEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderEvents indicates an expected call of GetOrderEvents.
+func (mr *MockUserServiceMockRecorder) GetOrderEvents(ctx, userID, afterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderEvents", reflect.TypeOf((*MockUserService)(nil).GetOrderEvents), ctx, userID, afterID)
+}
+
+// GetOrderGoods mocks base method.
+func (m *MockUserService) GetOrderGoods(ctx context.Context, orderNumber string) ([]entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderGoods", ctx, orderNumber)
+	ret0, _ := ret[0].([]entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderGoods indicates an expected call of GetOrderGoods.
+func (mr *MockUserServiceMockRecorder) GetOrderGoods(ctx, orderNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderGoods", reflect.TypeOf((*MockUserService)(nil).GetOrderGoods), ctx, orderNumber)
+}
+
 // GetUnprocessedOrders mocks base method.
 func (m *MockUserService) GetUnprocessedOrders(ctx context.Context) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +389,21 @@ func (mr *MockUserServiceMockRecorder) GetUserByEmail(ctx, u interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockUserService)(nil).GetUserByEmail), ctx, u)
 }
 
+// GetUserByID mocks base method.
+func (m *MockUserService) GetUserByID(ctx context.Context, userID uint) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserServiceMockRecorder) GetUserByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserService)(nil).GetUserByID), ctx, userID)
+}
+
 // GetUserByLogin mocks base method.
 func (m *MockUserService) GetUserByLogin(ctx context.Context, u entity.User) (*entity.User, error) {
 	m.ctrl.T.Helper()
@@ -96,33 +420,48 @@ func (mr *MockUserServiceMockRecorder) GetUserByLogin(ctx, u interface{}) *gomoc
 }
 
 // GetUserOrders mocks base method.
-func (m *MockUserService) GetUserOrders(ctx context.Context, userID uint) ([]entity.OrderResponse, error) {
+func (m *MockUserService) GetUserOrders(ctx context.Context, userID uint, f entity.ListFilter) (*entity.OrderPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserOrders", ctx, userID)
-	ret0, _ := ret[0].([]entity.OrderResponse)
+	ret := m.ctrl.Call(m, "GetUserOrders", ctx, userID, f)
+	ret0, _ := ret[0].(*entity.OrderPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserOrders indicates an expected call of GetUserOrders.
-func (mr *MockUserServiceMockRecorder) GetUserOrders(ctx, userID interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) GetUserOrders(ctx, userID, f interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrders", reflect.TypeOf((*MockUserService)(nil).GetUserOrders), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrders", reflect.TypeOf((*MockUserService)(nil).GetUserOrders), ctx, userID, f)
+}
+
+// GetUserTransfers mocks base method.
+func (m *MockUserService) GetUserTransfers(ctx context.Context, userID uint, f entity.ListFilter) (*entity.TransferPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTransfers", ctx, userID, f)
+	ret0, _ := ret[0].(*entity.TransferPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTransfers indicates an expected call of GetUserTransfers.
+func (mr *MockUserServiceMockRecorder) GetUserTransfers(ctx, userID, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTransfers", reflect.TypeOf((*MockUserService)(nil).GetUserTransfers), ctx, userID, f)
 }
 
 // GetUserWithdrawals mocks base method.
-func (m *MockUserService) GetUserWithdrawals(ctx context.Context, userID uint) ([]entity.Withdrawal, error) {
+func (m *MockUserService) GetUserWithdrawals(ctx context.Context, userID uint, f entity.ListFilter) (*entity.WithdrawalPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserWithdrawals", ctx, userID)
-	ret0, _ := ret[0].([]entity.Withdrawal)
+	ret := m.ctrl.Call(m, "GetUserWithdrawals", ctx, userID, f)
+	ret0, _ := ret[0].(*entity.WithdrawalPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserWithdrawals indicates an expected call of GetUserWithdrawals.
-func (mr *MockUserServiceMockRecorder) GetUserWithdrawals(ctx, userID interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) GetUserWithdrawals(ctx, userID, f interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWithdrawals", reflect.TypeOf((*MockUserService)(nil).GetUserWithdrawals), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWithdrawals", reflect.TypeOf((*MockUserService)(nil).GetUserWithdrawals), ctx, userID, f)
 }
 
 // GetUsers mocks base method.
@@ -140,6 +479,64 @@ func (mr *MockUserServiceMockRecorder) GetUsers(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserService)(nil).GetUsers), ctx)
 }
 
+// HoldBalance mocks base method.
+func (m *MockUserService) HoldBalance(ctx context.Context, userID uint, r entity.HoldRequest) (*entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HoldBalance", ctx, userID, r)
+	ret0, _ := ret[0].(*entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HoldBalance indicates an expected call of HoldBalance.
+func (mr *MockUserServiceMockRecorder) HoldBalance(ctx, userID, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HoldBalance", reflect.TypeOf((*MockUserService)(nil).HoldBalance), ctx, userID, r)
+}
+
+// LockUser mocks base method.
+func (m *MockUserService) LockUser(ctx context.Context, actorID, userID uint, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUser", ctx, actorID, userID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockUser indicates an expected call of LockUser.
+func (mr *MockUserServiceMockRecorder) LockUser(ctx, actorID, userID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUser", reflect.TypeOf((*MockUserService)(nil).LockUser), ctx, actorID, userID, reason)
+}
+
+// MerchantReverseWithdrawal mocks base method.
+func (m *MockUserService) MerchantReverseWithdrawal(ctx context.Context, merchantID uint, r entity.WithdrawalReversal) (*entity.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MerchantReverseWithdrawal", ctx, merchantID, r)
+	ret0, _ := ret[0].(*entity.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MerchantReverseWithdrawal indicates an expected call of MerchantReverseWithdrawal.
+func (mr *MockUserServiceMockRecorder) MerchantReverseWithdrawal(ctx, merchantID, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MerchantReverseWithdrawal", reflect.TypeOf((*MockUserService)(nil).MerchantReverseWithdrawal), ctx, merchantID, r)
+}
+
+// PostponeAccrualJob mocks base method.
+func (m *MockUserService) PostponeAccrualJob(ctx context.Context, jobID int64, delay time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostponeAccrualJob", ctx, jobID, delay)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostponeAccrualJob indicates an expected call of PostponeAccrualJob.
+func (mr *MockUserServiceMockRecorder) PostponeAccrualJob(ctx, jobID, delay interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostponeAccrualJob", reflect.TypeOf((*MockUserService)(nil).PostponeAccrualJob), ctx, jobID, delay)
+}
+
 // RegisterUser mocks base method.
 func (m *MockUserService) RegisterUser(ctx context.Context, u entity.User) error {
 	m.ctrl.T.Helper()
@@ -154,8 +551,122 @@ func (mr *MockUserServiceMockRecorder) RegisterUser(ctx, u interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockUserService)(nil).RegisterUser), ctx, u)
 }
 
+// ReleaseHold mocks base method.
+func (m *MockUserService) ReleaseHold(ctx context.Context, userID uint, holdID int64) (*entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseHold", ctx, userID, holdID)
+	ret0, _ := ret[0].(*entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseHold indicates an expected call of ReleaseHold.
+func (mr *MockUserServiceMockRecorder) ReleaseHold(ctx, userID, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseHold", reflect.TypeOf((*MockUserService)(nil).ReleaseHold), ctx, userID, holdID)
+}
+
+// ReleaseIdempotentRequest mocks base method.
+func (m *MockUserService) ReleaseIdempotentRequest(ctx context.Context, userID uint, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotentRequest", ctx, userID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotentRequest indicates an expected call of ReleaseIdempotentRequest.
+func (mr *MockUserServiceMockRecorder) ReleaseIdempotentRequest(ctx, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotentRequest", reflect.TypeOf((*MockUserService)(nil).ReleaseIdempotentRequest), ctx, userID, key)
+}
+
+// RenewIdempotentRequest mocks base method.
+func (m *MockUserService) RenewIdempotentRequest(ctx context.Context, k entity.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewIdempotentRequest", ctx, k)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenewIdempotentRequest indicates an expected call of RenewIdempotentRequest.
+func (mr *MockUserServiceMockRecorder) RenewIdempotentRequest(ctx, k interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewIdempotentRequest", reflect.TypeOf((*MockUserService)(nil).RenewIdempotentRequest), ctx, k)
+}
+
+// ResetPassword mocks base method.
+func (m *MockUserService) ResetPassword(ctx context.Context, r entity.PasswordReset) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserServiceMockRecorder) ResetPassword(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserService)(nil).ResetPassword), ctx, r)
+}
+
+// RetryAccrualJob mocks base method.
+func (m *MockUserService) RetryAccrualJob(ctx context.Context, jobID int64, delay time.Duration, lastErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryAccrualJob", ctx, jobID, delay, lastErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryAccrualJob indicates an expected call of RetryAccrualJob.
+func (mr *MockUserServiceMockRecorder) RetryAccrualJob(ctx, jobID, delay, lastErr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryAccrualJob", reflect.TypeOf((*MockUserService)(nil).RetryAccrualJob), ctx, jobID, delay, lastErr)
+}
+
+// RevokeTokenFamily mocks base method.
+func (m *MockUserService) RevokeTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeTokenFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeTokenFamily indicates an expected call of RevokeTokenFamily.
+func (mr *MockUserServiceMockRecorder) RevokeTokenFamily(ctx, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeTokenFamily", reflect.TypeOf((*MockUserService)(nil).RevokeTokenFamily), ctx, familyID)
+}
+
+// RevokeUserTokens mocks base method.
+func (m *MockUserService) RevokeUserTokens(ctx context.Context, userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserTokens", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserTokens indicates an expected call of RevokeUserTokens.
+func (mr *MockUserServiceMockRecorder) RevokeUserTokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserTokens", reflect.TypeOf((*MockUserService)(nil).RevokeUserTokens), ctx, userID)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockUserService) RotateRefreshToken(ctx context.Context, tokenID uuid.UUID) (*entity.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", ctx, tokenID)
+	ret0, _ := ret[0].(*entity.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockUserServiceMockRecorder) RotateRefreshToken(ctx, tokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockUserService)(nil).RotateRefreshToken), ctx, tokenID)
+}
+
 // SaveAccrual mocks base method.
-func (m *MockUserService) SaveAccrual(ctx context.Context, orderNumber, status string, accrual float32) error {
+func (m *MockUserService) SaveAccrual(ctx context.Context, orderNumber, status string, accrual entity.Points) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SaveAccrual", ctx, orderNumber, status, accrual)
 	ret0, _ := ret[0].(error)
@@ -168,6 +679,35 @@ func (mr *MockUserServiceMockRecorder) SaveAccrual(ctx, orderNumber, status, acc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAccrual", reflect.TypeOf((*MockUserService)(nil).SaveAccrual), ctx, orderNumber, status, accrual)
 }
 
+// SearchUsers mocks base method.
+func (m *MockUserService) SearchUsers(ctx context.Context, actorID uint, query string) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", ctx, actorID, query)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockUserServiceMockRecorder) SearchUsers(ctx, actorID, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockUserService)(nil).SearchUsers), ctx, actorID, query)
+}
+
+// SetAccrualJobRegistered mocks base method.
+func (m *MockUserService) SetAccrualJobRegistered(ctx context.Context, jobID int64, registered bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccrualJobRegistered", ctx, jobID, registered)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAccrualJobRegistered indicates an expected call of SetAccrualJobRegistered.
+func (mr *MockUserServiceMockRecorder) SetAccrualJobRegistered(ctx, jobID, registered interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccrualJobRegistered", reflect.TypeOf((*MockUserService)(nil).SetAccrualJobRegistered), ctx, jobID, registered)
+}
+
 // SetOrders mocks base method.
 func (m *MockUserService) SetOrders(ctx context.Context, userID uint, o entity.Order) error {
 	m.ctrl.T.Helper()
@@ -182,6 +722,125 @@ func (mr *MockUserServiceMockRecorder) SetOrders(ctx, userID, o interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrders", reflect.TypeOf((*MockUserService)(nil).SetOrders), ctx, userID, o)
 }
 
+// SubscribeOrderEvents mocks base method.
+func (m *MockUserService) SubscribeOrderEvents(userID uint) (<-chan struct{}, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeOrderEvents", userID)
+	ret0, _ := ret[0].(<-chan struct{})
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SubscribeOrderEvents indicates an expected call of SubscribeOrderEvents.
+func (mr *MockUserServiceMockRecorder) SubscribeOrderEvents(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeOrderEvents", reflect.TypeOf((*MockUserService)(nil).SubscribeOrderEvents), userID)
+}
+
+// TransferPoints mocks base method.
+func (m *MockUserService) TransferPoints(ctx context.Context, senderID uint, r entity.TransferRequest) (*entity.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferPoints", ctx, senderID, r)
+	ret0, _ := ret[0].(*entity.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferPoints indicates an expected call of TransferPoints.
+func (mr *MockUserServiceMockRecorder) TransferPoints(ctx, senderID, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferPoints", reflect.TypeOf((*MockUserService)(nil).TransferPoints), ctx, senderID, r)
+}
+
+// UnlockUser mocks base method.
+func (m *MockUserService) UnlockUser(ctx context.Context, actorID, userID uint, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", ctx, actorID, userID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockUserServiceMockRecorder) UnlockUser(ctx, actorID, userID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockUserService)(nil).UnlockUser), ctx, actorID, userID, reason)
+}
+
+// ValidateAccessToken mocks base method.
+func (m *MockUserService) ValidateAccessToken(ctx context.Context, tokenID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateAccessToken", ctx, tokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateAccessToken indicates an expected call of ValidateAccessToken.
+func (mr *MockUserServiceMockRecorder) ValidateAccessToken(ctx, tokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAccessToken", reflect.TypeOf((*MockUserService)(nil).ValidateAccessToken), ctx, tokenID)
+}
+
+// VerifyUserBalance mocks base method.
+func (m *MockUserService) VerifyUserBalance(ctx context.Context, userID uint, repair bool) (*entity.BalanceCheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyUserBalance", ctx, userID, repair)
+	ret0, _ := ret[0].(*entity.BalanceCheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyUserBalance indicates an expected call of VerifyUserBalance.
+func (mr *MockUserServiceMockRecorder) VerifyUserBalance(ctx, userID, repair interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyUserBalance", reflect.TypeOf((*MockUserService)(nil).VerifyUserBalance), ctx, userID, repair)
+}
+
+// ViewBalanceHistory mocks base method.
+func (m *MockUserService) ViewBalanceHistory(ctx context.Context, actorID, userID uint) (*entity.BalanceHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ViewBalanceHistory", ctx, actorID, userID)
+	ret0, _ := ret[0].(*entity.BalanceHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ViewBalanceHistory indicates an expected call of ViewBalanceHistory.
+func (mr *MockUserServiceMockRecorder) ViewBalanceHistory(ctx, actorID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewBalanceHistory", reflect.TypeOf((*MockUserService)(nil).ViewBalanceHistory), ctx, actorID, userID)
+}
+
+// ViewUserOrders mocks base method.
+func (m *MockUserService) ViewUserOrders(ctx context.Context, actorID, userID uint, f entity.ListFilter) (*entity.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ViewUserOrders", ctx, actorID, userID, f)
+	ret0, _ := ret[0].(*entity.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ViewUserOrders indicates an expected call of ViewUserOrders.
+func (mr *MockUserServiceMockRecorder) ViewUserOrders(ctx, actorID, userID, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewUserOrders", reflect.TypeOf((*MockUserService)(nil).ViewUserOrders), ctx, actorID, userID, f)
+}
+
+// ViewUserWithdrawals mocks base method.
+func (m *MockUserService) ViewUserWithdrawals(ctx context.Context, actorID, userID uint, f entity.ListFilter) (*entity.WithdrawalPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ViewUserWithdrawals", ctx, actorID, userID, f)
+	ret0, _ := ret[0].(*entity.WithdrawalPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ViewUserWithdrawals indicates an expected call of ViewUserWithdrawals.
+func (mr *MockUserServiceMockRecorder) ViewUserWithdrawals(ctx, actorID, userID, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewUserWithdrawals", reflect.TypeOf((*MockUserService)(nil).ViewUserWithdrawals), ctx, actorID, userID, f)
+}
+
 // WithdrawBalance mocks base method.
 func (m *MockUserService) WithdrawBalance(ctx context.Context, withdrawal entity.Withdrawal) error {
 	m.ctrl.T.Helper()
@@ -220,15 +879,15 @@ func (m *MockTestEntity) EXPECT() *MockTestEntityMockRecorder {
 }
 
 // AddOrder mocks base method.
-func (m *MockTestEntity) AddOrder(orderNumber string) {
+func (m *MockTestEntity) AddOrder(ctx context.Context, orderNumber string) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "AddOrder", orderNumber)
+	m.ctrl.Call(m, "AddOrder", ctx, orderNumber)
 }
 
 // AddOrder indicates an expected call of AddOrder.
-func (mr *MockTestEntityMockRecorder) AddOrder(orderNumber interface{}) *gomock.Call {
+func (mr *MockTestEntityMockRecorder) AddOrder(ctx, orderNumber interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockTestEntity)(nil).AddOrder), orderNumber)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockTestEntity)(nil).AddOrder), ctx, orderNumber)
 }
 
 // CreateToken mocks base method.
@@ -245,31 +904,31 @@ func (mr *MockTestEntityMockRecorder) CreateToken(ctx, t interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockTestEntity)(nil).CreateToken), ctx, t)
 }
 
-// GenerateToken mocks base method.
-func (m *MockTestEntity) GenerateToken(user *entity.User) (string, error) {
+// IssueTokens mocks base method.
+func (m *MockTestEntity) IssueTokens(ctx context.Context, user *entity.User) (*entity.TokenPair, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateToken", user)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "IssueTokens", ctx, user)
+	ret0, _ := ret[0].(*entity.TokenPair)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GenerateToken indicates an expected call of GenerateToken.
-func (mr *MockTestEntityMockRecorder) GenerateToken(user interface{}) *gomock.Call {
+// IssueTokens indicates an expected call of IssueTokens.
+func (mr *MockTestEntityMockRecorder) IssueTokens(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockTestEntity)(nil).GenerateToken), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueTokens", reflect.TypeOf((*MockTestEntity)(nil).IssueTokens), ctx, user)
 }
 
 // PersistToken mocks base method.
-func (m *MockTestEntity) PersistToken(user *entity.User, tokenID uuid.UUID) error {
+func (m *MockTestEntity) PersistToken(ctx context.Context, t *entity.Token) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PersistToken", user, tokenID)
+	ret := m.ctrl.Call(m, "PersistToken", ctx, t)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PersistToken indicates an expected call of PersistToken.
-func (mr *MockTestEntityMockRecorder) PersistToken(user, tokenID interface{}) *gomock.Call {
+func (mr *MockTestEntityMockRecorder) PersistToken(ctx, t interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PersistToken", reflect.TypeOf((*MockTestEntity)(nil).PersistToken), user, tokenID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PersistToken", reflect.TypeOf((*MockTestEntity)(nil).PersistToken), ctx, t)
 }
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: tier_pg.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	entity "go-loyalty-system/internal/entity"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTierRepository is a mock of TierRepository interface.
+type MockTierRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTierRepositoryMockRecorder
+}
+
+// MockTierRepositoryMockRecorder is the mock recorder for MockTierRepository.
+type MockTierRepositoryMockRecorder struct {
+	mock *MockTierRepository
+}
+
+// NewMockTierRepository creates a new mock instance.
+func NewMockTierRepository(ctrl *gomock.Controller) *MockTierRepository {
+	mock := &MockTierRepository{ctrl: ctrl}
+	mock.recorder = &MockTierRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTierRepository) EXPECT() *MockTierRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreditTierBonus mocks base method.
+func (m *MockTierRepository) CreditTierBonus(ctx context.Context, userID uint, orderNumber string, amount entity.Points, tier entity.Tier) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreditTierBonus", ctx, userID, orderNumber, amount, tier)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreditTierBonus indicates an expected call of CreditTierBonus.
+func (mr *MockTierRepositoryMockRecorder) CreditTierBonus(ctx, userID, orderNumber, amount, tier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreditTierBonus", reflect.TypeOf((*MockTierRepository)(nil).CreditTierBonus), ctx, userID, orderNumber, amount, tier)
+}
+
+// GetRollingAccrual mocks base method.
+func (m *MockTierRepository) GetRollingAccrual(ctx context.Context, userID uint, since time.Time) (entity.Points, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRollingAccrual", ctx, userID, since)
+	ret0, _ := ret[0].(entity.Points)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRollingAccrual indicates an expected call of GetRollingAccrual.
+func (mr *MockTierRepositoryMockRecorder) GetRollingAccrual(ctx, userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRollingAccrual", reflect.TypeOf((*MockTierRepository)(nil).GetRollingAccrual), ctx, userID, since)
+}
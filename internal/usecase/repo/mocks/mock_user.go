@@ -64,6 +64,21 @@ func (mr *MockAuthUseCaseMockRecorder) GetUserByEmail(ctx, u interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockAuthUseCase)(nil).GetUserByEmail), ctx, u)
 }
 
+// GetUserByID mocks base method.
+func (m *MockAuthUseCase) GetUserByID(ctx context.Context, id uint) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, id)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockAuthUseCaseMockRecorder) GetUserByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockAuthUseCase)(nil).GetUserByID), ctx, id)
+}
+
 // GetUserByLogin mocks base method.
 func (m *MockAuthUseCase) GetUserByLogin(ctx context.Context, u entity.User) (*entity.User, error) {
 	m.ctrl.T.Helper()
@@ -107,3 +122,46 @@ func (mr *MockAuthUseCaseMockRecorder) RegisterUser(ctx, u interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockAuthUseCase)(nil).RegisterUser), ctx, u)
 }
+
+// SearchUsers mocks base method.
+func (m *MockAuthUseCase) SearchUsers(ctx context.Context, query string, limit int) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", ctx, query, limit)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockAuthUseCaseMockRecorder) SearchUsers(ctx, query, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockAuthUseCase)(nil).SearchUsers), ctx, query, limit)
+}
+
+// SetUserLocked mocks base method.
+func (m *MockAuthUseCase) SetUserLocked(ctx context.Context, userID uint, locked bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserLocked", ctx, userID, locked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserLocked indicates an expected call of SetUserLocked.
+func (mr *MockAuthUseCaseMockRecorder) SetUserLocked(ctx, userID, locked interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserLocked", reflect.TypeOf((*MockAuthUseCase)(nil).SetUserLocked), ctx, userID, locked)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockAuthUseCase) UpdatePassword(ctx context.Context, userID uint, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, userID, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockAuthUseCaseMockRecorder) UpdatePassword(ctx, userID, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockAuthUseCase)(nil).UpdatePassword), ctx, userID, hash)
+}
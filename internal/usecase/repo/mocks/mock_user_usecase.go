@@ -260,7 +260,7 @@ func (mr *MockGopherMartRepoMockRecorder) RegisterUser(ctx, u interface{}) *gomo
 }
 
 // SaveAccrual mocks base method.
-func (m *MockGopherMartRepo) SaveAccrual(ctx context.Context, orderNumber, status string, accrual float32) error {
+func (m *MockGopherMartRepo) SaveAccrual(ctx context.Context, orderNumber, status string, accrual entity.Points) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SaveAccrual", ctx, orderNumber, status, accrual)
 	ret0, _ := ret[0].(error)
@@ -288,7 +288,7 @@ func (mr *MockGopherMartRepoMockRecorder) SetOrders(ctx, userID, order interface
 }
 
 // UpdateBalanceTx mocks base method.
-func (m *MockGopherMartRepo) UpdateBalanceTx(ctx context.Context, tx pgx.Tx, userID uint, amount float32) error {
+func (m *MockGopherMartRepo) UpdateBalanceTx(ctx context.Context, tx pgx.Tx, userID uint, amount entity.Points) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateBalanceTx", ctx, tx, userID, amount)
 	ret0, _ := ret[0].(error)
@@ -472,7 +472,7 @@ func (mr *MockGopherMartUseCaseMockRecorder) RegisterUser(ctx, u interface{}) *g
 }
 
 // SaveAccrual mocks base method.
-func (m *MockGopherMartUseCase) SaveAccrual(ctx context.Context, orderNumber, status string, accrual float32) error {
+func (m *MockGopherMartUseCase) SaveAccrual(ctx context.Context, orderNumber, status string, accrual entity.Points) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SaveAccrual", ctx, orderNumber, status, accrual)
 	ret0, _ := ret[0].(error)
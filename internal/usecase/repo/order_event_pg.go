@@ -0,0 +1,128 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/postgres"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// orderEventsChannel канал NOTIFY; в полезной нагрузке id пользователя,
+// сами события читаются из order_events
+const orderEventsChannel = "order_events"
+
+//go:generate mockgen -source=order_event_pg.go -destination=./mocks/mock_order_event.go -package=mocks
+type OrderEventRepository interface {
+	GetOrderEvents(ctx context.Context, userID uint, afterID int64, limit int) ([]entity.OrderEvent, error)
+	GetLastOrderEventID(ctx context.Context, userID uint) (int64, error)
+	ListenOrderEvents(ctx context.Context, notify func(userID uint)) error
+}
+
+func NewOrderEventRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
+	return &GopherMartRepo{
+		pg:     pg,
+		Logger: l,
+		pool:   pool,
+	}
+}
+
+// createOrderEventTx записывает событие и уведомляет слушателей. NOTIFY доставляется
+// только после коммита, поэтому подписчики не увидят событие откатившейся транзакции.
+func (g *GopherMartRepo) createOrderEventTx(ctx context.Context,
+	tx pgx.Tx,
+	userID uint,
+	orderID int,
+	status string,
+	accrual *entity.Points) error {
+	const queryCreateOrderEvent = `
+	WITH e AS (
+		INSERT INTO order_events (user_id, order_id, status, accrual)
+		VALUES ($1, $2, $3, $4)
+		RETURNING user_id
+	)
+	SELECT pg_notify($5, CAST(user_id AS TEXT)) FROM e`
+	if _, err := tx.Exec(ctx, queryCreateOrderEvent, userID, orderID, status, accrual, orderEventsChannel); err != nil {
+		return g.logAndReturnError(ctx, "createOrderEventTx - Exec", err)
+	}
+	return nil
+}
+
+// GetOrderEvents события пользователя после afterID в порядке возникновения
+func (g *GopherMartRepo) GetOrderEvents(ctx context.Context,
+	userID uint,
+	afterID int64,
+	limit int) ([]entity.OrderEvent, error) {
+	const queryGetOrderEvents = `
+	SELECT e.id, e.user_id, CAST(o.number AS TEXT), e.status, e.accrual, e.created_at
+	FROM order_events e
+	JOIN orders o ON o.id = e.order_id
+	WHERE e.user_id = $1 AND e.id > $2
+	ORDER BY e.id
+	LIMIT $3`
+	rows, err := g.conn(ctx).Query(ctx, queryGetOrderEvents, userID, afterID, limit)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetOrderEvents - Query", err)
+	}
+	defer rows.Close()
+
+	events := make([]entity.OrderEvent, 0, limit)
+	for rows.Next() {
+		e := entity.OrderEvent{}
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Number, &e.Status, &e.Accrual, &e.CreatedAt); err != nil {
+			return nil, g.logAndReturnError(ctx, "GetOrderEvents - Scan", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "GetOrderEvents - rows", err)
+	}
+	return events, nil
+}
+
+// GetLastOrderEventID id последнего события пользователя, 0 если событий не было
+func (g *GopherMartRepo) GetLastOrderEventID(ctx context.Context, userID uint) (int64, error) {
+	const queryLastOrderEventID = `
+	SELECT COALESCE(MAX(id), 0)
+	FROM order_events
+	WHERE user_id = $1`
+	var id int64
+	if err := g.conn(ctx).QueryRow(ctx, queryLastOrderEventID, userID).Scan(&id); err != nil {
+		return 0, g.logAndReturnError(ctx, "GetLastOrderEventID - QueryRow", err)
+	}
+	return id, nil
+}
+
+// ListenOrderEvents слушает канал событий на отдельном соединении вне пула,
+// чтобы долгое ожидание не занимало соединение у запросов. Возвращает ошибку
+// при обрыве соединения или отмене ctx.
+func (g *GopherMartRepo) ListenOrderEvents(ctx context.Context, notify func(userID uint)) error {
+	conn, err := pgx.ConnectConfig(ctx, g.pool.Config().ConnConfig)
+	if err != nil {
+		return g.logAndReturnError(ctx, "ListenOrderEvents - connect", err)
+	}
+	defer func() {
+		_ = conn.Close(context.Background())
+	}()
+
+	if _, err = conn.Exec(ctx, "LISTEN "+orderEventsChannel); err != nil {
+		return g.logAndReturnError(ctx, "ListenOrderEvents - LISTEN", err)
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("ListenOrderEvents - wait: %w", err)
+		}
+		userID, err := strconv.ParseUint(n.Payload, 10, 64)
+		if err != nil {
+			g.Logger.WarnCtx(ctx, "ListenOrderEvents - bad payload", zap.String("payload", n.Payload))
+			continue
+		}
+		notify(uint(userID))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"go-loyalty-system/pkg/logging"
 	"go-loyalty-system/pkg/postgres"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -15,10 +16,12 @@ import (
 //go:generate mockgen -source=order_pg.go -destination=./mocks/mock_order.go -package=mocks
 type OrderUseCase interface {
 	SetOrders(ctx context.Context, userID uint, order entity.Order) error
-	GetUserOrders(ctx context.Context, userID uint) ([]entity.OrderResponse, error)
+	GetUserOrders(ctx context.Context, userID uint, f entity.ListFilter) ([]entity.OrderResponse, error)
 	GetOrderByNumber(ctx context.Context, orderNumber string) (*entity.OrderResponse, error)
 	CheckOrderExistence(ctx context.Context, orderNumber string, userID uint) (exists bool, existingUserID uint, err error)
 	ValidateOrder(order entity.Order, userID uint) error
+	GetOrderGoods(ctx context.Context, orderNumber string) ([]entity.Product, error)
+	IsMerchantOrder(ctx context.Context, orderNumber string, buyerID, merchantID uint) (bool, error)
 }
 
 func NewOrderepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
@@ -29,32 +32,115 @@ func NewOrderepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpoo
 	}
 }
 
+// SetOrders сохраняет заказ вместе с корзиной товаров, если она передана
 func (g *GopherMartRepo) SetOrders(ctx context.Context, userID uint, o entity.Order) error {
+	tx, err := g.conn(ctx).Begin(ctx)
+	if err != nil {
+		return g.logAndReturnError(ctx, "SetOrders - begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
 	const querySetOrders = `
-	INSERT INTO orders (user_id, status_id, creation_date, uploaded_at, number) 
-	VALUES ($1, $2, $3, $4, $5)
+	INSERT INTO orders (user_id, status_id, creation_date, uploaded_at, number, merchant_id) 
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id
 	`
-	_, err := g.pool.Exec(ctx, querySetOrders, userID, entity.OrderStatusNewID, o.CreatedAt, o.UploadedAt, o.Number)
+	var orderID int
+	err = tx.QueryRow(ctx, querySetOrders,
+		userID, entity.OrderStatusNewID, o.CreatedAt, o.UploadedAt, o.Number, o.MerchantID).Scan(&orderID)
 	if err != nil {
-		return g.logAndReturnError(ctx, "SetOrders - Exec", err)
+		return g.logAndReturnError(ctx, "SetOrders - insert order", err)
+	}
+
+	if len(o.Goods) > 0 {
+		rows := make([][]any, 0, len(o.Goods))
+		for _, p := range o.Goods {
+			rows = append(rows, []any{orderID, p.Description, p.Price})
+		}
+		_, err = tx.CopyFrom(ctx,
+			pgx.Identifier{"order_items"},
+			[]string{"order_id", "description", "price"},
+			pgx.CopyFromRows(rows))
+		if err != nil {
+			return g.logAndReturnError(ctx, "SetOrders - insert goods", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return g.logAndReturnError(ctx, "SetOrders - commit transaction", err)
 	}
 	return nil
 }
 
-func (g *GopherMartRepo) GetUserOrders(ctx context.Context, userID uint) ([]entity.OrderResponse, error) {
-	const queryUserOrders = `
-	SELECT 
-		CAST(o.number AS TEXT) as number,
-		s.status,
-		COALESCE(a.accrual, 0) as accrual,
-		o.uploaded_at
-	FROM orders as o
-	left join statuses as s ON o.status_id = s.id 
-	left join accrual as a ON o.id = a.order_id 
-	WHERE o.user_id = $1
-	ORDER BY uploaded_at DESC
+// IsMerchantOrder загружал ли магазин merchantID заказ с этим номером для покупателя buyerID
+// через /api/merchant/orders
+func (g *GopherMartRepo) IsMerchantOrder(ctx context.Context, orderNumber string, buyerID, merchantID uint) (bool, error) {
+	const queryMerchantOrder = `
+	SELECT EXISTS (SELECT 1 FROM orders WHERE number = $1 AND user_id = $2 AND merchant_id = $3)
+	`
+	var exists bool
+	err := g.conn(ctx).QueryRow(ctx, queryMerchantOrder, orderNumber, buyerID, merchantID).Scan(&exists)
+	if err != nil {
+		return false, g.logAndReturnError(ctx, "IsMerchantOrder - QueryRow", err)
+	}
+	return exists, nil
+}
+
+// GetOrderGoods возвращает корзину заказа. Для заказа, загруженного одним номером, она пустая.
+func (g *GopherMartRepo) GetOrderGoods(ctx context.Context, orderNumber string) ([]entity.Product, error) {
+	const queryOrderGoods = `
+	SELECT i.description, i.price
+	FROM order_items i
+	JOIN orders o ON o.id = i.order_id
+	WHERE o.number = $1
+	ORDER BY i.id
 	`
-	rows, err := g.pg.Pool.Query(ctx, queryUserOrders, userID)
+	rows, err := g.conn(ctx).Query(ctx, queryOrderGoods, orderNumber)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetOrderGoods - Query", err)
+	}
+	defer rows.Close()
+
+	goods := make([]entity.Product, 0)
+	for rows.Next() {
+		var p entity.Product
+		if err := rows.Scan(&p.Description, &p.Price); err != nil {
+			return nil, g.logAndReturnError(ctx, "GetOrderGoods - Scan", err)
+		}
+		goods = append(goods, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "GetOrderGoods - rows.Err", err)
+	}
+	return goods, nil
+}
+
+// GetUserOrders страница заказов пользователя, отфильтрованная по статусам и дате загрузки
+func (g *GopherMartRepo) GetUserOrders(ctx context.Context,
+	userID uint,
+	f entity.ListFilter) ([]entity.OrderResponse, error) {
+	q := g.pg.Builder.
+		Select("o.id", "CAST(o.number AS TEXT)", "s.status", "COALESCE(a.accrual, 0)", "o.uploaded_at").
+		From("orders AS o").
+		LeftJoin("statuses AS s ON o.status_id = s.id").
+		LeftJoin("accrual AS a ON o.id = a.order_id").
+		Where(squirrel.Eq{"o.user_id": userID})
+	if len(f.Statuses) > 0 {
+		ids := make([]entity.OrderStatusID, 0, len(f.Statuses))
+		for _, s := range f.Statuses {
+			if id, ok := s.ID(); ok {
+				ids = append(ids, id)
+			}
+		}
+		q = q.Where(squirrel.Eq{"o.status_id": ids})
+	}
+	sql, args, err := pageQuery(q, "o.uploaded_at", "o.id", f).ToSql()
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetUserOrders - ToSql", err)
+	}
+	rows, err := g.conn(ctx).Query(ctx, sql, args...)
 	if err != nil {
 		return nil, g.logAndReturnError(ctx, "GetUserOrders - Query", err)
 	}
@@ -68,6 +154,7 @@ func (g *GopherMartRepo) GetUserOrders(ctx context.Context, userID uint) ([]enti
 
 		var order entity.OrderResponse
 		err := rows.Scan(
+			&order.ID,
 			&order.Number,
 			&order.Status,
 			&order.Accrual,
@@ -95,7 +182,7 @@ func (g *GopherMartRepo) CheckOrderExistence(ctx context.Context,
 		WHERE number = $1 
 		LIMIT 1
 	`
-	err = g.pg.Pool.QueryRow(ctx, queryCheckOrderExist, orderNumber).Scan(&existingUserID)
+	err = g.conn(ctx).QueryRow(ctx, queryCheckOrderExist, orderNumber).Scan(&existingUserID)
 	if err != nil {
 		return false, 0, nil
 	}
@@ -115,7 +202,7 @@ func (g *GopherMartRepo) GetOrderByNumber(ctx context.Context, orderNumber strin
 	left join accrual as a ON o.id = a.order_id 
 	WHERE o.number = $1
 	`
-	row := g.pg.Pool.QueryRow(ctx, queryGetOrderByNumber, orderNumber)
+	row := g.conn(ctx).QueryRow(ctx, queryGetOrderByNumber, orderNumber)
 	order := &entity.OrderResponse{}
 	err := row.Scan(
 		&order.ID,
@@ -195,7 +282,7 @@ func (g *GopherMartRepo) OrderExists(ctx context.Context, orderNumber string) (b
 	SELECT 1 
 	FROM orders 
 	WHERE number = $1)`
-	err := g.pg.Pool.QueryRow(ctx, queryOrderExists, orderNumber).Scan(&exists)
+	err := g.conn(ctx).QueryRow(ctx, queryOrderExists, orderNumber).Scan(&exists)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return false, nil
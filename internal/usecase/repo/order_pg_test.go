@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -74,7 +75,7 @@ func TestGetUserOrders(t *testing.T) {
 	ctx := context.Background()
 	userID := uint(1)
 	now := time.Now()
-	accrual1 := float64(100.50)
+	accrual1 := entity.NewPoints(100, 50)
 
 	expectedOrders := []entity.OrderResponse{
 		{
@@ -93,25 +94,25 @@ func TestGetUserOrders(t *testing.T) {
 
 	t.Run("successful orders retrieval", func(t *testing.T) {
 		mockOrderUseCase.EXPECT().
-			GetUserOrders(gomock.Any(), userID).
+			GetUserOrders(gomock.Any(), userID, gomock.Any()).
 			Return(expectedOrders, nil)
 
-		orders, err := mockOrderUseCase.GetUserOrders(ctx, userID)
+		orders, err := mockOrderUseCase.GetUserOrders(ctx, userID, entity.ListFilter{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedOrders, orders)
 		assert.Len(t, orders, 2)
 		assert.Equal(t, "12345678", orders[0].Number)
 		assert.Equal(t, "PROCESSED", orders[0].Status)
-		assert.Equal(t, float64(100.50), *orders[0].Accrual)
+		assert.Equal(t, entity.NewPoints(100, 50), *orders[0].Accrual)
 	})
 
 	t.Run("empty orders list", func(t *testing.T) {
 		mockOrderUseCase.EXPECT().
-			GetUserOrders(gomock.Any(), userID).
+			GetUserOrders(gomock.Any(), userID, gomock.Any()).
 			Return([]entity.OrderResponse{}, nil)
 
-		orders, err := mockOrderUseCase.GetUserOrders(ctx, userID)
+		orders, err := mockOrderUseCase.GetUserOrders(ctx, userID, entity.ListFilter{})
 
 		assert.NoError(t, err)
 		assert.Empty(t, orders)
@@ -120,10 +121,10 @@ func TestGetUserOrders(t *testing.T) {
 	t.Run("error getting orders", func(t *testing.T) {
 		expectedErr := errors.New("database error")
 		mockOrderUseCase.EXPECT().
-			GetUserOrders(gomock.Any(), userID).
+			GetUserOrders(gomock.Any(), userID, gomock.Any()).
 			Return(nil, expectedErr)
 
-		orders, err := mockOrderUseCase.GetUserOrders(ctx, userID)
+		orders, err := mockOrderUseCase.GetUserOrders(ctx, userID, entity.ListFilter{})
 
 		assert.Error(t, err)
 		assert.Nil(t, orders)
@@ -139,7 +140,7 @@ func TestGetOrderByNumber(t *testing.T) {
 	ctx := context.Background()
 	orderNumber := "12345678"
 	now := time.Now()
-	accrual2 := float64(100.50)
+	accrual2 := entity.NewPoints(100, 50)
 
 	expectedOrder := &entity.OrderResponse{
 		Number:     orderNumber,
@@ -159,7 +160,7 @@ func TestGetOrderByNumber(t *testing.T) {
 		assert.Equal(t, expectedOrder, order)
 		assert.Equal(t, orderNumber, order.Number)
 		assert.Equal(t, "PROCESSED", order.Status)
-		assert.Equal(t, float64(100.50), *order.Accrual)
+		assert.Equal(t, entity.NewPoints(100, 50), *order.Accrual)
 	})
 
 	t.Run("order not found", func(t *testing.T) {
@@ -326,7 +327,7 @@ func TestOrderFlow(t *testing.T) {
 		Number: orderNumber,
 	}
 	now := time.Now()
-	accrual2 := float64(0)
+	accrual2 := entity.NewPoints(0, 0)
 
 	expectedOrder := &entity.OrderResponse{
 		Number:     orderNumber,
@@ -353,7 +354,7 @@ func TestOrderFlow(t *testing.T) {
 			Return(expectedOrder, nil)
 
 		mockOrderUseCase.EXPECT().
-			GetUserOrders(gomock.Any(), userID).
+			GetUserOrders(gomock.Any(), userID, gomock.Any()).
 			Return([]entity.OrderResponse{*expectedOrder}, nil)
 
 		err := mockOrderUseCase.ValidateOrder(order, userID)
@@ -371,7 +372,7 @@ func TestOrderFlow(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, expectedOrder, orderInfo)
 
-		orders, err := mockOrderUseCase.GetUserOrders(ctx, userID)
+		orders, err := mockOrderUseCase.GetUserOrders(ctx, userID, entity.ListFilter{})
 		assert.NoError(t, err)
 		assert.Len(t, orders, 1)
 		assert.Equal(t, orderNumber, orders[0].Number)
@@ -443,3 +444,26 @@ func TestOrderExistenceFlow(t *testing.T) {
 		assert.Equal(t, entity.ErrOrderExistsOtherUser, err)
 	})
 }
+
+func TestPageQuery(t *testing.T) {
+	builder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	base := builder.Select("o.id").From("orders AS o").Where(squirrel.Eq{"o.user_id": 1})
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cursor := &entity.PageCursor{At: from.Add(time.Hour), ID: 10}
+
+	t.Run("newest first after cursor", func(t *testing.T) {
+		sql, args, err := pageQuery(base, "o.uploaded_at", "o.id",
+			entity.ListFilter{Limit: 20, Sort: entity.SortDesc, From: &from, Cursor: cursor}).ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT o.id FROM orders AS o WHERE o.user_id = $1 AND o.uploaded_at >= $2 "+
+			"AND (o.uploaded_at, o.id) < ($3, $4) ORDER BY o.uploaded_at DESC, o.id DESC LIMIT 21", sql)
+		assert.Equal(t, []interface{}{1, from, cursor.At, cursor.ID}, args)
+	})
+
+	t.Run("oldest first", func(t *testing.T) {
+		sql, _, err := pageQuery(base, "o.uploaded_at", "o.id",
+			entity.ListFilter{Limit: 5, Sort: entity.SortAsc, Cursor: cursor}).ToSql()
+		assert.NoError(t, err)
+		assert.Contains(t, sql, "(o.uploaded_at, o.id) > ($2, $3) ORDER BY o.uploaded_at ASC, o.id ASC LIMIT 6")
+	})
+}
@@ -2,22 +2,112 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/postgres"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+//go:generate mockgen -source=security_pg.go -destination=./mocks/mock_security.go -package=mocks
+type TokenRepository interface {
+	GetToken(ctx context.Context, id uuid.UUID) (*entity.Token, error)
+	UseRefreshToken(ctx context.Context, id uuid.UUID) (*entity.Token, error)
+	RevokeTokenFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeUserTokens(ctx context.Context, userID uint) error
+}
+
+func NewTokenRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
+	return &GopherMartRepo{
+		pg:     pg,
+		Logger: l,
+		pool:   pool,
+	}
+}
+
+const queryTokenColumns = `id, user_id, kind, family_id, creation_date, expires_at, used_at, revoked_at`
+
 func (g *GopherMartRepo) CreateToken(ctx context.Context, t *entity.Token) error {
 	sql, args, err := g.pg.Builder.
 		Insert("token").
-		Columns("id", "user_id, creation_date, used_at").
-		Values(t.ID, t.UserID, t.CreationDate, t.UsedAt).
+		Columns("id", "user_id", "kind", "family_id", "creation_date", "expires_at").
+		Values(t.ID, t.UserID, t.Kind, t.FamilyID, t.CreationDate, t.ExpiresAt).
 		ToSql()
 	if err != nil {
 		return g.logAndReturnError(ctx, "TranslationRepo - CreateToken - r.Builder", err)
 	}
 
-	_, err = g.pg.Pool.Exec(ctx, sql, args...)
+	_, err = g.conn(ctx).Exec(ctx, sql, args...)
 	if err != nil {
 		return g.logAndReturnError(ctx, "TranslationRepo - CreateToken - r.Pool.Exec", err)
 	}
 	return nil
 }
+
+// GetToken возвращает токен по идентификатору или nil, если его нет
+func (g *GopherMartRepo) GetToken(ctx context.Context, id uuid.UUID) (*entity.Token, error) {
+	row := g.conn(ctx).QueryRow(ctx, `SELECT `+queryTokenColumns+` FROM token WHERE id = $1`, id)
+	t, err := scanToken(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetToken - QueryRow", err)
+	}
+	return t, nil
+}
+
+// UseRefreshToken атомарно помечает refresh-токен использованным.
+// nil означает, что токен уже использован, отозван, истек или не найден.
+func (g *GopherMartRepo) UseRefreshToken(ctx context.Context, id uuid.UUID) (*entity.Token, error) {
+	const queryUseRefreshToken = `
+	UPDATE token SET used_at = NOW()
+	WHERE id = $1
+		AND kind = 'REFRESH'
+		AND used_at IS NULL
+		AND revoked_at IS NULL
+		AND expires_at > NOW()
+	RETURNING ` + queryTokenColumns
+	t, err := scanToken(g.conn(ctx).QueryRow(ctx, queryUseRefreshToken, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "UseRefreshToken - QueryRow", err)
+	}
+	return t, nil
+}
+
+// RevokeTokenFamily отзывает все токены одного входа
+func (g *GopherMartRepo) RevokeTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	const queryRevokeFamily = `
+	UPDATE token SET revoked_at = NOW()
+	WHERE family_id = $1 AND revoked_at IS NULL`
+	if _, err := g.conn(ctx).Exec(ctx, queryRevokeFamily, familyID); err != nil {
+		return g.logAndReturnError(ctx, "RevokeTokenFamily - Exec", err)
+	}
+	return nil
+}
+
+// RevokeUserTokens отзывает все токены пользователя на всех устройствах
+func (g *GopherMartRepo) RevokeUserTokens(ctx context.Context, userID uint) error {
+	const queryRevokeUser = `
+	UPDATE token SET revoked_at = NOW()
+	WHERE user_id = $1 AND revoked_at IS NULL`
+	if _, err := g.conn(ctx).Exec(ctx, queryRevokeUser, userID); err != nil {
+		return g.logAndReturnError(ctx, "RevokeUserTokens - Exec", err)
+	}
+	return nil
+}
+
+func scanToken(row pgx.Row) (*entity.Token, error) {
+	var t entity.Token
+	err := row.Scan(&t.ID, &t.UserID, &t.Kind, &t.FamilyID, &t.CreationDate, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"context"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:generate mockgen -source=stats_pg.go -destination=./mocks/mock_stats.go -package=mocks
+type StatsRepository interface {
+	GetLoyaltyStats(ctx context.Context) (*entity.LoyaltyStats, error)
+}
+
+func NewStatsRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
+	return &GopherMartRepo{
+		pg:     pg,
+		Logger: l,
+		pool:   pool,
+	}
+}
+
+// GetLoyaltyStats сумма баллов на счетах, включая резервы, и глубина очереди начислений.
+// Выполненные задания не считаются: их число только растет и ничего не говорит о нагрузке.
+func (g *GopherMartRepo) GetLoyaltyStats(ctx context.Context) (*entity.LoyaltyStats, error) {
+	const queryBalanceTotals = `
+	SELECT COALESCE(SUM(current_balance + held), 0), COALESCE(SUM(withdrawn), 0)
+	FROM balance`
+	stats := &entity.LoyaltyStats{AccrualJobs: make(map[entity.AccrualJobState]int64)}
+	err := g.conn(ctx).QueryRow(ctx, queryBalanceTotals).Scan(&stats.Outstanding, &stats.Withdrawn)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetLoyaltyStats - balance totals", err)
+	}
+
+	const queryAccrualJobs = `
+	SELECT state, COUNT(*)
+	FROM accrual_jobs
+	WHERE state IN ('PENDING', 'RUNNING', 'FAILED')
+	GROUP BY state`
+	rows, err := g.conn(ctx).Query(ctx, queryAccrualJobs)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "GetLoyaltyStats - accrual jobs", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var state entity.AccrualJobState
+		var count int64
+		if err := rows.Scan(&state, &count); err != nil {
+			return nil, g.logAndReturnError(ctx, "GetLoyaltyStats - Scan", err)
+		}
+		stats.AccrualJobs[state] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "GetLoyaltyStats - rows", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/postgres"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:generate mockgen -source=tier_pg.go -destination=./mocks/mock_tier.go -package=mocks
+type TierRepository interface {
+	GetRollingAccrual(ctx context.Context, userID uint, since time.Time) (entity.Points, error)
+	CreditTierBonus(ctx context.Context, userID uint, orderNumber string, amount entity.Points, tier entity.Tier) error
+}
+
+func NewTierRepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
+	return &GopherMartRepo{
+		pg:     pg,
+		Logger: l,
+		pool:   pool,
+	}
+}
+
+// GetRollingAccrual сумма начислений от системы расчета с момента since.
+// Надбавки уровня, корректировки и переводы в сумму не входят.
+func (g *GopherMartRepo) GetRollingAccrual(ctx context.Context, userID uint, since time.Time) (entity.Points, error) {
+	const queryRollingAccrual = `
+	SELECT COALESCE(SUM(amount), 0)
+	FROM ledger_entries
+	WHERE user_id = $1 AND kind = 'ACCRUAL' AND account = 'USER' AND created_at >= $2`
+	var accrued entity.Points
+	if err := g.conn(ctx).QueryRow(ctx, queryRollingAccrual, userID, since).Scan(&accrued); err != nil {
+		return 0, g.logAndReturnError(ctx, "GetRollingAccrual - QueryRow", err)
+	}
+	return accrued, nil
+}
+
+// CreditTierBonus зачисляет надбавку уровня к начислению по заказу отдельной проводкой
+// TIER_BONUS, чтобы сумма от системы расчета осталась в журнале как есть
+func (g *GopherMartRepo) CreditTierBonus(ctx context.Context,
+	userID uint,
+	orderNumber string,
+	amount entity.Points,
+	tier entity.Tier) error {
+	tx, err := g.conn(ctx).Begin(ctx)
+	if err != nil {
+		return g.logAndReturnError(ctx, "CreditTierBonus - begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var orderID int
+	const queryOrder = `SELECT id FROM orders WHERE number = $1`
+	if err = tx.QueryRow(ctx, queryOrder, orderNumber).Scan(&orderID); err != nil {
+		return g.logAndReturnError(ctx, "CreditTierBonus - get order", err)
+	}
+	_, err = g.postLedgerTx(ctx, tx, ledgerPosting{
+		UserID:      userID,
+		Kind:        entity.LedgerTierBonus,
+		Counter:     entity.LedgerAccountBonus,
+		Amount:      amount,
+		OrderID:     &orderID,
+		Description: fmt.Sprintf("tier %s x%g bonus", tier.Name, tier.Multiplier),
+	})
+	if err != nil {
+		return err
+	}
+	if err = g.applyBalanceTx(ctx, tx, userID, amount, 0); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return g.logAndReturnError(ctx, "CreditTierBonus - commit transaction", err)
+	}
+	return nil
+}
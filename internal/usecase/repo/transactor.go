@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"context"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/postgres"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:generate mockgen -source=transactor.go -destination=./mocks/mock_transactor.go -package=mocks
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// querier общее у пула и транзакции
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+type txKey struct{}
+
+func NewTransactor(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
+	return &GopherMartRepo{
+		pg:     pg,
+		Logger: l,
+		pool:   pool,
+	}
+}
+
+// WithinTransaction выполняет fn в одной транзакции. Методы репозитория, вызванные
+// с переданным в fn контекстом, присоединяются к ней, а их собственные транзакции
+// становятся точками сохранения. Вложенный вызов использует уже открытую транзакцию.
+func (g *GopherMartRepo) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+	tx, err := g.pool.Begin(ctx)
+	if err != nil {
+		return g.logAndReturnError(ctx, "WithinTransaction - begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return g.logAndReturnError(ctx, "WithinTransaction - commit transaction", err)
+	}
+	return nil
+}
+
+// conn возвращает транзакцию из контекста, а без нее пул
+func (g *GopherMartRepo) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return g.pool
+}
@@ -2,10 +2,13 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"go-loyalty-system/internal/entity"
 	"go-loyalty-system/pkg/logging"
 	"go-loyalty-system/pkg/postgres"
+	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
@@ -17,6 +20,10 @@ type AuthUseCase interface {
 	GetUsers(context.Context) ([]entity.User, error)
 	GetUserByEmail(ctx context.Context, u entity.User) (*entity.User, error)
 	GetUserByLogin(ctx context.Context, u entity.User) (*entity.User, error)
+	GetUserByID(ctx context.Context, id uint) (*entity.User, error)
+	UpdatePassword(ctx context.Context, userID uint, hash string) error
+	SearchUsers(ctx context.Context, query string, limit int) ([]entity.User, error)
+	SetUserLocked(ctx context.Context, userID uint, locked bool) error
 }
 
 func NewUserrepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpool.Pool) *GopherMartRepo {
@@ -26,31 +33,42 @@ func NewUserrepository(pg *postgres.Postgres, l *logging.ZapLogger, pool *pgxpoo
 		pool:   pool,
 	}
 }
+
+const querySelectUser = `
+	SELECT id, login, password, COALESCE(email, ''), password_reset_required, role, locked_at
+	FROM users
+	`
+
 func (g *GopherMartRepo) GetUserByID(ctx context.Context, id uint) (*entity.User, error) {
-	return g.getUser(ctx, "SELECT id, login, password, email FROM users WHERE id = $1", id)
+	return g.getUser(ctx, querySelectUser+"WHERE id = $1", id)
 }
 
 func (g *GopherMartRepo) GetUserByLogin(ctx context.Context, u entity.User) (*entity.User, error) {
-	return g.getUser(ctx, "SELECT id, login, password, email FROM users WHERE login = $1", u.Login)
+	return g.getUser(ctx, querySelectUser+"WHERE login = $1", u.Login)
 }
 
 func (g *GopherMartRepo) GetUserByEmail(ctx context.Context, u entity.User) (*entity.User, error) {
-	return g.getUser(ctx, `SELECT id, login, password, email FROM users WHERE email = $1 and password = $2`, u.Email, u.Password)
+	return g.getUser(ctx, querySelectUser+"WHERE email = $1", u.Email)
 }
 
 func (g *GopherMartRepo) getUser(ctx context.Context, query string, args ...interface{}) (*entity.User, error) {
-	row := g.pg.Pool.QueryRow(ctx, query, args...)
+	row := g.conn(ctx).QueryRow(ctx, query, args...)
 
 	user := &entity.User{}
-	err := row.Scan(&user.ID, &user.Login, &user.Password, &user.Email)
+	err := row.Scan(&user.ID, &user.Login, &user.Password, &user.Email, &user.PasswordResetRequired, &user.Access, &user.LockedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrUserDoesNotExist
+	}
 	if err != nil {
-		g.Logger.ErrorCtx(ctx, "Error scanning user row: %w", zap.Error(err))
+		g.Logger.ErrorCtx(ctx, "getUser - scan user row", zap.Error(err))
 		return nil, err
 	}
 	return user, nil
 }
+
+// RegisterUser сохраняет пользователя, пароль должен быть уже захеширован
 func (g *GopherMartRepo) RegisterUser(ctx context.Context, u entity.User) error {
-	_, err := g.pg.Pool.Exec(ctx, `
+	tag, err := g.conn(ctx).Exec(ctx, `
 		INSERT INTO users (login, email, password)
 		VALUES ($1, $2, $3)
 		ON CONFLICT (login) DO NOTHING
@@ -58,19 +76,38 @@ func (g *GopherMartRepo) RegisterUser(ctx context.Context, u entity.User) error
 	if err != nil {
 		return g.logAndReturnError(ctx, "RegisterUser", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrUserExists
+	}
+	return nil
+}
+
+// UpdatePassword заменяет хеш пароля и снимает требование сменить пароль
+func (g *GopherMartRepo) UpdatePassword(ctx context.Context, userID uint, hash string) error {
+	const queryUpdatePassword = `
+	UPDATE users
+	SET password = $2, password_reset_required = FALSE, updated = 'UpdatePassword'
+	WHERE id = $1`
+	tag, err := g.conn(ctx).Exec(ctx, queryUpdatePassword, userID, hash)
+	if err != nil {
+		return g.logAndReturnError(ctx, "UpdatePassword - Exec", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrUserDoesNotExist
+	}
 	return nil
 }
 
 func (g *GopherMartRepo) GetUsers(ctx context.Context) ([]entity.User, error) {
 	sql, _, err := g.pg.Builder.
-		Select("login, email").
+		Select("login, COALESCE(email, ''), role").
 		From("users").
 		ToSql()
 	if err != nil {
 		return nil, g.logAndReturnError(ctx, "GetUsers", err)
 	}
 
-	rows, err := g.pg.Pool.Query(ctx, sql)
+	rows, err := g.conn(ctx).Query(ctx, sql)
 	if err != nil {
 		return nil, g.logAndReturnError(ctx, "GetUsers", err)
 	}
@@ -79,7 +116,7 @@ func (g *GopherMartRepo) GetUsers(ctx context.Context) ([]entity.User, error) {
 
 	for rows.Next() {
 		e := entity.User{}
-		if err := rows.Scan(&e.Login, &e.Email); err != nil {
+		if err := rows.Scan(&e.Login, &e.Email, &e.Access); err != nil {
 			return nil, g.logAndReturnError(ctx, "GetUsers", err)
 		}
 		entities = append(entities, e)
@@ -87,3 +124,52 @@ func (g *GopherMartRepo) GetUsers(ctx context.Context) ([]entity.User, error) {
 
 	return entities, nil
 }
+
+// SearchUsers ищет пользователей по подстроке логина или email без учета регистра
+func (g *GopherMartRepo) SearchUsers(ctx context.Context, query string, limit int) ([]entity.User, error) {
+	const querySearchUsers = `
+	SELECT id, login, COALESCE(email, ''), role, locked_at
+	FROM users
+	WHERE login ILIKE $1 OR email ILIKE $1
+	ORDER BY login
+	LIMIT $2`
+	rows, err := g.conn(ctx).Query(ctx, querySearchUsers, "%"+escapeLike(query)+"%", limit)
+	if err != nil {
+		return nil, g.logAndReturnError(ctx, "SearchUsers - Query", err)
+	}
+	defer rows.Close()
+
+	users := make([]entity.User, 0, _defaultEntityCap)
+	for rows.Next() {
+		u := entity.User{}
+		if err := rows.Scan(&u.ID, &u.Login, &u.Email, &u.Access, &u.LockedAt); err != nil {
+			return nil, g.logAndReturnError(ctx, "SearchUsers - Scan", err)
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, g.logAndReturnError(ctx, "SearchUsers - rows", err)
+	}
+	return users, nil
+}
+
+// SetUserLocked блокирует или разблокирует учетную запись
+func (g *GopherMartRepo) SetUserLocked(ctx context.Context, userID uint, locked bool) error {
+	const querySetUserLocked = `
+	UPDATE users
+	SET locked_at = CASE WHEN $2 THEN COALESCE(locked_at, NOW()) END, updated = 'SetUserLocked'
+	WHERE id = $1`
+	tag, err := g.conn(ctx).Exec(ctx, querySetUserLocked, userID, locked)
+	if err != nil {
+		return g.logAndReturnError(ctx, "SetUserLocked - Exec", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrUserDoesNotExist
+	}
+	return nil
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/audit"
+	"go-loyalty-system/internal/entity"
+	"strings"
+)
+
+// AdminReverseWithdrawal возврат по списанию администратором; попадает и в журнал действий сотрудников
+func (uc *UserUseCase) AdminReverseWithdrawal(ctx context.Context,
+	actorID uint,
+	r entity.WithdrawalReversal) (*entity.Withdrawal, error) {
+	return uc.reverseWithdrawal(ctx, actorID, r, false)
+}
+
+// MerchantReverseWithdrawal возврат по списанию магазином, когда он отменил заказ, на который
+// были потрачены баллы. Магазин видит только списания по заказам, которые сам загрузил
+// через /api/merchant/orders; чужое списание для него не существует.
+func (uc *UserUseCase) MerchantReverseWithdrawal(ctx context.Context,
+	merchantID uint,
+	r entity.WithdrawalReversal) (*entity.Withdrawal, error) {
+	return uc.reverseWithdrawal(ctx, merchantID, r, true)
+}
+
+// reverseWithdrawal блокирует списание и баланс владельца, проверяет остаток
+// и проводит возврат вместе с записями журналов одной транзакцией.
+// Без суммы возвращается весь остаток; вернуть больше списанного нельзя,
+// в том числе несколькими частичными возвратами.
+func (uc *UserUseCase) reverseWithdrawal(ctx context.Context,
+	actorID uint,
+	r entity.WithdrawalReversal,
+	merchant bool) (*entity.Withdrawal, error) {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" {
+		return nil, entity.ErrReasonRequired
+	}
+	if r.Sum.IsNegative() {
+		return nil, entity.ErrInvalidReversal
+	}
+
+	var reversed *entity.Withdrawal
+	err := uc.withinTransaction(ctx, func(ctx context.Context) error {
+		w, err := uc.balance.GetWithdrawalForUpdate(ctx, r.Order)
+		if err != nil {
+			return fmt.Errorf("GopherMartUseCase - ReverseWithdrawal: %w", err)
+		}
+		if merchant {
+			// заказ должен быть загружен этим магазином и для того же покупателя, что потратил баллы
+			owned, err := uc.order.IsMerchantOrder(ctx, w.OrderNumber, w.UserID, actorID)
+			if err != nil {
+				return fmt.Errorf("GopherMartUseCase - ReverseWithdrawal: %w", err)
+			}
+			if !owned {
+				return fmt.Errorf("GopherMartUseCase - ReverseWithdrawal: %w", entity.ErrWithdrawalNotFound)
+			}
+		}
+		amount := r.Sum
+		if amount.IsZero() {
+			amount = w.Remaining()
+		}
+		if !amount.IsPositive() || amount.Cmp(w.Remaining()) > 0 {
+			return fmt.Errorf("GopherMartUseCase - ReverseWithdrawal: %w: %s of %s left",
+				entity.ErrReversalExceeded, amount, w.Remaining())
+		}
+
+		balance, err := uc.balance.GetBalanceForUpdate(ctx, w.UserID)
+		if err != nil {
+			return fmt.Errorf("GopherMartUseCase - ReverseWithdrawal: %w", err)
+		}
+		if err := uc.balance.ReverseWithdrawal(ctx, *w, amount, r.Reason); err != nil {
+			return fmt.Errorf("GopherMartUseCase - ReverseWithdrawal: %w", err)
+		}
+		// возвращенные баллы получают новый срок: исходные партии могли уже сгореть
+		if err := uc.creditLot(ctx, w.UserID, w.OrderNumber, amount); err != nil {
+			return err
+		}
+		w.SetReversed(w.Reversed.Add(amount))
+
+		after := balance.Current.Add(amount)
+		if !merchant {
+			if err := uc.auditAdmin(ctx, actorID, entity.AdminReverseWithdrawal, &w.UserID, map[string]any{
+				"order":  w.OrderNumber,
+				"amount": amount,
+				"reason": r.Reason,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := uc.recordAudit(ctx, audit.Event{
+			Type:        audit.WithdrawalReversed,
+			ActorID:     &actorID,
+			UserID:      &w.UserID,
+			OrderNumber: w.OrderNumber,
+			Amount:      &amount,
+			Before:      &balance.Current,
+			After:       &after,
+			Details:     map[string]string{"reason": r.Reason, "status": string(w.Status)},
+		}); err != nil {
+			return err
+		}
+		reversed = w
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reversed, nil
+}
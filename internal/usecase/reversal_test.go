@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reversalMocks struct {
+	balance *mocks.MockBalanceUseCase
+	order   *mocks.MockOrderUseCase
+	audit   *mocks.MockAdminAuditRepository
+}
+
+func setupReversalUseCase(t *testing.T) (*UserUseCase, reversalMocks) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	m := reversalMocks{
+		balance: mocks.NewMockBalanceUseCase(ctrl),
+		order:   mocks.NewMockOrderUseCase(ctrl),
+		audit:   mocks.NewMockAdminAuditRepository(ctrl),
+	}
+	uc := NewGopherMart(mocks.NewMockRepository(ctrl), m.balance, m.order,
+		mocks.NewMockAuthUseCase(ctrl), log, WithAdminAudit(m.audit))
+	return uc, m
+}
+
+func reversedWithdrawal(order string, amount, reversed entity.Points) *entity.Withdrawal {
+	w := &entity.Withdrawal{ID: 3, UserID: 7, OrderNumber: order, Amount: amount}
+	w.SetReversed(reversed)
+	return w
+}
+
+func TestReverseWithdrawal(t *testing.T) {
+	ctx := context.Background()
+	const order = "2377225624"
+
+	t.Run("whole remainder is returned by default", func(t *testing.T) {
+		uc, m := setupReversalUseCase(t)
+		w := reversedWithdrawal(order, entity.NewPoints(100, 0), entity.NewPoints(40, 0))
+		gomock.InOrder(
+			m.balance.EXPECT().GetWithdrawalForUpdate(ctx, order).Return(w, nil),
+			m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{}, nil),
+			m.balance.EXPECT().ReverseWithdrawal(ctx, *w, entity.NewPoints(60, 0), "order cancelled").Return(nil),
+			m.audit.EXPECT().CreateAdminAudit(ctx, gomock.Any()).Return(nil),
+		)
+
+		got, err := uc.AdminReverseWithdrawal(ctx, 1, entity.WithdrawalReversal{Order: order, Reason: " order cancelled "})
+		require.NoError(t, err)
+		assert.Equal(t, entity.WithdrawalReversed, got.Status)
+		assert.Equal(t, entity.NewPoints(100, 0), got.Reversed)
+	})
+
+	t.Run("partial reversal", func(t *testing.T) {
+		uc, m := setupReversalUseCase(t)
+		w := reversedWithdrawal(order, entity.NewPoints(100, 0), 0)
+		m.balance.EXPECT().GetWithdrawalForUpdate(ctx, order).Return(w, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{}, nil)
+		m.balance.EXPECT().ReverseWithdrawal(ctx, *w, entity.NewPoints(30, 0), "one item returned").Return(nil)
+		m.audit.EXPECT().CreateAdminAudit(ctx, gomock.Any()).Return(nil)
+
+		got, err := uc.AdminReverseWithdrawal(ctx, 1, entity.WithdrawalReversal{
+			Order: order, Sum: entity.NewPoints(30, 0), Reason: "one item returned",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, entity.WithdrawalPartiallyReversed, got.Status)
+		assert.Equal(t, entity.NewPoints(70, 0), got.Remaining())
+	})
+
+	t.Run("reversals cannot exceed the withdrawal", func(t *testing.T) {
+		uc, m := setupReversalUseCase(t)
+		m.balance.EXPECT().GetWithdrawalForUpdate(ctx, order).
+			Return(reversedWithdrawal(order, entity.NewPoints(100, 0), entity.NewPoints(80, 0)), nil)
+
+		_, err := uc.AdminReverseWithdrawal(ctx, 1, entity.WithdrawalReversal{
+			Order: order, Sum: entity.NewPoints(30, 0), Reason: "order cancelled",
+		})
+		assert.ErrorIs(t, err, entity.ErrReversalExceeded)
+	})
+
+	t.Run("reversed withdrawal cannot be reversed again", func(t *testing.T) {
+		uc, m := setupReversalUseCase(t)
+		m.balance.EXPECT().GetWithdrawalForUpdate(ctx, order).
+			Return(reversedWithdrawal(order, entity.NewPoints(100, 0), entity.NewPoints(100, 0)), nil)
+
+		_, err := uc.AdminReverseWithdrawal(ctx, 1, entity.WithdrawalReversal{Order: order, Reason: "order cancelled"})
+		assert.ErrorIs(t, err, entity.ErrReversalExceeded)
+	})
+
+	t.Run("reason and positive sum are required", func(t *testing.T) {
+		uc, _ := setupReversalUseCase(t)
+
+		_, err := uc.AdminReverseWithdrawal(ctx, 1, entity.WithdrawalReversal{Order: order, Reason: "  "})
+		assert.ErrorIs(t, err, entity.ErrReasonRequired)
+		_, err = uc.AdminReverseWithdrawal(ctx, 1, entity.WithdrawalReversal{
+			Order: order, Sum: entity.NewPoints(-1, 0), Reason: "order cancelled",
+		})
+		assert.ErrorIs(t, err, entity.ErrInvalidReversal)
+	})
+
+	t.Run("unknown withdrawal", func(t *testing.T) {
+		uc, m := setupReversalUseCase(t)
+		m.balance.EXPECT().GetWithdrawalForUpdate(ctx, order).Return(nil, entity.ErrWithdrawalNotFound)
+
+		_, err := uc.AdminReverseWithdrawal(ctx, 1, entity.WithdrawalReversal{Order: order, Reason: "order cancelled"})
+		assert.ErrorIs(t, err, entity.ErrWithdrawalNotFound)
+	})
+
+	t.Run("reversal is audited with the admin", func(t *testing.T) {
+		uc, m := setupReversalUseCase(t)
+		w := reversedWithdrawal(order, entity.NewPoints(100, 0), 0)
+		m.balance.EXPECT().GetWithdrawalForUpdate(ctx, order).Return(w, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{}, nil)
+		m.balance.EXPECT().ReverseWithdrawal(ctx, *w, entity.NewPoints(100, 0), "duplicate charge").Return(nil)
+		m.audit.EXPECT().CreateAdminAudit(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, e entity.AdminAuditEntry) error {
+				assert.Equal(t, uint(1), e.ActorID)
+				assert.Equal(t, entity.AdminReverseWithdrawal, e.Action)
+				assert.Equal(t, uint(7), *e.TargetUserID)
+				assert.JSONEq(t, `{"order":"2377225624","amount":100,"reason":"duplicate charge"}`, e.Details)
+				return nil
+			})
+
+		_, err := uc.AdminReverseWithdrawal(ctx, 1, entity.WithdrawalReversal{Order: order, Reason: "duplicate charge"})
+		require.NoError(t, err)
+	})
+
+	t.Run("repository error aborts the reversal", func(t *testing.T) {
+		uc, m := setupReversalUseCase(t)
+		expectedErr := errors.New("database error")
+		w := reversedWithdrawal(order, entity.NewPoints(100, 0), 0)
+		m.balance.EXPECT().GetWithdrawalForUpdate(ctx, order).Return(w, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{}, nil)
+		m.balance.EXPECT().ReverseWithdrawal(ctx, *w, entity.NewPoints(100, 0), "order cancelled").Return(expectedErr)
+
+		_, err := uc.AdminReverseWithdrawal(ctx, 1, entity.WithdrawalReversal{Order: order, Reason: "order cancelled"})
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestMerchantReverseWithdrawal(t *testing.T) {
+	ctx := context.Background()
+	const order = "2377225624"
+	const merchantID = 42
+	cancelled := entity.WithdrawalReversal{Order: order, Reason: "order cancelled"}
+
+	t.Run("merchant reverses a withdrawal on its own order", func(t *testing.T) {
+		uc, m := setupReversalUseCase(t)
+		w := reversedWithdrawal(order, entity.NewPoints(100, 0), 0)
+		gomock.InOrder(
+			m.balance.EXPECT().GetWithdrawalForUpdate(ctx, order).Return(w, nil),
+			m.order.EXPECT().IsMerchantOrder(ctx, order, uint(7), uint(merchantID)).Return(true, nil),
+			m.balance.EXPECT().GetBalanceForUpdate(ctx, uint(7)).Return(&entity.Balance{}, nil),
+			m.balance.EXPECT().ReverseWithdrawal(ctx, *w, entity.NewPoints(100, 0), "order cancelled").Return(nil),
+		)
+
+		got, err := uc.MerchantReverseWithdrawal(ctx, merchantID, cancelled)
+		require.NoError(t, err)
+		assert.Equal(t, entity.WithdrawalReversed, got.Status)
+	})
+
+	t.Run("withdrawal outside the merchant's orders is not found", func(t *testing.T) {
+		// заказ загрузил другой магазин или сам покупатель — ответ тот же, что и для несуществующего
+		uc, m := setupReversalUseCase(t)
+		m.balance.EXPECT().GetWithdrawalForUpdate(ctx, order).
+			Return(reversedWithdrawal(order, entity.NewPoints(100, 0), 0), nil)
+		m.order.EXPECT().IsMerchantOrder(ctx, order, uint(7), uint(merchantID)).Return(false, nil)
+
+		_, err := uc.MerchantReverseWithdrawal(ctx, merchantID, cancelled)
+		assert.ErrorIs(t, err, entity.ErrWithdrawalNotFound)
+	})
+
+	t.Run("ownership check error aborts the reversal", func(t *testing.T) {
+		uc, m := setupReversalUseCase(t)
+		expectedErr := errors.New("database error")
+		m.balance.EXPECT().GetWithdrawalForUpdate(ctx, order).
+			Return(reversedWithdrawal(order, entity.NewPoints(100, 0), 0), nil)
+		m.order.EXPECT().IsMerchantOrder(ctx, order, uint(7), uint(merchantID)).Return(false, expectedErr)
+
+		_, err := uc.MerchantReverseWithdrawal(ctx, merchantID, cancelled)
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
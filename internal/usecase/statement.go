@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/entity"
+	"time"
+)
+
+// ExportStatement выписка по счету пользователя за период: заказы и проводки
+// передаются в w по мере чтения из базы. Баланс на начало берется из журнала,
+// баланс после каждой проводки и на конец периода считается по ходу выписки,
+// поэтому итог всегда сходится со строками.
+func (uc *UserUseCase) ExportStatement(ctx context.Context,
+	userID uint,
+	p entity.StatementPeriod,
+	w entity.StatementWriter) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	p, err := p.Normalize(time.Now())
+	if err != nil {
+		return err
+	}
+	statement := entity.Statement{From: p.From, To: *p.To}
+	if p.From != nil {
+		statement.OpeningBalance, err = uc.balance.GetBalanceAt(ctx, userID, *p.From)
+		if err != nil {
+			return fmt.Errorf("GopherMartUseCase - ExportStatement: %w", err)
+		}
+	}
+	if err := w.Begin(statement); err != nil {
+		return err
+	}
+
+	balance := statement.OpeningBalance
+	err = uc.balance.StreamStatement(ctx, userID, p, func(line entity.StatementLine) error {
+		if line.Amount != nil {
+			balance = balance.Add(*line.Amount)
+			after := balance
+			line.Balance = &after
+		}
+		return w.Line(line)
+	})
+	if err != nil {
+		return fmt.Errorf("GopherMartUseCase - ExportStatement: %w", err)
+	}
+	statement.ClosingBalance = balance
+	return w.End(statement)
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-loyalty-system/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statementRecorder собирает выписку, отданную StatementWriter
+type statementRecorder struct {
+	begin *entity.Statement
+	lines []entity.StatementLine
+	end   *entity.Statement
+}
+
+func (r *statementRecorder) Begin(s entity.Statement) error { r.begin = &s; return nil }
+
+func (r *statementRecorder) Line(l entity.StatementLine) error {
+	r.lines = append(r.lines, l)
+	return nil
+}
+
+func (r *statementRecorder) End(s entity.Statement) error { r.end = &s; return nil }
+
+func TestExportStatement(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	amount := func(units int64) *entity.Points {
+		p := entity.NewPoints(units, 0)
+		return &p
+	}
+	stream := func(lines ...entity.StatementLine) func(context.Context, uint, entity.StatementPeriod,
+		func(entity.StatementLine) error) error {
+		return func(_ context.Context, _ uint, _ entity.StatementPeriod, fn func(entity.StatementLine) error) error {
+			for _, l := range lines {
+				if err := fn(l); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	t.Run("balances run from the opening balance", func(t *testing.T) {
+		uc, balanceRepo := setupLedgerUseCase(t)
+		period := entity.StatementPeriod{From: &from, To: &to}
+		balanceRepo.EXPECT().GetBalanceAt(ctx, uint(7), from).Return(entity.NewPoints(50, 0), nil)
+		balanceRepo.EXPECT().StreamStatement(ctx, uint(7), period, gomock.Any()).DoAndReturn(stream(
+			entity.StatementLine{Type: entity.StatementOrder, OrderNumber: "12345678903", Status: entity.OrderStatusProcessed},
+			entity.StatementLine{Type: string(entity.LedgerAccrual), OrderNumber: "12345678903", Amount: amount(100)},
+			entity.StatementLine{Type: string(entity.LedgerWithdrawal), OrderNumber: "2377225624", Amount: amount(-30)},
+		))
+
+		rec := &statementRecorder{}
+		require.NoError(t, uc.ExportStatement(ctx, 7, period, rec))
+		assert.Equal(t, entity.Statement{From: &from, To: to, OpeningBalance: entity.NewPoints(50, 0)}, *rec.begin)
+		require.Len(t, rec.lines, 3)
+		assert.Nil(t, rec.lines[0].Balance)
+		assert.Equal(t, amount(150), rec.lines[1].Balance)
+		assert.Equal(t, amount(120), rec.lines[2].Balance)
+		assert.Equal(t, entity.NewPoints(120, 0), rec.end.ClosingBalance)
+	})
+
+	t.Run("statement without from starts from zero", func(t *testing.T) {
+		uc, balanceRepo := setupLedgerUseCase(t)
+		balanceRepo.EXPECT().StreamStatement(ctx, uint(7), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uint, p entity.StatementPeriod, _ func(entity.StatementLine) error) error {
+				assert.Nil(t, p.From)
+				assert.WithinDuration(t, time.Now(), *p.To, time.Minute)
+				return nil
+			})
+
+		rec := &statementRecorder{}
+		require.NoError(t, uc.ExportStatement(ctx, 7, entity.StatementPeriod{}, rec))
+		assert.True(t, rec.begin.OpeningBalance.IsZero())
+		assert.True(t, rec.end.ClosingBalance.IsZero())
+	})
+
+	t.Run("from must be before to", func(t *testing.T) {
+		uc, _ := setupLedgerUseCase(t)
+
+		err := uc.ExportStatement(ctx, 7, entity.StatementPeriod{From: &to, To: &from}, &statementRecorder{})
+		assert.ErrorIs(t, err, entity.ErrInvalidStatement)
+	})
+
+	t.Run("stream error leaves the statement without closing balance", func(t *testing.T) {
+		uc, balanceRepo := setupLedgerUseCase(t)
+		balanceRepo.EXPECT().GetBalanceAt(ctx, uint(7), from).Return(entity.NewPoints(0, 0), nil)
+		balanceRepo.EXPECT().StreamStatement(ctx, uint(7), gomock.Any(), gomock.Any()).Return(errors.New("conn reset"))
+
+		rec := &statementRecorder{}
+		err := uc.ExportStatement(ctx, 7, entity.StatementPeriod{From: &from, To: &to}, rec)
+		assert.Error(t, err)
+		assert.NotNil(t, rec.begin)
+		assert.Nil(t, rec.end)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		uc, _ := setupLedgerUseCase(t)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := uc.ExportStatement(cancelled, 7, entity.StatementPeriod{}, &statementRecorder{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
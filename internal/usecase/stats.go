@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/entity"
+)
+
+// GetLoyaltyStats сводные показатели программы для метрик
+func (uc *UserUseCase) GetLoyaltyStats(ctx context.Context) (*entity.LoyaltyStats, error) {
+	if uc.stats == nil {
+		return nil, entity.ErrStatsUnavailable
+	}
+	stats, err := uc.stats.GetLoyaltyStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - GetLoyaltyStats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/audit"
+	"go-loyalty-system/internal/entity"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// applyTier пересчитывает уровень после начисления accrual по заказу. Начисление
+// уже в журнале: уровень до него определяет надбавку, уровень после — новый статус.
+// balance — доступный баланс сразу после начисления.
+func (uc *UserUseCase) applyTier(ctx context.Context,
+	userID uint,
+	orderNumber string,
+	accrual, balance entity.Points) error {
+	if uc.tiers == nil || !uc.tierPolicy.Enabled() {
+		return nil
+	}
+	accrued, err := uc.tiers.GetRollingAccrual(ctx, userID, uc.tierPolicy.Since(time.Now()))
+	if err != nil {
+		return fmt.Errorf("GopherMartUseCase - applyTier: %w", err)
+	}
+	before := uc.tierPolicy.TierFor(accrued.Sub(accrual))
+	after := uc.tierPolicy.TierFor(accrued)
+
+	if bonus := before.Bonus(accrual); bonus.IsPositive() {
+		if err := uc.tiers.CreditTierBonus(ctx, userID, orderNumber, bonus, *before); err != nil {
+			return fmt.Errorf("GopherMartUseCase - applyTier: %w", err)
+		}
+		if err := uc.creditLot(ctx, userID, orderNumber, bonus); err != nil {
+			return err
+		}
+		err = uc.recordAudit(ctx, audit.Event{
+			Type:        audit.TierBonusCredited,
+			UserID:      &userID,
+			OrderNumber: orderNumber,
+			Amount:      &bonus,
+			Before:      &balance,
+			After:       audit.Ptr(balance.Add(bonus)),
+			Details: map[string]string{"tier": before.Name,
+				"multiplier": strconv.FormatFloat(before.Multiplier, 'f', -1, 64)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if before.TierName() == after.TierName() {
+		return nil
+	}
+	uc.Logger.InfoCtx(ctx, "tier changed",
+		zap.Uint("user_id", userID),
+		zap.String("from", before.TierName()),
+		zap.String("to", after.TierName()))
+	return uc.recordAudit(ctx, audit.Event{
+		Type:        audit.TierChanged,
+		UserID:      &userID,
+		OrderNumber: orderNumber,
+		Details: map[string]string{"from": before.TierName(), "to": after.TierName(),
+			"rolling_accrual": accrued.String()},
+	})
+}
+
+// tierStatus уровень пользователя по начислениям за последние месяцы и прогресс до следующего
+func (uc *UserUseCase) tierStatus(ctx context.Context, userID uint) (*entity.TierStatus, error) {
+	accrued, err := uc.tiers.GetRollingAccrual(ctx, userID, uc.tierPolicy.Since(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - tierStatus: %w", err)
+	}
+	return uc.tierPolicy.Status(accrued), nil
+}
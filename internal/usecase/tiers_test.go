@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tierMocks struct {
+	accrual *mocks.MockRepository
+	balance *mocks.MockBalanceUseCase
+	order   *mocks.MockOrderUseCase
+	tiers   *mocks.MockTierRepository
+}
+
+var testTiers = entity.TierPolicy{Tiers: []entity.Tier{
+	{Name: "SILVER", Threshold: entity.NewPoints(1000, 0), Multiplier: 1.1},
+	{Name: "GOLD", Threshold: entity.NewPoints(5000, 0), Multiplier: 1.25},
+}}
+
+func setupTierUseCase(t *testing.T, policy entity.TierPolicy) (*UserUseCase, tierMocks) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	m := tierMocks{
+		accrual: mocks.NewMockRepository(ctrl),
+		balance: mocks.NewMockBalanceUseCase(ctrl),
+		order:   mocks.NewMockOrderUseCase(ctrl),
+		tiers:   mocks.NewMockTierRepository(ctrl),
+	}
+	uc := NewGopherMart(m.accrual, m.balance, m.order, mocks.NewMockAuthUseCase(ctrl), log,
+		WithTiers(m.tiers, policy))
+	return uc, m
+}
+
+func TestSaveAccrualTiers(t *testing.T) {
+	ctx := context.Background()
+	const orderNumber = "12345678903"
+	expectAccrual := func(m tierMocks, accrual entity.Points) {
+		m.accrual.EXPECT().ExistOrderAccrual(gomock.Any(), orderNumber).Return(false, nil)
+		m.order.EXPECT().CheckOrderExistence(gomock.Any(), orderNumber, uint(0)).Return(true, uint(7), nil)
+		m.balance.EXPECT().GetBalanceForUpdate(gomock.Any(), uint(7)).Return(&entity.Balance{}, nil)
+		m.accrual.EXPECT().SaveAccrual(gomock.Any(), orderNumber, entity.AccrualStatusProcessed, accrual).Return(nil)
+	}
+
+	t.Run("tier held before the accrual earns a separate bonus", func(t *testing.T) {
+		uc, m := setupTierUseCase(t, testTiers)
+		accrual := entity.NewPoints(200, 0)
+		expectAccrual(m, accrual)
+		m.tiers.EXPECT().GetRollingAccrual(gomock.Any(), uint(7), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uint, since time.Time) (entity.Points, error) {
+				assert.WithinDuration(t, time.Now().AddDate(-1, 0, 0), since, time.Minute)
+				return entity.NewPoints(1500, 0), nil
+			})
+		m.tiers.EXPECT().CreditTierBonus(gomock.Any(), uint(7), orderNumber, entity.NewPoints(20, 0), testTiers.Tiers[0]).
+			Return(nil)
+
+		require.NoError(t, uc.SaveAccrual(ctx, orderNumber, entity.AccrualStatusProcessed, accrual))
+	})
+
+	t.Run("accrual that reaches a tier is credited at the previous multiplier", func(t *testing.T) {
+		uc, m := setupTierUseCase(t, testTiers)
+		accrual := entity.NewPoints(300, 0)
+		expectAccrual(m, accrual)
+		// 800 до начисления — базовый уровень, 1100 после — SILVER
+		m.tiers.EXPECT().GetRollingAccrual(gomock.Any(), uint(7), gomock.Any()).Return(entity.NewPoints(1100, 0), nil)
+
+		require.NoError(t, uc.SaveAccrual(ctx, orderNumber, entity.AccrualStatusProcessed, accrual))
+	})
+
+	t.Run("pending accrual does not touch tiers", func(t *testing.T) {
+		uc, m := setupTierUseCase(t, testTiers)
+		m.accrual.EXPECT().ExistOrderAccrual(gomock.Any(), orderNumber).Return(false, nil)
+		m.accrual.EXPECT().SaveAccrual(gomock.Any(), orderNumber, entity.AccrualStatusProcessing, entity.Points(0)).Return(nil)
+
+		require.NoError(t, uc.SaveAccrual(ctx, orderNumber, entity.AccrualStatusProcessing, 0))
+	})
+
+	t.Run("without tiers the accrual is saved as before", func(t *testing.T) {
+		uc, m := setupTierUseCase(t, entity.TierPolicy{})
+		accrual := entity.NewPoints(200, 0)
+		m.accrual.EXPECT().ExistOrderAccrual(gomock.Any(), orderNumber).Return(false, nil)
+		m.accrual.EXPECT().SaveAccrual(gomock.Any(), orderNumber, entity.AccrualStatusProcessed, accrual).Return(nil)
+
+		require.NoError(t, uc.SaveAccrual(ctx, orderNumber, entity.AccrualStatusProcessed, accrual))
+	})
+}
+
+func TestBalanceTier(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("balance shows the tier and progress to the next one", func(t *testing.T) {
+		uc, m := setupTierUseCase(t, testTiers)
+		m.balance.EXPECT().GetBalance(ctx, "7").Return(&entity.Balance{Current: entity.NewPoints(300, 0)}, nil)
+		m.tiers.EXPECT().GetRollingAccrual(ctx, uint(7), gomock.Any()).Return(entity.NewPoints(1200, 0), nil)
+
+		summary, err := uc.GetUserBalance(ctx, "7")
+		require.NoError(t, err)
+		require.NotNil(t, summary.Tier)
+		assert.Equal(t, "SILVER", summary.Tier.Name)
+		assert.Equal(t, entity.NewPoints(1200, 0), summary.Tier.Accrued)
+		assert.Equal(t, &entity.TierProgress{Name: "GOLD", Threshold: entity.NewPoints(5000, 0),
+			Remaining: entity.NewPoints(3800, 0)}, summary.Tier.Next)
+	})
+
+	t.Run("disabled tiers are not shown", func(t *testing.T) {
+		uc, m := setupTierUseCase(t, entity.TierPolicy{})
+		m.balance.EXPECT().GetBalance(ctx, "7").Return(&entity.Balance{}, nil)
+
+		summary, err := uc.GetUserBalance(ctx, "7")
+		require.NoError(t, err)
+		assert.Nil(t, summary.Tier)
+	})
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"go-loyalty-system/internal/entity"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// tokenCacheTTL сколько проверка access-токена живет в памяти. Отзыв через этот
+	// экземпляр сбрасывает кеш сразу, остальные экземпляры увидят его не позже TTL.
+	tokenCacheTTL = 30 * time.Second
+	// tokenCacheSweep размер, после которого из кеша выметаются истекшие записи
+	tokenCacheSweep = 10000
+)
+
+type cachedToken struct {
+	token entity.Token
+	until time.Time
+}
+
+// tokenCache кеш действующих access-токенов
+type tokenCache struct {
+	mu    sync.Mutex
+	items map[uuid.UUID]cachedToken
+	now   func() time.Time
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{
+		items: make(map[uuid.UUID]cachedToken),
+		now:   time.Now,
+	}
+}
+
+func (c *tokenCache) get(id uuid.UUID) (entity.Token, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, ok := c.items[id]
+	if !ok {
+		return entity.Token{}, false
+	}
+	if !c.now().Before(item.until) {
+		delete(c.items, id)
+		return entity.Token{}, false
+	}
+	return item.token, true
+}
+
+func (c *tokenCache) put(t entity.Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.items) >= tokenCacheSweep {
+		for id, item := range c.items {
+			if !now.Before(item.until) {
+				delete(c.items, id)
+			}
+		}
+	}
+	until := now.Add(tokenCacheTTL)
+	if t.ExpiresAt.Before(until) {
+		until = t.ExpiresAt
+	}
+	c.items[t.ID] = cachedToken{token: t, until: until}
+}
+
+func (c *tokenCache) dropFamily(familyID uuid.UUID) {
+	c.drop(func(t entity.Token) bool { return t.FamilyID == familyID })
+}
+
+func (c *tokenCache) dropUser(userID uint) {
+	c.drop(func(t entity.Token) bool { return t.UserID == userID })
+}
+
+func (c *tokenCache) drop(match func(entity.Token) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, item := range c.items {
+		if match(item.token) {
+			delete(c.items, id)
+		}
+	}
+}
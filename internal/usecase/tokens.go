@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ValidateAccessToken проверяет, что access-токен выдан сервером и не отозван.
+// Если хранилище токенов не подключено, проверка пропускается.
+func (uc *UserUseCase) ValidateAccessToken(ctx context.Context, tokenID uuid.UUID) error {
+	if uc.tokens == nil {
+		return nil
+	}
+	if _, ok := uc.tokenCache.get(tokenID); ok {
+		return nil
+	}
+	t, err := uc.tokens.GetToken(ctx, tokenID)
+	if err != nil {
+		return fmt.Errorf("GopherMartUseCase - ValidateAccessToken: %w", err)
+	}
+	if t == nil || t.Kind != entity.TokenAccess || !t.Active(time.Now()) {
+		return entity.ErrTokenRevoked
+	}
+	uc.tokenCache.put(*t)
+	return nil
+}
+
+// RotateRefreshToken погашает refresh-токен и возвращает его для выпуска новой пары
+// в той же семье. Повторное предъявление уже погашенного токена означает, что он
+// утек: вся семья отзывается.
+func (uc *UserUseCase) RotateRefreshToken(ctx context.Context, tokenID uuid.UUID) (*entity.Token, error) {
+	if uc.tokens == nil {
+		return nil, entity.ErrTokenRevoked
+	}
+	t, err := uc.tokens.UseRefreshToken(ctx, tokenID)
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - RotateRefreshToken: %w", err)
+	}
+	if t != nil {
+		return t, nil
+	}
+
+	stored, err := uc.tokens.GetToken(ctx, tokenID)
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - RotateRefreshToken: %w", err)
+	}
+	if stored == nil || stored.Kind != entity.TokenRefresh || stored.UsedAt == nil {
+		return nil, entity.ErrTokenRevoked
+	}
+	uc.Logger.WarnCtx(ctx, "refresh token reuse detected, revoking family",
+		zap.Uint("userID", stored.UserID), zap.String("family", stored.FamilyID.String()))
+	if err := uc.RevokeTokenFamily(ctx, stored.FamilyID); err != nil {
+		return nil, err
+	}
+	return nil, entity.ErrTokenReused
+}
+
+// RevokeTokenFamily завершает один сеанс: отзывает его access- и refresh-токены
+func (uc *UserUseCase) RevokeTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	if uc.tokens == nil {
+		return nil
+	}
+	uc.tokenCache.dropFamily(familyID)
+	if err := uc.tokens.RevokeTokenFamily(ctx, familyID); err != nil {
+		return fmt.Errorf("GopherMartUseCase - RevokeTokenFamily: %w", err)
+	}
+	return nil
+}
+
+// RevokeUserTokens завершает все сеансы пользователя
+func (uc *UserUseCase) RevokeUserTokens(ctx context.Context, userID uint) error {
+	if uc.tokens == nil {
+		return nil
+	}
+	uc.tokenCache.dropUser(userID)
+	if err := uc.tokens.RevokeUserTokens(ctx, userID); err != nil {
+		return fmt.Errorf("GopherMartUseCase - RevokeUserTokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTokenUseCase(t *testing.T) (*UserUseCase, *mocks.MockTokenRepository) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	tokens := mocks.NewMockTokenRepository(ctrl)
+	uc := NewGopherMart(
+		mocks.NewMockRepository(ctrl),
+		mocks.NewMockBalanceUseCase(ctrl),
+		mocks.NewMockOrderUseCase(ctrl),
+		mocks.NewMockAuthUseCase(ctrl),
+		log,
+		WithTokens(tokens))
+	return uc, tokens
+}
+
+func TestValidateAccessToken(t *testing.T) {
+	ctx := context.Background()
+	family := uuid.New()
+	access := entity.Token{ID: uuid.New(), UserID: 7, Kind: entity.TokenAccess, FamilyID: family,
+		ExpiresAt: time.Now().Add(time.Minute)}
+
+	t.Run("active token is cached", func(t *testing.T) {
+		uc, tokens := setupTokenUseCase(t)
+		tokens.EXPECT().GetToken(ctx, access.ID).Return(&access, nil).Times(1)
+
+		require.NoError(t, uc.ValidateAccessToken(ctx, access.ID))
+		require.NoError(t, uc.ValidateAccessToken(ctx, access.ID))
+	})
+
+	t.Run("logout drops cached token", func(t *testing.T) {
+		uc, tokens := setupTokenUseCase(t)
+		revoked := access
+		now := time.Now()
+		revoked.RevokedAt = &now
+		gomock.InOrder(
+			tokens.EXPECT().GetToken(ctx, access.ID).Return(&access, nil),
+			tokens.EXPECT().RevokeTokenFamily(ctx, family).Return(nil),
+			tokens.EXPECT().GetToken(ctx, access.ID).Return(&revoked, nil),
+		)
+
+		require.NoError(t, uc.ValidateAccessToken(ctx, access.ID))
+		require.NoError(t, uc.RevokeTokenFamily(ctx, family))
+		assert.ErrorIs(t, uc.ValidateAccessToken(ctx, access.ID), entity.ErrTokenRevoked)
+	})
+
+	t.Run("logout everywhere drops cached token", func(t *testing.T) {
+		uc, tokens := setupTokenUseCase(t)
+		tokens.EXPECT().GetToken(ctx, access.ID).Return(&access, nil).Times(2)
+		tokens.EXPECT().RevokeUserTokens(ctx, uint(7)).Return(nil)
+
+		require.NoError(t, uc.ValidateAccessToken(ctx, access.ID))
+		require.NoError(t, uc.RevokeUserTokens(ctx, 7))
+		require.NoError(t, uc.ValidateAccessToken(ctx, access.ID))
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		uc, tokens := setupTokenUseCase(t)
+		tokens.EXPECT().GetToken(ctx, access.ID).Return(nil, nil)
+
+		assert.ErrorIs(t, uc.ValidateAccessToken(ctx, access.ID), entity.ErrTokenRevoked)
+	})
+
+	t.Run("refresh token is not an access token", func(t *testing.T) {
+		uc, tokens := setupTokenUseCase(t)
+		refresh := access
+		refresh.Kind = entity.TokenRefresh
+		tokens.EXPECT().GetToken(ctx, access.ID).Return(&refresh, nil)
+
+		assert.ErrorIs(t, uc.ValidateAccessToken(ctx, access.ID), entity.ErrTokenRevoked)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		uc, tokens := setupTokenUseCase(t)
+		expired := access
+		expired.ExpiresAt = time.Now().Add(-time.Second)
+		tokens.EXPECT().GetToken(ctx, access.ID).Return(&expired, nil)
+
+		assert.ErrorIs(t, uc.ValidateAccessToken(ctx, access.ID), entity.ErrTokenRevoked)
+	})
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	family := uuid.New()
+	refresh := entity.Token{ID: uuid.New(), UserID: 7, Kind: entity.TokenRefresh, FamilyID: family,
+		ExpiresAt: time.Now().Add(time.Hour)}
+
+	t.Run("refresh token is consumed", func(t *testing.T) {
+		uc, tokens := setupTokenUseCase(t)
+		tokens.EXPECT().UseRefreshToken(ctx, refresh.ID).Return(&refresh, nil)
+
+		got, err := uc.RotateRefreshToken(ctx, refresh.ID)
+		require.NoError(t, err)
+		assert.Equal(t, family, got.FamilyID)
+	})
+
+	t.Run("reuse revokes the family", func(t *testing.T) {
+		uc, tokens := setupTokenUseCase(t)
+		used := refresh
+		now := time.Now()
+		used.UsedAt = &now
+		tokens.EXPECT().UseRefreshToken(ctx, refresh.ID).Return(nil, nil)
+		tokens.EXPECT().GetToken(ctx, refresh.ID).Return(&used, nil)
+		tokens.EXPECT().RevokeTokenFamily(ctx, family).Return(nil)
+
+		_, err := uc.RotateRefreshToken(ctx, refresh.ID)
+		assert.ErrorIs(t, err, entity.ErrTokenReused)
+	})
+
+	t.Run("revoked refresh token", func(t *testing.T) {
+		uc, tokens := setupTokenUseCase(t)
+		revoked := refresh
+		now := time.Now()
+		revoked.RevokedAt = &now
+		tokens.EXPECT().UseRefreshToken(ctx, refresh.ID).Return(nil, nil)
+		tokens.EXPECT().GetToken(ctx, refresh.ID).Return(&revoked, nil)
+
+		_, err := uc.RotateRefreshToken(ctx, refresh.ID)
+		assert.ErrorIs(t, err, entity.ErrTokenRevoked)
+	})
+
+	t.Run("access token cannot be rotated", func(t *testing.T) {
+		uc, tokens := setupTokenUseCase(t)
+		access := refresh
+		access.Kind = entity.TokenAccess
+		tokens.EXPECT().UseRefreshToken(ctx, refresh.ID).Return(nil, nil)
+		tokens.EXPECT().GetToken(ctx, refresh.ID).Return(&access, nil)
+
+		_, err := uc.RotateRefreshToken(ctx, refresh.ID)
+		assert.ErrorIs(t, err, entity.ErrTokenRevoked)
+	})
+}
@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("go-loyalty-system/internal/usecase")
+
+// startSpan открывает спан сценария. Запросы к базе внутри него
+// становятся дочерними спанами.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "GopherMartUseCase."+name)
+}
+
+// endSpan закрывает спан, отмечая ошибку сценария
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
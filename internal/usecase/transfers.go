@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-loyalty-system/internal/audit"
+	"go-loyalty-system/internal/entity"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// transferLimitWindow за какой период считаются лимиты переводов
+const transferLimitWindow = 24 * time.Hour
+
+// TransferPoints переводит баллы другому пользователю по логину. Списание у отправителя
+// и зачисление получателю проходят одной транзакцией; переводить себе, заблокированным
+// пользователям и сверх суточных лимитов нельзя.
+func (uc *UserUseCase) TransferPoints(ctx context.Context,
+	senderID uint,
+	r entity.TransferRequest) (*entity.Transfer, error) {
+	if !r.Sum.IsPositive() {
+		return nil, entity.ErrInvalidTransfer
+	}
+	sender, err := uc.user.GetUserByID(ctx, senderID)
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - TransferPoints: %w", err)
+	}
+	recipient, err := uc.user.GetUserByLogin(ctx, entity.User{Login: strings.TrimSpace(r.Login)})
+	if err != nil {
+		return nil, fmt.Errorf("GopherMartUseCase - TransferPoints: %w", err)
+	}
+	if recipient.ID == sender.ID {
+		return nil, entity.ErrSelfTransfer
+	}
+	if sender.LockedAt != nil || recipient.LockedAt != nil {
+		return nil, entity.ErrUserLocked
+	}
+
+	var transfer *entity.Transfer
+	err = uc.withinTransaction(ctx, func(ctx context.Context) error {
+		recipientBalance, err := uc.lockTransferBalances(ctx, sender.ID, recipient.ID)
+		if err != nil {
+			return err
+		}
+		if err := uc.expireBeforeDebit(ctx, sender.ID); err != nil {
+			return err
+		}
+		senderBalance, err := uc.balance.GetBalanceForUpdate(ctx, sender.ID)
+		if err != nil {
+			return fmt.Errorf("GopherMartUseCase - TransferPoints: %w", err)
+		}
+		if senderBalance.Current.Cmp(r.Sum) < 0 {
+			return entity.ErrInsufficientFunds
+		}
+		sent, count, err := uc.balance.GetSentTransfers(ctx, sender.ID, time.Now().Add(-transferLimitWindow))
+		if err != nil {
+			return fmt.Errorf("GopherMartUseCase - TransferPoints: %w", err)
+		}
+		if !uc.transferLimits.Allows(sent, count, r.Sum) {
+			return fmt.Errorf("GopherMartUseCase - TransferPoints: %w: sent %s in %d transfers",
+				entity.ErrTransferLimit, sent, count)
+		}
+
+		consumed, err := uc.consumeLots(ctx, sender.ID, r.Sum)
+		if err != nil {
+			return err
+		}
+		transfer, err = uc.balance.CreateTransferTx(ctx, entity.Transfer{
+			SenderID:    sender.ID,
+			RecipientID: recipient.ID,
+			Amount:      r.Sum,
+		})
+		if err != nil {
+			return fmt.Errorf("GopherMartUseCase - TransferPoints: %w", err)
+		}
+		if err := uc.transferLots(ctx, recipient.ID, r.Sum, consumed); err != nil {
+			return err
+		}
+
+		transferID := strconv.FormatInt(transfer.ID, 10)
+		if err := uc.recordAudit(ctx, audit.Event{
+			Type:    audit.PointsTransferred,
+			ActorID: &sender.ID,
+			UserID:  &sender.ID,
+			Amount:  &r.Sum,
+			Before:  &senderBalance.Current,
+			After:   audit.Ptr(senderBalance.Current.Sub(r.Sum)),
+			Details: map[string]string{"transfer_id": transferID, "direction": string(entity.TransferOut),
+				"recipient_id": strconv.FormatUint(uint64(recipient.ID), 10)},
+		}); err != nil {
+			return err
+		}
+		return uc.recordAudit(ctx, audit.Event{
+			Type:    audit.PointsTransferred,
+			ActorID: &sender.ID,
+			UserID:  &recipient.ID,
+			Amount:  &r.Sum,
+			Before:  &recipientBalance.Current,
+			After:   audit.Ptr(recipientBalance.Current.Add(r.Sum)),
+			Details: map[string]string{"transfer_id": transferID, "direction": string(entity.TransferIn),
+				"sender_id": strconv.FormatUint(uint64(sender.ID), 10)},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	transfer.Direction = entity.TransferOut
+	transfer.Login = recipient.Login
+	return transfer, nil
+}
+
+// transferLots заводит получателю по партии на каждую израсходованную партию
+// отправителя, чтобы переведенные баллы сгорали в свои прежние сроки.
+// Баллы, не покрытые партиями, переходят бессрочными, как и были у отправителя.
+func (uc *UserUseCase) transferLots(ctx context.Context,
+	recipientID uint,
+	amount entity.Points,
+	consumed []entity.PointLot) error {
+	for _, l := range consumed {
+		if err := uc.restoreLot(ctx, recipientID, "", l.Amount, l.ExpiresAt); err != nil {
+			return err
+		}
+		amount = amount.Sub(l.Amount)
+	}
+	return uc.restoreLot(ctx, recipientID, "", amount, nil)
+}
+
+// lockTransferBalances блокирует балансы обеих сторон по возрастанию ID, чтобы
+// встречные переводы не ждали друг друга, и возвращает баланс получателя
+func (uc *UserUseCase) lockTransferBalances(ctx context.Context, senderID, recipientID uint) (*entity.Balance, error) {
+	first, second := senderID, recipientID
+	if recipientID < senderID {
+		first, second = recipientID, senderID
+	}
+	balances := make(map[uint]*entity.Balance, 2)
+	for _, userID := range []uint{first, second} {
+		balance, err := uc.balance.GetBalanceForUpdate(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("GopherMartUseCase - TransferPoints: %w", err)
+		}
+		balances[userID] = balance
+	}
+	return balances[recipientID], nil
+}
+
+// GetUserTransfers страница отправленных и полученных переводов пользователя
+func (uc *UserUseCase) GetUserTransfers(ctx context.Context,
+	userID uint,
+	f entity.ListFilter) (*entity.TransferPage, error) {
+	f, err := f.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Statuses) > 0 {
+		return nil, fmt.Errorf("%w: transfers have no status", entity.ErrInvalidListFilter)
+	}
+	transfers, err := uc.balance.GetUserTransfers(ctx, userID, f)
+	if err != nil {
+		uc.Logger.ErrorCtx(ctx, "GetUserTransfers", zap.Error(err))
+		return nil, fmt.Errorf("GetUserTransfers: %w", err)
+	}
+	page := &entity.TransferPage{}
+	page.Transfers, page.NextCursor = trimPage(transfers, f.Limit, func(t entity.Transfer) entity.PageCursor {
+		return entity.PageCursor{At: t.CreatedAt, ID: t.ID}
+	})
+	return page, nil
+}
@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type transferMocks struct {
+	balance *mocks.MockBalanceUseCase
+	user    *mocks.MockAuthUseCase
+	lots    *mocks.MockPointLotRepository
+}
+
+var transferLimits = entity.TransferLimits{DailyAmount: entity.NewPoints(1000, 0), DailyCount: 3}
+
+func setupTransferUseCase(t *testing.T) (*UserUseCase, transferMocks) {
+	ctrl := gomock.NewController(t)
+	log, _ := logging.NewZapLogger(1)
+	m := transferMocks{
+		balance: mocks.NewMockBalanceUseCase(ctrl),
+		user:    mocks.NewMockAuthUseCase(ctrl),
+		lots:    mocks.NewMockPointLotRepository(ctrl),
+	}
+	uc := NewGopherMart(mocks.NewMockRepository(ctrl), m.balance, mocks.NewMockOrderUseCase(ctrl), m.user, log,
+		WithTransferLimits(transferLimits), WithPointExpiry(m.lots, entity.ExpiryPolicy{}))
+	return uc, m
+}
+
+func TestTransferPoints(t *testing.T) {
+	ctx := context.Background()
+	sender := &entity.User{ID: 9, Login: "alice"}
+	recipient := &entity.User{ID: 4, Login: "bob"}
+	request := entity.TransferRequest{Login: " bob ", Sum: entity.NewPoints(150, 0)}
+
+	t.Run("sender is debited and recipient credited", func(t *testing.T) {
+		uc, m := setupTransferUseCase(t)
+		m.user.EXPECT().GetUserByID(ctx, sender.ID).Return(sender, nil)
+		m.user.EXPECT().GetUserByLogin(ctx, entity.User{Login: "bob"}).Return(recipient, nil)
+		gomock.InOrder(
+			// балансы блокируются по возрастанию ID, а не по ролям
+			m.balance.EXPECT().GetBalanceForUpdate(ctx, recipient.ID).Return(&entity.Balance{}, nil),
+			m.balance.EXPECT().GetBalanceForUpdate(ctx, sender.ID).Return(&entity.Balance{}, nil),
+			m.balance.EXPECT().GetBalanceForUpdate(ctx, sender.ID).
+				Return(&entity.Balance{Current: entity.NewPoints(200, 0)}, nil),
+			m.balance.EXPECT().GetSentTransfers(ctx, sender.ID, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ uint, since time.Time) (entity.Points, int, error) {
+					assert.WithinDuration(t, time.Now().Add(-24*time.Hour), since, time.Minute)
+					return entity.NewPoints(800, 0), 2, nil
+				}),
+			m.lots.EXPECT().ConsumePointLots(ctx, sender.ID, request.Sum, gomock.Any()).Return(nil, nil),
+			m.balance.EXPECT().CreateTransferTx(ctx, entity.Transfer{
+				SenderID:    sender.ID,
+				RecipientID: recipient.ID,
+				Amount:      request.Sum,
+			}).Return(&entity.Transfer{ID: 11, SenderID: sender.ID, RecipientID: recipient.ID, Amount: request.Sum}, nil),
+			m.lots.EXPECT().CreatePointLot(ctx, entity.PointLot{
+				UserID: recipient.ID, Amount: request.Sum, Remaining: request.Sum,
+			}).Return(nil),
+		)
+
+		transfer, err := uc.TransferPoints(ctx, sender.ID, request)
+		require.NoError(t, err)
+		assert.Equal(t, int64(11), transfer.ID)
+		assert.Equal(t, entity.TransferOut, transfer.Direction)
+		assert.Equal(t, "bob", transfer.Login)
+	})
+
+	t.Run("each sender lot keeps its expiry", func(t *testing.T) {
+		uc, m := setupTransferUseCase(t)
+		soon := time.Now().Add(24 * time.Hour)
+		later := time.Now().Add(90 * 24 * time.Hour)
+		m.user.EXPECT().GetUserByID(ctx, sender.ID).Return(sender, nil)
+		m.user.EXPECT().GetUserByLogin(ctx, entity.User{Login: "bob"}).Return(recipient, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, recipient.ID).Return(&entity.Balance{}, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, sender.ID).
+			Return(&entity.Balance{Current: entity.NewPoints(200, 0)}, nil).Times(2)
+		m.balance.EXPECT().GetSentTransfers(ctx, sender.ID, gomock.Any()).Return(entity.Points(0), 0, nil)
+		m.lots.EXPECT().ConsumePointLots(ctx, sender.ID, request.Sum, gomock.Any()).Return([]entity.PointLot{
+			{ID: 1, Amount: entity.NewPoints(50, 0), ExpiresAt: &soon},
+			{ID: 2, Amount: entity.NewPoints(80, 0), ExpiresAt: &later},
+		}, nil)
+		m.balance.EXPECT().CreateTransferTx(ctx, gomock.Any()).
+			Return(&entity.Transfer{ID: 11, SenderID: sender.ID, RecipientID: recipient.ID, Amount: request.Sum}, nil)
+		var created []entity.PointLot
+		m.lots.EXPECT().CreatePointLot(ctx, gomock.Any()).Times(3).
+			DoAndReturn(func(_ context.Context, l entity.PointLot) error {
+				created = append(created, l)
+				return nil
+			})
+
+		_, err := uc.TransferPoints(ctx, sender.ID, request)
+		require.NoError(t, err)
+		require.Len(t, created, 3)
+		assert.Equal(t, entity.PointLot{UserID: recipient.ID, Amount: entity.NewPoints(50, 0),
+			Remaining: entity.NewPoints(50, 0), ExpiresAt: &soon}, created[0])
+		assert.Equal(t, entity.PointLot{UserID: recipient.ID, Amount: entity.NewPoints(80, 0),
+			Remaining: entity.NewPoints(80, 0), ExpiresAt: &later}, created[1])
+		// баллы вне партий переходят бессрочными
+		assert.Equal(t, entity.PointLot{UserID: recipient.ID, Amount: entity.NewPoints(20, 0),
+			Remaining: entity.NewPoints(20, 0)}, created[2])
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		uc, m := setupTransferUseCase(t)
+		m.user.EXPECT().GetUserByID(ctx, sender.ID).Return(sender, nil)
+		m.user.EXPECT().GetUserByLogin(ctx, entity.User{Login: "bob"}).Return(recipient, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, recipient.ID).Return(&entity.Balance{}, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, sender.ID).
+			Return(&entity.Balance{Current: entity.NewPoints(100, 0)}, nil).Times(2)
+
+		_, err := uc.TransferPoints(ctx, sender.ID, request)
+		assert.ErrorIs(t, err, entity.ErrInsufficientFunds)
+	})
+
+	t.Run("daily amount limit", func(t *testing.T) {
+		uc, m := setupTransferUseCase(t)
+		m.user.EXPECT().GetUserByID(ctx, sender.ID).Return(sender, nil)
+		m.user.EXPECT().GetUserByLogin(ctx, entity.User{Login: "bob"}).Return(recipient, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, recipient.ID).Return(&entity.Balance{}, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, sender.ID).
+			Return(&entity.Balance{Current: entity.NewPoints(500, 0)}, nil).Times(2)
+		m.balance.EXPECT().GetSentTransfers(ctx, sender.ID, gomock.Any()).Return(entity.NewPoints(900, 0), 1, nil)
+
+		_, err := uc.TransferPoints(ctx, sender.ID, request)
+		assert.ErrorIs(t, err, entity.ErrTransferLimit)
+	})
+
+	t.Run("daily count limit", func(t *testing.T) {
+		uc, m := setupTransferUseCase(t)
+		m.user.EXPECT().GetUserByID(ctx, sender.ID).Return(sender, nil)
+		m.user.EXPECT().GetUserByLogin(ctx, entity.User{Login: "bob"}).Return(recipient, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, recipient.ID).Return(&entity.Balance{}, nil)
+		m.balance.EXPECT().GetBalanceForUpdate(ctx, sender.ID).
+			Return(&entity.Balance{Current: entity.NewPoints(500, 0)}, nil).Times(2)
+		m.balance.EXPECT().GetSentTransfers(ctx, sender.ID, gomock.Any()).Return(entity.NewPoints(30, 0), 3, nil)
+
+		_, err := uc.TransferPoints(ctx, sender.ID, request)
+		assert.ErrorIs(t, err, entity.ErrTransferLimit)
+	})
+
+	t.Run("self transfer", func(t *testing.T) {
+		uc, m := setupTransferUseCase(t)
+		m.user.EXPECT().GetUserByID(ctx, sender.ID).Return(sender, nil)
+		m.user.EXPECT().GetUserByLogin(ctx, entity.User{Login: "alice"}).Return(sender, nil)
+
+		_, err := uc.TransferPoints(ctx, sender.ID, entity.TransferRequest{Login: "alice", Sum: request.Sum})
+		assert.ErrorIs(t, err, entity.ErrSelfTransfer)
+	})
+
+	t.Run("locked recipient", func(t *testing.T) {
+		uc, m := setupTransferUseCase(t)
+		now := time.Now()
+		m.user.EXPECT().GetUserByID(ctx, sender.ID).Return(sender, nil)
+		m.user.EXPECT().GetUserByLogin(ctx, entity.User{Login: "bob"}).
+			Return(&entity.User{ID: recipient.ID, Login: "bob", LockedAt: &now}, nil)
+
+		_, err := uc.TransferPoints(ctx, sender.ID, request)
+		assert.ErrorIs(t, err, entity.ErrUserLocked)
+	})
+
+	t.Run("unknown recipient", func(t *testing.T) {
+		uc, m := setupTransferUseCase(t)
+		m.user.EXPECT().GetUserByID(ctx, sender.ID).Return(sender, nil)
+		m.user.EXPECT().GetUserByLogin(ctx, entity.User{Login: "bob"}).Return(nil, entity.ErrUserDoesNotExist)
+
+		_, err := uc.TransferPoints(ctx, sender.ID, request)
+		assert.ErrorIs(t, err, entity.ErrUserDoesNotExist)
+	})
+
+	t.Run("sum must be positive", func(t *testing.T) {
+		uc, _ := setupTransferUseCase(t)
+
+		_, err := uc.TransferPoints(ctx, sender.ID, entity.TransferRequest{Login: "bob"})
+		assert.ErrorIs(t, err, entity.ErrInvalidTransfer)
+	})
+}
+
+func TestGetUserTransfers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("page with next cursor", func(t *testing.T) {
+		uc, m := setupTransferUseCase(t)
+		at := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+		transfers := []entity.Transfer{
+			{ID: 3, Direction: entity.TransferIn, Login: "bob", CreatedAt: at},
+			{ID: 2, Direction: entity.TransferOut, Login: "bob", CreatedAt: at.Add(-time.Hour)},
+		}
+		m.balance.EXPECT().GetUserTransfers(ctx, uint(9), gomock.Any()).Return(transfers, nil)
+
+		page, err := uc.GetUserTransfers(ctx, 9, entity.ListFilter{Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, transfers[:1], page.Transfers)
+		assert.Equal(t, entity.PageCursor{At: at, ID: 3}.Encode(), page.NextCursor)
+	})
+
+	t.Run("status filter is rejected", func(t *testing.T) {
+		uc, _ := setupTransferUseCase(t)
+
+		_, err := uc.GetUserTransfers(ctx, 9, entity.ListFilter{Statuses: []entity.OrderStatus{entity.OrderStatusNew}})
+		assert.ErrorIs(t, err, entity.ErrInvalidListFilter)
+	})
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-loyalty-system/internal/entity"
+	"go-loyalty-system/internal/usecase/repo"
+	"go-loyalty-system/internal/usecase/repo/mocks"
+	"go-loyalty-system/pkg/logging"
+	"go-loyalty-system/pkg/postgres"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithdrawBalance(t *testing.T) {
+	ctx := context.Background()
+	withdrawal := entity.Withdrawal{UserID: 7, OrderNumber: "2377225624", Amount: entity.NewPoints(100, 0)}
+	order := &entity.OrderResponse{ID: 3, Number: "2377225624"}
+
+	setup := func(t *testing.T) (*UserUseCase, *mocks.MockBalanceUseCase, *mocks.MockOrderUseCase) {
+		ctrl := gomock.NewController(t)
+		log, _ := logging.NewZapLogger(1)
+		balanceRepo := mocks.NewMockBalanceUseCase(ctrl)
+		orderRepo := mocks.NewMockOrderUseCase(ctrl)
+		tx := mocks.NewMockTransactor(ctrl)
+		tx.EXPECT().WithinTransaction(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+		uc := NewGopherMart(mocks.NewMockRepository(ctrl), balanceRepo, orderRepo,
+			mocks.NewMockAuthUseCase(ctrl), log, WithTransactor(tx))
+		return uc, balanceRepo, orderRepo
+	}
+
+	t.Run("balance is locked before the withdrawal", func(t *testing.T) {
+		uc, balanceRepo, orderRepo := setup(t)
+		gomock.InOrder(
+			balanceRepo.EXPECT().GetBalanceForUpdate(ctx, uint(7)).
+				Return(&entity.Balance{Current: entity.NewPoints(150, 0)}, nil),
+			orderRepo.EXPECT().SetOrders(ctx, uint(7), gomock.Any()).Return(nil),
+			orderRepo.EXPECT().GetOrderByNumber(ctx, "2377225624").Return(order, nil),
+			balanceRepo.EXPECT().CreateWithdrawalTx(ctx, withdrawal, order).Return(nil),
+			balanceRepo.EXPECT().UpdateBalanceTx(ctx, uint(7), entity.NewPoints(100, 0)).Return(nil),
+		)
+
+		assert.NoError(t, uc.WithdrawBalance(ctx, withdrawal))
+	})
+
+	t.Run("insufficient funds creates nothing", func(t *testing.T) {
+		uc, balanceRepo, _ := setup(t)
+		balanceRepo.EXPECT().GetBalanceForUpdate(ctx, uint(7)).
+			Return(&entity.Balance{Current: entity.NewPoints(99, 99)}, nil)
+
+		assert.ErrorIs(t, uc.WithdrawBalance(ctx, withdrawal), entity.ErrInsufficientFunds)
+	})
+
+	t.Run("withdrawal error aborts the transaction", func(t *testing.T) {
+		uc, balanceRepo, orderRepo := setup(t)
+		expectedErr := errors.New("database error")
+		balanceRepo.EXPECT().GetBalanceForUpdate(ctx, uint(7)).
+			Return(&entity.Balance{Current: entity.NewPoints(150, 0)}, nil)
+		orderRepo.EXPECT().SetOrders(ctx, uint(7), gomock.Any()).Return(nil)
+		orderRepo.EXPECT().GetOrderByNumber(ctx, "2377225624").Return(order, nil)
+		balanceRepo.EXPECT().CreateWithdrawalTx(ctx, withdrawal, order).Return(expectedErr)
+
+		assert.ErrorIs(t, uc.WithdrawBalance(ctx, withdrawal), expectedErr)
+	})
+}
+
+// TestWithdrawBalanceConcurrent запускает параллельные списания на реальной базе.
+// Нужна переменная TEST_DATABASE_URI, миграции накатываются перед тестом.
+func TestWithdrawBalanceConcurrent(t *testing.T) {
+	uri := os.Getenv("TEST_DATABASE_URI")
+	if uri == "" {
+		t.Skip("TEST_DATABASE_URI is not set")
+	}
+	m, err := migrate.New("file://../../migrations", uri)
+	require.NoError(t, err)
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		require.NoError(t, err)
+	}
+	_, _ = m.Close()
+
+	const workers = 10
+	ctx := context.Background()
+	log, _ := logging.NewZapLogger(1)
+	pg, err := postgres.NewPostgres(uri, postgres.MaxPoolSize(workers+2))
+	require.NoError(t, err)
+	defer pg.Close()
+
+	uc := NewGopherMart(
+		repo.NewOrderAccrualRepository(pg, log, pg.Pool),
+		repo.NewBalanceRepository(pg, log, pg.Pool),
+		repo.NewOrderepository(pg, log, pg.Pool),
+		repo.NewUserrepository(pg, log, pg.Pool),
+		log,
+		WithTransactor(repo.NewTransactor(pg, log, pg.Pool)))
+
+	suffix := time.Now().UnixNano()
+	login := fmt.Sprintf("withdraw-race-%d", suffix)
+	require.NoError(t, uc.RegisterUser(ctx, entity.User{Login: login, Email: login + "@example.com", Password: "secret"}))
+	user, err := uc.GetUserByLogin(ctx, entity.User{Login: login})
+	require.NoError(t, err)
+	require.NoError(t, uc.AdjustUserBalance(ctx, user.ID, entity.NewPoints(100, 0), "race test"))
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		refused   int
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := uc.WithdrawBalance(ctx, entity.Withdrawal{
+				UserID:      user.ID,
+				OrderNumber: fmt.Sprintf("%d%02d", suffix%1_000_000_000, i),
+				Amount:      entity.NewPoints(30, 0),
+				CreatedAt:   time.Now(),
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, entity.ErrInsufficientFunds):
+				refused++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 3, succeeded)
+	assert.Equal(t, workers-3, refused)
+
+	check, err := uc.VerifyUserBalance(ctx, user.ID, false)
+	require.NoError(t, err)
+	assert.True(t, check.Consistent)
+	assert.Equal(t, entity.NewPoints(10, 0), check.Ledger.Current)
+	assert.Equal(t, entity.NewPoints(90, 0), check.Ledger.Withdrawn)
+	assert.False(t, check.Projection.Current.IsNegative())
+}
@@ -0,0 +1 @@
+DROP TABLE accrual_jobs;
\ No newline at end of file
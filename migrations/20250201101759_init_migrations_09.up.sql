@@ -0,0 +1,23 @@
+CREATE TABLE accrual_jobs (
+  id BIGSERIAL PRIMARY KEY,
+  order_id INTEGER NOT NULL UNIQUE REFERENCES orders(id),
+  state VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  locked_by VARCHAR(150) NULL,
+  locked_until TIMESTAMP NULL,
+  last_error TEXT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_accrual_jobs_state_next_run ON accrual_jobs(state, next_run_at);
+
+-- заказы, которые ждали обработки в памяти до появления очереди
+INSERT INTO accrual_jobs (order_id)
+SELECT o.id
+FROM orders o
+LEFT JOIN accrual a ON a.order_id = o.id
+LEFT JOIN accrual_statuses s ON a.status_id = s.id
+WHERE (a.id IS NULL OR s.status NOT IN ('PROCESSED', 'INVALID'))
+  AND NOT EXISTS (SELECT 1 FROM withdrawals w WHERE w.order_id = o.id);
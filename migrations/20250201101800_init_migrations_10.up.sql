@@ -0,0 +1,9 @@
+CREATE TABLE order_items (
+  id BIGSERIAL PRIMARY KEY,
+  order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  description TEXT NOT NULL,
+  price DECIMAL NOT NULL CHECK (price >= 0),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_items_order_id ON order_items(order_id);
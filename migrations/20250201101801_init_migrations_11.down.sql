@@ -0,0 +1,6 @@
+ALTER TABLE order_items ALTER COLUMN price TYPE DECIMAL;
+ALTER TABLE withdrawals ALTER COLUMN amount TYPE DECIMAL;
+ALTER TABLE accrual ALTER COLUMN accrual TYPE DECIMAL;
+ALTER TABLE balance
+  ALTER COLUMN current_balance TYPE DECIMAL,
+  ALTER COLUMN withdrawn TYPE DECIMAL;
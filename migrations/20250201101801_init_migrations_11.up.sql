@@ -0,0 +1,13 @@
+-- суммы баллов хранятся с точностью до сотых, как entity.Points
+ALTER TABLE balance
+  ALTER COLUMN current_balance TYPE NUMERIC(14, 2) USING round(current_balance, 2),
+  ALTER COLUMN withdrawn TYPE NUMERIC(14, 2) USING round(withdrawn, 2);
+
+ALTER TABLE accrual
+  ALTER COLUMN accrual TYPE NUMERIC(14, 2) USING round(accrual, 2);
+
+ALTER TABLE withdrawals
+  ALTER COLUMN amount TYPE NUMERIC(14, 2) USING round(amount, 2);
+
+ALTER TABLE order_items
+  ALTER COLUMN price TYPE NUMERIC(14, 2) USING round(price, 2);
@@ -0,0 +1,7 @@
+ALTER TABLE balance DROP CONSTRAINT balance_user_id_key;
+ALTER TABLE balance ALTER COLUMN withdrawn DROP NOT NULL;
+ALTER TABLE balance ALTER COLUMN withdrawn DROP DEFAULT;
+
+DROP TABLE ledger_entries;
+DROP FUNCTION ledger_entries_check_balanced();
+DROP FUNCTION ledger_entries_append_only();
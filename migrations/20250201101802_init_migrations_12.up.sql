@@ -0,0 +1,85 @@
+CREATE TABLE ledger_entries (
+  id BIGSERIAL PRIMARY KEY,
+  transaction_id UUID NOT NULL,
+  account VARCHAR(20) NOT NULL CHECK (account IN ('USER', 'ISSUED', 'REDEEMED', 'ADJUSTMENT')),
+  user_id INTEGER NOT NULL REFERENCES users(id),
+  kind VARCHAR(20) NOT NULL CHECK (kind IN ('ACCRUAL', 'WITHDRAWAL', 'ADJUSTMENT', 'REVERSAL')),
+  amount NUMERIC(14, 2) NOT NULL CHECK (amount <> 0),
+  order_id INTEGER NULL REFERENCES orders(id),
+  withdrawal_id INTEGER NULL REFERENCES withdrawals(id),
+  reverses_transaction_id UUID NULL,
+  description TEXT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_ledger_entries_user_account ON ledger_entries(user_id, account);
+CREATE INDEX idx_ledger_entries_transaction ON ledger_entries(transaction_id);
+-- одно начисление на заказ и одна проводка на списание
+CREATE UNIQUE INDEX idx_ledger_entries_accrual_order ON ledger_entries(order_id, account) WHERE kind = 'ACCRUAL';
+CREATE UNIQUE INDEX idx_ledger_entries_withdrawal ON ledger_entries(withdrawal_id, account) WHERE kind = 'WITHDRAWAL';
+
+-- журнал только дополняется
+CREATE FUNCTION ledger_entries_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'ledger_entries is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entries_no_update
+  BEFORE UPDATE OR DELETE ON ledger_entries
+  FOR EACH ROW EXECUTE FUNCTION ledger_entries_append_only();
+
+CREATE TRIGGER ledger_entries_no_truncate
+  BEFORE TRUNCATE ON ledger_entries
+  FOR EACH STATEMENT EXECUTE FUNCTION ledger_entries_append_only();
+
+-- проводка сбалансирована: к коммиту сумма по transaction_id равна нулю
+CREATE FUNCTION ledger_entries_check_balanced() RETURNS trigger AS $$
+BEGIN
+  IF (SELECT SUM(amount) FROM ledger_entries WHERE transaction_id = NEW.transaction_id) <> 0 THEN
+    RAISE EXCEPTION 'ledger transaction % is not balanced', NEW.transaction_id;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced
+  AFTER INSERT ON ledger_entries
+  DEFERRABLE INITIALLY DEFERRED
+  FOR EACH ROW EXECUTE FUNCTION ledger_entries_check_balanced();
+
+-- переносим историю: начисления по обработанным заказам и списания
+WITH src AS (
+  SELECT gen_random_uuid() AS tx, o.user_id, o.id AS order_id, round(a.accrual, 2) AS amount
+  FROM accrual a
+  JOIN orders o ON o.id = a.order_id
+  JOIN accrual_statuses s ON s.id = a.status_id
+  WHERE s.status = 'PROCESSED' AND round(a.accrual, 2) > 0
+)
+INSERT INTO ledger_entries (transaction_id, account, user_id, kind, amount, order_id)
+SELECT tx, 'USER', user_id, 'ACCRUAL', amount, order_id FROM src
+UNION ALL
+SELECT tx, 'ISSUED', user_id, 'ACCRUAL', -amount, order_id FROM src;
+
+WITH src AS (
+  SELECT gen_random_uuid() AS tx, w.user_id, w.order_id, w.id AS withdrawal_id, w.amount
+  FROM withdrawals w
+  WHERE w.amount > 0
+)
+INSERT INTO ledger_entries (transaction_id, account, user_id, kind, amount, order_id, withdrawal_id)
+SELECT tx, 'USER', user_id, 'WITHDRAWAL', -amount, order_id, withdrawal_id FROM src
+UNION ALL
+SELECT tx, 'REDEEMED', user_id, 'WITHDRAWAL', amount, order_id, withdrawal_id FROM src;
+
+-- balance становится проекцией журнала: одна строка на пользователя
+DELETE FROM balance;
+ALTER TABLE balance ALTER COLUMN withdrawn SET DEFAULT 0;
+ALTER TABLE balance ALTER COLUMN withdrawn SET NOT NULL;
+ALTER TABLE balance ADD CONSTRAINT balance_user_id_key UNIQUE (user_id);
+
+INSERT INTO balance (user_id, current_balance, withdrawn)
+SELECT user_id,
+       COALESCE(SUM(amount) FILTER (WHERE account = 'USER'), 0),
+       COALESCE(SUM(amount) FILTER (WHERE account = 'REDEEMED'), 0)
+FROM ledger_entries
+GROUP BY user_id;
@@ -0,0 +1,12 @@
+CREATE TABLE idempotency_keys (
+  user_id INTEGER NOT NULL REFERENCES users(id),
+  idempotency_key VARCHAR(255) NOT NULL,
+  fingerprint CHAR(64) NOT NULL,
+  state VARCHAR(20) NOT NULL DEFAULT 'IN_PROGRESS',
+  status_code INTEGER NULL,
+  content_type VARCHAR(100) NULL,
+  response_body BYTEA NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (user_id, idempotency_key)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- всё, что не похоже на bcrypt-хеш, осталось от хранения паролей открытым текстом
+UPDATE users
+SET password_reset_required = TRUE
+WHERE password !~ '^\$2[abxy]\$[0-9]{2}\$[./A-Za-z0-9]{53}$';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_token_family_id;
+
+ALTER TABLE token
+  DROP COLUMN revoked_at,
+  DROP COLUMN expires_at,
+  DROP COLUMN family_id,
+  DROP COLUMN kind;
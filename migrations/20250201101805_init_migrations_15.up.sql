@@ -0,0 +1,17 @@
+ALTER TABLE token
+  ADD COLUMN kind VARCHAR(10) NOT NULL DEFAULT 'ACCESS',
+  ADD COLUMN family_id UUID NULL,
+  ADD COLUMN expires_at TIMESTAMP NULL,
+  ADD COLUMN revoked_at TIMESTAMP NULL;
+
+-- used_at раньше заполнялся нулевой датой
+UPDATE token SET used_at = NULL WHERE used_at < '1970-01-01';
+
+-- старые токены выдавались на час и каждый сам себе семья
+UPDATE token SET family_id = id, expires_at = creation_date + INTERVAL '1 hour';
+
+ALTER TABLE token
+  ALTER COLUMN family_id SET NOT NULL,
+  ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX idx_token_family_id ON token(family_id);
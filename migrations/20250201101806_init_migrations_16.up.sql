@@ -0,0 +1,13 @@
+CREATE TABLE roles (
+    name VARCHAR(20) PRIMARY KEY,
+    description VARCHAR(150) NOT NULL
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('customer', 'Покупатель, работает только со своим счетом'),
+    ('support', 'Сотрудник поддержки'),
+    ('admin', 'Администратор'),
+    ('merchant', 'Учетная запись магазина');
+
+ALTER TABLE users
+  ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'customer' REFERENCES roles(name);
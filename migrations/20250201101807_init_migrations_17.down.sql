@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS admin_audit;
+DROP FUNCTION IF EXISTS admin_audit_append_only();
+
+ALTER TABLE users DROP COLUMN locked_at;
@@ -0,0 +1,28 @@
+ALTER TABLE users ADD COLUMN locked_at TIMESTAMP NULL;
+
+CREATE TABLE admin_audit (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id INTEGER NOT NULL REFERENCES users(id),
+    action VARCHAR(30) NOT NULL,
+    target_user_id INTEGER NULL REFERENCES users(id),
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_admin_audit_target_user_id ON admin_audit(target_user_id, created_at);
+CREATE INDEX idx_admin_audit_actor_id ON admin_audit(actor_id, created_at);
+
+-- журнал только дополняется
+CREATE FUNCTION admin_audit_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'admin_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER admin_audit_no_update
+  BEFORE UPDATE OR DELETE ON admin_audit
+  FOR EACH ROW EXECUTE FUNCTION admin_audit_append_only();
+
+CREATE TRIGGER admin_audit_no_truncate
+  BEFORE TRUNCATE ON admin_audit
+  FOR EACH STATEMENT EXECUTE FUNCTION admin_audit_append_only();
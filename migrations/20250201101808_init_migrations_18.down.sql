@@ -0,0 +1,8 @@
+CREATE INDEX idx_orders_user_id ON orders(user_id);
+DROP INDEX IF EXISTS idx_withdrawals_user_created;
+DROP INDEX IF EXISTS idx_orders_user_status_uploaded;
+DROP INDEX IF EXISTS idx_orders_user_uploaded;
+
+ALTER TABLE orders
+  ALTER COLUMN uploaded_at DROP NOT NULL,
+  ALTER COLUMN uploaded_at DROP DEFAULT;
@@ -0,0 +1,13 @@
+-- постраничная выдача идет по паре (uploaded_at, id), NULL в ней недопустим
+UPDATE orders SET uploaded_at = creation_date WHERE uploaded_at IS NULL;
+
+ALTER TABLE orders
+  ALTER COLUMN uploaded_at SET DEFAULT CURRENT_TIMESTAMP,
+  ALTER COLUMN uploaded_at SET NOT NULL;
+
+-- индексы покрывают выборку пользователя в обоих направлениях сортировки,
+-- отдельный индекс по user_id больше не нужен
+CREATE INDEX idx_orders_user_uploaded ON orders(user_id, uploaded_at, id);
+CREATE INDEX idx_orders_user_status_uploaded ON orders(user_id, status_id, uploaded_at, id);
+CREATE INDEX idx_withdrawals_user_created ON withdrawals(user_id, created_at, id);
+DROP INDEX IF EXISTS idx_orders_user_id;
@@ -0,0 +1,11 @@
+CREATE TABLE order_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    order_id INTEGER NOT NULL REFERENCES orders(id),
+    status VARCHAR(20) NOT NULL,
+    accrual NUMERIC(14, 2) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- догрузка пропущенных событий по Last-Event-ID
+CREATE INDEX idx_order_events_user_id ON order_events(user_id, id);
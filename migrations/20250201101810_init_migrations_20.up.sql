@@ -0,0 +1,2 @@
+-- traceparent запроса, поставившего заказ в очередь: воркер ссылается на него из своей трассы
+ALTER TABLE accrual_jobs ADD COLUMN traceparent VARCHAR(64) NULL;
@@ -0,0 +1,35 @@
+-- без внешних ключей: журнал переживает удаление пользователей и хранит попытки входа под несуществующими логинами
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(40) NOT NULL,
+    actor_id INTEGER NULL,
+    user_id INTEGER NULL,
+    ip VARCHAR(64) NULL,
+    request_id VARCHAR(64) NULL,
+    order_number VARCHAR(255) NULL,
+    amount NUMERIC(14, 2) NULL,
+    balance_before NUMERIC(14, 2) NULL,
+    balance_after NUMERIC(14, 2) NULL,
+    details JSONB NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at, id);
+CREATE INDEX idx_audit_events_user_id ON audit_events(user_id, created_at);
+CREATE INDEX idx_audit_events_actor_id ON audit_events(actor_id, created_at);
+CREATE INDEX idx_audit_events_request_id ON audit_events(request_id);
+
+-- журнал только дополняется
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update
+  BEFORE UPDATE OR DELETE ON audit_events
+  FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+  BEFORE TRUNCATE ON audit_events
+  FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
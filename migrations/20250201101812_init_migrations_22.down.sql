@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_ledger_entries_reversal;
+
+ALTER TABLE withdrawals
+  DROP CONSTRAINT IF EXISTS withdrawals_reversed_amount_check,
+  DROP COLUMN IF EXISTS reversed_amount;
@@ -0,0 +1,6 @@
+-- сколько баллов списания уже возвращено; база не даст вернуть больше списанного
+ALTER TABLE withdrawals
+  ADD COLUMN reversed_amount NUMERIC(14, 2) NOT NULL DEFAULT 0,
+  ADD CONSTRAINT withdrawals_reversed_amount_check CHECK (reversed_amount >= 0 AND reversed_amount <= amount);
+
+CREATE INDEX idx_ledger_entries_reversal ON ledger_entries(withdrawal_id) WHERE kind = 'REVERSAL';
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS point_lots;
+
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_kind_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_kind_check
+  CHECK (kind IN ('ACCRUAL', 'WITHDRAWAL', 'ADJUSTMENT', 'REVERSAL'));
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_account_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_account_check
+  CHECK (account IN ('USER', 'ISSUED', 'REDEEMED', 'ADJUSTMENT'));
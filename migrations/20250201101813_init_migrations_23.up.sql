@@ -0,0 +1,26 @@
+-- партии начисленных баллов: списания расходуют их по порядку сгорания
+CREATE TABLE point_lots (
+  id BIGSERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id),
+  order_id INTEGER NULL REFERENCES orders(id),
+  amount NUMERIC(14, 2) NOT NULL CHECK (amount > 0),
+  remaining NUMERIC(14, 2) NOT NULL CHECK (remaining >= 0 AND remaining <= amount),
+  expires_at TIMESTAMP NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_point_lots_user_open ON point_lots(user_id, expires_at, id) WHERE remaining > 0;
+CREATE INDEX idx_point_lots_expires_at ON point_lots(expires_at) WHERE remaining > 0;
+
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_kind_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_kind_check
+  CHECK (kind IN ('ACCRUAL', 'WITHDRAWAL', 'ADJUSTMENT', 'REVERSAL', 'EXPIRY'));
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_account_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_account_check
+  CHECK (account IN ('USER', 'ISSUED', 'REDEEMED', 'ADJUSTMENT', 'EXPIRED'));
+
+-- баланс, накопленный до партий, переносится одной бессрочной партией
+INSERT INTO point_lots (user_id, amount, remaining)
+SELECT user_id, current_balance, current_balance
+FROM balance
+WHERE current_balance > 0;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS balance_holds;
+ALTER TABLE balance DROP COLUMN IF EXISTS held;
+
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_kind_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_kind_check
+  CHECK (kind IN ('ACCRUAL', 'WITHDRAWAL', 'ADJUSTMENT', 'REVERSAL', 'EXPIRY'));
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_account_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_account_check
+  CHECK (account IN ('USER', 'ISSUED', 'REDEEMED', 'ADJUSTMENT', 'EXPIRED'));
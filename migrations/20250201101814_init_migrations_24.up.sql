@@ -0,0 +1,27 @@
+-- резервы баллов под неоплаченные заказы: списываются после оплаты или возвращаются
+ALTER TABLE balance ADD COLUMN held NUMERIC(14, 2) NOT NULL DEFAULT 0 CHECK (held >= 0);
+
+CREATE TABLE balance_holds (
+  id BIGSERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id),
+  order_number VARCHAR(20) NOT NULL,
+  amount NUMERIC(14, 2) NOT NULL CHECK (amount > 0),
+  captured_amount NUMERIC(14, 2) NULL CHECK (captured_amount > 0 AND captured_amount <= amount),
+  status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE'
+    CHECK (status IN ('ACTIVE', 'CAPTURED', 'RELEASED', 'EXPIRED')),
+  points_expire_at TIMESTAMP NULL,
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  settled_at TIMESTAMP NULL
+);
+
+-- на заказ не больше одного действующего резерва
+CREATE UNIQUE INDEX idx_balance_holds_active_order ON balance_holds(order_number) WHERE status = 'ACTIVE';
+CREATE INDEX idx_balance_holds_expires_at ON balance_holds(expires_at) WHERE status = 'ACTIVE';
+
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_kind_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_kind_check
+  CHECK (kind IN ('ACCRUAL', 'WITHDRAWAL', 'ADJUSTMENT', 'REVERSAL', 'EXPIRY', 'HOLD', 'RELEASE'));
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_account_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_account_check
+  CHECK (account IN ('USER', 'ISSUED', 'REDEEMED', 'ADJUSTMENT', 'EXPIRED', 'HELD'));
@@ -0,0 +1,19 @@
+-- переводы баллов между пользователями
+CREATE TABLE transfers (
+  id BIGSERIAL PRIMARY KEY,
+  sender_id INTEGER NOT NULL REFERENCES users(id),
+  recipient_id INTEGER NOT NULL REFERENCES users(id),
+  amount NUMERIC(14, 2) NOT NULL CHECK (amount > 0),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CHECK (sender_id <> recipient_id)
+);
+
+CREATE INDEX idx_transfers_sender ON transfers(sender_id, created_at, id);
+CREATE INDEX idx_transfers_recipient ON transfers(recipient_id, created_at, id);
+
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_kind_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_kind_check
+  CHECK (kind IN ('ACCRUAL', 'WITHDRAWAL', 'ADJUSTMENT', 'REVERSAL', 'EXPIRY', 'HOLD', 'RELEASE', 'TRANSFER'));
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_account_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_account_check
+  CHECK (account IN ('USER', 'ISSUED', 'REDEEMED', 'ADJUSTMENT', 'EXPIRED', 'HELD', 'TRANSFER'));
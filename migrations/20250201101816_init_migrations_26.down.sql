@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_ledger_entries_user_accrual;
+DROP INDEX IF EXISTS idx_ledger_entries_tier_bonus_order;
+
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_kind_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_kind_check
+  CHECK (kind IN ('ACCRUAL', 'WITHDRAWAL', 'ADJUSTMENT', 'REVERSAL', 'EXPIRY', 'HOLD', 'RELEASE', 'TRANSFER'));
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_account_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_account_check
+  CHECK (account IN ('USER', 'ISSUED', 'REDEEMED', 'ADJUSTMENT', 'EXPIRED', 'HELD', 'TRANSFER'));
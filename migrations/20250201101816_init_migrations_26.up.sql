@@ -0,0 +1,12 @@
+-- надбавка уровня участия — отдельная проводка TIER_BONUS на счет BONUS рядом с начислением
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_kind_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_kind_check
+  CHECK (kind IN ('ACCRUAL', 'WITHDRAWAL', 'ADJUSTMENT', 'REVERSAL', 'EXPIRY', 'HOLD', 'RELEASE', 'TRANSFER', 'TIER_BONUS'));
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_account_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_account_check
+  CHECK (account IN ('USER', 'ISSUED', 'REDEEMED', 'ADJUSTMENT', 'EXPIRED', 'HELD', 'TRANSFER', 'BONUS'));
+
+-- одна надбавка на заказ
+CREATE UNIQUE INDEX idx_ledger_entries_tier_bonus_order ON ledger_entries(order_id, account) WHERE kind = 'TIER_BONUS';
+-- сумма начислений за окно уровня
+CREATE INDEX idx_ledger_entries_user_accrual ON ledger_entries(user_id, created_at) WHERE kind = 'ACCRUAL' AND account = 'USER';
@@ -0,0 +1,2 @@
+-- момент регистрации заказа в системе начислений: повторные опросы статуса его больше не отправляют
+ALTER TABLE accrual_jobs ADD COLUMN registered_at TIMESTAMP NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_orders_merchant_number;
+ALTER TABLE orders DROP COLUMN merchant_id;
@@ -0,0 +1,4 @@
+-- учетная запись магазина, загрузившего заказ через /api/merchant/orders; по ней магазин возвращает баллы только за свои заказы
+ALTER TABLE orders ADD COLUMN merchant_id INTEGER NULL REFERENCES users(id);
+
+CREATE INDEX idx_orders_merchant_number ON orders(merchant_id, number) WHERE merchant_id IS NOT NULL;
@@ -1,6 +1,7 @@
 package httpserver
 
 import (
+	"context"
 	"net"
 	"time"
 )
@@ -30,3 +31,19 @@ func ShutdownTimeout(timeout time.Duration) Option {
 		s.shutdownTimeout = timeout
 	}
 }
+
+func Address(addr string) Option {
+	return func(s *Server) {
+		s.Server.Addr = addr
+	}
+}
+
+// BaseContext задает родительский контекст запросов. Его отмена завершает
+// долгие запросы, например потоки SSE, которых Shutdown сам не прерывает.
+func BaseContext(ctx context.Context) Option {
+	return func(s *Server) {
+		s.Server.BaseContext = func(net.Listener) context.Context {
+			return ctx
+		}
+	}
+}
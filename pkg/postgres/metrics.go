@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type poolMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	value     func(s *pgxpool.Stat) float64
+}
+
+// poolCollector снимает статистику пула pgxpool в момент сбора метрик
+type poolCollector struct {
+	pool    *pgxpool.Pool
+	metrics []poolMetric
+}
+
+// Collector возвращает сборщик статистики пула соединений для Prometheus
+func (p *Postgres) Collector(namespace string) prometheus.Collector {
+	metric := func(name, help string, t prometheus.ValueType, value func(s *pgxpool.Stat) float64) poolMetric {
+		return poolMetric{
+			desc:      prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil),
+			valueType: t,
+			value:     value,
+		}
+	}
+	return &poolCollector{
+		pool: p.Pool,
+		metrics: []poolMetric{
+			metric("max_conns", "Maximum size of the pool.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) }),
+			metric("total_conns", "Connections currently open.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) }),
+			metric("acquired_conns", "Connections currently in use.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) }),
+			metric("idle_conns", "Idle connections.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) }),
+			metric("constructing_conns", "Connections being established.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.ConstructingConns()) }),
+			metric("acquires_total", "Successful connection acquires.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) }),
+			metric("acquire_duration_seconds_total", "Total time spent acquiring connections.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() }),
+			metric("empty_acquires_total", "Acquires that had to wait for a connection.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) }),
+			metric("canceled_acquires_total", "Acquires canceled by context.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.CanceledAcquireCount()) }),
+			metric("new_conns_total", "Connections opened.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.NewConnsCount()) }),
+			metric("max_lifetime_destroys_total", "Connections closed after reaching max lifetime.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.MaxLifetimeDestroyCount()) }),
+			metric("max_idle_destroys_total", "Connections closed after reaching max idle time.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.MaxIdleDestroyCount()) }),
+		},
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics {
+		ch <- m.desc
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	for _, m := range c.metrics {
+		ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.value(stat))
+	}
+}
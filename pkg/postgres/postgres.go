@@ -46,6 +46,7 @@ func NewPostgres(url string, opts ...Option) (*Postgres, error) {
 	}
 
 	poolConfig.MaxConns = pg.maxPoolSize
+	poolConfig.ConnConfig.Tracer = newQueryTracer()
 
 	for pg.connAttempts > 0 {
 		pg.Pool, err = pgxpool.NewWithConfig(context.Background(), poolConfig)